@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 
@@ -21,6 +25,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -40,6 +46,18 @@ var log = logf.Logger.WithName("entrypoint")
 func main() {
 	metrics.DefaultBindAddress = ":60000"
 
+	// Set the log verbosity first so that it applies to everything that
+	// follows.  Verbosity 1 enables debug logs for each reconcile step.
+	if v := os.Getenv("LOG_VERBOSITY"); len(v) != 0 {
+		verbosity, err := strconv.Atoi(v)
+		if err != nil || verbosity < 0 {
+			log.Error(fmt.Errorf("invalid value %q", v), "'LOG_VERBOSITY' environment variable must be a non-negative integer")
+			os.Exit(1)
+		}
+		logf.SetVerbosity(verbosity)
+		log.Info("using log verbosity", "verbosity", verbosity)
+	}
+
 	// Get a kube client.
 	kubeConfig, err := config.GetConfig()
 	if err != nil {
@@ -59,6 +77,22 @@ func main() {
 	}
 	log.Info("using operator namespace", "namespace", operatorNamespace)
 
+	operandNamespace := os.Getenv("OPERAND_NAMESPACE")
+	if len(operandNamespace) == 0 {
+		operandNamespace = controller.DefaultOperandNamespace
+	}
+	log.Info("using operand namespace", "namespace", operandNamespace)
+
+	monitoringNamespace := os.Getenv("MONITORING_NAMESPACE")
+	if len(monitoringNamespace) == 0 {
+		monitoringNamespace = controller.DefaultMonitoringNamespace
+	}
+	if msgs := validation.IsDNS1123Label(monitoringNamespace); len(msgs) != 0 {
+		log.Error(fmt.Errorf("invalid namespace %q: %s", monitoringNamespace, strings.Join(msgs, "; ")), "'MONITORING_NAMESPACE' environment variable must be a namespace name")
+		os.Exit(1)
+	}
+	log.Info("using monitoring namespace", "namespace", monitoringNamespace)
+
 	ingressControllerImage := os.Getenv("IMAGE")
 	if len(ingressControllerImage) == 0 {
 		log.Error(fmt.Errorf("missing environment variable"), "'IMAGE' environment variable must be set")
@@ -70,6 +104,147 @@ func main() {
 		log.Info("RELEASE_VERSION environment variable missing", "release version", controller.UnknownVersionValue)
 	}
 
+	operandLabels, err := parseOperandMetadata("OPERAND_LABELS", true)
+	if err != nil {
+		log.Error(err, "invalid 'OPERAND_LABELS' environment variable")
+		os.Exit(1)
+	}
+	operandAnnotations, err := parseOperandMetadata("OPERAND_ANNOTATIONS", false)
+	if err != nil {
+		log.Error(err, "invalid 'OPERAND_ANNOTATIONS' environment variable")
+		os.Exit(1)
+	}
+
+	maxLoadBalancerIngressControllers := 0
+	if v := os.Getenv("MAX_LOAD_BALANCER_INGRESSCONTROLLERS"); len(v) != 0 {
+		maxLoadBalancerIngressControllers, err = strconv.Atoi(v)
+		if err != nil || maxLoadBalancerIngressControllers < 0 {
+			log.Error(fmt.Errorf("invalid value %q", v), "'MAX_LOAD_BALANCER_INGRESSCONTROLLERS' environment variable must be a non-negative integer")
+			os.Exit(1)
+		}
+		log.Info("limiting load balancer ingresscontrollers", "max", maxLoadBalancerIngressControllers)
+	}
+
+	operandEventQPS := controller.DefaultOperandEventQPS
+	if v := os.Getenv("OPERAND_EVENT_QPS"); len(v) != 0 {
+		operandEventQPS, err = strconv.ParseFloat(v, 64)
+		if err != nil || operandEventQPS < 0 {
+			log.Error(fmt.Errorf("invalid value %q", v), "'OPERAND_EVENT_QPS' environment variable must be a non-negative number")
+			os.Exit(1)
+		}
+	}
+	operandEventBurst := controller.DefaultOperandEventBurst
+	if v := os.Getenv("OPERAND_EVENT_BURST"); len(v) != 0 {
+		operandEventBurst, err = strconv.Atoi(v)
+		if err != nil || operandEventBurst < 1 {
+			log.Error(fmt.Errorf("invalid value %q", v), "'OPERAND_EVENT_BURST' environment variable must be a positive integer")
+			os.Exit(1)
+		}
+	}
+	log.Info("rate limiting operand events", "qps", operandEventQPS, "burst", operandEventBurst)
+
+	clientQPS := float32(operator.DefaultClientQPS)
+	if v := os.Getenv("CLIENT_QPS"); len(v) != 0 {
+		qps, err := strconv.ParseFloat(v, 32)
+		if err != nil || qps <= 0 {
+			log.Error(fmt.Errorf("invalid value %q", v), "'CLIENT_QPS' environment variable must be a positive number")
+			os.Exit(1)
+		}
+		clientQPS = float32(qps)
+	}
+	clientBurst := operator.DefaultClientBurst
+	if v := os.Getenv("CLIENT_BURST"); len(v) != 0 {
+		clientBurst, err = strconv.Atoi(v)
+		if err != nil || clientBurst < 1 {
+			log.Error(fmt.Errorf("invalid value %q", v), "'CLIENT_BURST' environment variable must be a positive integer")
+			os.Exit(1)
+		}
+	}
+	log.Info("rate limiting api requests", "qps", clientQPS, "burst", clientBurst)
+
+	unmanagedIngressControllers := []string{}
+	for _, name := range strings.Split(os.Getenv("UNMANAGED_INGRESSCONTROLLERS"), ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) != 0 {
+			log.Error(fmt.Errorf("invalid name %q: %s", name, strings.Join(msgs, "; ")), "'UNMANAGED_INGRESSCONTROLLERS' environment variable must be a comma-separated list of ingresscontroller names")
+			os.Exit(1)
+		}
+		unmanagedIngressControllers = append(unmanagedIngressControllers, name)
+	}
+	if len(unmanagedIngressControllers) != 0 {
+		log.Info("not managing ingresscontrollers", "names", unmanagedIngressControllers)
+	}
+
+	ingressAddressesBindAddress := os.Getenv("INGRESS_ADDRESSES_BIND_ADDRESS")
+	healthBindAddress := os.Getenv("HEALTH_BIND_ADDRESS")
+
+	var degradedGracePeriod time.Duration
+	if v := os.Getenv("DEGRADED_GRACE_PERIOD"); len(v) != 0 {
+		degradedGracePeriod, err = time.ParseDuration(v)
+		if err != nil || degradedGracePeriod < 0 {
+			log.Error(fmt.Errorf("invalid value %q", v), "'DEGRADED_GRACE_PERIOD' environment variable must be a non-negative duration")
+			os.Exit(1)
+		}
+		log.Info("delaying Degraded conditions", "gracePeriod", degradedGracePeriod)
+	}
+
+	var finalizerTimeout time.Duration
+	if v := os.Getenv("FINALIZER_TIMEOUT"); len(v) != 0 {
+		finalizerTimeout, err = time.ParseDuration(v)
+		if err != nil || finalizerTimeout < 0 {
+			log.Error(fmt.Errorf("invalid value %q", v), "'FINALIZER_TIMEOUT' environment variable must be a non-negative duration")
+			os.Exit(1)
+		}
+		log.Info("removing ingresscontroller finalizers after a timeout even if cleanup fails", "timeout", finalizerTimeout)
+	}
+
+	resyncPeriod := controller.DefaultResyncPeriod
+	if v := os.Getenv("RESYNC_PERIOD"); len(v) != 0 {
+		resyncPeriod, err = time.ParseDuration(v)
+		if err != nil || resyncPeriod < 0 {
+			log.Error(fmt.Errorf("invalid value %q", v), "'RESYNC_PERIOD' environment variable must be a non-negative duration")
+			os.Exit(1)
+		}
+	}
+	if resyncPeriod > 0 {
+		log.Info("reconciling ingresscontrollers periodically", "resyncPeriod", resyncPeriod)
+	}
+
+	degradeOnOperandVersionSkew := false
+	switch v := os.Getenv("OPERAND_VERSION_SKEW_POLICY"); strings.ToLower(v) {
+	case "", "warn":
+	case "degrade":
+		degradeOnOperandVersionSkew = true
+		log.Info("marking ingresscontrollers degraded on operand version skew")
+	default:
+		log.Error(fmt.Errorf("invalid value %q", v), "'OPERAND_VERSION_SKEW_POLICY' environment variable must be Warn or Degrade")
+		os.Exit(1)
+	}
+
+	preferClusterIngressDomain := false
+	switch v := os.Getenv("INGRESS_DOMAIN_PRECEDENCE"); strings.ToLower(v) {
+	case "", "ingresscontroller":
+	case "clusterconfig":
+		preferClusterIngressDomain = true
+		log.Info("preferring the cluster ingress config domain over spec.domain")
+	default:
+		log.Error(fmt.Errorf("invalid value %q", v), "'INGRESS_DOMAIN_PRECEDENCE' environment variable must be IngressController or ClusterConfig")
+		os.Exit(1)
+	}
+
+	failOnEmptyIngressDomain := false
+	switch v := os.Getenv("EMPTY_INGRESS_DOMAIN_POLICY"); strings.ToLower(v) {
+	case "", "defer":
+	case "error":
+		failOnEmptyIngressDomain = true
+	default:
+		log.Error(fmt.Errorf("invalid value %q", v), "'EMPTY_INGRESS_DOMAIN_POLICY' environment variable must be Defer or Error")
+		os.Exit(1)
+	}
+
 	// Retrieve the cluster infrastructure config.
 	infraConfig := &configv1.Infrastructure{}
 	err = kubeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infraConfig)
@@ -102,18 +277,40 @@ func main() {
 	operatorConfig := operatorconfig.Config{
 		OperatorReleaseVersion: releaseVersion,
 		Namespace:              operatorNamespace,
+		OperandNamespace:       operandNamespace,
 		IngressControllerImage: ingressControllerImage,
+		MonitoringNamespace:    monitoringNamespace,
+		OperandLabels:          operandLabels,
+		OperandAnnotations:     operandAnnotations,
+
+		MaxLoadBalancerIngressControllers: maxLoadBalancerIngressControllers,
+		OperandEventQPS:                   operandEventQPS,
+		OperandEventBurst:                 operandEventBurst,
+		ClientQPS:                         clientQPS,
+		ClientBurst:                       clientBurst,
+		UnmanagedIngressControllers:       unmanagedIngressControllers,
+		IngressAddressesBindAddress:       ingressAddressesBindAddress,
+		HealthBindAddress:                 healthBindAddress,
+		DegradedGracePeriod:               degradedGracePeriod,
+		FinalizerTimeout:                  finalizerTimeout,
+		ResyncPeriod:                      resyncPeriod,
+		DegradeOnOperandVersionSkew:       degradeOnOperandVersionSkew,
+		PreferClusterIngressDomain:        preferClusterIngressDomain,
+		FailOnEmptyIngressDomain:          failOnEmptyIngressDomain,
 	}
 
-	// Set up the DNS manager.
-	dnsManager, err := createDNSManager(kubeClient, operatorConfig, infraConfig, dnsConfig, installConfig)
+	// Set up the DNS manager.  The operator keeps the proxy configuration
+	// with which the DNS managers reach their provider's API up to date with
+	// the cluster proxy config.
+	dnsProxy := &dns.Proxy{}
+	dnsManager, dnsManagerFactory, err := createDNSManager(kubeClient, operatorConfig, infraConfig, dnsConfig, installConfig, dnsProxy)
 	if err != nil {
 		log.Error(err, "failed to create DNS manager")
 		os.Exit(1)
 	}
 
 	// Set up and start the operator.
-	op, err := operator.New(operatorConfig, dnsManager, kubeConfig)
+	op, err := operator.New(operatorConfig, dnsManager, dnsManagerFactory, dnsProxy, kubeConfig)
 	if err != nil {
 		log.Error(err, "failed to create operator")
 		os.Exit(1)
@@ -125,50 +322,113 @@ func main() {
 }
 
 // createDNSManager creates a DNS manager compatible with the given cluster
-// configuration.
-func createDNSManager(cl client.Client, operatorConfig operatorconfig.Config, infraConfig *configv1.Infrastructure, dnsConfig *configv1.DNS, installConfig *installConfig) (dns.Manager, error) {
-	var dnsManager dns.Manager
+// configuration, using the operator's cloud credentials, along with a factory
+// that creates DNS managers for ingresscontrollers that specify their own
+// credentials.  The factory is nil if the operator does not manage DNS on the
+// cluster's platform.  The DNS managers call their provider's API through the
+// given proxy.
+func createDNSManager(cl client.Client, operatorConfig operatorconfig.Config, infraConfig *configv1.Infrastructure, dnsConfig *configv1.DNS, installConfig *installConfig, proxy *dns.Proxy) (dns.Manager, dns.ManagerFactory, error) {
+	factory := newDNSManagerFactory(operatorConfig, infraConfig, dnsConfig, installConfig, proxy)
+	if factory == nil {
+		return &dns.NoopManager{}, nil, nil
+	}
+	creds := &corev1.Secret{}
+	name := types.NamespacedName{Namespace: operatorConfig.Namespace, Name: cloudCredentialsSecretName}
+	if err := cl.Get(context.TODO(), name, creds); err != nil {
+		return nil, nil, fmt.Errorf("failed to get %s creds from secret %s: %v", infraConfig.Status.Platform, name, err)
+	}
+	log.Info("using creds from secret", "platform", infraConfig.Status.Platform, "namespace", creds.Namespace, "name", creds.Name)
+	dnsManager, err := factory(creds)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dnsManager, factory, nil
+}
+
+// newDNSManagerFactory returns a factory that creates DNS managers compatible
+// with the given cluster configuration from cloud credentials secrets, or nil
+// if the operator does not manage DNS on the cluster's platform.  The DNS
+// managers call their provider's API through the given proxy.
+func newDNSManagerFactory(operatorConfig operatorconfig.Config, infraConfig *configv1.Infrastructure, dnsConfig *configv1.DNS, installConfig *installConfig, proxy *dns.Proxy) dns.ManagerFactory {
+	// requireKeys verifies that the given secret has every given key.
+	requireKeys := func(creds *corev1.Secret, keys ...string) error {
+		for _, key := range keys {
+			if len(creds.Data[key]) == 0 {
+				return fmt.Errorf("secret %s/%s has no key %s", creds.Namespace, creds.Name, key)
+			}
+		}
+		return nil
+	}
 	switch infraConfig.Status.Platform {
 	case configv1.AWSPlatformType:
-		awsCreds := &corev1.Secret{}
-		err := cl.Get(context.TODO(), types.NamespacedName{Namespace: operatorConfig.Namespace, Name: cloudCredentialsSecretName}, awsCreds)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get aws creds from secret %s/%s: %v", awsCreds.Namespace, awsCreds.Name, err)
-		}
-		log.Info("using aws creds from secret", "namespace", awsCreds.Namespace, "name", awsCreds.Name)
-		manager, err := awsdns.NewManager(awsdns.Config{
-			AccessID:  string(awsCreds.Data["aws_access_key_id"]),
-			AccessKey: string(awsCreds.Data["aws_secret_access_key"]),
-			DNS:       dnsConfig,
-			Region:    installConfig.Platform.AWS.Region,
-		}, operatorConfig.OperatorReleaseVersion)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create AWS DNS manager: %v", err)
-		}
-		dnsManager = manager
+		return func(awsCreds *corev1.Secret) (dns.Manager, error) {
+			if err := requireKeys(awsCreds, "aws_access_key_id", "aws_secret_access_key"); err != nil {
+				return nil, err
+			}
+			manager, err := awsdns.NewManager(awsdns.Config{
+				AccessID:   string(awsCreds.Data["aws_access_key_id"]),
+				AccessKey:  string(awsCreds.Data["aws_secret_access_key"]),
+				DNS:        dnsConfig,
+				Region:     installConfig.Platform.AWS.Region,
+				HTTPClient: proxy.HTTPClient(),
+			}, operatorConfig.OperatorReleaseVersion)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create AWS DNS manager: %v", err)
+			}
+			return manager, nil
+		}
 	case configv1.AzurePlatformType:
-		azureCreds := &corev1.Secret{}
-		err := cl.Get(context.TODO(), types.NamespacedName{Namespace: operatorConfig.Namespace, Name: cloudCredentialsSecretName}, azureCreds)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get azure creds from secret %s/%s: %v", azureCreds.Namespace, azureCreds.Name, err)
-		}
-		log.Info("using azure creds from secret", "namespace", azureCreds.Namespace, "name", azureCreds.Name)
-		manager, err := azuredns.NewManager(azuredns.Config{
-			Environment:    "AzurePublicCloud",
-			ClientID:       string(azureCreds.Data["azure_client_id"]),
-			ClientSecret:   string(azureCreds.Data["azure_client_secret"]),
-			TenantID:       string(azureCreds.Data["azure_tenant_id"]),
-			SubscriptionID: string(azureCreds.Data["azure_subscription_id"]),
-			DNS:            dnsConfig,
-		}, operatorConfig.OperatorReleaseVersion)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create Azure DNS manager: %v", err)
-		}
-		dnsManager = manager
-	default:
-		dnsManager = &dns.NoopManager{}
+		return func(azureCreds *corev1.Secret) (dns.Manager, error) {
+			if err := requireKeys(azureCreds, "azure_client_id", "azure_client_secret", "azure_tenant_id", "azure_subscription_id"); err != nil {
+				return nil, err
+			}
+			manager, err := azuredns.NewManager(azuredns.Config{
+				Environment:    "AzurePublicCloud",
+				ClientID:       string(azureCreds.Data["azure_client_id"]),
+				ClientSecret:   string(azureCreds.Data["azure_client_secret"]),
+				TenantID:       string(azureCreds.Data["azure_tenant_id"]),
+				SubscriptionID: string(azureCreds.Data["azure_subscription_id"]),
+				DNS:            dnsConfig,
+				HTTPClient:     proxy.HTTPClient(),
+			}, operatorConfig.OperatorReleaseVersion)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create Azure DNS manager: %v", err)
+			}
+			return manager, nil
+		}
+	}
+	return nil
+}
+
+// parseOperandMetadata parses the named environment variable, which if set
+// must be a JSON object mapping label or annotation keys to values, for
+// example {"example.com/team":"network"}.
+func parseOperandMetadata(envVar string, isLabels bool) (map[string]string, error) {
+	data := os.Getenv(envVar)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	metadata := map[string]string{}
+	if err := json.Unmarshal([]byte(data), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", envVar, err)
+	}
+	errs := []error{}
+	for k, v := range metadata {
+		for _, msg := range validation.IsQualifiedName(k) {
+			errs = append(errs, fmt.Errorf("invalid key %q: %s", k, msg))
+		}
+		if !isLabels {
+			continue
+		}
+		for _, msg := range validation.IsValidLabelValue(v) {
+			errs = append(errs, fmt.Errorf("invalid value %q for key %q: %s", v, k, msg))
+		}
+	}
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		return nil, err
 	}
-	return dnsManager, nil
+	log.Info("using extra operand metadata", "variable", envVar, "metadata", metadata)
+	return metadata, nil
 }
 
 // TODO: This can be replaced by cluster API when
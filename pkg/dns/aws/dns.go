@@ -2,6 +2,7 @@ package aws
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 	"strings"
 	"sync"
@@ -71,6 +72,9 @@ type Config struct {
 	Region string
 	// DNS is public and private DNS zone configuration for the cluster.
 	DNS *configv1.DNS
+	// HTTPClient is the HTTP client with which to call the AWS APIs.  If
+	// nil, the AWS SDK's default client is used.
+	HTTPClient *http.Client
 }
 
 func NewManager(config Config, operatorReleaseVersion string) (*Manager, error) {
@@ -78,6 +82,7 @@ func NewManager(config Config, operatorReleaseVersion string) (*Manager, error)
 	sess, err := session.NewSessionWithOptions(session.Options{
 		Config: aws.Config{
 			Credentials: creds,
+			HTTPClient:  config.HTTPClient,
 		},
 		SharedConfigState: session.SharedConfigEnable,
 	})
@@ -224,16 +229,22 @@ const (
 )
 
 func (m *Manager) Ensure(record *dns.Record) error {
-	return m.change(record, upsertAction)
+	return m.change(record, upsertAction, false)
+}
+
+// ForceEnsure upserts the record even if it is in the cache of updated records.
+func (m *Manager) ForceEnsure(record *dns.Record) error {
+	return m.change(record, upsertAction, true)
 }
 
 func (m *Manager) Delete(record *dns.Record) error {
-	return m.change(record, deleteAction)
+	return m.change(record, deleteAction, false)
 }
 
 // change will perform an action on a record. The target must correspond to the
-// hostname of an ELB which will be automatically discovered.
-func (m *Manager) change(record *dns.Record, action action) error {
+// hostname of an ELB which will be automatically discovered.  Upserts of
+// records that have already been updated are skipped unless force is true.
+func (m *Manager) change(record *dns.Record, action action, force bool) error {
 	if record.Type != dns.ALIASRecord {
 		return fmt.Errorf("unsupported record type %s", record.Type)
 	}
@@ -266,7 +277,7 @@ func (m *Manager) change(record *dns.Record, action action) error {
 	defer m.lock.Unlock()
 	key := zoneID + domain + target
 	// Only process updates once for now because we're not diffing.
-	if m.updatedRecords.Has(key) && action == upsertAction {
+	if m.updatedRecords.Has(key) && action == upsertAction && !force {
 		log.Info("skipping DNS record update", "record", record)
 		return nil
 	}
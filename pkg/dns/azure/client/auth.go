@@ -23,5 +23,8 @@ func getAuthorizerForResource(config Config) (autorest.Authorizer, error) {
 	if err != nil {
 		return nil, err
 	}
+	if config.HTTPClient != nil {
+		token.SetSender(config.HTTPClient)
+	}
 	return autorest.NewBearerAuthorizer(token), err
 }
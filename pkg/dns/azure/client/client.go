@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/Azure/azure-sdk-for-go/services/dns/mgmt/2017-10-01/dns"
 	"github.com/pkg/errors"
@@ -10,6 +11,8 @@ import (
 type DNSClient interface {
 	Put(ctx context.Context, zone Zone, arec ARecord) error
 	Delete(ctx context.Context, zone Zone, arec ARecord) error
+	PutAAAA(ctx context.Context, zone Zone, rec AAAARecord) error
+	DeleteAAAA(ctx context.Context, zone Zone, rec AAAARecord) error
 }
 
 type Config struct {
@@ -18,6 +21,9 @@ type Config struct {
 	ClientID       string
 	ClientSecret   string
 	TenantID       string
+	// HTTPClient is the HTTP client with which to call the Azure APIs.  If
+	// nil, the Azure SDK's default client is used.
+	HTTPClient *http.Client
 }
 
 // ARecord is a DNS A record.
@@ -32,6 +38,18 @@ type ARecord struct {
 	TTL int64
 }
 
+// AAAARecord is a DNS AAAA record.
+type AAAARecord struct {
+	// Name is the record name.
+	Name string
+
+	// Address is the IPv6 address of the AAAA record.
+	Address string
+
+	// TTL is the Time To Live property of the AAAA record.
+	TTL int64
+}
+
 type dnsClient struct {
 	zones      dns.ZonesClient
 	recordSets dns.RecordSetsClient
@@ -50,6 +68,10 @@ func New(config Config, userAgentExtension string) (DNSClient, error) {
 	rc := dns.NewRecordSetsClient(config.SubscriptionID)
 	rc.AddToUserAgent(userAgentExtension)
 	rc.Authorizer = authorizer
+	if config.HTTPClient != nil {
+		zc.Sender = config.HTTPClient
+		rc.Sender = config.HTTPClient
+	}
 	return &dnsClient{zones: zc, recordSets: rc, config: config}, nil
 }
 
@@ -81,3 +103,32 @@ func (c *dnsClient) Delete(ctx context.Context, zone Zone, arec ARecord) error {
 	}
 	return nil
 }
+
+func (c *dnsClient) PutAAAA(ctx context.Context, zone Zone, rec AAAARecord) error {
+	rs := dns.RecordSet{
+		RecordSetProperties: &dns.RecordSetProperties{
+			TTL: &rec.TTL,
+			AaaaRecords: &[]dns.AaaaRecord{
+				{Ipv6Address: &rec.Address},
+			},
+		},
+	}
+	_, err := c.recordSets.CreateOrUpdate(ctx, zone.ResourceGroup, zone.Name, rec.Name, dns.AAAA, rs, "", "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to update dns aaaa record: %s.%s", rec.Name, zone.Name)
+	}
+	return nil
+}
+
+func (c *dnsClient) DeleteAAAA(ctx context.Context, zone Zone, rec AAAARecord) error {
+	_, err := c.recordSets.Get(ctx, zone.ResourceGroup, zone.Name, rec.Name, dns.AAAA)
+	if err != nil {
+		// TODO: How do we interpret this as a notfound error?
+		return nil
+	}
+	_, err = c.recordSets.Delete(ctx, zone.ResourceGroup, zone.Name, rec.Name, dns.AAAA, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete dns aaaa record: %s.%s", rec.Name, zone.Name)
+	}
+	return nil
+}
@@ -22,7 +22,22 @@ func (c *FakeDNSClient) Delete(ctx context.Context, zone Zone, arec ARecord) err
 	return nil
 }
 
+func (c *FakeDNSClient) PutAAAA(ctx context.Context, zone Zone, rec AAAARecord) error {
+	c.fakeARM[zone.ResourceGroup+zone.Name+rec.Name+"/AAAA"] = "PUT"
+	return nil
+}
+
+func (c *FakeDNSClient) DeleteAAAA(ctx context.Context, zone Zone, rec AAAARecord) error {
+	c.fakeARM[zone.ResourceGroup+zone.Name+rec.Name+"/AAAA"] = "DELETE"
+	return nil
+}
+
 func (c *FakeDNSClient) RecordedCall(rg, zone, rel string) (string, bool) {
 	call, ok := c.fakeARM[rg+zone+rel]
 	return call, ok
 }
+
+func (c *FakeDNSClient) RecordedAAAACall(rg, zone, rel string) (string, bool) {
+	call, ok := c.fakeARM[rg+zone+rel+"/AAAA"]
+	return call, ok
+}
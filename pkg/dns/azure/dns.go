@@ -3,6 +3,7 @@ package azure
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -31,6 +32,9 @@ type Config struct {
 	SubscriptionID string
 	// DNS is public and private DNS zone configuration for the cluster.
 	DNS *configv1.DNS
+	// HTTPClient is the HTTP client with which to call the Azure APIs.  If
+	// nil, the Azure SDK's default client is used.
+	HTTPClient *http.Client
 }
 
 type manager struct {
@@ -46,6 +50,7 @@ func NewManager(config Config, operatorReleaseVersion string) (dns.Manager, erro
 		ClientID:       config.ClientID,
 		ClientSecret:   config.ClientSecret,
 		TenantID:       config.TenantID,
+		HTTPClient:     config.HTTPClient,
 	}, userAgent(operatorReleaseVersion))
 	if err != nil {
 		return nil, err
@@ -58,8 +63,8 @@ func userAgent(operatorReleaseVersion string) string {
 }
 
 func (m *manager) Ensure(record *dns.Record) error {
-	if record.Type != dns.ARecordType {
-		return fmt.Errorf("only A record types are supported")
+	if record.Type != dns.ARecordType && record.Type != dns.AAAARecordType {
+		return fmt.Errorf("only A and AAAA record types are supported")
 	}
 
 	targetZone, err := client.ParseZone(record.Zone.ID)
@@ -67,6 +72,24 @@ func (m *manager) Ensure(record *dns.Record) error {
 		return errors.Wrap(err, "failed to parse zoneID")
 	}
 
+	if record.Type == dns.AAAARecordType {
+		name, err := getARecordName(record.AAAARecord.Domain, "."+targetZone.Name)
+		if err != nil {
+			return err
+		}
+		err = m.client.PutAAAA(
+			context.TODO(),
+			*targetZone,
+			client.AAAARecord{
+				Address: record.AAAARecord.Address,
+				Name:    name,
+			})
+		if err == nil {
+			log.Info("upserted DNS record", "record", record)
+		}
+		return err
+	}
+
 	ARecordName, err := getARecordName(record.ARecord.Domain, "."+targetZone.Name)
 	if err != nil {
 		return err
@@ -87,12 +110,40 @@ func (m *manager) Ensure(record *dns.Record) error {
 	return err
 }
 
+// ForceEnsure is equivalent to Ensure because the manager does not cache
+// records.
+func (m *manager) ForceEnsure(record *dns.Record) error {
+	return m.Ensure(record)
+}
+
 func (m *manager) Delete(record *dns.Record) error {
+	if record.Type != dns.ARecordType && record.Type != dns.AAAARecordType {
+		return fmt.Errorf("only A and AAAA record types are supported")
+	}
+
 	targetZone, err := client.ParseZone(record.Zone.ID)
 	if err != nil {
 		return errors.Wrap(err, "failed to parse zoneID")
 	}
 
+	if record.Type == dns.AAAARecordType {
+		name, err := getARecordName(record.AAAARecord.Domain, "."+targetZone.Name)
+		if err != nil {
+			return err
+		}
+		err = m.client.DeleteAAAA(
+			context.TODO(),
+			*targetZone,
+			client.AAAARecord{
+				Address: record.AAAARecord.Address,
+				Name:    name,
+			})
+		if err == nil {
+			log.Info("deleted DNS record", "record", record)
+		}
+		return err
+	}
+
 	ARecordName, err := getARecordName(record.ARecord.Domain, "."+targetZone.Name)
 	if err != nil {
 		return err
@@ -114,7 +165,8 @@ func (m *manager) Delete(record *dns.Record) error {
 }
 
 // getARecordName extracts the ARecord subdomain name from the full domain string.
-// azure defines the ARecord Name as the subdomain name only.
+// azure defines the ARecord Name as the subdomain name only.  AAAA records are
+// named the same way.
 func getARecordName(recordDomain string, zoneName string) (string, error) {
 	return strings.TrimSuffix(recordDomain, zoneName), nil
 }
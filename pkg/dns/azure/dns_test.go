@@ -92,3 +92,44 @@ func TestDeleteDNS(t *testing.T) {
 		t.Fatalf("expected the dns client 'Delete' func to be called, but found %s instead", recordedCall)
 	}
 }
+
+func TestEnsureAndDeleteAAAADNS(t *testing.T) {
+	fc, err := client.NewFake(client.Config{})
+	if err != nil {
+		t.Fatal("failed to create client")
+	}
+	mgr, err := azure.NewFakeManager(azure.Config{}, fc)
+	if err != nil {
+		t.Fatal("failed to create manager")
+	}
+
+	rg := "test-rg"
+	zone := "dnszone.io"
+	recordName := "subdomain"
+	record := dns.Record{
+		Zone: v1.DNSZone{
+			ID: "/subscriptions/E540B02D-5CCE-4D47-A13B-EB05A19D696E/resourceGroups/test-rg/providers/Microsoft.Network/dnszones/dnszone.io",
+		},
+		Type: dns.AAAARecordType,
+		AAAARecord: &dns.AAAARecord{
+			Domain:  "subdomain.dnszone.io",
+			Address: "2001:db8::1",
+		},
+	}
+	if err := mgr.Ensure(&record); err != nil {
+		t.Fatalf("failed to ensure dns: %v", err)
+	}
+	if call, _ := fc.RecordedAAAACall(rg, zone, recordName); call != "PUT" {
+		t.Fatalf("expected the dns client 'PutAAAA' func to be called, but found %q instead", call)
+	}
+	if call, ok := fc.RecordedCall(rg, zone, recordName); ok {
+		t.Fatalf("expected no A record call, but found %q", call)
+	}
+
+	if err := mgr.Delete(&record); err != nil {
+		t.Fatalf("failed to delete dns: %v", err)
+	}
+	if call, _ := fc.RecordedAAAACall(rg, zone, recordName); call != "DELETE" {
+		t.Fatalf("expected the dns client 'DeleteAAAA' func to be called, but found %q instead", call)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	configv1 "github.com/openshift/api/config/v1"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 // Manager knows how to manage DNS zones only as pertains to routing.
@@ -11,16 +13,26 @@ type Manager interface {
 	// Ensure will create or update record.
 	Ensure(record *Record) error
 
+	// ForceEnsure will create or update record even if the manager believes
+	// that it has already done so, for example because the record was
+	// deleted out of band.
+	ForceEnsure(record *Record) error
+
 	// Delete will delete record.
 	Delete(record *Record) error
 }
 
+// ManagerFactory creates a Manager that authenticates to the DNS provider with
+// the cloud credentials in the given secret.
+type ManagerFactory func(credentials *corev1.Secret) (Manager, error)
+
 var _ Manager = &NoopManager{}
 
 type NoopManager struct{}
 
-func (_ *NoopManager) Ensure(record *Record) error { return nil }
-func (_ *NoopManager) Delete(record *Record) error { return nil }
+func (_ *NoopManager) Ensure(record *Record) error      { return nil }
+func (_ *NoopManager) ForceEnsure(record *Record) error { return nil }
+func (_ *NoopManager) Delete(record *Record) error      { return nil }
 
 // Record represents a DNS record.
 type Record struct {
@@ -34,10 +46,13 @@ type Record struct {
 
 	// ARecord is options for an A record.
 	ARecord *ARecord
+
+	// AAAARecord is options for an AAAA record.
+	AAAARecord *AAAARecord
 }
 
 func (r *Record) String() string {
-	return fmt.Sprintf("Zone: %v, Type: %v, Alias: %s, A: %s", r.Zone, r.Type, r.Alias, r.ARecord)
+	return fmt.Sprintf("Zone: %v, Type: %v, Alias: %s, A: %s, AAAA: %s", r.Zone, r.Type, r.Alias, r.ARecord, r.AAAARecord)
 }
 
 // RecordType is a DNS record type.
@@ -49,6 +64,9 @@ const (
 
 	// ARecordType is a DNS A record.
 	ARecordType RecordType = "A"
+
+	// AAAARecordType is a DNS AAAA record.
+	AAAARecordType RecordType = "AAAA"
 )
 
 // AliasRecord is a DNS ALIAS record.
@@ -76,3 +94,16 @@ type ARecord struct {
 func (r *ARecord) String() string {
 	return fmt.Sprintf("%s -> %s", r.Domain, r.Address)
 }
+
+// AAAARecord is a DNS AAAA record.
+type AAAARecord struct {
+	// Domain is the record name.
+	Domain string
+
+	// Address is the IPv6 address of the AAAA record.
+	Address string
+}
+
+func (r *AAAARecord) String() string {
+	return fmt.Sprintf("%s -> %s", r.Domain, r.Address)
+}
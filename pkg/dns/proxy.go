@@ -0,0 +1,153 @@
+package dns
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// Proxy is the HTTP proxy configuration with which DNS managers reach their
+// provider's API.  Managers are created once, but the cluster proxy config can
+// change at any time, so the HTTP client that Proxy provides looks up the
+// current configuration for every request.  Until a configuration is set, or
+// if the configuration that is set is empty, the proxy environment variables
+// of the operator's process are used.
+type Proxy struct {
+	lock      sync.RWMutex
+	spec      configv1.ProxySpec
+	transport *http.Transport
+}
+
+// Set sets the proxy configuration and returns a Boolean indicating whether it
+// changed.  If it changed, idle connections, which may go through the
+// previous proxy, are closed.
+func (p *Proxy) Set(spec configv1.ProxySpec) bool {
+	p.lock.Lock()
+	if p.spec == spec {
+		p.lock.Unlock()
+		return false
+	}
+	p.spec = spec
+	transport := p.transport
+	p.lock.Unlock()
+	if transport != nil {
+		transport.CloseIdleConnections()
+	}
+	return true
+}
+
+// HTTPClient returns an HTTP client that uses the current proxy configuration.
+// All clients share one transport.
+func (p *Proxy) HTTPClient() *http.Client {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.transport == nil {
+		defaultTransport := http.DefaultTransport.(*http.Transport)
+		p.transport = &http.Transport{
+			Proxy:                 p.proxyURL,
+			DialContext:           defaultTransport.DialContext,
+			MaxIdleConns:          defaultTransport.MaxIdleConns,
+			IdleConnTimeout:       defaultTransport.IdleConnTimeout,
+			TLSHandshakeTimeout:   defaultTransport.TLSHandshakeTimeout,
+			ExpectContinueTimeout: defaultTransport.ExpectContinueTimeout,
+			TLSClientConfig:       &tls.Config{MinVersion: tls.VersionTLS12},
+		}
+	}
+	return &http.Client{Transport: p.transport}
+}
+
+// proxyURL returns the URL of the proxy to use for the given request, or nil
+// if the request should not use a proxy.
+func (p *Proxy) proxyURL(req *http.Request) (*url.URL, error) {
+	p.lock.RLock()
+	spec := p.spec
+	p.lock.RUnlock()
+	if spec == (configv1.ProxySpec{}) {
+		return http.ProxyFromEnvironment(req)
+	}
+	return proxyURLForSpec(spec, req.URL)
+}
+
+// proxyURLForSpec returns the URL of the proxy that the given proxy
+// configuration specifies for the given request URL, or nil if the request
+// should not use a proxy.  As with the proxy environment variables, HTTPS
+// requests use httpsProxy, HTTP requests use httpProxy, and a proxy URL
+// without a scheme is an HTTP proxy.
+func proxyURLForSpec(spec configv1.ProxySpec, reqURL *url.URL) (*url.URL, error) {
+	var proxy string
+	switch reqURL.Scheme {
+	case "https":
+		proxy = spec.HTTPSProxy
+	case "http":
+		proxy = spec.HTTPProxy
+	}
+	if len(proxy) == 0 || noProxyMatches(spec.NoProxy, reqURL) {
+		return nil, nil
+	}
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || len(u.Host) == 0 {
+		return nil, fmt.Errorf("invalid proxy URL %q", proxy)
+	}
+	return u, nil
+}
+
+// noProxyMatches returns a Boolean indicating whether the given request URL
+// matches an entry of the given comma-separated noProxy list.  An entry
+// matches if it is "*", if it is an IP address or CIDR that contains the
+// request's host, or if it is a domain name that is the request's host or,
+// with or without a leading ".", a parent domain of it.  An entry may have a
+// port, in which case it matches only requests to that port.
+func noProxyMatches(noProxy string, reqURL *url.URL) bool {
+	host, port := reqURL.Hostname(), reqURL.Port()
+	if len(port) == 0 {
+		switch reqURL.Scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		}
+	}
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case len(entry) == 0:
+			continue
+		case entry == "*":
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if entryHost, entryPort, err := net.SplitHostPort(entry); err == nil {
+			if entryPort != port {
+				continue
+			}
+			entry = entryHost
+		}
+		if entryIP := net.ParseIP(entry); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		entry = strings.TrimPrefix(entry, ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
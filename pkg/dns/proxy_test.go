@@ -0,0 +1,88 @@
+package dns
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// TestProxyURLForSpec verifies that requests use the proxy for their scheme
+// unless the noProxy list matches their host.
+func TestProxyURLForSpec(t *testing.T) {
+	spec := configv1.ProxySpec{
+		HTTPProxy:  "http://proxy.example.com:3128",
+		HTTPSProxy: "proxy.example.com:3129",
+		NoProxy:    ".internal.example.com,10.0.0.0/16,metadata.example.com:8080,192.168.1.1",
+	}
+	testCases := []struct {
+		url    string
+		expect string
+	}{
+		{"https://route53.amazonaws.com/2013-04-01/hostedzone", "http://proxy.example.com:3129"},
+		{"http://route53.amazonaws.com/", "http://proxy.example.com:3128"},
+		{"https://api.internal.example.com/", ""},
+		{"https://internal.example.com/", ""},
+		{"https://notinternal.example.com/", "http://proxy.example.com:3129"},
+		{"https://10.0.3.4/", ""},
+		{"https://10.1.3.4/", "http://proxy.example.com:3129"},
+		{"http://metadata.example.com:8080/", ""},
+		{"http://metadata.example.com/", "http://proxy.example.com:3128"},
+		{"https://192.168.1.1:8443/", ""},
+	}
+	for _, tc := range testCases {
+		reqURL, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proxyURL, err := proxyURLForSpec(spec, reqURL)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.url, err)
+			continue
+		}
+		actual := ""
+		if proxyURL != nil {
+			actual = proxyURL.String()
+		}
+		if actual != tc.expect {
+			t.Errorf("%s: expected proxy %q, got %q", tc.url, tc.expect, actual)
+		}
+	}
+
+	reqURL, _ := url.Parse("https://route53.amazonaws.com/")
+	if _, err := proxyURLForSpec(configv1.ProxySpec{HTTPSProxy: "http://"}, reqURL); err == nil || err.Error() != `invalid proxy URL "http://"` {
+		t.Errorf("expected an invalid proxy URL error, got %v", err)
+	}
+	if proxyURL, err := proxyURLForSpec(configv1.ProxySpec{HTTPSProxy: "http://proxy.example.com", NoProxy: "*"}, reqURL); err != nil || proxyURL != nil {
+		t.Errorf("expected noProxy * to bypass the proxy, got %v, %v", proxyURL, err)
+	}
+}
+
+// TestProxySet verifies that the HTTP client follows changes to the proxy
+// configuration and that Set reports whether the configuration changed.
+func TestProxySet(t *testing.T) {
+	p := &Proxy{}
+	client := p.HTTPClient()
+	transport := client.Transport.(*http.Transport)
+	req, _ := http.NewRequest("GET", "https://route53.amazonaws.com/", nil)
+
+	spec := configv1.ProxySpec{HTTPSProxy: "http://proxy.example.com:3128"}
+	if !p.Set(spec) {
+		t.Error("expected setting a proxy to be a change")
+	}
+	if p.Set(spec) {
+		t.Error("expected setting the same proxy again not to be a change")
+	}
+	if proxyURL, err := transport.Proxy(req); err != nil || proxyURL == nil || proxyURL.String() != "http://proxy.example.com:3128" {
+		t.Errorf("expected proxy http://proxy.example.com:3128, got %v, %v", proxyURL, err)
+	}
+
+	spec.NoProxy = "amazonaws.com"
+	if !p.Set(spec) {
+		t.Error("expected changing noProxy to be a change")
+	}
+	if proxyURL, err := transport.Proxy(req); err != nil || proxyURL != nil {
+		t.Errorf("expected no proxy, got %v, %v", proxyURL, err)
+	}
+}
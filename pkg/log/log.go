@@ -6,15 +6,22 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
 )
 
 // Logger is a simple logging interface for Go.
 var Logger logr.Logger
 
+// level is the minimum level of the entries that Logger writes.  Logger.V(n)
+// writes at zap level -n, so raising the verbosity lowers the level.
+var level = zap.NewAtomicLevelAt(zap.InfoLevel)
+
 func init() {
 	// Build a zap development logger.
-	zapLogger, err := zap.NewDevelopment(zap.AddCallerSkip(1), zap.AddStacktrace(zap.FatalLevel))
+	config := zap.NewDevelopmentConfig()
+	config.Level = level
+	zapLogger, err := config.Build(zap.AddCallerSkip(1), zap.AddStacktrace(zap.FatalLevel))
 	if err != nil {
 		panic(fmt.Sprintf("error building logger: %v", err))
 	}
@@ -26,6 +33,13 @@ func init() {
 	Logger.Info("started zapr logger")
 }
 
+// SetVerbosity sets the verbosity of Logger so that entries logged with V(n)
+// are written for every n up to and including the given verbosity.  The
+// default verbosity is 0.
+func SetVerbosity(verbosity int) {
+	level.SetLevel(zapcore.Level(-verbosity))
+}
+
 // SetRuntimeLogger sets a concrete logging implementation for all
 // controller-runtime deferred Loggers.
 func SetRuntimeLogger(logger logr.Logger) {
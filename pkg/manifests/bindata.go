@@ -2,7 +2,7 @@
 // sources:
 // assets/router/cluster-role-binding.yaml (329B)
 // assets/router/cluster-role.yaml (788B)
-// assets/router/deployment.yaml (1.723kB)
+// assets/router/deployment.yaml (1.643kB)
 // assets/router/metrics/cluster-role-binding.yaml (285B)
 // assets/router/metrics/cluster-role.yaml (259B)
 // assets/router/metrics/role-binding.yaml (297B)
@@ -79,7 +79,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _assetsRouterClusterRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x8f\x31\x4e\xc4\x40\x0c\x45\xfb\x39\x85\x25\xea\x0c\xa2\x43\xd3\x01\x37\x58\x24\x7a\xef\xc4\xbb\x31\x49\xec\xc8\xf6\xa4\xe0\xf4\x28\x4a\x44\xc3\x4a\x29\x2d\xf9\xbf\xff\xfe\x13\xbc\xb3\xf4\x0e\x31\x10\x98\xb6\x20\x03\xd3\x89\x20\x14\x38\x1c\x3e\xc9\x56\xae\x04\x6f\xb5\x6a\x93\xc8\x69\x64\xe9\x0b\x7c\x4c\xcd\x83\xec\xa2\x13\x6d\x71\x96\x7b\xc2\x85\xbf\xc8\x9c\x55\x0a\xd8\x15\x6b\xc6\x16\x83\x1a\xff\x60\xb0\x4a\x1e\x5f\x3d\xb3\x3e\xaf\x2f\x69\xa6\xc0\x1e\x03\x4b\x02\x10\x9c\xa9\x80\x2e\x24\x3e\xf0\x2d\x3a\x96\xbb\x91\x7b\xb7\x9b\x24\x6f\xd7\x6f\xaa\xe1\x25\x75\xb0\x17\x1f\x3e\x87\xce\x1f\xe1\xf8\xdf\x4f\x5f\xb0\x3e\xa2\xa6\x6d\xd8\x85\x6e\x5b\xf1\xbf\x19\xe7\x32\x27\xf0\xdf\x00\x00\x00\xff\xff\x83\x13\xa9\xa6\x49\x01\x00\x00")
+var _assetsRouterClusterRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x8f\x31\x4e\xc4\x40\x0c\x45\xfb\x39\x85\x25\xea\x0c\xa2\x43\xd3\x01\x37\x58\x24\x7a\xef\xc4\xbb\x31\x49\xec\xc8\xf6\xa4\xe0\xf4\x28\x4a\x44\xc3\x4a\x29\x2d\xf9\xbf\xff\xfe\x13\xbc\xb3\xf4\x0e\x31\x10\x98\xb6\x20\x03\xd3\x89\x20\x14\x38\x1c\x3e\xc9\x56\xae\x04\x6f\xb5\x6a\x93\xc8\x69\x64\xe9\x0b\x7c\x4c\xcd\x83\xec\xa2\x13\x6d\x71\x96\x7b\xc2\x85\xbf\xc8\x9c\x55\x0a\xd8\x15\x6b\xc6\x16\x83\x1a\xff\x60\xb0\x4a\x1e\x5f\x3d\xb3\x3e\xaf\x2f\x69\xa6\xc0\x1e\x03\x4b\x02\x10\x9c\xa9\x80\x2e\x24\x3e\xf0\x2d\x3a\x96\xbb\x91\x7b\xb7\x9b\x24\x6f\xd7\x6f\xaa\xe1\x25\x75\xb0\x17\x1f\x3e\x87\xce\x1f\xe1\xf8\xdf\x4f\x5f\xb0\x3e\xa2\xa6\x6d\xd8\x85\x6e\x5b\xf1\xbf\x19\xe7\x32\x27\xf0\xdf\x01\x00\x83\x13\xa9\xa6\x49\x01\x00\x00")
 
 func assetsRouterClusterRoleBindingYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -99,7 +99,7 @@ func assetsRouterClusterRoleBindingYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x92\x31\x6f\xe3\x30\x0c\x85\x77\xfd\x0a\x22\x37\xdb\xc1\x6d\x07\xaf\x37\xdc\x76\x43\x51\x74\xa7\x65\xa6\x66\xed\x88\x02\x49\x39\x6d\x7f\x7d\x61\x3b\x29\x82\x24\x45\x9b\xcd\xcf\x22\xbf\x47\x3e\xe9\x17\xfc\x1d\x8b\x39\x29\x58\x94\x4c\x1d\xa8\x8c\x04\x3b\x51\x50\x29\x4e\x6a\x35\x3c\xf6\x6c\x60\xbd\x94\xb1\x83\x96\x00\x0d\x94\xcc\x95\xa3\xf3\xb4\xc8\x2c\x66\xdc\x8e\x54\x87\x81\x53\xd7\x9c\x88\x0f\x32\x52\xc0\xcc\x4f\xa4\xc6\x92\x1a\xd0\x16\x63\x8d\xc5\x7b\x51\x7e\x47\x67\x49\xf5\xf0\xc7\x6a\x96\xed\xf4\x3b\xec\xc9\xb1\x43\xc7\x26\x00\x24\xdc\x53\x03\x92\x29\x59\xcf\x3b\xaf\x38\x3d\x2b\x99\x55\xeb\x48\x41\xcb\x48\xd6\x84\x0a\x30\xf3\x3f\x95\x92\x6d\x6e\xaa\x60\xb3\x09\x30\xcf\x26\x45\x23\x1d\xff\x51\xea\xb2\x70\x72\x5b\xd4\x0c\xb6\x8c\x91\x56\x69\xa4\x13\xaf\x62\x22\x6d\x8f\x2d\x23\x9b\x2f\x1f\x07\xf4\xd8\x87\x6b\x9f\x79\x05\x4a\xce\xf1\x7c\x87\x6b\x6b\x97\x81\x92\xd2\xc4\x74\xb8\x70\x88\x4a\xe8\xf4\x05\xf9\x32\x9c\x6b\xb0\x95\xf6\x85\xa2\x63\x8c\x64\x76\x9f\xc1\x92\x60\xfd\x99\xec\x4d\xfc\x52\x73\x6f\x26\x3f\x07\x6f\xcd\xd1\xcb\x05\xbf\xe4\xee\xf6\xc0\x46\xb1\x28\xfb\xdb\x37\xe8\x53\x59\x94\xe4\xf4\xea\x51\x92\xb9\xe2\xf1\xde\xcf\x7d\x8c\xce\x9a\xff\xcf\xcf\x61\x3d\xe8\xc5\x3c\x91\x1f\x44\x87\xf0\x11\x00\x00\xff\xff\xad\x45\xb2\xc3\x14\x03\x00\x00")
+var _assetsRouterClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x92\x31\x6f\xe3\x30\x0c\x85\x77\xfd\x0a\x22\x37\xdb\xc1\x6d\x07\xaf\x37\xdc\x76\x43\x51\x74\xa7\x65\xa6\x66\xed\x88\x02\x49\x39\x6d\x7f\x7d\x61\x3b\x29\x82\x24\x45\x9b\x4d\x14\xc8\xef\x3d\x3e\xe9\x17\xfc\x1d\x8b\x39\x29\x58\x94\x4c\x1d\xa8\x8c\x04\x3b\x51\x50\x29\x4e\x6a\x35\x3c\xf6\x6c\x60\xbd\x94\xb1\x83\x96\x00\x0d\x94\xcc\x95\xa3\xf3\xb4\x94\x59\xcc\xb8\x1d\xa9\x0e\x03\xa7\xae\x39\x11\x1f\x64\xa4\x80\x99\x9f\x48\x8d\x25\x35\xa0\x2d\xc6\x1a\x8b\xf7\xa2\xfc\x8e\xce\x92\xea\xe1\x8f\xd5\x2c\xdb\xe9\x77\xd8\x93\x63\x87\x8e\x4d\x00\x48\xb8\xa7\x06\x24\x53\xb2\x9e\x77\x5e\x71\x7a\x56\x32\xab\x56\x4b\x41\xcb\x48\xd6\x84\x0a\x30\xf3\x3f\x95\x92\x6d\x1e\xaa\x60\xb3\x09\x30\x7b\x93\xa2\x91\x8e\x77\x94\xba\x2c\x9c\xdc\x96\x8e\x19\x6c\x19\x23\xad\xa5\x91\x4e\xbc\x16\x13\x69\x7b\x1c\x19\xd9\x7c\x39\x1c\xd0\x63\x1f\xae\x75\xe6\x15\x28\x39\xc7\xf3\x1d\xae\xa5\x5d\x06\x4a\x4a\x13\xd3\xe1\x42\x21\x2a\xa1\xd3\x17\xe4\xcb\x70\xae\xc1\x56\xda\x17\x8a\x8e\x31\x92\xd9\x7d\x02\x4b\x82\xf5\x67\xb2\x37\xf1\x4b\xcf\xbd\x99\xfc\x1c\xbc\x35\x47\x2f\x17\xfc\x92\xbb\xdb\x86\x8d\x62\x51\xf6\xb7\x6f\xd0\xa7\xb6\x28\xc9\xe9\xd5\xa3\x24\x73\xc5\xe3\xbb\x9f\xeb\x18\x9d\x0d\xff\x9f\xbf\xc3\xaa\xd3\x8b\x79\x22\x3f\x88\x0e\xe1\x63\x00\xad\x45\xb2\xc3\x14\x03\x00\x00")
 
 func assetsRouterClusterRoleYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -119,7 +119,7 @@ func assetsRouterClusterRoleYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterDeploymentYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x54\x4f\x6f\xe2\x4e\x0c\xbd\xf3\x29\xac\x72\xe6\x47\xff\xfd\xaa\xdd\xdc\x10\xa4\x2b\xa4\x52\x22\x48\x7b\x45\xd3\x89\x01\xab\x93\x99\x59\xdb\xa1\x62\x3f\xfd\x2a\x81\xb6\x84\xa5\x55\xf7\xb6\x39\x8d\xec\xe7\xe7\x67\xc7\x76\x17\x46\x18\x5d\xd8\x96\xe8\x15\x5e\x48\xd7\x50\xe0\xd2\x54\x4e\x61\x63\x5c\x85\xd2\xe9\xc2\xd8\xaf\x18\x45\x60\x18\xbc\x72\x70\x0e\x19\x24\xa2\xa5\x25\xd9\x3d\x08\x0c\x23\x98\x18\x1d\x61\x01\x46\x81\x2b\xaf\x54\xe2\x7f\x9d\x67\xf2\x45\x72\x90\xa1\x63\x22\x3d\x22\x0b\x05\x9f\xd4\x01\xd2\xdf\x5c\x74\xba\xe0\x4d\x89\x60\x7c\xd1\x3c\x24\x1a\x8b\x0d\xa3\xa0\xb6\xd8\xea\xac\x49\x07\x40\xb1\x8c\xce\x28\xd6\x6f\x80\x57\x6b\xf3\x46\xde\x90\xc5\x81\xb5\xa1\xf2\x7a\x6f\x4a\x4c\x80\x43\xa5\xc8\x7b\x40\x17\x7c\x28\x70\x8e\x0e\xad\x06\x06\x92\x3f\x92\xec\x70\x91\x29\x30\xe9\x76\xe8\x8c\xc8\x8e\x47\xb6\xa2\x58\xf6\xac\xab\x44\x91\x7b\x96\x49\xc9\x1a\xb7\x0f\xb0\xc1\xab\x21\x8f\x2c\xaf\x5a\x00\x7a\x4d\x3d\x47\x0a\x76\x2a\xa8\x34\x2b\xfc\x38\x7d\xfd\x35\x90\xac\x72\x2e\x0b\x8e\xec\x36\x81\xf1\xf2\x3e\x68\xc6\x28\x75\x23\xdf\x71\x8a\x5c\x92\x37\x4a\xc1\x4f\x50\xa4\x0e\xda\x07\xdc\x1a\xe7\x9e\x8c\x7d\xce\xc3\x5d\x58\xc9\xd4\xa7\xcc\xe1\x50\x46\x0c\xac\x07\x72\xdf\x05\xaf\x55\xe3\x81\xf9\xa0\xba\x2c\xb0\x26\xf0\xed\xbc\xe5\x8d\x1c\x34\xd8\xe0\x12\xc8\x87\xd9\x07\x74\xf2\x19\xdf\xf5\xf5\xd5\x5f\x11\x96\xa8\x4c\xf6\x53\xca\x8b\xef\x57\x37\x5f\xe2\xec\xc2\x04\x79\x75\x34\xb7\xef\x6e\xf4\x9b\xa4\x85\x16\x35\x2a\x50\x09\xf2\xdb\xd4\x46\x23\xf2\x12\xb8\x68\x86\x76\x85\x1e\xd9\x68\x8b\xf0\x44\x09\xf3\x7c\x90\xcf\x17\xd9\x74\x96\xb7\x54\x36\xfb\x94\xc0\x59\x2d\xff\xec\x44\xd8\x6c\xfa\x90\xa7\xb3\xc5\x3c\x9d\x3d\x8e\x87\xe9\xe2\x7e\x30\x49\xe7\xd9\x60\x98\x9e\x22\x09\x11\xbd\xac\x69\xa9\x3d\xda\x6d\xf0\x09\xbe\x51\x7a\x3b\x78\xb8\xcb\x17\xc3\x74\x96\x8f\x6f\xc7\xc3\x41\x9e\x2e\x46\xe3\xd9\x29\xba\x3e\xaa\xed\xc7\x67\xea\xab\x93\x7e\x64\xda\x18\x3d\x2c\xcc\xd1\x06\x3d\x8a\x64\x1c\x9e\x30\x69\x11\x90\x27\x25\xe3\x46\xe8\xcc\x76\x8e\x36\xf8\x42\x12\xb8\x68\xcf\x50\x3d\x23\x3f\x50\xdb\x81\x00\xd1\xe8\x3a\x81\xfe\x1a\x8d\xd3\xf5\xaf\x63\xe7\xa9\x3f\xcd\x68\x0a\xfa\x37\x84\x48\xa8\xd8\xa2\xb4\xa9\x18\x7f\x56\x28\x2a\xc7\x09\x6c\xac\x6a\x2d\xe7\xe5\x91\xbd\xc4\x32\xf0\x36\x81\xcb\xff\x6f\x26\x74\xe0\xdb\x04\x57\x95\x38\xa9\xef\xdc\xd1\x0e\x97\xb5\x2d\xdb\xe9\xfd\xfc\x9f\xc1\x7e\x0a\xf6\x27\xbf\x67\x91\xb5\x3e\xeb\xc7\xa8\xba\xa7\x53\xef\xb6\x09\x28\x57\xaf\xae\x9d\x80\xb7\xdc\xbd\x2f\x70\x09\x5a\x6e\xb7\x76\x8f\x9e\x84\x02\x13\xb8\xbe\x3c\x6f\xad\xda\xbc\x81\xd7\xd7\xb7\x7d\x29\x7b\xbb\x25\xfd\x1d\x00\x00\xff\xff\x40\x5a\x90\x7d\xbb\x06\x00\x00")
+var _assetsRouterDeploymentYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x54\x5d\x4f\xe3\x4a\x0c\x7d\xef\xaf\xb0\xc8\x73\x29\x5f\x17\xdd\x9b\xb7\xaa\x2d\x57\x95\x28\x44\x34\xbb\xaf\x68\x98\x98\x76\xc4\x7c\xad\xed\x14\x75\x7f\xfd\x6a\xd2\x94\x4d\x4a\x41\xec\xdb\x6a\x5e\x46\xf6\xf1\xf1\x19\x8f\xed\x0c\xa6\x18\x6d\xd8\x3a\xf4\x02\xaf\x46\xd6\x50\xe1\xb3\xaa\xad\xc0\x46\xd9\x1a\x79\x90\xc1\xdc\xaf\x08\x99\x61\x12\xbc\x50\xb0\x16\x09\x38\xa2\x36\xcf\x46\xb7\x20\x50\x84\xa0\x62\xb4\x06\x2b\x50\x02\x54\x7b\x31\x0e\x4f\x07\x2f\xc6\x57\x79\x27\xc3\x40\x45\xf3\x1d\x89\x4d\xf0\x79\x0a\xe0\xd1\xe6\x7c\x90\x81\x57\x0e\x41\xf9\xaa\xb9\x70\x54\x1a\x1b\x46\x46\xe9\xb1\xa5\xac\xf9\x00\x40\xd0\x45\xab\x04\xd3\x1d\x60\x6f\x6d\xee\x48\x1b\xa3\x71\xac\x75\xa8\xbd\xdc\x29\x87\x39\x50\xa8\x05\xa9\x05\x64\xe0\x43\x85\x4b\xb4\xa8\x25\x10\x18\x7e\x97\xa4\x81\x41\x24\x13\xc8\xc8\x76\x62\x15\xf3\x8e\x87\xb7\x2c\xe8\x86\xda\xd6\x2c\x48\x43\x4d\x46\x8c\x56\xb6\x0d\xd0\xc1\x8b\x32\x1e\x89\xf7\x5a\x00\x86\xe0\xdf\x2b\x48\x27\x03\xe3\xd4\x0a\x3f\x4e\x9f\x4e\x03\x29\x6a\x6b\x8b\x60\x8d\xde\xe6\x30\x7f\xbe\x0b\x52\x10\x72\x2a\xe4\x1e\x95\xaa\x41\xce\x78\x25\x26\xf8\x05\x32\xa7\xa0\x36\xe0\x46\x59\xfb\xa4\xf4\x4b\x19\x6e\xc3\x8a\xef\xfd\x8c\x28\x74\x65\xc4\x40\xd2\x91\xfb\x5b\xf0\x5a\x24\x76\xcc\x9d\xd7\x15\x81\x24\x87\x7f\xcf\x7a\xde\x48\x41\x82\x0e\x36\x87\x72\x52\x7c\x40\xc7\x9f\xf1\x5d\x5d\x5d\xfe\x11\xa1\x43\x21\xa3\x3f\xa5\x3c\xff\xef\xf2\xfa\x4b\x9c\x19\x2c\x90\x56\x07\x7d\xbb\x77\x02\xa0\xdf\x74\x2b\x94\x01\x8b\x12\x86\x9a\x91\xde\xba\x36\x2a\xe6\xd7\x40\x55\xd3\xb4\x2b\xf4\x48\x4a\x7a\x84\x47\x9e\xb0\x2c\xc7\xe5\xf2\xb1\xb8\x7f\x28\x3b\x4e\xd8\xcd\x53\x0e\x27\x49\xfe\xc9\x91\xb0\xe9\xec\x66\xfc\xed\xb6\x7c\x9c\xcc\x1e\xca\xf9\xcd\x7c\x32\x2e\x67\x8f\xd3\xf9\xc3\x31\x8e\x11\x8a\x1e\xc5\x17\x33\x12\xcb\xa3\x48\x66\xa3\x04\x3b\x38\x6b\x36\xe8\x91\xb9\xa0\xf0\xd4\x4e\xd2\xfe\x18\x6f\xc4\x28\x3b\x45\xab\xb6\x4b\xd4\xc1\x57\x9c\xc3\x79\xff\xcf\x53\x8b\xfc\x8f\xd2\x0f\x04\x88\x4a\xd6\x39\x8c\xd6\xa8\xac\xac\x7f\x1e\x3a\x8f\xfd\x0c\xa1\xaa\xcc\xdf\x21\x84\x43\x4d\x1a\x7b\x13\x91\xcc\x3f\x6a\xe4\xfe\x9c\xa4\xa3\x63\x9d\xb4\x9c\xb9\x03\xbb\x43\x17\x68\x9b\xc3\xc5\x3f\xd7\x0b\xd3\xf1\x6d\x82\xad\x1d\x2e\xd2\x5e\xea\x71\x0d\xc1\x25\x5b\xb1\x2b\xdc\xe7\x7f\x06\x6d\x17\xb4\x2b\x7a\xa8\x91\x24\xad\xe1\x43\x54\xaa\xe9\xbd\xb7\xdb\x1c\x84\xea\xbd\x6b\x27\xe0\x2d\xf7\xf0\x0b\x5c\x8c\x9a\xfa\xa5\x6d\xd1\x8b\x50\x61\x0e\x57\x17\xdd\xaf\xc8\x60\xd9\xc0\xd3\xb6\xec\x6f\xb6\xa1\x18\x87\xa7\x83\x5f\x03\x00\xcf\x12\xf6\xef\x6b\x06\x00\x00")
 
 func assetsRouterDeploymentYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -134,12 +134,12 @@ func assetsRouterDeploymentYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "assets/router/deployment.yaml", size: 1723, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x73, 0x3a, 0x4, 0x3c, 0x74, 0xd2, 0x6, 0x8, 0x18, 0x6d, 0x45, 0x37, 0x5a, 0x20, 0xa0, 0x18, 0xd6, 0xcc, 0x36, 0x3a, 0x9, 0x17, 0xd3, 0x69, 0xdc, 0x15, 0x1b, 0xfa, 0x92, 0xc9, 0x2a, 0x6a}}
+	info := bindataFileInfo{name: "assets/router/deployment.yaml", size: 1643, mode: os.FileMode(420), modTime: time.Unix(1, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x4f, 0x50, 0xd3, 0xe, 0xdc, 0xc0, 0xdd, 0x54, 0x5d, 0xc, 0x67, 0x9c, 0x3b, 0x97, 0x17, 0x48, 0xd6, 0xcc, 0x6f, 0xd3, 0xe1, 0xf1, 0x2c, 0xc6, 0x2d, 0xed, 0xfe, 0x37, 0xab, 0x5a, 0xb4, 0xa9}}
 	return a, nil
 }
 
-var _assetsRouterMetricsClusterRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8f\xc1\x4a\xc4\x40\x0c\x86\xef\xf3\x14\x79\x81\x56\xbc\x2d\x73\x53\x0f\xde\x57\xf0\x9e\x9d\xa6\x36\xb6\x93\x0c\x49\xa6\x07\x9f\x5e\x8a\x22\xc2\x42\xaf\x81\x7c\xdf\xff\xad\x2c\x53\x86\x97\xad\x7b\x90\x5d\x75\xa3\x67\x96\x89\xe5\x23\x61\xe3\x77\x32\x67\x95\x0c\x76\xc3\x32\x62\x8f\x45\x8d\xbf\x30\x58\x65\x5c\x2f\x3e\xb2\x3e\xec\x8f\xa9\x52\xe0\x84\x81\x39\x01\x08\x56\xca\x60\xda\x83\x6c\xa8\x2a\x1c\x6a\x07\xcc\xfb\xed\x93\x4a\x78\x4e\x03\xfc\x18\xdf\xc8\x76\x2e\xf4\x54\x8a\x76\x89\xbf\xd7\x66\x5a\x29\x16\xea\x3e\xac\x17\xff\x3d\x7b\xc3\x42\x19\xb4\x91\xf8\xc2\x73\xfc\x27\x9b\x6e\x74\xa5\xf9\x90\xdf\xa5\x9c\x0c\x02\xc0\xc6\xaf\xa6\xbd\x9d\xd4\xa5\xef\x00\x00\x00\xff\xff\x7f\xc0\x4a\x40\x1d\x01\x00\x00")
+var _assetsRouterMetricsClusterRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8f\xc1\x4a\xc4\x40\x0c\x86\xef\xf3\x14\x79\x81\x56\xbc\x2d\x73\x53\x0f\xde\x57\xf0\x9e\x9d\xa6\x36\xb6\x93\x0c\x49\xa6\x07\x9f\x5e\x8a\x22\xc2\x42\xaf\x81\x7c\xdf\xff\xad\x2c\x53\x86\x97\xad\x7b\x90\x5d\x75\xa3\x67\x96\x89\xe5\x23\x61\xe3\x77\x32\x67\x95\x0c\x76\xc3\x32\x62\x8f\x45\x8d\xbf\x30\x58\x65\x5c\x2f\x3e\xb2\x3e\xec\x8f\xa9\x52\xe0\x84\x81\x39\x01\x08\x56\xca\x60\xda\x83\x6c\xa8\x2a\x1c\x6a\x07\xcc\xfb\xed\x93\x4a\x78\x4e\x03\xfc\x18\xdf\xc8\x76\x2e\xf4\x54\x8a\x76\x89\xbf\xd7\x66\x5a\x29\x16\xea\x3e\xac\x17\xff\x3d\x7b\xc3\x42\x19\xb4\x91\xf8\xc2\x73\xfc\x27\x9b\x6e\x74\xa5\xf9\x90\xdf\xa5\x9c\x0c\x02\xc0\xc6\xaf\xa6\xbd\x9d\xd4\xa5\xef\x01\x00\x7f\xc0\x4a\x40\x1d\x01\x00\x00")
 
 func assetsRouterMetricsClusterRoleBindingYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -159,7 +159,7 @@ func assetsRouterMetricsClusterRoleBindingYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterMetricsClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\xce\xb1\x4e\xc4\x40\x0c\x84\xe1\x7e\x9f\xc2\x12\x75\x72\xa2\x43\x69\x29\xe8\x29\xe8\x9d\xec\x70\xb1\x2e\x6b\xaf\x6c\xef\x49\xf0\xf4\xe8\x44\x90\xa8\xe7\x93\xfe\x79\xa2\xd7\x63\x44\xc2\xc9\xed\x40\x90\x02\x15\x95\xd6\x2f\xea\x6e\x0d\xb9\x63\x04\xa5\x51\x6c\xce\x1d\xe4\x36\x1e\xb6\x21\x5d\xb6\x20\x68\xed\x26\x9a\x85\xbb\x7c\xc0\x43\x4c\x17\xf2\x95\xb7\x99\x47\xee\xe6\xf2\xcd\x29\xa6\xf3\xed\x25\x66\xb1\xcb\xfd\xb9\xdc\x44\xeb\xf2\xd7\x7c\xb7\x03\xa5\x21\xb9\x72\xf2\x52\x88\x94\x1b\x96\x33\x32\x35\x53\x49\x73\xd1\x6b\xf1\x71\x20\x96\x32\x11\x77\x79\x73\x1b\x3d\x1e\x7a\xfa\x95\xb3\x75\x68\xec\xf2\x99\xb3\x58\x21\x72\x84\x0d\xdf\xf0\xdf\x78\x5c\xce\xcf\x85\xe8\x0e\x5f\xcf\xf1\x8a\x2c\x3f\x01\x00\x00\xff\xff\x4f\xd5\xdf\xe0\x03\x01\x00\x00")
+var _assetsRouterMetricsClusterRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\xce\x31\x4b\x03\x41\x10\x86\xe1\x7e\x7f\xc5\x07\xd6\x77\xc1\x4e\xb6\xb5\xb0\xb7\xb0\xdf\xdc\x7d\xe6\x86\xdc\xcd\x2c\x33\xb3\x01\xfd\xf5\x12\x8c\x60\xff\xc0\xfb\x3e\xe1\x75\x1f\x91\x74\xb8\xed\x0c\x28\xb9\x72\xc5\xf9\x0b\xdd\xed\x60\x6e\x1c\x81\x34\xc4\xe2\xad\x13\x6e\xe3\x6e\x0f\xa6\xcb\x12\xa0\xae\xdd\x44\xb3\xb4\x2e\x1f\xf4\x10\xd3\x0a\x3f\xb7\x65\x6e\x23\x37\x73\xf9\x6e\x29\xa6\xf3\xf5\x25\x66\xb1\xd3\xed\xb9\x5c\x45\xd7\xfa\xd7\x7c\xb7\x9d\xe5\x60\xb6\xb5\x65\xab\x05\xd0\x76\xb0\x3e\x22\xd3\x61\x2a\x69\x2e\x7a\x29\x3e\x76\x46\x2d\x13\x5a\x97\x37\xb7\xd1\xe3\xae\xa7\x5f\x39\x5b\xa7\xc6\x26\x9f\x39\x8b\x15\xc0\x19\x36\x7c\xe1\x7f\xe3\x71\x7a\x3c\x17\xe0\x46\x3f\x47\x2d\xc0\x84\x0b\xb3\xfc\x0c\x00\x4f\xd5\xdf\xe0\x03\x01\x00\x00")
 
 func assetsRouterMetricsClusterRoleYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -179,7 +179,7 @@ func assetsRouterMetricsClusterRoleYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterMetricsRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\xce\x31\x4e\xc5\x40\x0c\x04\xd0\x7e\x4f\xe1\x0b\x24\x88\xee\x6b\x3b\x68\xe8\x3f\x12\xbd\xb3\x71\x12\x93\xac\xbd\xb2\xbd\x29\x38\x3d\x42\x8a\x44\x05\xd2\x6f\x47\x33\x9a\x87\x8d\x3f\xc8\x9c\x55\x32\xd8\x84\x65\xc4\x1e\x9b\x1a\x7f\x61\xb0\xca\xb8\xdf\x7c\x64\x7d\x3a\x9f\xd3\xce\x32\x67\xb8\xeb\x41\xaf\x2c\x33\xcb\x9a\x2a\x05\xce\x18\x98\x13\x80\x60\xa5\x0c\xcd\xb4\x52\x6c\xd4\x7d\xd8\x6f\x7e\xc5\xde\xb0\x50\x06\x6d\x24\xbe\xf1\x12\x03\xcb\x6a\xe4\x9e\x4c\x0f\xba\xd3\xf2\x33\xc7\xc6\x6f\xa6\xbd\xfd\x63\x48\x00\xbf\x84\xbf\x1e\xbd\x4f\x9f\x54\xc2\x73\x1a\xae\xf6\x3b\xd9\xc9\x85\x5e\x4a\xd1\x2e\xf1\xa0\xb4\xaa\x70\xa8\xb1\xac\x90\xbe\x03\x00\x00\xff\xff\x15\x9f\x30\x56\x29\x01\x00\x00")
+var _assetsRouterMetricsRoleBindingYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\xce\x31\x4e\xc5\x40\x0c\x04\xd0\x7e\x4f\xe1\x0b\x24\x88\xee\x6b\x3b\x68\xe8\x3f\x12\xbd\xb3\x71\x12\x93\xac\xbd\xb2\xbd\x29\x38\x3d\x42\x8a\x44\x05\xd2\x6f\x47\x33\x9a\x87\x8d\x3f\xc8\x9c\x55\x32\xd8\x84\x65\xc4\x1e\x9b\x1a\x7f\x61\xb0\xca\xb8\xdf\x7c\x64\x7d\x3a\x9f\xd3\xce\x32\x67\xb8\xeb\x41\xaf\x2c\x33\xcb\x9a\x2a\x05\xce\x18\x98\x13\x80\x60\xa5\x0c\xcd\xb4\x52\x6c\xd4\x7d\xd8\x6f\x7e\xc5\xde\xb0\x50\x06\x6d\x24\xbe\xf1\x12\x03\xcb\x6a\xe4\x9e\x4c\x0f\xba\xd3\xf2\x33\xc7\xc6\x6f\xa6\xbd\xfd\x63\x48\x00\xbf\x84\xbf\x1e\xbd\x4f\x9f\x54\xc2\x73\x1a\xae\xf6\x3b\xd9\xc9\x85\x5e\x4a\xd1\x2e\xf1\xa0\xb4\xaa\x70\xa8\xb1\xac\x90\xbe\x07\x00\x15\x9f\x30\x56\x29\x01\x00\x00")
 
 func assetsRouterMetricsRoleBindingYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -199,7 +199,7 @@ func assetsRouterMetricsRoleBindingYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterMetricsRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\x8e\xb1\x6e\xeb\x30\x0c\x45\x77\x7d\x05\x91\x37\x3b\x0f\xdd\x02\xfd\x40\xf7\x0e\xdd\x19\xe9\x36\x26\x62\x8b\x02\x49\xb9\x68\xbf\xbe\x88\xe3\x02\x9d\x78\xef\x01\xc1\xc3\x7f\xf4\xa6\x0b\x9c\x1a\x50\x51\xe9\xfa\x45\xdd\x74\x45\xcc\x18\x4e\xa1\xe4\xc5\xb8\x83\x4c\x47\xc0\x68\x45\x98\x14\x27\xb4\xda\x55\x5a\x24\xee\xf2\x0e\x73\xd1\x96\xc9\xae\x5c\xce\x3c\x62\x56\x93\x6f\x0e\xd1\x76\xbe\x5f\xfc\x2c\xfa\x7f\x7b\x49\x77\x69\x35\xef\xae\xb4\x22\xb8\x72\x70\x4e\x44\x8d\x57\xe4\x3f\xca\xe9\x7e\xf1\x03\x7b\xe7\x82\x4c\xda\xd1\x7c\x96\x8f\x98\xa4\xdd\x0c\xee\xc9\xc6\x02\xcf\x69\x22\xee\xf2\x6a\x3a\xba\x3f\x2e\x4d\x74\x3a\x25\x22\x83\xeb\xb0\x82\x83\x39\x6c\x93\x02\xdf\xcb\xef\xd7\xcf\xd6\xb5\x3e\xc2\x06\xbb\x1e\xcb\x37\xc4\x3e\x17\xf1\x67\xf8\xe4\x28\x73\xfa\x09\x00\x00\xff\xff\x67\x78\x6f\x08\x23\x01\x00\x00")
+var _assetsRouterMetricsRoleYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\x8e\xb1\x6e\xeb\x30\x0c\x45\x77\x7d\x05\x91\x37\x3b\x0f\xdd\x02\xfd\x40\xf7\x0e\xdd\x19\xe9\x36\x26\x62\x8b\x02\x49\xb9\x68\xbf\xbe\x70\x62\x14\x9d\x78\x79\x41\x9c\xc3\x7f\xf4\xa6\x0b\x9c\x1a\x50\x51\xe9\xfa\x45\xdd\x74\x45\xcc\x18\x4e\xa1\xe4\xc5\xb8\x83\x4c\x47\xc0\x68\x45\x98\x14\x27\xb4\xda\x55\x5a\x24\xee\xf2\x0e\x73\xd1\x96\xc9\xae\x5c\xce\x3c\x62\x56\x93\x6f\x0e\xd1\x76\xbe\x5f\xfc\x2c\xfa\x7f\x7b\x49\x77\x69\x35\x3f\x5c\x69\x45\x70\xe5\xe0\x9c\x88\x1a\xaf\xc8\x7f\x94\xd3\xfd\xe2\x47\xed\x9d\x0b\x32\x69\x47\xf3\x59\x3e\x62\x92\x76\x33\xb8\x27\x1b\x0b\x3c\xa7\x89\xb8\xcb\xab\xe9\xe8\xbe\x93\x26\x3a\x9d\x12\x91\xc1\x75\x58\xc1\xd1\x39\x6c\x93\x82\x9d\x39\xfd\x7e\xfd\xdc\xba\xd6\x3d\x6c\xb0\xeb\x71\x7c\x43\x3c\xe6\x22\xfe\x0c\x9f\x1c\x65\x4e\x3f\x03\x00\x67\x78\x6f\x08\x23\x01\x00\x00")
 
 func assetsRouterMetricsRoleYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -219,7 +219,7 @@ func assetsRouterMetricsRoleYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterNamespaceYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\xcf\xb1\x4e\xc3\x50\x0c\x85\xe1\x3d\x4f\x71\x14\xe6\x14\xb1\xde\x77\x80\x05\x89\xdd\xbd\x71\x53\x93\x1b\x3b\xb2\x9d\x56\xbc\x3d\xa2\x05\x51\x84\x3a\xfb\xd3\x2f\x9f\x59\x74\x2c\x78\xa1\x85\x63\xa5\xca\x1d\xad\xf2\xc6\x1e\x62\x5a\x70\x7a\xea\x16\x4e\x1a\x29\xa9\x74\x80\xd2\xc2\x05\xb6\xb2\xc6\x51\x0e\x39\x88\x4e\xce\x11\x1d\x40\xaa\x96\x94\x62\x1a\x5f\x10\xbf\x68\x27\xf6\xa8\x36\xf2\x10\xdc\xb8\xa6\x79\x41\xdf\x77\x40\xa3\x3d\xb7\x6f\xfc\x00\x6a\xcd\xce\x37\xe5\xc5\x54\xd2\x5c\x74\x42\x1a\x9a\xd9\x8c\x83\x39\x5e\xd9\x4f\x52\xf9\xf9\x7a\x85\xed\xdf\xb9\x66\x40\x14\x79\x94\xb8\xfc\x77\x1d\xf1\xef\x85\xda\xb6\x48\xf6\x9b\x70\x41\x9f\xbe\x71\x7f\xb1\xf7\x97\x01\xca\x79\x36\x9f\x77\x7f\x7a\xab\x35\xa9\x1f\xc3\xe4\xb6\xad\x05\x3f\xfc\x33\x00\x00\xff\xff\xfc\x31\x60\x23\x4c\x01\x00\x00")
+var _assetsRouterNamespaceYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8f\xc1\x4a\x03\x51\x0c\x45\xf7\xef\x2b\x2e\x75\x3d\x15\xb7\xef\x1f\x74\x23\xb8\x4f\xdf\xa4\xd3\x38\x6f\x92\x21\xc9\xb4\xf8\xf7\x52\x2b\x58\x11\x5c\xdf\xc3\xe1\xdc\x59\x74\xac\x78\xa1\x85\x63\xa5\xc6\x85\x56\x79\x63\x0f\x31\xad\x38\x3f\x95\x85\x93\x46\x4a\xaa\x05\x50\x5a\xb8\xc2\x56\xd6\x38\xc9\x31\x07\xd1\xc9\x39\xa2\x00\xa4\x6a\x49\x29\xa6\x71\x05\xf1\x03\xed\xc5\x1e\xd5\x46\x1e\x82\x3b\xb7\x34\xaf\xd8\xed\x0a\xd0\xe9\xc0\xfd\x1b\x7e\x00\xf5\x6e\x97\x3b\xf3\x62\x2a\x69\x2e\x3a\x21\x0d\xdd\x6c\xc6\xd1\x1c\xaf\xec\x67\x69\xfc\x7c\x5b\x61\x87\x77\x6e\x19\x10\x45\x9e\x24\xbe\xfa\x6e\x27\xfe\x24\xb4\xbe\x45\xb2\xdf\x89\x2b\x76\xe9\x1b\x5f\x5b\xfe\x7b\x06\x28\xe7\xc5\x7c\xde\xff\xf2\xad\xd6\xa5\x7d\x0c\x93\xdb\xb6\x56\x88\x4e\xce\x11\xe5\x73\x00\xfc\x31\x60\x23\x4c\x01\x00\x00")
 
 func assetsRouterNamespaceYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -239,7 +239,7 @@ func assetsRouterNamespaceYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterServiceAccountYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x2c\xce\xb1\x4e\xc4\x30\x10\x84\xe1\xde\x4f\x31\xd2\xd5\x9c\x44\xeb\x8e\x92\x16\x24\x7a\xb3\x99\xbb\x5b\x91\x78\xcd\xee\x3a\x88\xb7\x47\x41\x29\xa7\x98\x5f\xdf\x05\x2f\x22\x36\x7b\xe2\x66\x0e\xb7\x99\xf4\x80\x38\x5b\x72\xc1\xe7\x2f\xf2\x41\xd8\xa0\xb7\x34\xbf\xe2\x35\xf1\xa3\xeb\x0a\xe7\xf7\x54\x27\x64\x9d\x91\x74\x84\xd8\xe0\x52\x2e\x18\xf4\x4d\x23\xd4\x7a\xc0\xb9\xfe\x57\xd2\xf0\x76\x84\x31\xdc\x84\x11\xda\xef\xd7\xf2\xa5\x7d\xa9\x78\xa7\xef\x2a\x3c\x0d\xa5\x0d\xfd\xa0\x1f\xef\x8a\xfd\xb9\x6c\xcc\xb6\xb4\x6c\xb5\x00\xbd\x6d\xac\x27\xf0\x9c\x31\x9a\xb0\x1e\xba\x1e\x0f\xbd\xe5\x93\xf6\xbb\x33\xa2\xfc\x05\x00\x00\xff\xff\x33\xdc\xda\x8c\xd5\x00\x00\x00")
+var _assetsRouterServiceAccountYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x2c\xce\xb1\x4e\xc4\x30\x10\x84\xe1\xde\x4f\x31\xd2\xd5\x9c\x44\xeb\x8e\x92\x16\x24\x7a\xb3\x99\xbb\x5b\x91\x78\xcd\xee\x3a\x88\xb7\x47\x41\x29\xa7\x98\x5f\xdf\x05\x2f\x22\x36\x7b\xe2\x66\x0e\xb7\x99\xf4\x80\x38\x5b\x72\xc1\xe7\x2f\xf2\x41\xd8\xa0\xb7\x34\xbf\xe2\x35\xf1\xa3\xeb\x0a\xe7\xf7\x54\x27\x64\x9d\x91\x74\x84\xd8\xe0\x52\x2e\x18\xf4\x4d\x23\xd4\x7a\xc0\xb9\xfe\x57\xd2\xf0\x76\x84\x31\xdc\x84\x11\xda\xef\xd7\xf2\xa5\x7d\xa9\x78\xa7\xef\x2a\x3c\x0d\xa5\x0d\xfd\xa0\x1f\xef\x8a\xfd\xb9\x6c\xcc\xb6\xb4\x6c\xb5\x00\xbd\x6d\xac\x27\xf0\x9c\x31\x9a\xb0\x1e\xba\x1e\x0f\xbd\xe5\x93\xf6\xbb\x33\xa2\xfc\x0d\x00\x33\xdc\xda\x8c\xd5\x00\x00\x00")
 
 func assetsRouterServiceAccountYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -259,7 +259,7 @@ func assetsRouterServiceAccountYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterServiceCloudYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\x41\x6b\x14\x41\x10\x85\xef\xfd\x2b\x1e\xec\x39\x41\x31\x07\x99\x63\x72\x12\x82\x2c\xb8\x78\xaf\xf4\xd4\xec\x34\xe9\xa9\x6a\xaa\x6a\x56\xf7\xdf\xcb\xf4\xec\x82\xa2\x78\xec\x07\xf5\xfa\x7b\xdf\x01\xaf\x4a\x23\x9e\xa9\x92\x64\x36\x7c\x63\xbb\x94\xcc\x08\x45\xab\x94\x19\x45\x30\x99\x4a\x40\x27\xc4\xcc\x30\x5d\x83\x6d\x8b\x73\xd5\x75\x04\xcb\xa5\x98\xca\xc2\x12\xfe\x98\x0e\xf8\x22\x67\x63\x77\xbc\xa8\x84\x69\xad\x6c\xf0\xc6\xb9\x4c\x25\xe3\x42\x75\x65\x07\x19\x83\x5a\xab\x85\x47\x50\xc0\x56\x89\xb2\xf0\x63\x7a\x2f\x32\x0e\x77\x82\x44\xad\x7c\x67\xf3\xa2\x32\xe0\xf2\x31\x2d\x1c\x34\x52\xd0\x90\x80\x03\xbe\xd2\xc2\x28\x0e\xe7\xf8\xa3\x02\x10\x5a\xd8\x1b\x65\x1e\xa0\x8d\xc5\xe7\x32\xc5\x43\xd9\xa1\x12\x50\xe9\x8d\xab\x6f\x25\xd8\x18\x86\xdb\x9e\xb4\x31\x6e\x69\x5c\x1b\x0f\xdd\xc9\x5d\x49\x02\x9c\x2b\xe7\x50\xfb\xfb\x6c\x63\x39\xcd\xc5\x41\xd5\x15\x33\x79\x77\xc4\xd3\xc4\xb9\x1b\x5b\xc8\xde\x8b\x9c\xf1\xfa\x8c\xa6\x5a\x11\x64\x67\x0e\x07\x39\x56\x99\x99\x6a\xcc\x57\xfc\x98\x59\x20\xda\xcb\x6e\x7a\x9b\x8e\xbb\xa7\x66\xec\xbc\xd9\x17\x10\x44\x47\xc6\x1b\xcf\x45\xc6\xfe\x8f\xef\xaa\xb6\xd9\xfc\x33\xd8\x84\xea\xc9\x68\x9a\x4a\x3e\x6a\x2d\xf9\xba\x0d\xc9\x54\x13\xd0\xd4\xa2\xaf\x7e\xe8\x82\x06\xcc\x11\xad\xaf\x69\xa6\xa1\x59\xeb\x80\xd3\xcb\x71\x4f\xd4\x62\xc0\xe7\x0f\xfd\xb1\x03\x1f\x7b\x74\xbb\xf9\xbd\xc2\xff\xdb\xf1\xf4\xf4\xe9\x9f\x25\x9e\x7e\x05\x00\x00\xff\xff\x56\xdc\x0d\xe9\x77\x02\x00\x00")
+var _assetsRouterServiceCloudYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\x41\x6b\x14\x41\x10\x85\xef\xfd\x2b\x1e\xec\x39\x8b\x62\x0e\x32\xc7\xe4\x24\x04\x59\x70\xf1\x5e\xe9\xa9\xd9\x69\xd2\x53\xd5\x54\xd5\xac\xee\xbf\x97\xe9\xd9\x80\xa2\x78\xec\x07\xf5\xfa\x7b\xdf\x01\x2f\x4a\x23\x9e\xa8\x92\x64\x36\x7c\x63\xbb\x96\xcc\x08\x45\xab\x94\x19\x45\x30\x99\x4a\x40\x27\xc4\xcc\x30\x5d\x83\x6d\x8b\x73\xd5\x75\x04\xcb\xb5\x98\xca\xc2\x12\x7e\x4c\x07\x7c\x91\x8b\xb1\x3b\x9e\x55\xc2\xb4\x56\x36\x78\xe3\x5c\xa6\x92\x71\xa5\xba\xb2\x83\x8c\x41\xad\xd5\xc2\x23\x28\x60\xab\x44\x59\xf8\x98\xde\x8a\x8c\xc3\x3b\x41\xa2\x56\xbe\xb3\x79\x51\x19\x70\xfd\x98\x16\x0e\x1a\x29\x68\x48\xc0\x01\x5f\x69\x61\x14\x87\x73\xfc\x51\x01\x08\x2d\xec\x8d\x32\x0f\xd0\xc6\xe2\x73\x99\xe2\xa1\xec\x50\x09\xa8\xf4\xca\xd5\xb7\x12\x6c\x0c\xc3\x7d\x4f\xda\x18\xb7\x34\x6e\x8d\x87\xee\xe4\x5d\x49\x02\x9c\x2b\xe7\x50\xfb\xfb\x6c\x63\x39\xcf\xc5\x41\xd5\x15\x33\x79\x77\xc4\xd3\xc4\xb9\x1b\x5b\xc8\xde\x8a\x5c\xf0\xf2\x84\xa6\x5a\x11\x64\x17\x0e\x07\x39\x56\x99\x99\x6a\xcc\x37\xfc\x98\x59\x20\xda\x87\xdd\xf5\x36\x1d\x77\x4f\xcd\xd8\x79\xb3\x2f\x20\x88\x8e\x8c\x57\x9e\x8b\x8c\xfd\x1f\xdf\x55\x1d\x13\xc0\x3f\x83\x4d\xa8\x9e\x8d\xa6\xa9\xe4\x93\xd6\x92\x6f\xdb\x90\x4c\x35\x01\x4d\x2d\xfa\xea\x87\x2e\x68\xc0\x1c\xd1\xfa\x9a\x66\x1a\x9a\xb5\x0e\x38\x3f\x9f\xf6\x44\x2d\x06\x7c\xfe\xd0\x1f\x3b\xf0\xa9\x47\xf7\x9b\xdf\x2b\xfc\xbf\x1d\x8f\x8f\x9f\xfe\x59\xe2\xe9\xd7\x00\x56\xdc\x0d\xe9\x77\x02\x00\x00")
 
 func assetsRouterServiceCloudYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -279,7 +279,7 @@ func assetsRouterServiceCloudYaml() (*asset, error) {
 	return a, nil
 }
 
-var _assetsRouterServiceInternalYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\xcf\x31\x6b\xfb\x30\x10\x05\xf0\x5d\x9f\xe2\x41\xd6\xff\xbf\x34\x24\x94\x56\xab\xa7\x6c\x86\x96\xee\x87\x7c\x49\x8e\xca\x92\xb8\x3b\xbb\xf4\xdb\x97\x38\x0d\xb8\x74\xc9\x22\x90\xee\xe9\xf7\xb8\x0d\xba\x3c\x99\xb3\xe2\x95\x75\x96\xc4\xf8\x14\x3f\x63\xe0\x23\x4d\xd9\x31\x53\x9e\xd8\xc2\x06\x87\x72\x52\x36\x43\x57\x8b\x6b\xcd\x99\x15\xd6\x38\xc9\x51\x12\xa8\x94\xea\xe4\x52\x8b\x81\x94\x41\xad\x65\xe1\x01\xe4\xd0\xa9\xb8\x8c\xfc\x10\x3e\xa4\x0c\xf1\xd6\x11\xa8\xc9\x3b\xab\x49\x2d\x11\xf3\x36\x6c\x50\x68\xe4\x7f\xcb\x69\x8d\x12\x83\xca\xf0\x87\x35\xf6\x5f\xe4\xa5\x3f\x06\xc0\xbf\x1a\xc7\xdb\x1a\x87\x3e\x00\xad\xaa\xdb\x65\xf4\x7f\x21\x23\xce\xee\x2d\x00\xd7\x49\xc4\xf3\xe3\xf5\xa2\xd5\x6b\xaa\x39\xe2\xad\xeb\x97\x17\x27\x3d\xb1\xf7\x4b\xe8\xe7\xcf\x9a\xb0\x95\xb1\xdf\xef\xee\x44\x6c\xa5\x8c\xec\x2a\x69\xed\x6c\x5f\x76\x4f\x77\x40\x4b\xec\x3b\x00\x00\xff\xff\x90\x5e\x33\xca\xad\x01\x00\x00")
+var _assetsRouterServiceInternalYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\xcf\x31\x6b\xc3\x30\x10\x05\xe0\x5d\xbf\xe2\x81\xd7\xb6\xd4\x38\x84\x46\xab\xa7\x6c\x81\x96\xee\x87\x7c\x49\x44\x65\x49\xdc\x9d\x5d\xfa\xef\x8b\x13\x52\x5c\xb2\x64\x11\x88\xc7\xfb\x1e\xd7\xa0\x4f\x93\x1a\x0b\xde\x59\xe6\x18\x18\xdf\xd1\xce\x18\xf8\x48\x53\x32\xcc\x94\x26\x56\xd7\x60\x9f\x4f\xc2\xaa\xe8\x4b\x36\x29\x29\xb1\x40\x2b\x87\x78\x8c\x01\x94\x73\x31\xb2\x58\xb2\x82\x84\x41\xb5\xa6\xc8\x03\xc8\x20\x53\xb6\x38\xf2\x8b\xfb\x8a\x79\xf0\xb7\x0d\x47\x35\x7e\xb2\x68\x2c\xd9\x63\x6e\x5d\x83\x4c\x23\x3f\x5d\x5e\xad\x14\x18\x94\x87\x3b\x56\xd9\xfe\x91\xcb\xbe\x77\x80\xfd\x54\xf6\xb7\x33\xf6\x07\x07\xd4\x22\xa6\x4b\xf4\x7c\x21\x3d\xce\x66\xd5\x01\xd7\xc4\xe3\xed\xf5\xfa\x91\x62\x25\x94\xe4\xf1\xd1\x2f\x35\xc0\x48\x4e\x6c\x87\x22\xf6\xd7\x59\x13\xba\x32\x36\x9b\xee\x41\x44\x57\xca\xc8\x26\x31\xac\x9d\x76\xd7\x6d\x1f\x80\xda\x5d\xb7\x75\xbf\x03\x00\x90\x5e\x33\xca\xad\x01\x00\x00")
 
 func assetsRouterServiceInternalYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -454,21 +454,21 @@ type bintree struct {
 }
 
 var _bintree = &bintree{nil, map[string]*bintree{
-	"assets": {nil, map[string]*bintree{
-		"router": {nil, map[string]*bintree{
-			"cluster-role-binding.yaml": {assetsRouterClusterRoleBindingYaml, map[string]*bintree{}},
-			"cluster-role.yaml":         {assetsRouterClusterRoleYaml, map[string]*bintree{}},
-			"deployment.yaml":           {assetsRouterDeploymentYaml, map[string]*bintree{}},
-			"metrics": {nil, map[string]*bintree{
-				"cluster-role-binding.yaml": {assetsRouterMetricsClusterRoleBindingYaml, map[string]*bintree{}},
-				"cluster-role.yaml":         {assetsRouterMetricsClusterRoleYaml, map[string]*bintree{}},
-				"role-binding.yaml":         {assetsRouterMetricsRoleBindingYaml, map[string]*bintree{}},
-				"role.yaml":                 {assetsRouterMetricsRoleYaml, map[string]*bintree{}},
+	"assets": &bintree{nil, map[string]*bintree{
+		"router": &bintree{nil, map[string]*bintree{
+			"cluster-role-binding.yaml": &bintree{assetsRouterClusterRoleBindingYaml, map[string]*bintree{}},
+			"cluster-role.yaml":         &bintree{assetsRouterClusterRoleYaml, map[string]*bintree{}},
+			"deployment.yaml":           &bintree{assetsRouterDeploymentYaml, map[string]*bintree{}},
+			"metrics": &bintree{nil, map[string]*bintree{
+				"cluster-role-binding.yaml": &bintree{assetsRouterMetricsClusterRoleBindingYaml, map[string]*bintree{}},
+				"cluster-role.yaml":         &bintree{assetsRouterMetricsClusterRoleYaml, map[string]*bintree{}},
+				"role-binding.yaml":         &bintree{assetsRouterMetricsRoleBindingYaml, map[string]*bintree{}},
+				"role.yaml":                 &bintree{assetsRouterMetricsRoleYaml, map[string]*bintree{}},
 			}},
-			"namespace.yaml":        {assetsRouterNamespaceYaml, map[string]*bintree{}},
-			"service-account.yaml":  {assetsRouterServiceAccountYaml, map[string]*bintree{}},
-			"service-cloud.yaml":    {assetsRouterServiceCloudYaml, map[string]*bintree{}},
-			"service-internal.yaml": {assetsRouterServiceInternalYaml, map[string]*bintree{}},
+			"namespace.yaml":        &bintree{assetsRouterNamespaceYaml, map[string]*bintree{}},
+			"service-account.yaml":  &bintree{assetsRouterServiceAccountYaml, map[string]*bintree{}},
+			"service-cloud.yaml":    &bintree{assetsRouterServiceCloudYaml, map[string]*bintree{}},
+			"service-internal.yaml": &bintree{assetsRouterServiceInternalYaml, map[string]*bintree{}},
 		}},
 	}},
 }}
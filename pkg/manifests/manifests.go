@@ -47,19 +47,22 @@ func MustAssetReader(asset string) io.Reader {
 	return bytes.NewReader(MustAsset(asset))
 }
 
-func RouterNamespace() *corev1.Namespace {
+func RouterNamespace(name string) *corev1.Namespace {
 	ns, err := NewNamespace(MustAssetReader(RouterNamespaceAsset))
 	if err != nil {
 		panic(err)
 	}
+	ns.Name = name
+	ns.Labels["name"] = name
 	return ns
 }
 
-func RouterServiceAccount() *corev1.ServiceAccount {
+func RouterServiceAccount(namespace string) *corev1.ServiceAccount {
 	sa, err := NewServiceAccount(MustAssetReader(RouterServiceAccountAsset))
 	if err != nil {
 		panic(err)
 	}
+	sa.Namespace = namespace
 	return sa
 }
 
@@ -71,19 +74,22 @@ func RouterClusterRole() *rbacv1.ClusterRole {
 	return cr
 }
 
-func RouterClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+func RouterClusterRoleBinding(namespace string) *rbacv1.ClusterRoleBinding {
 	crb, err := NewClusterRoleBinding(MustAssetReader(RouterClusterRoleBindingAsset))
 	if err != nil {
 		panic(err)
 	}
+	for i := range crb.Subjects {
+		crb.Subjects[i].Namespace = namespace
+	}
 	return crb
 }
 
-func RouterStatsSecret(cr *operatorv1.IngressController) *corev1.Secret {
+func RouterStatsSecret(cr *operatorv1.IngressController, namespace string) *corev1.Secret {
 	s := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("router-stats-%s", cr.Name),
-			Namespace: "openshift-ingress",
+			Namespace: namespace,
 		},
 		Type: corev1.SecretTypeOpaque,
 		Data: map[string][]byte{},
@@ -128,27 +134,41 @@ func MetricsClusterRole() *rbacv1.ClusterRole {
 	return cr
 }
 
-func MetricsClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+// MetricsClusterRoleBinding returns the cluster role binding that lets the
+// prometheus service account in the given monitoring namespace scrape router
+// metrics.
+func MetricsClusterRoleBinding(monitoringNamespace string) *rbacv1.ClusterRoleBinding {
 	crb, err := NewClusterRoleBinding(MustAssetReader(MetricsClusterRoleBindingAsset))
 	if err != nil {
 		panic(err)
 	}
+	for i := range crb.Subjects {
+		crb.Subjects[i].Namespace = monitoringNamespace
+	}
 	return crb
 }
 
-func MetricsRole() *rbacv1.Role {
+func MetricsRole(namespace string) *rbacv1.Role {
 	r, err := NewRole(MustAssetReader(MetricsRoleAsset))
 	if err != nil {
 		panic(err)
 	}
+	r.Namespace = namespace
 	return r
 }
 
-func MetricsRoleBinding() *rbacv1.RoleBinding {
+// MetricsRoleBinding returns the role binding in the given namespace that lets
+// the prometheus service account in the given monitoring namespace discover
+// the router's metrics endpoints.
+func MetricsRoleBinding(namespace, monitoringNamespace string) *rbacv1.RoleBinding {
 	rb, err := NewRoleBinding(MustAssetReader(MetricsRoleBindingAsset))
 	if err != nil {
 		panic(err)
 	}
+	rb.Namespace = namespace
+	for i := range rb.Subjects {
+		rb.Subjects[i].Namespace = monitoringNamespace
+	}
 	return rb
 }
 
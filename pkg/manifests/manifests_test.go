@@ -29,18 +29,63 @@ func TestManifests(t *testing.T) {
 		},
 	}
 
-	RouterServiceAccount()
+	RouterServiceAccount("openshift-ingress")
 	RouterClusterRole()
-	RouterClusterRoleBinding()
-	RouterStatsSecret(ci)
+	RouterClusterRoleBinding("openshift-ingress")
+	RouterStatsSecret(ci, "openshift-ingress")
 
 	MetricsClusterRole()
-	MetricsClusterRoleBinding()
-	MetricsRole()
-	MetricsRoleBinding()
+	MetricsClusterRoleBinding("openshift-monitoring")
+	MetricsRole("openshift-ingress")
+	MetricsRoleBinding("openshift-ingress", "openshift-monitoring")
 
-	RouterNamespace()
+	RouterNamespace("openshift-ingress")
 	RouterDeployment()
 	InternalIngressControllerService()
 	LoadBalancerService()
 }
+
+func TestManifestsOperandNamespace(t *testing.T) {
+	const namespace = "custom-ingress"
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	if ns := RouterNamespace(namespace); ns.Name != namespace || ns.Labels["name"] != namespace {
+		t.Errorf("expected namespace %q with label name=%q, got %q with labels %v", namespace, namespace, ns.Name, ns.Labels)
+	}
+	if sa := RouterServiceAccount(namespace); sa.Namespace != namespace {
+		t.Errorf("expected service account namespace %q, got %q", namespace, sa.Namespace)
+	}
+	for _, subject := range RouterClusterRoleBinding(namespace).Subjects {
+		if subject.Namespace != namespace {
+			t.Errorf("expected cluster role binding subject namespace %q, got %q", namespace, subject.Namespace)
+		}
+	}
+	if s := RouterStatsSecret(ci, namespace); s.Namespace != namespace {
+		t.Errorf("expected stats secret namespace %q, got %q", namespace, s.Namespace)
+	}
+	if r := MetricsRole(namespace); r.Namespace != namespace {
+		t.Errorf("expected metrics role namespace %q, got %q", namespace, r.Namespace)
+	}
+	if rb := MetricsRoleBinding(namespace, "openshift-monitoring"); rb.Namespace != namespace {
+		t.Errorf("expected metrics role binding namespace %q, got %q", namespace, rb.Namespace)
+	}
+}
+
+func TestManifestsMonitoringNamespace(t *testing.T) {
+	const monitoringNamespace = "custom-monitoring"
+
+	for _, subject := range MetricsClusterRoleBinding(monitoringNamespace).Subjects {
+		if subject.Namespace != monitoringNamespace {
+			t.Errorf("expected metrics cluster role binding subject namespace %q, got %q", monitoringNamespace, subject.Namespace)
+		}
+	}
+	for _, subject := range MetricsRoleBinding("openshift-ingress", monitoringNamespace).Subjects {
+		if subject.Namespace != monitoringNamespace {
+			t.Errorf("expected metrics role binding subject namespace %q, got %q", monitoringNamespace, subject.Namespace)
+		}
+	}
+}
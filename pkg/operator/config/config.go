@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // Config is configuration for the operator and should include things like
 // operated images, scheduling configuration, etc.
 type Config struct {
@@ -9,6 +11,101 @@ type Config struct {
 	// Namespace is the operator namespace.
 	Namespace string
 
+	// OperandNamespace is the namespace in which the operator manages the
+	// router deployments and related resources.
+	OperandNamespace string
+
 	// IngressControllerImage is the ingress controller image to manage.
 	IngressControllerImage string
+
+	// MonitoringNamespace is the namespace of the monitoring stack whose
+	// prometheus service account is granted access to router metrics.
+	MonitoringNamespace string
+
+	// OperandLabels are extra labels to set on the resources that the
+	// operator manages for each ingresscontroller.
+	OperandLabels map[string]string
+
+	// OperandAnnotations are extra annotations to set on the resources
+	// that the operator manages for each ingresscontroller.
+	OperandAnnotations map[string]string
+
+	// MaxLoadBalancerIngressControllers is the maximum number of
+	// ingresscontrollers that may use the LoadBalancerService endpoint
+	// publishing strategy.  Zero means there is no limit.
+	MaxLoadBalancerIngressControllers int
+
+	// OperandEventQPS is the maximum sustained rate per second at which
+	// events for an ingresscontroller's operands trigger reconciliation
+	// of that ingresscontroller.  Zero means there is no limit.
+	OperandEventQPS float64
+
+	// OperandEventBurst is the number of operand events for an
+	// ingresscontroller that may trigger reconciliation immediately
+	// before OperandEventQPS applies.
+	OperandEventBurst int
+
+	// ClientQPS is the maximum sustained rate per second of the operator's
+	// requests to the API server.  Higher values let the operator reconcile
+	// many ingresscontrollers faster but put more load on the API server.
+	// Zero means the client-go default.
+	ClientQPS float32
+
+	// ClientBurst is the number of requests that the operator may make to
+	// the API server at once before ClientQPS applies.  Zero means the
+	// client-go default.
+	ClientBurst int
+
+	// UnmanagedIngressControllers are the names of ingresscontrollers
+	// that are managed externally.  The operator neither creates nor
+	// reconciles them.
+	UnmanagedIngressControllers []string
+
+	// DegradeOnOperandVersionSkew indicates whether an ingresscontroller
+	// whose router pods run an image other than the one that the
+	// operator's release expects is marked degraded.
+	DegradeOnOperandVersionSkew bool
+
+	// DegradedGracePeriod is how long an ingresscontroller must be in a
+	// degraded state before its Degraded condition is set to True.  Zero
+	// means that the condition is set immediately.
+	DegradedGracePeriod time.Duration
+
+	// FinalizerTimeout is how long the operator keeps retrying the cleanup
+	// of a deleted ingresscontroller before it removes the
+	// ingresscontroller's finalizer anyway, possibly orphaning cloud
+	// resources.  Zero means that the finalizer is only removed once
+	// cleanup succeeds.
+	FinalizerTimeout time.Duration
+
+	// ResyncPeriod is how often every ingresscontroller is reconciled even
+	// if no event triggers it, so that drift of its operands that no watch
+	// event reports is corrected.  Zero means that ingresscontrollers are
+	// only reconciled in response to events.
+	ResyncPeriod time.Duration
+
+	// PreferClusterIngressDomain indicates whether the domain of the
+	// cluster ingress config takes precedence over an ingresscontroller's
+	// spec.domain when the operator determines the ingresscontroller's
+	// effective domain.
+	PreferClusterIngressDomain bool
+
+	// FailOnEmptyIngressDomain indicates whether failing to determine an
+	// ingresscontroller's effective domain is a reconcile error.
+	// Otherwise the operator defers reconciling the ingresscontroller
+	// and checks again later.
+	FailOnEmptyIngressDomain bool
+
+	// IngressAddressesBindAddress is the address on which the operator
+	// serves the domain and load balancer addresses of every
+	// ingresscontroller, and the resources that the operator manages for
+	// each ingresscontroller.  The endpoints are not authenticated, so an
+	// address without a host binds to 127.0.0.1.  Empty means the
+	// endpoints are disabled.
+	IngressAddressesBindAddress string
+
+	// HealthBindAddress is the address on which the operator serves its
+	// liveness (/healthz) and readiness (/readyz) endpoints.  Empty means
+	// the endpoints are disabled.
+	HealthBindAddress string
 }
@@ -38,12 +38,13 @@ const (
 
 var log = logf.Logger.WithName(controllerName)
 
-func New(mgr manager.Manager, operatorNamespace string) (runtimecontroller.Controller, error) {
+func New(mgr manager.Manager, operatorNamespace, operandNamespace string) (runtimecontroller.Controller, error) {
 	reconciler := &reconciler{
 		client:            mgr.GetClient(),
 		cache:             mgr.GetCache(),
 		recorder:          mgr.GetEventRecorderFor(controllerName),
 		operatorNamespace: operatorNamespace,
+		operandNamespace:  operandNamespace,
 	}
 	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
 	if err != nil {
@@ -60,6 +61,7 @@ type reconciler struct {
 	cache             cache.Cache
 	recorder          record.EventRecorder
 	operatorNamespace string
+	operandNamespace  string
 }
 
 func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
@@ -83,7 +85,7 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 		log.Info("ingresscontroller domain not set; reconciliation will be skipped", "request", request)
 	} else {
 		deployment := &appsv1.Deployment{}
-		err = r.client.Get(context.TODO(), controller.RouterDeploymentName(ingress), deployment)
+		err = r.client.Get(context.TODO(), controller.RouterDeploymentName(ingress, r.operandNamespace), deployment)
 		if err != nil {
 			if errors.IsNotFound(err) {
 				// All ingresses should have a deployment, so this one may not have been
@@ -3,6 +3,8 @@ package controller
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/dns"
@@ -10,6 +12,8 @@ import (
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
 
+	"github.com/go-logr/logr"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
@@ -17,15 +21,19 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -37,10 +45,42 @@ const (
 	IngressControllerFinalizer = "ingresscontroller.operator.openshift.io/finalizer-ingresscontroller"
 
 	controllerName = "ingress_controller"
+
+	// loadBalancerLimitExceededReason and hostNetworkPortConflictReason are
+	// reasons of the Admitted condition of an ingresscontroller that is not
+	// admitted because of other ingresscontrollers.  Deleting or changing
+	// those may admit it, so its admission is checked again periodically.
+	loadBalancerLimitExceededReason = "LoadBalancerLimitExceeded"
+	hostNetworkPortConflictReason   = "HostNetworkPortConflict"
+
+	// rotateStatsCredentialsAnnotation, when present on an
+	// ingresscontroller, makes the operator regenerate the router stats
+	// credentials and restart the router to pick them up.  The operator
+	// removes the annotation once the credentials have been rotated.
+	rotateStatsCredentialsAnnotation = "ingress.operator.openshift.io/rotate-stats-credentials"
+
+	// statsCredentialsRotatedAnnotation is set on the router pod template
+	// to the time of the last stats credentials rotation so that the
+	// rotation rolls out the router pods.
+	statsCredentialsRotatedAnnotation = "ingress.operator.openshift.io/stats-credentials-rotated"
+
+	// disableMetricsIntegrationAnnotation, when set to "true" on an
+	// ingresscontroller, disables the integration of the router's metrics
+	// with openshift-monitoring and removes the objects that the operator
+	// created for it.  Allowed values are "true" and "false".  If unset,
+	// metrics integration is enabled.
+	disableMetricsIntegrationAnnotation = "ingress.operator.openshift.io/disable-metrics-integration"
 )
 
 var log = logf.Logger.WithName("controller")
 
+// stepLogger returns a logger whose entries identify the given
+// ingresscontroller and reconcile step.  Use V(1) for entries that only help
+// with debugging, such as the start of each step.
+func stepLogger(ic *operatorv1.IngressController, step string) logr.Logger {
+	return log.WithValues("ingresscontroller", ic.Namespace+"/"+ic.Name, "step", step)
+}
+
 // New creates the operator controller from configuration. This is the
 // controller that handles all the logic for implementing ingress based on
 // IngressController resources.
@@ -61,42 +101,335 @@ func New(mgr manager.Manager, config Config) (controller.Controller, error) {
 	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestForObject{}); err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, enqueueRequestForOwningIngressController(config.Namespace)); err != nil {
+	// Share one handler among the operand watches so that the rate limit
+	// for each ingresscontroller applies to all of its operands.
+	operandEventHandler := enqueueRequestForOwningIngressController(config.Namespace, config.OperandEventQPS, config.OperandEventBurst)
+	if err := c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, operandEventHandler); err != nil {
+		return nil, err
+	}
+	if err := c.Watch(&source.Kind{Type: &corev1.Service{}}, operandEventHandler); err != nil {
+		return nil, err
+	}
+	// Watch the configmaps so that changing an ingresscontroller's own
+	// configmaps, or its maintenance page, backend CA bundle, or any other
+	// configmap in the operand namespace that its router deployment
+	// references, reconciles the ingresscontroller.  A single watch covers
+	// both so that each event enqueues each ingresscontroller once and
+	// within its operand event rate limit.
+	if err := c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, operandEventHandler.withMapper(handler.ToRequestsFunc(reconciler.configMapEventToIngressControllers))); err != nil {
+		return nil, err
+	}
+	// Watch the secrets in the operand namespace so that rotating an
+	// ingresscontroller's default certificate, or changing any other
+	// secret that its router deployment references, rolls out its router
+	// pods.
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.secretToIngressControllers)}, operandSecretPredicate(config.OperandNamespace)); err != nil {
+		return nil, err
+	}
+	// Watch the secrets in the operator namespace so that creating or
+	// fixing an ingresscontroller's DNS credentials takes effect.
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.dnsCredentialsSecretToIngressControllers)}, operandSecretPredicate(config.Namespace)); err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &corev1.Service{}}, enqueueRequestForOwningIngressController(config.Namespace)); err != nil {
+	// Watch the router pods so that readiness transitions and image pull
+	// failures update the ingresscontroller's status without waiting for
+	// the deployment's status to change.
+	if err := c.Watch(&source.Kind{Type: &corev1.Pod{}}, operandEventHandler, routerPodPredicate(config.OperandNamespace)); err != nil {
+		return nil, err
+	}
+	// Watch cluster-scoped objects through a cache of their own.  The
+	// manager's cache has an informer for each of its namespaces, and each
+	// of those informers would deliver every event for a cluster-scoped
+	// object.
+	clusterCache, err := cache.New(mgr.GetConfig(), cache.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache for cluster-scoped objects: %v", err)
+	}
+	if err := mgr.Add(clusterCache); err != nil {
+		return nil, err
+	}
+	// Watch the nodes so that an ingresscontroller whose node selector
+	// matches no schedulable node is reported as degraded, and recovers,
+	// as nodes are added, removed, relabeled, or cordoned.
+	nodesInformer, err := clusterCache.GetInformer(&corev1.Node{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create informer for nodes: %v", err)
+	}
+	if err := c.Watch(&source.Informer{Informer: nodesInformer}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.nodeToIngressControllers)}, nodePredicate()); err != nil {
+		return nil, err
+	}
+	// Watch the cluster proxy config so that the DNS managers call their
+	// provider's API through the current proxy, and DNS records that could
+	// not be published through the previous one are retried.
+	proxiesInformer, err := clusterCache.GetInformer(&configv1.Proxy{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create informer for proxies: %v", err)
+	}
+	if err := c.Watch(&source.Informer{Informer: proxiesInformer}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.proxyToIngressControllers)}); err != nil {
+		return nil, err
+	}
+	// Periodically reconcile every ingresscontroller so that drift of its
+	// operands that no watch event reports, such as a change that the
+	// predicates filter out or an event that was missed, is corrected.
+	if config.ResyncPeriod > 0 {
+		resyncEvents := make(chan event.GenericEvent)
+		if err := c.Watch(&source.Channel{Source: resyncEvents}, &handler.EnqueueRequestForObject{}); err != nil {
+			return nil, err
+		}
+		if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+			return reconciler.resyncIngressControllers(resyncEvents, config.ResyncPeriod, stop)
+		})); err != nil {
+			return nil, err
+		}
+	}
+	// Periodically scrape the router pods so that the operator's own
+	// metrics summarize the load of every ingresscontroller.
+	if err := mgr.Add(newRouterLoadCollector(reconciler)); err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
-func enqueueRequestForOwningIngressController(namespace string) handler.EventHandler {
-	return &handler.EnqueueRequestsFromMapFunc{
-		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
-			labels := a.Meta.GetLabels()
-			if ingressName, ok := labels[manifests.OwningIngressControllerLabel]; ok {
-				log.Info("queueing ingress", "name", ingressName, "related", a.Meta.GetSelfLink())
-				return []reconcile.Request{
-					{
-						NamespacedName: types.NamespacedName{
-							Namespace: namespace,
-							Name:      ingressName,
-						},
-					},
-				}
-			} else {
-				return []reconcile.Request{}
+// routerPodPredicate returns a predicate that accepts events for router pods
+// in the given namespace.  Updates are accepted only if the pod's status
+// changed.
+func routerPodPredicate(namespace string) predicate.Funcs {
+	isRouterPod := func(meta metav1.Object) bool {
+		_, ok := meta.GetLabels()[controllerDeploymentLabel]
+		return ok && meta.GetNamespace() == namespace
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return isRouterPod(e.Meta) },
+		DeleteFunc: func(e event.DeleteEvent) bool { return isRouterPod(e.Meta) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !isRouterPod(e.MetaNew) {
+				return false
+			}
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return true
 			}
-		}),
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return true
+			}
+			return !reflect.DeepEqual(oldPod.Status, newPod.Status)
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return isRouterPod(e.Meta) },
+	}
+}
+
+// operandSecretPredicate returns a predicate that accepts events for secrets
+// in the given namespace.  Updates are accepted only if the secret's data
+// changed.
+func operandSecretPredicate(namespace string) predicate.Funcs {
+	inNamespace := func(meta metav1.Object) bool {
+		return meta.GetNamespace() == namespace
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return inNamespace(e.Meta) },
+		DeleteFunc: func(e event.DeleteEvent) bool { return inNamespace(e.Meta) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !inNamespace(e.MetaNew) {
+				return false
+			}
+			oldSecret, ok := e.ObjectOld.(*corev1.Secret)
+			if !ok {
+				return true
+			}
+			newSecret, ok := e.ObjectNew.(*corev1.Secret)
+			if !ok {
+				return true
+			}
+			return !reflect.DeepEqual(oldSecret.Data, newSecret.Data)
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return inNamespace(e.Meta) },
+	}
+}
+
+// secretToIngressControllers maps a secret in the operand namespace to
+// requests for the ingresscontrollers that use it as their default
+// certificate, whether the secret is user-provided or operator-generated, or
+// whose router deployments reference it otherwise.
+func (r *reconciler) secretToIngressControllers(a handler.MapObject) []reconcile.Request {
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for secret", "related", a.Meta.GetSelfLink())
+		return []reconcile.Request{}
+	}
+	requests := []reconcile.Request{}
+	for i := range ingresses.Items {
+		ic := &ingresses.Items[i]
+		normalizeIngressController(ic)
+		if RouterEffectiveDefaultCertificateSecretName(ic, r.OperandNamespace).Name != a.Meta.GetName() && !r.routerDeploymentReferencesConfig(ic, "secret", a.Meta.GetName()) {
+			continue
+		}
+		log.Info("queueing ingress", "name", ic.Name, "related", a.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+		})
+	}
+	return requests
+}
+
+// configMapEventToIngressControllers maps a configmap to a request for the
+// ingresscontroller that owns it and, if the configmap is in the operand
+// namespace, to requests for the ingresscontrollers that reference it.
+func (r *reconciler) configMapEventToIngressControllers(a handler.MapObject) []reconcile.Request {
+	requests := owningIngressControllerRequests(r.Namespace, a)
+	if a.Meta.GetNamespace() == r.OperandNamespace {
+		requests = append(requests, r.configMapToIngressControllers(a)...)
+	}
+	return requests
+}
+
+// configMapToIngressControllers maps a configmap in the operand namespace to
+// requests for the ingresscontrollers that use it as their maintenance page or
+// backend CA bundle, or whose router deployments reference it otherwise.
+func (r *reconciler) configMapToIngressControllers(a handler.MapObject) []reconcile.Request {
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for configmap", "related", a.Meta.GetSelfLink())
+		return []reconcile.Request{}
+	}
+	requests := []reconcile.Request{}
+	for i := range ingresses.Items {
+		ic := &ingresses.Items[i]
+		if ic.Annotations[maintenancePageConfigMapAnnotation] != a.Meta.GetName() && ic.Annotations[backendCABundleConfigMapAnnotation] != a.Meta.GetName() && ic.Annotations[routerTemplateConfigMapAnnotation] != a.Meta.GetName() && !r.routerDeploymentReferencesConfig(ic, "configmap", a.Meta.GetName()) {
+			continue
+		}
+		log.Info("queueing ingress", "name", ic.Name, "related", a.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+		})
+	}
+	return requests
+}
+
+// enqueueRequestForOwningIngressController returns an event handler that
+// enqueues a request for the ingresscontroller that owns the object in the
+// event, rate limited to qps per second with the given burst for each
+// ingresscontroller.  The owner is identified by the owning ingresscontroller
+// label or, for router pods, by the deployment label of the pod template.
+func enqueueRequestForOwningIngressController(namespace string, qps float64, burst int) *rateLimitedEnqueueRequests {
+	return newRateLimitedEnqueueRequests(handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+		return owningIngressControllerRequests(namespace, a)
+	}), qps, burst)
+}
+
+// owningIngressControllerRequests maps the given object to a request for the
+// ingresscontroller in the given namespace that owns it, if any.
+func owningIngressControllerRequests(namespace string, a handler.MapObject) []reconcile.Request {
+	labels := a.Meta.GetLabels()
+	ingressName, ok := labels[manifests.OwningIngressControllerLabel]
+	if !ok {
+		ingressName, ok = labels[controllerDeploymentLabel]
+	}
+	if ok {
+		log.Info("queueing ingress", "name", ingressName, "related", a.Meta.GetSelfLink())
+		return []reconcile.Request{
+			{
+				NamespacedName: types.NamespacedName{
+					Namespace: namespace,
+					Name:      ingressName,
+				},
+			},
+		}
+	} else {
+		return []reconcile.Request{}
 	}
 }
 
 // Config holds all the things necessary for the controller to run.
 type Config struct {
-	Namespace              string
+	Namespace string
+	// OperandNamespace is the namespace in which the operator manages the
+	// router deployments and related resources.
+	OperandNamespace       string
 	DNSManager             dns.Manager
 	IngressControllerImage string
 	OperatorReleaseVersion string
+	// MonitoringNamespace is the namespace of the monitoring stack whose
+	// prometheus service account is granted access to router metrics.
+	// Empty means DefaultMonitoringNamespace.
+	MonitoringNamespace string
+	// OperandLabels are extra labels to set on the resources that the
+	// operator manages for each ingresscontroller.
+	OperandLabels map[string]string
+	// OperandAnnotations are extra annotations to set on the resources
+	// that the operator manages for each ingresscontroller.
+	OperandAnnotations map[string]string
+	// MaxLoadBalancerIngressControllers is the maximum number of admitted
+	// ingresscontrollers that may use the LoadBalancerService endpoint
+	// publishing strategy.  Zero means there is no limit.
+	MaxLoadBalancerIngressControllers int
+	// DegradeOnOperandVersionSkew indicates whether an ingresscontroller
+	// whose router pods run an image other than the one that the
+	// operator's release expects is marked degraded.  Otherwise the skew
+	// is only reported by a warning event.
+	DegradeOnOperandVersionSkew bool
+	// DegradedGracePeriod is how long an ingresscontroller must be in a
+	// degraded state before its Degraded condition is set to True.  Zero
+	// means that the condition is set immediately.
+	DegradedGracePeriod time.Duration
+	// FinalizerTimeout is how long the operator keeps retrying the cleanup
+	// of a deleted ingresscontroller before it removes the
+	// ingresscontroller's finalizer anyway.  Zero means that the finalizer
+	// is only removed once cleanup succeeds.
+	FinalizerTimeout time.Duration
+	// ResyncPeriod is how often every ingresscontroller is reconciled even
+	// if no event triggers it.  Zero means that ingresscontrollers are only
+	// reconciled in response to events.
+	ResyncPeriod time.Duration
+	// OperandEventQPS is the maximum sustained rate per second at which
+	// events for an ingresscontroller's operands trigger reconciliation
+	// of that ingresscontroller.  Zero means there is no limit.
+	OperandEventQPS float64
+	// OperandEventBurst is the number of operand events for an
+	// ingresscontroller that may trigger reconciliation immediately
+	// before OperandEventQPS applies.
+	OperandEventBurst int
+	// UnmanagedIngressControllers are the names of ingresscontrollers
+	// that are managed externally.  The operator does not reconcile them
+	// but includes them in the clusteroperator status.
+	UnmanagedIngressControllers []string
+	// PreferClusterIngressDomain indicates whether the domain of the
+	// cluster ingress config takes precedence over spec.domain.  See
+	// effectiveIngressDomain.
+	PreferClusterIngressDomain bool
+	// FailOnEmptyIngressDomain indicates whether failing to determine an
+	// ingresscontroller's effective domain is a reconcile error rather
+	// than a reason to defer reconciliation.
+	FailOnEmptyIngressDomain bool
+	// TopologyAwareHintsSupported indicates whether the cluster supports
+	// topology-aware hints on services.  Ingresscontrollers that request
+	// topology-aware hints on their internal service are not admitted
+	// otherwise.
+	TopologyAwareHintsSupported bool
+	// DNSManagerFactory creates the DNS managers for ingresscontrollers
+	// that specify their own DNS provider credentials.  It is nil if the
+	// operator does not manage DNS records on the cluster's platform.
+	DNSManagerFactory dns.ManagerFactory
+	// DNSProxy, if not nil, is the proxy configuration through which the
+	// DNS managers call their provider's API.  The operator keeps it up to
+	// date with the cluster proxy config.
+	DNSProxy *dns.Proxy
+	// Health, if not nil, records the outcome of each reconciliation.
+	Health *Health
+	// Shutdown, if not nil, tracks the reconciliations in progress so that
+	// the operator can let them finish when it stops.
+	Shutdown *Shutdown
+}
+
+// isIngressControllerManaged returns a Boolean indicating whether the operator
+// manages the ingresscontroller with the given name.
+func (c *Config) isIngressControllerManaged(name string) bool {
+	for _, unmanaged := range c.UnmanagedIngressControllers {
+		if name == unmanaged {
+			return false
+		}
+	}
+	return true
 }
 
 // reconciler handles the actual ingress reconciliation logic in response to
@@ -107,31 +440,61 @@ type reconciler struct {
 	client   client.Client
 	cache    cache.Cache
 	recorder record.EventRecorder
+
+	// dnsManagers caches the DNS managers created for ingresscontrollers
+	// that specify their own DNS provider credentials.
+	dnsManagers dnsManagerCache
 }
 
 // Reconcile expects request to refer to a ingresscontroller in the operator
 // namespace, and will do all the work to ensure the ingresscontroller is in the
 // desired state.
 func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	if !r.Shutdown.startReconcile() {
+		log.Info("operator is stopping; reconciliation will be skipped", "ingresscontroller", request.NamespacedName.String())
+		return reconcile.Result{}, nil
+	}
+	defer r.Shutdown.finishReconcile()
+
 	errs := []error{}
 	result := reconcile.Result{}
 
-	log.Info("reconciling", "request", request)
+	// tracked indicates whether the outcome of this reconciliation is
+	// recorded for the health endpoint.
+	tracked := true
+
+	log.Info("reconciling", "ingresscontroller", request.NamespacedName.String())
 
 	// Get the current ingress state.
 	ingress := &operatorv1.IngressController{}
-	if err := r.client.Get(context.TODO(), request.NamespacedName, ingress); err != nil {
+	if err := r.getIngressController(request.NamespacedName, ingress); err != nil {
 		if errors.IsNotFound(err) {
 			// This means the ingress was already deleted/finalized and there are
 			// stale queue entries (or something edge triggering from a related
 			// resource that got deleted async).
-			log.Info("ingresscontroller not found; reconciliation will be skipped", "request", request)
+			log.Info("ingresscontroller not found; reconciliation will be skipped", "ingresscontroller", request.NamespacedName.String())
+			tracked = false
 		} else {
 			errs = append(errs, fmt.Errorf("failed to get ingresscontroller %q: %v", request, err))
 		}
 		ingress = nil
 	}
 
+	if ingress != nil && !r.isIngressControllerManaged(ingress.Name) {
+		stepLogger(ingress, "unmanaged").Info("ingresscontroller is not managed by the operator; reconciliation will be skipped")
+		tracked = false
+		// The ingresscontroller may have been managed before, in which
+		// case it has the finalizer.  Remove the finalizer so that the
+		// operator does not block deletion, but leave the operands to
+		// whoever manages the ingresscontroller now.
+		if ingress.DeletionTimestamp != nil {
+			if err := r.removeIngressFinalizer(ingress); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		ingress = nil
+	}
+
 	if ingress != nil {
 		dnsConfig := &configv1.DNS{}
 		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, dnsConfig); err != nil {
@@ -155,6 +518,7 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 		// of the cluster config being available.
 		if dnsConfig != nil && infraConfig != nil && ingressConfig != nil {
 			// Ensure we have all the necessary scaffolding on which to place router instances.
+			stepLogger(ingress, "namespace").V(1).Info("ensuring router namespace")
 			if err := r.ensureRouterNamespace(); err != nil {
 				errs = append(errs, fmt.Errorf("failed to ensure router namespace: %v", err))
 			}
@@ -166,27 +530,51 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 					errs = append(errs, fmt.Errorf("failed to enforce the effective HA configuration for ingresscontroller %s: %v", ingress.Name, err))
 				} else if ingress.DeletionTimestamp != nil {
 					// Handle deletion.
+					stepLogger(ingress, "deletion").V(1).Info("ensuring ingresscontroller is deleted")
 					if err := r.ensureIngressDeleted(ingress, dnsConfig, infraConfig); err != nil {
 						errs = append(errs, fmt.Errorf("failed to ensure ingress deletion: %v", err))
 					}
 				} else if err := r.enforceIngressFinalizer(ingress); err != nil {
 					errs = append(errs, fmt.Errorf("failed to enforce ingress finalizer %s/%s: %v", ingress.Namespace, ingress.Name, err))
+				} else if admitted, err := r.admit(ingress, infraConfig); err != nil {
+					errs = append(errs, fmt.Errorf("failed to admit ingresscontroller %s/%s: %v", ingress.Namespace, ingress.Name, err))
+				} else if !admitted {
+					stepLogger(ingress, "admission").Info("ingresscontroller is not admitted; reconciliation will be skipped")
+					// Deleting or changing another ingresscontroller
+					// may free up capacity or host ports, so check
+					// again later.
+					if cond := getIngressCondition(ingress.Status.Conditions, IngressControllerAdmittedConditionType); cond != nil && (cond.Reason == loadBalancerLimitExceededReason || cond.Reason == hostNetworkPortConflictReason) {
+						result.RequeueAfter = time.Minute
+					}
 				} else {
 					// Handle everything else.
-					if err := r.ensureIngressController(ingress, dnsConfig, infraConfig); err != nil {
+					ensureResult, summary, err := r.ensureIngressController(ingress, dnsConfig, infraConfig)
+					if err != nil {
 						errs = append(errs, fmt.Errorf("failed to ensure ingresscontroller: %v", err))
 					}
+					if err := r.recordReconcileSummary(ingress, summary); err != nil {
+						errs = append(errs, err)
+					}
+					result.RequeueAfter = ensureResult.RequeueAfter
 				}
+			} else {
+				// The effective domain could not be determined or
+				// is in use by another ingresscontroller.  Changes
+				// to the cluster ingress config or to other
+				// ingresscontrollers do not trigger reconciliation
+				// of this one, so check again later.
+				result.RequeueAfter = time.Minute
 			}
 		}
 	}
 
-	// TODO: Should this be another controller?
-	if err := r.syncOperatorStatus(); err != nil {
-		errs = append(errs, fmt.Errorf("failed to sync operator status: %v", err))
+	err := utilerrors.NewAggregate(errs)
+	if tracked {
+		r.Health.recordReconcile(request.Name, err)
+	} else {
+		r.Health.forget(request.Name)
 	}
-
-	return result, utilerrors.NewAggregate(errs)
+	return result, err
 }
 
 // enforceEffectiveIngressDomain determines the effective ingress domain for the
@@ -199,20 +587,22 @@ func (r *reconciler) enforceEffectiveIngressDomain(ic *operatorv1.IngressControl
 		return nil
 	}
 
-	updated := ic.DeepCopy()
-	var domain string
-	switch {
-	case len(ic.Spec.Domain) > 0:
-		domain = ic.Spec.Domain
-	default:
-		domain = ingressConfig.Spec.Domain
+	domain := effectiveIngressDomain(ic, ingressConfig, r.PreferClusterIngressDomain)
+	if len(domain) == 0 {
+		if r.FailOnEmptyIngressDomain {
+			return fmt.Errorf("neither spec.domain nor the cluster ingress config specifies a domain")
+		}
+		stepLogger(ic, "domain").Info("neither spec.domain nor the cluster ingress config specifies a domain; deferring reconciliation")
+		return nil
 	}
+
+	updated := ic.DeepCopy()
 	unique, err := r.isDomainUnique(domain)
 	if err != nil {
 		return err
 	}
 	if !unique {
-		log.Info("domain not unique, not setting status domain for IngressController", "namespace", ic.Namespace, "name", ic.Name)
+		stepLogger(ic, "domain").Info("domain not unique, not setting status domain for IngressController", "domain", domain)
 		availableCondition := operatorv1.OperatorCondition{
 			Type:    operatorv1.IngressControllerAvailableConditionType,
 			Status:  operatorv1.ConditionFalse,
@@ -230,12 +620,31 @@ func (r *reconciler) enforceEffectiveIngressDomain(ic *operatorv1.IngressControl
 	if err := r.client.Status().Update(context.TODO(), updated); err != nil {
 		return fmt.Errorf("failed to update status of IngressController %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, ic); err != nil {
+	if err := r.getIngressController(types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, ic); err != nil {
 		return fmt.Errorf("failed to get IngressController %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
 	return nil
 }
 
+// effectiveIngressDomain returns the domain that the given ingresscontroller
+// should use, which is the first non-empty domain in order of precedence.  By
+// default, spec.domain takes precedence over the domain of the given cluster
+// ingress config; if preferClusterConfig is true, the cluster ingress config's
+// domain takes precedence.  The empty string means that neither specifies a
+// domain.
+func effectiveIngressDomain(ic *operatorv1.IngressController, ingressConfig *configv1.Ingress, preferClusterConfig bool) string {
+	domains := []string{ic.Spec.Domain, ingressConfig.Spec.Domain}
+	if preferClusterConfig {
+		domains[0], domains[1] = domains[1], domains[0]
+	}
+	for _, domain := range domains {
+		if len(domain) != 0 {
+			return domain
+		}
+	}
+	return ""
+}
+
 // isDomainUnique compares domain with spec.domain of all ingress controllers
 // and returns a false if a conflict exists or an error if the
 // ingress controller list operation returns an error.
@@ -292,42 +701,160 @@ func (r *reconciler) enforceEffectiveEndpointPublishingStrategy(ci *operatorv1.I
 	if err := r.client.Status().Update(context.TODO(), updated); err != nil {
 		return fmt.Errorf("failed to update status of ingresscontroller %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, ci); err != nil {
+	if err := r.getIngressController(types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, ci); err != nil {
 		return fmt.Errorf("failed to get ingresscontroller %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
 	return nil
 }
 
+// admit validates the given ingresscontroller's configuration and publishes
+// the result to the ingresscontroller's Admitted status condition.  Returns
+// true if the ingresscontroller is admitted, meaning its configuration is valid
+// and supported on the cluster's platform, and it should be reconciled.
+func (r *reconciler) admit(ic *operatorv1.IngressController, infraConfig *configv1.Infrastructure) (bool, error) {
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list ingresscontrollers: %v", err)
+	}
+
+	admittedCondition := operatorv1.OperatorCondition{
+		Type:   IngressControllerAdmittedConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "Valid",
+	}
+	if err := validateIngressController(ic); err != nil {
+		admittedCondition.Status = operatorv1.ConditionFalse
+		admittedCondition.Reason = "Invalid"
+		admittedCondition.Message = err.Error()
+	} else if err := validateHealthCheckNodePortConflict(ic, ingresses.Items); err != nil {
+		admittedCondition.Status = operatorv1.ConditionFalse
+		admittedCondition.Reason = "Invalid"
+		admittedCondition.Message = err.Error()
+	} else if err := validateHostNetworkPortConflict(ic, ingresses.Items); err != nil {
+		admittedCondition.Status = operatorv1.ConditionFalse
+		admittedCondition.Reason = hostNetworkPortConflictReason
+		admittedCondition.Message = err.Error()
+	} else if err := validateEndpointPublishingStrategyForPlatform(ic, infraConfig.Status.Platform); err != nil {
+		admittedCondition.Status = operatorv1.ConditionFalse
+		admittedCondition.Reason = "UnsupportedEndpointPublishingStrategy"
+		admittedCondition.Message = err.Error()
+	} else if err := validateInternalServiceTopologyAwareHintsSupported(ic, r.TopologyAwareHintsSupported); err != nil {
+		admittedCondition.Status = operatorv1.ConditionFalse
+		admittedCondition.Reason = "TopologyAwareHintsUnsupported"
+		admittedCondition.Message = err.Error()
+	} else if r.MaxLoadBalancerIngressControllers > 0 {
+		if err := validateLoadBalancerLimit(ic, ingresses.Items, r.MaxLoadBalancerIngressControllers); err != nil {
+			admittedCondition.Status = operatorv1.ConditionFalse
+			admittedCondition.Reason = loadBalancerLimitExceededReason
+			admittedCondition.Message = err.Error()
+		}
+	}
+
+	updated := ic.DeepCopy()
+	updated.Status.Conditions = setIngressCondition(updated.Status.Conditions, admittedCondition)
+	if !ingressStatusesEqual(updated.Status, ic.Status) {
+		if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+			return false, fmt.Errorf("failed to update status of ingresscontroller %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		if err := r.getIngressController(types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, ic); err != nil {
+			return false, fmt.Errorf("failed to get ingresscontroller %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+	}
+
+	return admittedCondition.Status == operatorv1.ConditionTrue, nil
+}
+
 // enforceIngressFinalizer adds IngressControllerFinalizer to ingress if it doesn't exist.
 func (r *reconciler) enforceIngressFinalizer(ingress *operatorv1.IngressController) error {
 	if !slice.ContainsString(ingress.Finalizers, IngressControllerFinalizer) {
-		ingress.Finalizers = append(ingress.Finalizers, IngressControllerFinalizer)
-		if err := r.client.Update(context.TODO(), ingress); err != nil {
+		if err := r.updateIngressControllerMetadata(ingress, func(meta *metav1.ObjectMeta) {
+			meta.Finalizers = append(meta.Finalizers, IngressControllerFinalizer)
+		}); err != nil {
 			return err
 		}
-		log.Info("enforced finalizer for ingress", "namespace", ingress.Namespace, "name", ingress.Name)
+		if err := r.getIngressController(types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, ingress); err != nil {
+			return fmt.Errorf("failed to get ingresscontroller %s/%s: %v", ingress.Namespace, ingress.Name, err)
+		}
+		stepLogger(ingress, "finalizer").Info("enforced finalizer for ingress")
 	}
 	return nil
 }
 
 // ensureIngressDeleted tries to delete ingress, and if successful, will remove
-// the finalizer.
+// the finalizer.  If cleanup keeps failing for longer than the finalizer
+// timeout, the finalizer is removed anyway so that the deletion is not blocked
+// indefinitely, and a warning event is emitted about the resources that may
+// have been orphaned.
 func (r *reconciler) ensureIngressDeleted(ingress *operatorv1.IngressController, dnsConfig *configv1.DNS, infraConfig *configv1.Infrastructure) error {
-	if err := r.finalizeLoadBalancerService(ingress, dnsConfig); err != nil {
-		return fmt.Errorf("failed to finalize load balancer service for %s: %v", ingress.Name, err)
+	if err := r.cleanUpIngressController(ingress, dnsConfig); err != nil {
+		if !finalizerTimeoutExpired(ingress, r.FinalizerTimeout, time.Now()) {
+			return err
+		}
+		stepLogger(ingress, "deletion").Info("cleanup did not succeed within the finalizer timeout; removing finalizer anyway", "timeout", r.FinalizerTimeout, "error", err)
+		if r.recorder != nil {
+			r.recorder.Eventf(ingress, "Warning", "FinalizerForceRemoved", "Cleanup did not succeed within %s of deletion, so the finalizer was removed anyway; the load balancer, its DNS records, and other operands may be orphaned and must be deleted manually: %v", r.FinalizerTimeout, err)
+		}
+	}
+
+	// Clean up the finalizer to allow the ingresscontroller to be deleted.
+	return r.removeIngressFinalizer(ingress)
+}
+
+// finalizerTimeoutExpired returns a Boolean indicating whether the given
+// ingresscontroller has been marked for deletion for longer than the given
+// finalizer timeout at the given time.  A zero timeout never expires.
+func finalizerTimeoutExpired(ingress *operatorv1.IngressController, timeout time.Duration, now time.Time) bool {
+	if timeout <= 0 || ingress.DeletionTimestamp == nil {
+		return false
+	}
+	return now.Sub(ingress.DeletionTimestamp.Time) >= timeout
+}
+
+// cleanUpIngressController deletes the DNS records, load balancer, router
+// deployment, and metrics integration of the given ingresscontroller, in that
+// order.  The DNS records are deleted before the load balancer so that they
+// never resolve to the address of a deleted load balancer, and the load
+// balancer before the router deployment, which would otherwise garbage-collect
+// the load balancer service.  A failure to delete the DNS records holds back
+// the rest of the cleanup only until the DNS cleanup timeout expires.
+func (r *reconciler) cleanUpIngressController(ingress *operatorv1.IngressController, dnsConfig *configv1.DNS) error {
+	errs := []error{}
+	finalized, err := r.finalizeLoadBalancerService(ingress, dnsConfig)
+	if err != nil {
+		err = fmt.Errorf("failed to finalize load balancer service for %s: %v", ingress.Name, err)
+		if !finalized {
+			return err
+		}
+		errs = append(errs, err)
+	} else {
+		stepLogger(ingress, "deletion").Info("finalized load balancer service for ingress")
 	}
-	log.Info("finalized load balancer service for ingress", "namespace", ingress.Namespace, "name", ingress.Name)
 
 	if err := r.ensureRouterDeleted(ingress); err != nil {
-		return fmt.Errorf("failed to delete deployment for ingress %s: %v", ingress.Name, err)
+		errs = append(errs, fmt.Errorf("failed to delete deployment for ingress %s: %v", ingress.Name, err))
+	} else {
+		stepLogger(ingress, "deletion").Info("deleted deployment for ingress")
 	}
-	log.Info("deleted deployment for ingress", "namespace", ingress.Namespace, "name", ingress.Name)
 
-	// Clean up the finalizer to allow the ingresscontroller to be deleted.
+	// The metrics objects are owned by the deployment and would eventually
+	// be garbage-collected, but delete them explicitly so that they are
+	// gone by the time the ingresscontroller is.
+	if err := r.ensureMetricsIntegrationDeleted(ingress); err != nil {
+		errs = append(errs, fmt.Errorf("failed to delete metrics integration for ingress %s: %v", ingress.Name, err))
+	} else {
+		stepLogger(ingress, "deletion").Info("deleted metrics integration for ingress")
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// removeIngressFinalizer removes IngressControllerFinalizer from ingress if it
+// exists.
+func (r *reconciler) removeIngressFinalizer(ingress *operatorv1.IngressController) error {
 	if slice.ContainsString(ingress.Finalizers, IngressControllerFinalizer) {
-		updated := ingress.DeepCopy()
-		updated.Finalizers = slice.RemoveString(updated.Finalizers, IngressControllerFinalizer)
-		if err := r.client.Update(context.TODO(), updated); err != nil {
+		if err := r.updateIngressControllerMetadata(ingress, func(meta *metav1.ObjectMeta) {
+			meta.Finalizers = slice.RemoveString(meta.Finalizers, IngressControllerFinalizer)
+		}); err != nil {
 			return fmt.Errorf("failed to remove finalizer from ingresscontroller %s: %v", ingress.Name, err)
 		}
 	}
@@ -337,18 +864,11 @@ func (r *reconciler) ensureIngressDeleted(ingress *operatorv1.IngressController,
 // ensureRouterNamespace ensures all the necessary scaffolding exists for
 // routers generally, including a namespace and all RBAC setup.
 func (r *reconciler) ensureRouterNamespace() error {
-	cr := manifests.RouterClusterRole()
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name}, cr); err != nil {
-		if !errors.IsNotFound(err) {
-			return fmt.Errorf("failed to get router cluster role %s: %v", cr.Name, err)
-		}
-		if err := r.client.Create(context.TODO(), cr); err != nil {
-			return fmt.Errorf("failed to create router cluster role %s: %v", cr.Name, err)
-		}
-		log.Info("created router cluster role", "name", cr.Name)
+	if err := r.ensureRouterClusterRole(); err != nil {
+		return err
 	}
 
-	ns := manifests.RouterNamespace()
+	ns := manifests.RouterNamespace(r.OperandNamespace)
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: ns.Name}, ns); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get router namespace %q: %v", ns.Name, err)
@@ -359,7 +879,7 @@ func (r *reconciler) ensureRouterNamespace() error {
 		log.Info("created router namespace", "name", ns.Name)
 	}
 
-	sa := manifests.RouterServiceAccount()
+	sa := manifests.RouterServiceAccount(r.OperandNamespace)
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: sa.Namespace, Name: sa.Name}, sa); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get router service account %s/%s: %v", sa.Namespace, sa.Name, err)
@@ -370,26 +890,31 @@ func (r *reconciler) ensureRouterNamespace() error {
 		log.Info("created router service account", "namespace", sa.Namespace, "name", sa.Name)
 	}
 
-	crb := manifests.RouterClusterRoleBinding()
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: crb.Name}, crb); err != nil {
-		if !errors.IsNotFound(err) {
-			return fmt.Errorf("failed to get router cluster role binding %s: %v", crb.Name, err)
-		}
-		if err := r.client.Create(context.TODO(), crb); err != nil {
-			return fmt.Errorf("failed to create router cluster role binding %s: %v", crb.Name, err)
-		}
-		log.Info("created router cluster role binding", "name", crb.Name)
-	}
-
-	return nil
+	return r.ensureRouterClusterRoleBinding()
 }
 
 // ensureIngressController ensures all necessary router resources exist for a given ingresscontroller.
-func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, dnsConfig *configv1.DNS, infraConfig *configv1.Infrastructure) error {
+// It returns a summary of the outcome of each step along with any errors.
+func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, dnsConfig *configv1.DNS, infraConfig *configv1.Infrastructure) (reconcile.Result, reconcileSummary, error) {
 	errs := []error{}
+	result := reconcile.Result{}
+	summary := reconcileSummary{}
+	deploymentName := RouterDeploymentName(ci, r.OperandNamespace).String()
+	autoscalerName := RouterHorizontalPodAutoscalerName(ci, r.OperandNamespace).String()
+	lbServiceName := LoadBalancerServiceName(ci, r.OperandNamespace).String()
+	internalSvcName := InternalIngressControllerServiceName(ci, r.OperandNamespace).String()
+	icName := ci.Namespace + "/" + ci.Name
 
-	if deployment, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+	stepLogger(ci, "deployment").V(1).Info("ensuring router deployment")
+	if deployment, err := r.ensureRouterDeployment(ci, infraConfig); summary.record("deployment", deploymentName, "ensure", err) != nil {
 		errs = append(errs, fmt.Errorf("failed to ensure router deployment for %s: %v", ci.Name, err))
+		summary.skip("autoscaling", autoscalerName, "ensure")
+		summary.skip("load-balancer", lbServiceName, "ensure")
+		summary.skip("dns", "", "ensure")
+		summary.skip("internal-service", internalSvcName, "ensure")
+		summary.skip("metrics", "", "ensure")
+		summary.skip("router-stats-route", RouterStatsRouteName(ci, r.OperandNamespace).String(), "ensure")
+		summary.skip("status", icName, "sync")
 	} else {
 		trueVar := true
 		deploymentRef := metav1.OwnerReference{
@@ -400,37 +925,144 @@ func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, d
 			Controller: &trueVar,
 		}
 
+		stepLogger(ci, "autoscaling").V(1).Info("ensuring router autoscaler")
+		if err := summary.record("autoscaling", autoscalerName, "ensure", r.ensureRouterAutoscaler(ci, deploymentRef)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure router autoscaler for %s: %v", ci.Name, err))
+		}
+
+		stepLogger(ci, "load-balancer").V(1).Info("ensuring load balancer service")
 		lbService, err := r.ensureLoadBalancerService(ci, deploymentRef, infraConfig)
-		if err != nil {
+		if summary.record("load-balancer", lbServiceName, "ensure", err) != nil {
 			errs = append(errs, fmt.Errorf("failed to ensure load balancer service for %s: %v", ci.Name, err))
+			summary.skip("dns", "", "ensure")
 		} else if lbService != nil {
-			if err := r.ensureDNS(ci, lbService, dnsConfig); err != nil {
+			stepLogger(ci, "dns").V(1).Info("ensuring DNS records")
+			if err := summary.record("dns", "", "ensure", r.ensureDNS(ci, lbService, dnsConfig)); err != nil {
 				errs = append(errs, fmt.Errorf("failed to ensure DNS for %s: %v", ci.Name, err))
 			}
+		} else {
+			summary.skip("dns", "", "ensure")
 		}
 
-		if internalSvc, err := r.ensureInternalIngressControllerService(ci, deploymentRef); err != nil {
+		stepLogger(ci, "internal-service").V(1).Info("ensuring internal service")
+		if internalSvc, err := r.ensureInternalIngressControllerService(ci, deploymentRef); summary.record("internal-service", internalSvcName, "ensure", err) != nil {
 			errs = append(errs, fmt.Errorf("failed to create internal router service for ingresscontroller %s: %v", ci.Name, err))
-		} else if err := r.ensureMetricsIntegration(ci, internalSvc, deploymentRef); err != nil {
-			errs = append(errs, fmt.Errorf("failed to integrate metrics with openshift-monitoring for ingresscontroller %s: %v", ci.Name, err))
+			summary.skip("metrics", "", "ensure")
+		} else if !metricsIntegrationEnabled(ci) {
+			stepLogger(ci, "metrics").V(1).Info("ensuring metrics integration is removed")
+			if err := summary.record("metrics", "", "delete", r.ensureMetricsIntegrationDeleted(ci)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove metrics integration for ingresscontroller %s: %v", ci.Name, err))
+			}
+		} else {
+			stepLogger(ci, "metrics").V(1).Info("ensuring metrics integration")
+			if err := summary.record("metrics", "", "ensure", r.ensureMetricsIntegration(ci, internalSvc, deploymentRef)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to integrate metrics with openshift-monitoring for ingresscontroller %s: %v", ci.Name, err))
+			}
+		}
+
+		stepLogger(ci, "router-stats-route").V(1).Info("ensuring router stats route")
+		if err := summary.record("router-stats-route", RouterStatsRouteName(ci, r.OperandNamespace).String(), "ensure", r.ensureRouterStatsRoute(ci, deploymentRef)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure router stats route for ingresscontroller %s: %v", ci.Name, err))
 		}
 
+		statusErrs := []error{}
 		operandEvents := &corev1.EventList{}
-		if err := r.cache.List(context.TODO(), operandEvents, client.InNamespace("openshift-ingress")); err != nil {
-			errs = append(errs, fmt.Errorf("failed to list events in namespace %q: %v", "openshift-ingress", err))
+		if err := r.cache.List(context.TODO(), operandEvents, client.InNamespace(r.OperandNamespace)); err != nil {
+			statusErrs = append(statusErrs, fmt.Errorf("failed to list events in namespace %q: %v", r.OperandNamespace, err))
+		}
+
+		pods := &corev1.PodList{}
+		if err := r.cache.List(context.TODO(), pods, client.InNamespace(deployment.Namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+			statusErrs = append(statusErrs, fmt.Errorf("failed to list pods in namespace %q: %v", deployment.Namespace, err))
+		}
+
+		if _, ok := ci.Annotations[dumpRouteConfigAnnotation]; ok {
+			stepLogger(ci, "route-dump").V(1).Info("dumping route config")
+			if err := summary.record("route-dump", RouteConfigDumpConfigMapName(ci, r.OperandNamespace).String(), "ensure", r.ensureRouteConfigDump(ci, pods.Items, deploymentRef)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to dump route config for ingresscontroller %s: %v", ci.Name, err))
+			}
 		}
 
-		if err := r.syncIngressControllerStatus(ci, deployment, lbService, operandEvents.Items); err != nil {
-			errs = append(errs, fmt.Errorf("failed to sync ingresscontroller status: %v", err))
+		stepLogger(ci, "status").V(1).Info("syncing ingresscontroller status")
+		statusResult, err := r.syncIngressControllerStatus(ci, deployment, pods.Items, lbService, operandEvents.Items, dnsConfig)
+		if err != nil {
+			statusErrs = append(statusErrs, fmt.Errorf("failed to sync ingresscontroller status: %v", err))
 		}
+		summary.record("status", icName, "sync", utilerrors.NewAggregate(statusErrs))
+		errs = append(errs, statusErrs...)
+		result.RequeueAfter = statusResult.RequeueAfter
 	}
 
-	return utilerrors.NewAggregate(errs)
+	return result, summary, utilerrors.NewAggregate(errs)
+}
+
+// rotateRouterStatsCredentials regenerates the credentials in the given router
+// stats secret, rolls out the router deployment so that the router pods pick
+// up the new credentials, and removes the rotation request annotation from the
+// ingresscontroller.  Metrics scraping is unaffected because the
+// ServiceMonitor authenticates with a bearer token rather than the stats
+// credentials.
+func (r *reconciler) rotateRouterStatsCredentials(ci *operatorv1.IngressController, statsSecret *corev1.Secret) error {
+	updatedSecret := statsSecret.DeepCopy()
+	updatedSecret.Data = manifests.RouterStatsSecret(ci, r.OperandNamespace).Data
+	if err := r.client.Update(context.TODO(), updatedSecret); err != nil {
+		return fmt.Errorf("failed to update router stats secret %s/%s: %v", updatedSecret.Namespace, updatedSecret.Name, err)
+	}
+	stepLogger(ci, "metrics").Info("rotated router stats credentials", "namespace", updatedSecret.Namespace, "name", updatedSecret.Name)
+
+	deployment, err := r.currentRouterDeployment(ci)
+	if err != nil {
+		return err
+	}
+	if deployment != nil {
+		updated := deployment.DeepCopy()
+		if updated.Spec.Template.Annotations == nil {
+			updated.Spec.Template.Annotations = map[string]string{}
+		}
+		updated.Spec.Template.Annotations[statsCredentialsRotatedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update router deployment %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		stepLogger(ci, "metrics").Info("restarted router deployment to pick up rotated stats credentials", "namespace", updated.Namespace, "name", updated.Name)
+	}
+
+	return r.removeIngressControllerAnnotation(ci, rotateStatsCredentialsAnnotation)
+}
+
+// removeIngressControllerAnnotation removes the given annotation from the
+// given ingresscontroller and refreshes the ingresscontroller.
+func (r *reconciler) removeIngressControllerAnnotation(ic *operatorv1.IngressController, annotation string) error {
+	if err := r.updateIngressControllerMetadata(ic, func(meta *metav1.ObjectMeta) {
+		delete(meta.Annotations, annotation)
+	}); err != nil {
+		return fmt.Errorf("failed to remove annotation %s from ingresscontroller %s/%s: %v", annotation, ic.Namespace, ic.Name, err)
+	}
+	if err := r.getIngressController(types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, ic); err != nil {
+		return fmt.Errorf("failed to get ingresscontroller %s/%s: %v", ic.Namespace, ic.Name, err)
+	}
+	log.Info("removed annotation from ingresscontroller", "ingresscontroller", ic.Namespace+"/"+ic.Name, "annotation", annotation)
+	return nil
+}
+
+// updateIngressControllerMetadata applies the given change to the metadata of
+// the given ingresscontroller.  The change is applied to a fresh copy of the
+// ingresscontroller from the API rather than to ic, whose spec has been
+// normalized, so that the update does not write the normalized spec back.  ic
+// itself is left unchanged.
+func (r *reconciler) updateIngressControllerMetadata(ic *operatorv1.IngressController, mutate func(*metav1.ObjectMeta)) error {
+	updated := &operatorv1.IngressController{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, updated); err != nil {
+		return err
+	}
+	mutate(&updated.ObjectMeta)
+	return r.client.Update(context.TODO(), updated)
 }
 
 // ensureMetricsIntegration ensures that router prometheus metrics is integrated with openshift-monitoring for the given ingresscontroller.
+// The metrics role bindings grant access to the prometheus service account in
+// the configured monitoring namespace and are updated if that changes.
 func (r *reconciler) ensureMetricsIntegration(ci *operatorv1.IngressController, svc *corev1.Service, deploymentRef metav1.OwnerReference) error {
-	statsSecret := manifests.RouterStatsSecret(ci)
+	statsSecret := manifests.RouterStatsSecret(ci, r.OperandNamespace)
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: statsSecret.Namespace, Name: statsSecret.Name}, statsSecret); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get router stats secret %s/%s, %v", statsSecret.Namespace, statsSecret.Name, err)
@@ -440,7 +1072,13 @@ func (r *reconciler) ensureMetricsIntegration(ci *operatorv1.IngressController,
 		if err := r.client.Create(context.TODO(), statsSecret); err != nil {
 			return fmt.Errorf("failed to create router stats secret %s/%s: %v", statsSecret.Namespace, statsSecret.Name, err)
 		}
-		log.Info("created router stats secret", "namespace", statsSecret.Namespace, "name", statsSecret.Name)
+		stepLogger(ci, "metrics").Info("created router stats secret", "namespace", statsSecret.Namespace, "name", statsSecret.Name)
+	}
+
+	if _, ok := ci.Annotations[rotateStatsCredentialsAnnotation]; ok {
+		if err := r.rotateRouterStatsCredentials(ci, statsSecret); err != nil {
+			return err
+		}
 	}
 
 	cr := manifests.MetricsClusterRole()
@@ -454,7 +1092,8 @@ func (r *reconciler) ensureMetricsIntegration(ci *operatorv1.IngressController,
 		log.Info("created router metrics cluster role", "name", cr.Name)
 	}
 
-	crb := manifests.MetricsClusterRoleBinding()
+	desiredCRB := manifests.MetricsClusterRoleBinding(r.monitoringNamespace())
+	crb := desiredCRB.DeepCopy()
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: crb.Name}, crb); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get router metrics cluster role binding %s: %v", crb.Name, err)
@@ -463,9 +1102,16 @@ func (r *reconciler) ensureMetricsIntegration(ci *operatorv1.IngressController,
 			return fmt.Errorf("failed to create router metrics cluster role binding %s: %v", crb.Name, err)
 		}
 		log.Info("created router metrics cluster role binding", "name", crb.Name)
+	} else if !reflect.DeepEqual(crb.Subjects, desiredCRB.Subjects) {
+		// The monitoring stack may have moved to another namespace.
+		crb.Subjects = desiredCRB.Subjects
+		if err := r.client.Update(context.TODO(), crb); err != nil {
+			return fmt.Errorf("failed to update router metrics cluster role binding %s: %v", crb.Name, err)
+		}
+		log.Info("updated router metrics cluster role binding", "name", crb.Name, "monitoringNamespace", r.monitoringNamespace())
 	}
 
-	mr := manifests.MetricsRole()
+	mr := manifests.MetricsRole(r.OperandNamespace)
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: mr.Namespace, Name: mr.Name}, mr); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get router metrics role %s: %v", mr.Name, err)
@@ -476,7 +1122,8 @@ func (r *reconciler) ensureMetricsIntegration(ci *operatorv1.IngressController,
 		log.Info("created router metrics role", "name", mr.Name)
 	}
 
-	mrb := manifests.MetricsRoleBinding()
+	desiredMRB := manifests.MetricsRoleBinding(r.OperandNamespace, r.monitoringNamespace())
+	mrb := desiredMRB.DeepCopy()
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: mrb.Namespace, Name: mrb.Name}, mrb); err != nil {
 		if !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to get router metrics role binding %s: %v", mrb.Name, err)
@@ -485,12 +1132,99 @@ func (r *reconciler) ensureMetricsIntegration(ci *operatorv1.IngressController,
 			return fmt.Errorf("failed to create router metrics role binding %s: %v", mrb.Name, err)
 		}
 		log.Info("created router metrics role binding", "name", mrb.Name)
+	} else if !reflect.DeepEqual(mrb.Subjects, desiredMRB.Subjects) {
+		mrb.Subjects = desiredMRB.Subjects
+		if err := r.client.Update(context.TODO(), mrb); err != nil {
+			return fmt.Errorf("failed to update router metrics role binding %s: %v", mrb.Name, err)
+		}
+		log.Info("updated router metrics role binding", "name", mrb.Name, "monitoringNamespace", r.monitoringNamespace())
 	}
 
-	if _, err := r.ensureServiceMonitor(ci, svc, deploymentRef); err != nil {
+	caBundle, err := r.ensureMetricsCABundleConfigMap(ci, deploymentRef)
+	if err != nil {
+		return fmt.Errorf("failed to ensure metrics CA bundle configmap for %s: %v", ci.Name, err)
+	}
+
+	if _, err := r.ensureServiceMonitor(ci, svc, caBundle, deploymentRef); err != nil {
 		return fmt.Errorf("failed to ensure servicemonitor for %s: %v", ci.Name, err)
 	}
 
+	if _, err := r.ensurePrometheusRule(ci, svc, deploymentRef); err != nil {
+		return fmt.Errorf("failed to ensure prometheusrule for %s: %v", ci.Name, err)
+	}
+
+	return nil
+}
+
+// monitoringNamespace returns the namespace of the monitoring stack whose
+// prometheus service account is granted access to router metrics.
+func (r *reconciler) monitoringNamespace() string {
+	if len(r.MonitoringNamespace) == 0 {
+		return DefaultMonitoringNamespace
+	}
+	return r.MonitoringNamespace
+}
+
+// metricsIntegrationEnabled returns a Boolean indicating whether the router's
+// metrics should be integrated with openshift-monitoring for the given
+// ingresscontroller.
+func metricsIntegrationEnabled(ic *operatorv1.IngressController) bool {
+	return ic.Annotations[disableMetricsIntegrationAnnotation] != "true"
+}
+
+// ensureMetricsIntegrationDeleted deletes the servicemonitor, prometheusrule,
+// stats secret, and metrics CA bundle configmap for the given ingresscontroller.  If no other
+// ingresscontroller integrates metrics, the shared metrics roles and role
+// bindings are deleted as well.
+func (r *reconciler) ensureMetricsIntegrationDeleted(ci *operatorv1.IngressController) error {
+	errs := []error{}
+
+	serviceMonitor := desiredServiceMonitor(ci, r.OperandNamespace, &corev1.Service{}, nil, metav1.OwnerReference{})
+	prometheusRule := desiredPrometheusRule(ci, r.OperandNamespace, &corev1.Service{}, metav1.OwnerReference{})
+	statsSecret := manifests.RouterStatsSecret(ci, r.OperandNamespace)
+	caBundleName := MetricsCABundleConfigMapName(ci, r.OperandNamespace)
+	caBundle := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: caBundleName.Namespace, Name: caBundleName.Name}}
+	for _, o := range []runtime.Object{serviceMonitor, prometheusRule, statsSecret, caBundle} {
+		if err := r.deleteIfExists(o); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.Namespace)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to list ingresscontrollers: %v", err))
+		return utilerrors.NewAggregate(errs)
+	}
+	for i := range ingresses.Items {
+		other := &ingresses.Items[i]
+		if other.Name != ci.Name && other.DeletionTimestamp == nil && metricsIntegrationEnabled(other) {
+			return utilerrors.NewAggregate(errs)
+		}
+	}
+	for _, o := range []runtime.Object{manifests.MetricsRoleBinding(r.OperandNamespace, r.monitoringNamespace()), manifests.MetricsRole(r.OperandNamespace), manifests.MetricsClusterRoleBinding(r.monitoringNamespace()), manifests.MetricsClusterRole()} {
+		if err := r.deleteIfExists(o); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// deleteIfExists deletes the given object, ignoring the error if the object or
+// its kind does not exist.  The latter is the case for the servicemonitor when
+// the monitoring stack is not installed.
+func (r *reconciler) deleteIfExists(o runtime.Object) error {
+	accessor, err := meta.Accessor(o)
+	if err != nil {
+		return err
+	}
+	if err := r.client.Delete(context.TODO(), o); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete %T %s/%s: %v", o, accessor.GetNamespace(), accessor.GetName(), err)
+	}
+	log.Info("deleted object", "kind", fmt.Sprintf("%T", o), "namespace", accessor.GetNamespace(), "name", accessor.GetName())
 	return nil
 }
 
@@ -1,30 +1,256 @@
 package controller
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"sort"
+	"strings"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
 
 	corev1 "k8s.io/api/core/v1"
 
+	"k8s.io/apimachinery/pkg/types"
+
 	configv1 "github.com/openshift/api/config/v1"
 )
 
+const (
+	// forceDNSSyncAnnotation, when present on an ingresscontroller, makes
+	// the operator create or update the ingresscontroller's DNS records
+	// even if it believes that it has already done so.  The operator
+	// removes the annotation once the records have been ensured.
+	forceDNSSyncAnnotation = "ingress.operator.openshift.io/force-dns-sync"
+
+	// dnsAliasesAnnotation specifies a comma-separated list of extra DNS
+	// names to publish for the ingresscontroller alongside the wildcard
+	// record, for example "console.apps.example.com".  Each name must be
+	// a subdomain of the ingresscontroller's domain.
+	dnsAliasesAnnotation = "ingress.operator.openshift.io/dns-aliases"
+
+	// publishedDNSAliasesAnnotation records the DNS aliases for which the
+	// operator has published records so that it can delete the records
+	// of aliases that are removed from dnsAliasesAnnotation.  The operator
+	// manages this annotation.
+	publishedDNSAliasesAnnotation = "ingress.operator.openshift.io/published-dns-aliases"
+
+	// publishedAAAARecordsAnnotation records that the operator has
+	// published AAAA records for the IPv6 addresses of the
+	// ingresscontroller's load balancer so that it can delete the records
+	// if the load balancer loses its IPv6 addresses.  The operator manages
+	// this annotation.
+	publishedAAAARecordsAnnotation = "ingress.operator.openshift.io/published-aaaa-records"
+
+	// dnsManagementPausedAnnotation, when set to "true" on an
+	// ingresscontroller, pauses the management of its DNS records, for
+	// example because an external controller such as external-dns manages
+	// them.  The operator then neither publishes nor deletes the records,
+	// including when the ingresscontroller is deleted, but otherwise
+	// reconciles the ingresscontroller as usual.  Records that the operator
+	// published before management was paused are left as they are.
+	// Allowed values are "true" and "false".  If unset, the operator
+	// manages the records.
+	dnsManagementPausedAnnotation = "ingress.operator.openshift.io/dns-management-paused"
+
+	// IngressControllerDNSZonesConfiguredConditionType indicates whether the
+	// cluster DNS configuration has the zones in which the operator
+	// publishes the ingresscontroller's DNS records.  It is False if the
+	// ingresscontroller is published by a load balancer but neither zone
+	// is configured, in which case the operator publishes no records.
+	IngressControllerDNSZonesConfiguredConditionType = "DNSZonesConfigured"
+)
+
+// dnsManagementPaused returns a Boolean indicating whether the management of
+// the given ingresscontroller's DNS records is paused.
+func dnsManagementPaused(ic *operatorv1.IngressController) bool {
+	return ic.Annotations[dnsManagementPausedAnnotation] == "true"
+}
+
 // ensureDNS will create DNS records for the given LB service. If service is
-// nil, nothing is done.
+// nil, nothing is done.  If the ingresscontroller has the force DNS sync
+// annotation, the records are re-asserted and the annotation is removed.  The
+// records are published through the cluster proxy config, if any.
 func (r *reconciler) ensureDNS(ci *operatorv1.IngressController, service *corev1.Service, dnsConfig *configv1.DNS) error {
+	if dnsManagementPaused(ci) {
+		stepLogger(ci, "dns").V(1).Info("DNS management is paused; not ensuring DNS records")
+		return nil
+	}
+	_, force := ci.Annotations[forceDNSSyncAnnotation]
 	records := desiredDNSRecords(ci, dnsConfig, service)
+	if err := r.ensureDNSProxy(); err != nil {
+		return err
+	}
+	manager, err := r.dnsManager(ci)
+	if err != nil {
+		return err
+	}
 	for _, record := range records {
-		err := r.DNSManager.Ensure(record)
-		if err != nil {
+		ensure := manager.Ensure
+		if force {
+			ensure = manager.ForceEnsure
+		}
+		if err := ensure(record); err != nil {
 			return fmt.Errorf("failed to ensure DNS record %v for %s/%s: %v", record, ci.Namespace, ci.Name, err)
 		}
-		log.Info("ensured DNS record for ingresscontroller", "namespace", ci.Namespace, "name", ci.Name, "record", record)
+		stepLogger(ci, "dns").Info("ensured DNS record for ingresscontroller", "record", record, "forced", force)
+	}
+	if err := r.deleteStaleDNSAliases(ci, manager, service, dnsConfig); err != nil {
+		return err
+	}
+	if err := r.deleteStaleAAAARecords(ci, manager, service, dnsConfig); err != nil {
+		return err
+	}
+	if force {
+		return r.removeIngressControllerAnnotation(ci, forceDNSSyncAnnotation)
+	}
+	return nil
+}
+
+// deleteStaleDNSAliases deletes the records of DNS aliases that the operator
+// published for the given ingresscontroller but that the ingresscontroller no
+// longer specifies, and then records the currently specified aliases as
+// published.  The records are deleted with the given DNS manager.
+func (r *reconciler) deleteStaleDNSAliases(ci *operatorv1.IngressController, manager dns.Manager, service *corev1.Service, dnsConfig *configv1.DNS) error {
+	for _, record := range dnsRecords(staleDNSAliases(ci), dnsConfig, service) {
+		if err := manager.Delete(record); err != nil {
+			return fmt.Errorf("failed to delete DNS record %v for %s/%s: %v", record, ci.Namespace, ci.Name, err)
+		}
+		stepLogger(ci, "dns").Info("deleted DNS record for removed alias", "record", record)
+	}
+	aliases, published := dnsAliases(ci), publishedDNSAliases(ci)
+	if strings.Join(aliases, ",") == strings.Join(published, ",") {
+		return nil
+	}
+	updated := ci.DeepCopy()
+	if len(aliases) == 0 {
+		delete(updated.Annotations, publishedDNSAliasesAnnotation)
+	} else {
+		updated.Annotations[publishedDNSAliasesAnnotation] = strings.Join(aliases, ",")
+	}
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to record published DNS aliases for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
+	}
+	if err := r.getIngressController(types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, ci); err != nil {
+		return fmt.Errorf("failed to get ingresscontroller %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
 	return nil
 }
 
+// deleteStaleAAAARecords deletes the AAAA records that the operator published
+// for the given ingresscontroller if its load balancer no longer has IPv6
+// addresses, and then records whether AAAA records are published.  The records
+// are deleted with the given DNS manager.
+func (r *reconciler) deleteStaleAAAARecords(ci *operatorv1.IngressController, manager dns.Manager, service *corev1.Service, dnsConfig *configv1.DNS) error {
+	for _, record := range staleAAAARecords(ci, dnsConfig, service) {
+		if err := manager.Delete(record); err != nil {
+			return fmt.Errorf("failed to delete DNS record %v for %s/%s: %v", record, ci.Namespace, ci.Name, err)
+		}
+		stepLogger(ci, "dns").Info("deleted AAAA record for load balancer without IPv6 addresses", "record", record)
+	}
+	_, published := ci.Annotations[publishedAAAARecordsAnnotation]
+	if published == hasIPv6Address(service) {
+		return nil
+	}
+	updated := ci.DeepCopy()
+	if published {
+		delete(updated.Annotations, publishedAAAARecordsAnnotation)
+	} else {
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[publishedAAAARecordsAnnotation] = "true"
+	}
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to record published AAAA records for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
+	}
+	if err := r.getIngressController(types.NamespacedName{Namespace: updated.Namespace, Name: updated.Name}, ci); err != nil {
+		return fmt.Errorf("failed to get ingresscontroller %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	return nil
+}
+
+// staleAAAARecords returns the AAAA records to delete for the given
+// ingresscontroller: if the operator has published AAAA records but the load
+// balancer no longer has IPv6 addresses, the records for the wildcard name
+// and for every current or published DNS alias in each zone.  The records
+// have no address because deleting a record only needs its name.
+func staleAAAARecords(ci *operatorv1.IngressController, dnsConfig *configv1.DNS, service *corev1.Service) []*dns.Record {
+	records := []*dns.Record{}
+	if _, ok := ci.Annotations[publishedAAAARecordsAnnotation]; !ok || hasIPv6Address(service) || len(ci.Status.Domain) == 0 {
+		return records
+	}
+	names := []string{fmt.Sprintf("*.%s", ci.Status.Domain)}
+	for _, name := range append(dnsAliases(ci), publishedDNSAliases(ci)...) {
+		if !slice.ContainsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		for _, zone := range dnsZones(dnsConfig) {
+			records = append(records, newAAAARecord(name, "", zone))
+		}
+	}
+	return records
+}
+
+// hasIPv6Address returns a Boolean indicating whether the given LB service
+// has an IPv6 ingress address or external IP.
+func hasIPv6Address(service *corev1.Service) bool {
+	for _, ingress := range serviceAddresses(service) {
+		if isIPv6(ingress.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIPv6 returns a Boolean indicating whether the given string is an IPv6
+// address.
+func isIPv6(address string) bool {
+	ip := net.ParseIP(address)
+	return ip != nil && ip.To4() == nil
+}
+
+// dnsAliases returns the DNS aliases that the given ingresscontroller
+// specifies, in order and without duplicates.
+func dnsAliases(ci *operatorv1.IngressController) []string {
+	return splitDNSNames(ci.Annotations[dnsAliasesAnnotation])
+}
+
+// publishedDNSAliases returns the DNS aliases for which the operator has
+// published records for the given ingresscontroller.
+func publishedDNSAliases(ci *operatorv1.IngressController) []string {
+	return splitDNSNames(ci.Annotations[publishedDNSAliasesAnnotation])
+}
+
+// staleDNSAliases returns the DNS aliases for which the operator has published
+// records for the given ingresscontroller but that the ingresscontroller no
+// longer specifies.
+func staleDNSAliases(ci *operatorv1.IngressController) []string {
+	aliases := dnsAliases(ci)
+	stale := []string{}
+	for _, name := range publishedDNSAliases(ci) {
+		if !slice.ContainsString(aliases, name) {
+			stale = append(stale, name)
+		}
+	}
+	return stale
+}
+
+func splitDNSNames(v string) []string {
+	names := []string{}
+	for _, name := range strings.Split(v, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) != 0 && !slice.ContainsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func newAliasRecord(domain, target string, zone configv1.DNSZone) *dns.Record {
 	return &dns.Record{
 		Zone: zone,
@@ -47,6 +273,17 @@ func newARecord(domain, target string, zone configv1.DNSZone) *dns.Record {
 	}
 }
 
+func newAAAARecord(domain, target string, zone configv1.DNSZone) *dns.Record {
+	return &dns.Record{
+		Zone: zone,
+		Type: dns.AAAARecordType,
+		AAAARecord: &dns.AAAARecord{
+			Domain:  domain,
+			Address: target,
+		},
+	}
+}
+
 // desiredDNSRecords will return any necessary DNS records for the given inputs.
 // If an ingress domain is in use, records are desired in every specified zone
 // present in the cluster DNS configuration.
@@ -64,7 +301,40 @@ func desiredDNSRecords(ci *operatorv1.IngressController, dnsConfig *configv1.DNS
 		return records
 	}
 
-	name := fmt.Sprintf("*.%s", ci.Status.Domain)
+	names := append([]string{fmt.Sprintf("*.%s", ci.Status.Domain)}, dnsAliases(ci)...)
+	return dnsRecords(names, dnsConfig, service)
+}
+
+// dnsRecords returns records for each of the given names in every zone in the
+// cluster DNS configuration, pointing at the given LB service's external IPs
+// if it has any, and at its load balancer otherwise.
+func dnsRecords(names []string, dnsConfig *configv1.DNS, service *corev1.Service) []*dns.Record {
+	records := []*dns.Record{}
+	zones := dnsZones(dnsConfig)
+	for _, name := range names {
+		for _, ingress := range serviceAddresses(service) {
+			if len(ingress.Hostname) > 0 {
+				for _, zone := range zones {
+					records = append(records, newAliasRecord(name, ingress.Hostname, zone))
+				}
+			}
+			if len(ingress.IP) > 0 {
+				for _, zone := range zones {
+					if isIPv6(ingress.IP) {
+						records = append(records, newAAAARecord(name, ingress.IP, zone))
+					} else {
+						records = append(records, newARecord(name, ingress.IP, zone))
+					}
+				}
+			}
+		}
+	}
+
+	return records
+}
+
+// dnsZones returns the zones in the cluster DNS configuration.
+func dnsZones(dnsConfig *configv1.DNS) []configv1.DNSZone {
 	zones := []configv1.DNSZone{}
 	if dnsConfig.Spec.PrivateZone != nil {
 		zones = append(zones, *dnsConfig.Spec.PrivateZone)
@@ -72,18 +342,78 @@ func desiredDNSRecords(ci *operatorv1.IngressController, dnsConfig *configv1.DNS
 	if dnsConfig.Spec.PublicZone != nil {
 		zones = append(zones, *dnsConfig.Spec.PublicZone)
 	}
-	for _, ingress := range service.Status.LoadBalancer.Ingress {
-		if len(ingress.Hostname) > 0 {
-			for _, zone := range zones {
-				records = append(records, newAliasRecord(name, ingress.Hostname, zone))
-			}
+	return zones
+}
+
+// dnsZoneConfigured returns a Boolean indicating whether the given zone
+// identifies a DNS hosted zone.
+func dnsZoneConfigured(zone *configv1.DNSZone) bool {
+	return zone != nil && (len(zone.ID) != 0 || len(zone.Tags) != 0)
+}
+
+// describeDNSZone returns a human-readable identification of the given zone.
+func describeDNSZone(zone *configv1.DNSZone) string {
+	if len(zone.ID) != 0 {
+		return zone.ID
+	}
+	tags := []string{}
+	for k, v := range zone.Tags {
+		tags = append(tags, k+"="+v)
+	}
+	sort.Strings(tags)
+	return "with tags " + strings.Join(tags, ",")
+}
+
+// computeDNSZonesConfiguredCondition computes the DNSZonesConfigured condition
+// of the given ingresscontroller from the given cluster DNS configuration.
+// Only the LoadBalancerService endpoint publishing strategy publishes DNS
+// records, so the condition is True for other strategies.  It is also True if
+// DNS management is paused, in which case the records are managed externally.
+func computeDNSZonesConfiguredCondition(ic *operatorv1.IngressController, dnsConfig *configv1.DNS) operatorv1.OperatorCondition {
+	if ic.Status.EndpointPublishingStrategy == nil || ic.Status.EndpointPublishingStrategy.Type != operatorv1.LoadBalancerServiceStrategyType {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDNSZonesConfiguredConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "DNSNotRequired",
+			Message: "The endpoint publishing strategy does not publish DNS records.",
 		}
-		if len(ingress.IP) > 0 {
-			for _, zone := range zones {
-				records = append(records, newARecord(name, ingress.IP, zone))
-			}
+	}
+	if dnsManagementPaused(ic) {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDNSZonesConfiguredConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "ExternallyManaged",
+			Message: fmt.Sprintf("DNS management is paused by annotation %s, so the operator neither publishes nor deletes the ingresscontroller's DNS records; they must be managed externally.", dnsManagementPausedAnnotation),
 		}
 	}
-
-	return records
+	public, private := dnsConfig.Spec.PublicZone, dnsConfig.Spec.PrivateZone
+	switch {
+	case !dnsZoneConfigured(public) && !dnsZoneConfigured(private):
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDNSZonesConfiguredConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "NoDNSZones",
+			Message: "The cluster DNS config dnses.config.openshift.io/cluster specifies neither a public nor a private zone, so no DNS records are published for the ingresscontroller.",
+		}
+	case !dnsZoneConfigured(public):
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDNSZonesConfiguredConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "PrivateZoneOnly",
+			Message: fmt.Sprintf("DNS records are published in private zone %s only; the cluster DNS config specifies no public zone.", describeDNSZone(private)),
+		}
+	case !dnsZoneConfigured(private):
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDNSZonesConfiguredConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "PublicZoneOnly",
+			Message: fmt.Sprintf("DNS records are published in public zone %s only; the cluster DNS config specifies no private zone.", describeDNSZone(public)),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerDNSZonesConfiguredConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "ZonesConfigured",
+		Message: fmt.Sprintf("DNS records are published in public zone %s and private zone %s.", describeDNSZone(public), describeDNSZone(private)),
+	}
 }
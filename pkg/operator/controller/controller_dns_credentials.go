@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// dnsCredentialsSecretAnnotation specifies the name of a secret in the
+	// operator namespace with the cloud credentials with which the
+	// operator manages the ingresscontroller's DNS records, instead of the
+	// operator's own credentials.  The secret must have the same keys as
+	// the operator's cloud-credentials secret for the cluster's platform,
+	// for example aws_access_key_id and aws_secret_access_key on AWS.  The
+	// records are also deleted with these credentials when the
+	// ingresscontroller is deleted, so the secret must remain valid until
+	// then.  If unset, the operator's own credentials are used.
+	dnsCredentialsSecretAnnotation = "ingress.operator.openshift.io/dns-credentials-secret"
+
+	// dnsCredentialsInvalidReason is the reason of the Degraded condition
+	// when the DNS credentials secret is missing or invalid.
+	dnsCredentialsInvalidReason = "DNSCredentialsInvalid"
+)
+
+// dnsManagerCache caches the DNS managers created from DNS credentials
+// secrets, keyed by the secrets' namespace and name, so that a manager is
+// created again only when its secret changes.
+type dnsManagerCache struct {
+	lock     sync.Mutex
+	managers map[string]cachedDNSManager
+}
+
+// cachedDNSManager is a DNS manager created from the given version of a DNS
+// credentials secret.
+type cachedDNSManager struct {
+	resourceVersion string
+	manager         dns.Manager
+}
+
+// dnsManager returns the DNS manager with which to manage the given
+// ingresscontroller's DNS records: the manager for the credentials that the
+// ingresscontroller specifies, if any, and the operator's own manager
+// otherwise.  If the operator does not manage DNS records on the cluster's
+// platform, its own manager, which does nothing, is always used so that
+// deleting the ingresscontroller does not wait for credentials that were
+// never used.
+func (r *reconciler) dnsManager(ic *operatorv1.IngressController) (dns.Manager, error) {
+	if _, ok := ic.Annotations[dnsCredentialsSecretAnnotation]; !ok || r.DNSManagerFactory == nil {
+		return r.DNSManager, nil
+	}
+	manager, problem, err := r.dnsCredentialsManager(ic)
+	if err != nil {
+		return nil, err
+	}
+	if len(problem) != 0 {
+		return nil, fmt.Errorf("cannot manage DNS records with the credentials from annotation %s: %s", dnsCredentialsSecretAnnotation, problem)
+	}
+	return manager, nil
+}
+
+// dnsCredentialsManager returns the DNS manager for the credentials secret
+// that the given ingresscontroller specifies or, if no manager can be created,
+// a description of the problem.  The error is only set if the secret cannot
+// be read.
+func (r *reconciler) dnsCredentialsManager(ic *operatorv1.IngressController) (dns.Manager, string, error) {
+	if r.DNSManagerFactory == nil {
+		return nil, "the operator does not manage DNS records on this platform", nil
+	}
+	name := types.NamespacedName{Namespace: r.Namespace, Name: ic.Annotations[dnsCredentialsSecretAnnotation]}
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), name, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Sprintf("secret %s does not exist", name), nil
+		}
+		return nil, "", fmt.Errorf("failed to get DNS credentials secret %s: %v", name, err)
+	}
+
+	r.dnsManagers.lock.Lock()
+	defer r.dnsManagers.lock.Unlock()
+	if cached, ok := r.dnsManagers.managers[name.String()]; ok && cached.resourceVersion == secret.ResourceVersion {
+		return cached.manager, "", nil
+	}
+	manager, err := r.DNSManagerFactory(secret)
+	if err != nil {
+		return nil, err.Error(), nil
+	}
+	if r.dnsManagers.managers == nil {
+		r.dnsManagers.managers = map[string]cachedDNSManager{}
+	}
+	r.dnsManagers.managers[name.String()] = cachedDNSManager{resourceVersion: secret.ResourceVersion, manager: manager}
+	stepLogger(ic, "dns").Info("created DNS manager for credentials secret", "namespace", name.Namespace, "name", name.Name)
+	return manager, "", nil
+}
+
+// dnsCredentialsProblem returns a description of why no DNS manager can be
+// created from the credentials secret that the given ingresscontroller
+// specifies, or the empty string if one can or the ingresscontroller
+// specifies none.  Only ingresscontrollers that are published by a load
+// balancer have DNS records, so others have no problem, and neither do
+// ingresscontrollers whose DNS management is paused.
+func (r *reconciler) dnsCredentialsProblem(ic *operatorv1.IngressController) (string, error) {
+	if _, ok := ic.Annotations[dnsCredentialsSecretAnnotation]; !ok || !usesLoadBalancer(ic) || dnsManagementPaused(ic) {
+		return "", nil
+	}
+	_, problem, err := r.dnsCredentialsManager(ic)
+	return problem, err
+}
+
+// dnsCredentialsSecretToIngressControllers maps a secret in the operator
+// namespace to requests for the ingresscontrollers that use it as their DNS
+// credentials.
+func (r *reconciler) dnsCredentialsSecretToIngressControllers(a handler.MapObject) []reconcile.Request {
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for secret", "related", a.Meta.GetSelfLink())
+		return []reconcile.Request{}
+	}
+	requests := []reconcile.Request{}
+	for _, ic := range ingresses.Items {
+		if ic.Annotations[dnsCredentialsSecretAnnotation] != a.Meta.GetName() {
+			continue
+		}
+		log.Info("queueing ingress", "name", ic.Name, "related", a.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+		})
+	}
+	return requests
+}
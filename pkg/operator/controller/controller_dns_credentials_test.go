@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// fakeDNSManagerFactory is a dns.ManagerFactory that creates fakeDNSManagers
+// from secrets with key "token" and records the secrets it was called with.
+type fakeDNSManagerFactory struct {
+	secrets  []string
+	managers []*fakeDNSManager
+}
+
+func (f *fakeDNSManagerFactory) create(secret *corev1.Secret) (dns.Manager, error) {
+	f.secrets = append(f.secrets, secret.Name+"@"+secret.ResourceVersion)
+	if len(secret.Data["token"]) == 0 {
+		return nil, fmt.Errorf("secret %s/%s has no key token", secret.Namespace, secret.Name)
+	}
+	manager := &fakeDNSManager{}
+	f.managers = append(f.managers, manager)
+	return manager, nil
+}
+
+// TestEnsureDNSCredentials verifies that an ingresscontroller's DNS records
+// are managed with the manager for its own credentials, that the manager is
+// created again only when the secret changes, and that a missing or invalid
+// secret is reported.
+func TestEnsureDNSCredentials(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "openshift-ingress-operator",
+			Name:        "default",
+			Annotations: map[string]string{dnsCredentialsSecretAnnotation: "dns-creds"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	service := &corev1.Service{}
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.cloudprovider.example.com"}}
+	operatorManager := &fakeDNSManager{}
+	factory := &fakeDNSManagerFactory{}
+	client := newFakeClient(ic)
+	r := &reconciler{
+		Config: Config{
+			Namespace:         "openshift-ingress-operator",
+			OperandNamespace:  "openshift-ingress",
+			DNSManager:        operatorManager,
+			DNSManagerFactory: factory.create,
+		},
+		client: client,
+	}
+
+	expectProblem := "secret openshift-ingress-operator/dns-creds does not exist"
+	if problem, err := r.dnsCredentialsProblem(ic); err != nil || problem != expectProblem {
+		t.Errorf("expected problem %q, got %q, %v", expectProblem, problem, err)
+	}
+	expectErr := "cannot manage DNS records with the credentials from annotation ingress.operator.openshift.io/dns-credentials-secret: " + expectProblem
+	if err := r.ensureDNS(ic, service, globalConfig); err == nil || err.Error() != expectErr {
+		t.Errorf("expected error %q, got %v", expectErr, err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "dns-creds", ResourceVersion: "1"},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	if err := client.Create(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.ensureDNS(ic, service, globalConfig); err != nil {
+			t.Fatalf("failed to ensure DNS: %v", err)
+		}
+	}
+	if !cmp.Equal(factory.secrets, []string{"dns-creds@1"}) {
+		t.Errorf("expected one DNS manager to be created for dns-creds@1, got %v", factory.secrets)
+	}
+	if len(factory.managers[0].ensured) != 4 || len(operatorManager.ensured) != 0 {
+		t.Errorf("expected 4 records to be ensured with the ingresscontroller's credentials and none with the operator's, got %d and %d", len(factory.managers[0].ensured), len(operatorManager.ensured))
+	}
+
+	secret.ResourceVersion = "2"
+	secret.Data = map[string][]byte{}
+	if err := client.replace(secret); err != nil {
+		t.Fatal(err)
+	}
+	expectProblem = "secret openshift-ingress-operator/dns-creds has no key token"
+	if problem, err := r.dnsCredentialsProblem(ic); err != nil || problem != expectProblem {
+		t.Errorf("expected problem %q, got %q, %v", expectProblem, problem, err)
+	}
+
+	// Without the annotation, the operator's credentials are used.
+	delete(ic.Annotations, dnsCredentialsSecretAnnotation)
+	if err := r.ensureDNS(ic, service, globalConfig); err != nil {
+		t.Fatalf("failed to ensure DNS: %v", err)
+	}
+	if len(operatorManager.ensured) != 2 {
+		t.Errorf("expected 2 records to be ensured with the operator's credentials, got %d", len(operatorManager.ensured))
+	}
+}
+
+// TestDNSCredentialsWithoutDNSManagement verifies that DNS credentials are
+// reported as unusable, but do not block DNS record management, on platforms
+// on which the operator does not manage DNS records.
+func TestDNSCredentialsWithoutDNSManagement(t *testing.T) {
+	ic := ingressController("default", operatorv1.LoadBalancerServiceStrategyType)
+	ic.Annotations = map[string]string{dnsCredentialsSecretAnnotation: "dns-creds"}
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", DNSManager: &dns.NoopManager{}},
+		client: newFakeClient(),
+	}
+	expectProblem := "the operator does not manage DNS records on this platform"
+	if problem, err := r.dnsCredentialsProblem(ic); err != nil || problem != expectProblem {
+		t.Errorf("expected problem %q, got %q, %v", expectProblem, problem, err)
+	}
+	if manager, err := r.dnsManager(ic); err != nil || manager != r.DNSManager {
+		t.Errorf("expected the operator's DNS manager, got %v, %v", manager, err)
+	}
+
+	ic = ingressController("default", operatorv1.PrivateStrategyType)
+	ic.Annotations = map[string]string{dnsCredentialsSecretAnnotation: "dns-creds"}
+	if problem, err := r.dnsCredentialsProblem(ic); err != nil || len(problem) != 0 {
+		t.Errorf("expected no problem for an ingresscontroller without DNS records, got %q, %v", problem, err)
+	}
+}
+
+// TestSyncIngressControllerStatusDNSCredentialsInvalid verifies that a missing
+// DNS credentials secret marks the ingresscontroller degraded.
+func TestSyncIngressControllerStatusDNSCredentialsInvalid(t *testing.T) {
+	deployment := manifests.RouterDeployment()
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "router"}}
+	ic := ingressController("default", operatorv1.LoadBalancerServiceStrategyType)
+	ic.Namespace = "openshift-ingress-operator"
+	ic.Annotations = map[string]string{dnsCredentialsSecretAnnotation: "dns-creds"}
+	client := newFakeClient(ic)
+	factory := &fakeDNSManagerFactory{}
+	r := &reconciler{
+		Config: Config{
+			Namespace:         "openshift-ingress-operator",
+			OperandNamespace:  "openshift-ingress",
+			DNSManagerFactory: factory.create,
+		},
+		client: client,
+	}
+	if _, err := r.syncIngressControllerStatus(ic, deployment, nil, nil, nil, &configv1.DNS{}); err != nil {
+		t.Fatalf("failed to sync status: %v", err)
+	}
+	current := &operatorv1.IngressController{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+		t.Fatal(err)
+	}
+	expect := operatorv1.OperatorCondition{
+		Type:    operatorv1.OperatorStatusTypeDegraded,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  dnsCredentialsInvalidReason,
+		Message: "the DNS credentials from annotation ingress.operator.openshift.io/dns-credentials-secret cannot be used: secret openshift-ingress-operator/dns-creds does not exist; the ingresscontroller's DNS records are not managed",
+	}
+	degraded := getIngressCondition(current.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+	if degraded == nil || !cmp.Equal(*degraded, expect, cmpopts.IgnoreFields(operatorv1.OperatorCondition{}, "LastTransitionTime")) {
+		t.Errorf("expected %#v, got %#v", expect, degraded)
+	}
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// clusterProxyName is the name of the cluster proxy config.
+const clusterProxyName = "cluster"
+
+// ensureDNSProxy sets the proxy configuration through which the DNS managers
+// call their provider's API to that of the cluster proxy config.  If the
+// cluster has no proxy config, the proxy environment variables of the
+// operator's process are used.
+func (r *reconciler) ensureDNSProxy() error {
+	if r.DNSProxy == nil {
+		return nil
+	}
+	proxy := &configv1.Proxy{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: clusterProxyName}, proxy); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get proxy %q: %v", clusterProxyName, err)
+		}
+		proxy = &configv1.Proxy{}
+	}
+	if r.DNSProxy.Set(proxy.Spec) {
+		log.Info("updated the proxy configuration for DNS providers", "httpProxy", proxy.Spec.HTTPProxy, "httpsProxy", proxy.Spec.HTTPSProxy, "noProxy", proxy.Spec.NoProxy)
+	}
+	return nil
+}
+
+// proxyToIngressControllers maps the cluster proxy config to requests for all
+// ingresscontrollers so that DNS records that could not be published through
+// the previous proxy configuration are retried.
+func (r *reconciler) proxyToIngressControllers(a handler.MapObject) []reconcile.Request {
+	if a.Meta.GetName() != clusterProxyName {
+		return []reconcile.Request{}
+	}
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for proxy", "related", a.Meta.GetSelfLink())
+		return []reconcile.Request{}
+	}
+	requests := []reconcile.Request{}
+	for _, ic := range ingresses.Items {
+		log.Info("queueing ingress", "name", ic.Name, "related", a.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestEnsureDNSProxy verifies that the DNS managers' HTTP client uses the
+// cluster proxy config and follows changes to it.
+func TestEnsureDNSProxy(t *testing.T) {
+	proxy := &configv1.Proxy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.ProxySpec{HTTPSProxy: "http://proxy.example.com:3128"},
+	}
+	client := newFakeClient(proxy)
+	r := &reconciler{
+		Config: Config{DNSProxy: &dns.Proxy{}},
+		client: client,
+	}
+	transport := r.DNSProxy.HTTPClient().Transport.(*http.Transport)
+	req, _ := http.NewRequest("GET", "https://route53.amazonaws.com/", nil)
+	expectProxy := func(expect string) {
+		t.Helper()
+		if err := r.ensureDNSProxy(); err != nil {
+			t.Fatalf("failed to ensure DNS proxy: %v", err)
+		}
+		proxyURL, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatalf("failed to get proxy: %v", err)
+		}
+		actual := ""
+		if proxyURL != nil {
+			actual = proxyURL.String()
+		}
+		if actual != expect {
+			t.Errorf("expected proxy %q, got %q", expect, actual)
+		}
+	}
+
+	expectProxy("http://proxy.example.com:3128")
+
+	proxy.Spec.NoProxy = ".amazonaws.com"
+	if err := client.replace(proxy); err != nil {
+		t.Fatal(err)
+	}
+	expectProxy("")
+}
+
+// TestEnsureDNSProxyNoProxyConfig verifies that a missing cluster proxy config
+// and a nil DNS proxy are not errors.
+func TestEnsureDNSProxyNoProxyConfig(t *testing.T) {
+	r := &reconciler{Config: Config{DNSProxy: &dns.Proxy{}}, client: newFakeClient()}
+	if err := r.ensureDNSProxy(); err != nil {
+		t.Errorf("expected no error without a cluster proxy config, got %v", err)
+	}
+	r.DNSProxy = nil
+	if err := r.ensureDNSProxy(); err != nil {
+		t.Errorf("expected no error without a DNS proxy, got %v", err)
+	}
+}
+
+// TestProxyToIngressControllers verifies that the cluster proxy config is
+// mapped to every ingresscontroller and that other proxy configs are ignored.
+func TestProxyToIngressControllers(t *testing.T) {
+	client := newFakeClient(
+		&operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"}},
+		&operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "internal"}},
+	)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	cluster := &configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+	expect := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "default"}},
+		{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "internal"}},
+	}
+	sortRequests := cmpopts.SortSlices(func(a, b reconcile.Request) bool { return a.Name < b.Name })
+	if requests := r.proxyToIngressControllers(handler.MapObject{Meta: cluster, Object: cluster}); !cmp.Equal(requests, expect, sortRequests) {
+		t.Errorf("expected requests %v, got %v", expect, requests)
+	}
+	other := &configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	if requests := r.proxyToIngressControllers(handler.MapObject{Meta: other, Object: other}); len(requests) != 0 {
+		t.Errorf("expected no requests, got %v", requests)
+	}
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"context"
+	"reflect"
 	"testing"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -9,6 +11,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 )
@@ -199,3 +204,409 @@ func TestDesiredDNSRecords(t *testing.T) {
 func cmpRecords(a, b *dns.Record) bool {
 	return string(a.Zone.ID) < string(b.Zone.ID)
 }
+
+// fakeDNSManager is a dns.Manager that records which methods were called.
+type fakeDNSManager struct {
+	ensured, forced, deleted []*dns.Record
+}
+
+func (m *fakeDNSManager) Ensure(record *dns.Record) error {
+	m.ensured = append(m.ensured, record)
+	return nil
+}
+
+func (m *fakeDNSManager) ForceEnsure(record *dns.Record) error {
+	m.forced = append(m.forced, record)
+	return nil
+}
+
+func (m *fakeDNSManager) Delete(record *dns.Record) error {
+	m.deleted = append(m.deleted, record)
+	return nil
+}
+
+func TestEnsureDNSForceSync(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+			Annotations: map[string]string{
+				forceDNSSyncAnnotation: "",
+				"unrelated":            "value",
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	service := &corev1.Service{}
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.cloudprovider.example.com"}}
+	dnsManager := &fakeDNSManager{}
+	client := newFakeClient(ci)
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", DNSManager: dnsManager},
+		client: client,
+	}
+
+	if err := r.ensureDNS(ci, service, globalConfig); err != nil {
+		t.Fatalf("failed to ensure DNS: %v", err)
+	}
+	if len(dnsManager.forced) != 2 || len(dnsManager.ensured) != 0 {
+		t.Errorf("expected 2 forced and 0 regular ensures, got %d forced and %d regular", len(dnsManager.forced), len(dnsManager.ensured))
+	}
+	if _, ok := ci.Annotations[forceDNSSyncAnnotation]; ok {
+		t.Errorf("expected annotation %s to be removed", forceDNSSyncAnnotation)
+	}
+	if ci.Annotations["unrelated"] != "value" {
+		t.Errorf("expected unrelated annotations to be preserved, got %v", ci.Annotations)
+	}
+
+	// Subsequent syncs are not forced.
+	if err := r.ensureDNS(ci, service, globalConfig); err != nil {
+		t.Fatalf("failed to ensure DNS: %v", err)
+	}
+	if len(dnsManager.forced) != 2 || len(dnsManager.ensured) != 2 {
+		t.Errorf("expected 2 forced and 2 regular ensures, got %d forced and %d regular", len(dnsManager.forced), len(dnsManager.ensured))
+	}
+	if client.calls["update"] != 1 {
+		t.Errorf("expected 1 ingresscontroller update, got %d", client.calls["update"])
+	}
+}
+
+// TestEnsureDNSAliases verifies that DNS aliases are published alongside the
+// wildcard record and that the records of removed aliases are deleted, both
+// when reconciling and when finalizing the load balancer service.
+func TestEnsureDNSAliases(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+			Annotations: map[string]string{
+				dnsAliasesAnnotation: "console.apps.example.com, Downloads.apps.example.com",
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      "router-default",
+		},
+	}
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.cloudprovider.example.com"}}
+	dnsManager := &fakeDNSManager{}
+	client := newFakeClient(ci, service)
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", DNSManager: dnsManager},
+		client: client,
+	}
+	names := func(records []*dns.Record) []string {
+		result := []string{}
+		for _, record := range records {
+			if record.Zone.ID == "public" {
+				result = append(result, record.Alias.Domain)
+			}
+		}
+		return result
+	}
+
+	if err := r.ensureDNS(ci, service, globalConfig); err != nil {
+		t.Fatalf("failed to ensure DNS: %v", err)
+	}
+	expected := []string{"*.apps.example.com", "console.apps.example.com", "downloads.apps.example.com"}
+	if actual := names(dnsManager.ensured); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected records %v to be ensured, got %v", expected, actual)
+	}
+	if v := ci.Annotations[publishedDNSAliasesAnnotation]; v != "console.apps.example.com,downloads.apps.example.com" {
+		t.Errorf("expected both aliases to be recorded as published, got %q", v)
+	}
+
+	// Removing an alias deletes its records.
+	ci.Annotations[dnsAliasesAnnotation] = "console.apps.example.com"
+	if err := client.Update(context.TODO(), ci); err != nil {
+		t.Fatal(err)
+	}
+	dnsManager.ensured = nil
+	if err := r.ensureDNS(ci, service, globalConfig); err != nil {
+		t.Fatalf("failed to ensure DNS: %v", err)
+	}
+	if actual, expected := names(dnsManager.deleted), []string{"downloads.apps.example.com"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected records %v to be deleted, got %v", expected, actual)
+	}
+	if v := ci.Annotations[publishedDNSAliasesAnnotation]; v != "console.apps.example.com" {
+		t.Errorf("expected only the remaining alias to be recorded as published, got %q", v)
+	}
+
+	// Reconciling again makes no changes.
+	updates := client.calls["update"]
+	if err := r.ensureDNS(ci, service, globalConfig); err != nil {
+		t.Fatalf("failed to ensure DNS: %v", err)
+	}
+	if client.calls["update"] != updates || len(dnsManager.deleted) != 2 {
+		t.Errorf("expected no further changes, got %d updates and %d deletions", client.calls["update"]-updates, len(dnsManager.deleted)-2)
+	}
+
+	// Finalizing deletes the wildcard record, the current alias, and an
+	// alias that was removed but whose records were not deleted yet.
+	ci.Annotations[dnsAliasesAnnotation] = ""
+	dnsManager.deleted = nil
+	if _, err := r.finalizeLoadBalancerService(ci, globalConfig); err != nil {
+		t.Fatalf("failed to finalize load balancer service: %v", err)
+	}
+	if actual, expected := names(dnsManager.deleted), []string{"*.apps.example.com", "console.apps.example.com"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected records %v to be deleted, got %v", expected, actual)
+	}
+}
+
+// TestEnsureDNSAAAARecords verifies that AAAA records are published for IPv6
+// load balancer addresses, that they are deleted when the load balancer loses
+// its IPv6 addresses, and that single-stack load balancers are unaffected.
+func TestEnsureDNSAAAARecords(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      "router-default",
+		},
+	}
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}, {IP: "2001:db8::1"}}
+	dnsManager := &fakeDNSManager{}
+	client := newFakeClient(ci, service)
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", DNSManager: dnsManager},
+		client: client,
+	}
+	describe := func(records []*dns.Record) []string {
+		result := []string{}
+		for _, record := range records {
+			if record.Zone.ID != "public" {
+				continue
+			}
+			switch record.Type {
+			case dns.ARecordType:
+				result = append(result, "A "+record.ARecord.String())
+			case dns.AAAARecordType:
+				result = append(result, "AAAA "+record.AAAARecord.String())
+			}
+		}
+		return result
+	}
+
+	if err := r.ensureDNS(ci, service, globalConfig); err != nil {
+		t.Fatalf("failed to ensure DNS: %v", err)
+	}
+	expected := []string{"A *.apps.example.com -> 192.0.2.1", "AAAA *.apps.example.com -> 2001:db8::1"}
+	if actual := describe(dnsManager.ensured); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected records %v to be ensured, got %v", expected, actual)
+	}
+	if _, ok := ci.Annotations[publishedAAAARecordsAnnotation]; !ok {
+		t.Errorf("expected AAAA records to be recorded as published")
+	}
+
+	// Losing the IPv6 address deletes the AAAA records.
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}}
+	dnsManager.ensured = nil
+	if err := r.ensureDNS(ci, service, globalConfig); err != nil {
+		t.Fatalf("failed to ensure DNS: %v", err)
+	}
+	if actual, expected := describe(dnsManager.ensured), []string{"A *.apps.example.com -> 192.0.2.1"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected records %v to be ensured, got %v", expected, actual)
+	}
+	if actual, expected := describe(dnsManager.deleted), []string{"AAAA *.apps.example.com -> "}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected records %v to be deleted, got %v", expected, actual)
+	}
+	if v, ok := ci.Annotations[publishedAAAARecordsAnnotation]; ok {
+		t.Errorf("expected the published AAAA records annotation to be removed, got %q", v)
+	}
+
+	// A single-stack load balancer causes no further deletions or
+	// updates.
+	updates := client.calls["update"]
+	if err := r.ensureDNS(ci, service, globalConfig); err != nil {
+		t.Fatalf("failed to ensure DNS: %v", err)
+	}
+	if client.calls["update"] != updates || len(dnsManager.deleted) != 2 {
+		t.Errorf("expected no further changes, got %d updates and %d deletions", client.calls["update"]-updates, len(dnsManager.deleted)-2)
+	}
+}
+
+// TestComputeDNSZonesConfiguredCondition verifies that the DNSZonesConfigured
+// condition reports which cluster DNS zones the ingresscontroller's records
+// are published in, and is False when there are none.
+func TestComputeDNSZonesConfiguredCondition(t *testing.T) {
+	taggedZone := configv1.DNSZone{Tags: map[string]string{"Name": "example", "Environment": "test"}}
+	testCases := []struct {
+		description string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		annotations map[string]string
+		public      *configv1.DNSZone
+		private     *configv1.DNSZone
+		expect      operatorv1.OperatorCondition
+	}{
+		{
+			description: "both zones",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			public:      &publicZone,
+			private:     &privateZone,
+			expect: operatorv1.OperatorCondition{
+				Type:    "DNSZonesConfigured",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "ZonesConfigured",
+				Message: "DNS records are published in public zone public and private zone private.",
+			},
+		},
+		{
+			description: "private zone only, identified by tags",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			private:     &taggedZone,
+			expect: operatorv1.OperatorCondition{
+				Type:    "DNSZonesConfigured",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "PrivateZoneOnly",
+				Message: "DNS records are published in private zone with tags Environment=test,Name=example only; the cluster DNS config specifies no public zone.",
+			},
+		},
+		{
+			description: "public zone only, private zone empty",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			public:      &publicZone,
+			private:     &configv1.DNSZone{},
+			expect: operatorv1.OperatorCondition{
+				Type:    "DNSZonesConfigured",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "PublicZoneOnly",
+				Message: "DNS records are published in public zone public only; the cluster DNS config specifies no private zone.",
+			},
+		},
+		{
+			description: "no zones",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expect: operatorv1.OperatorCondition{
+				Type:    "DNSZonesConfigured",
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "NoDNSZones",
+				Message: "The cluster DNS config dnses.config.openshift.io/cluster specifies neither a public nor a private zone, so no DNS records are published for the ingresscontroller.",
+			},
+		},
+		{
+			description: "no zones with host network",
+			strategy:    operatorv1.HostNetworkStrategyType,
+			expect: operatorv1.OperatorCondition{
+				Type:    "DNSZonesConfigured",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "DNSNotRequired",
+				Message: "The endpoint publishing strategy does not publish DNS records.",
+			},
+		},
+		{
+			description: "no zones with DNS management paused",
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			annotations: map[string]string{dnsManagementPausedAnnotation: "true"},
+			expect: operatorv1.OperatorCondition{
+				Type:    "DNSZonesConfigured",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "ExternallyManaged",
+				Message: "DNS management is paused by annotation ingress.operator.openshift.io/dns-management-paused, so the operator neither publishes nor deletes the ingresscontroller's DNS records; they must be managed externally.",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: tc.strategy},
+			},
+		}
+		dnsConfig := &configv1.DNS{Spec: configv1.DNSSpec{PublicZone: tc.public, PrivateZone: tc.private}}
+		if actual := computeDNSZonesConfiguredCondition(ic, dnsConfig); !cmp.Equal(actual, tc.expect) {
+			t.Errorf("%q: expected %#v, got %#v", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+// TestDNSManagementPaused verifies that pausing DNS management stops the
+// operator from publishing DNS records, leaves the force DNS sync annotation
+// for when management resumes, and lets the load balancer service be
+// finalized without deleting its records or requiring DNS credentials.
+func TestDNSManagementPaused(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+			Annotations: map[string]string{
+				dnsManagementPausedAnnotation:  "true",
+				forceDNSSyncAnnotation:         "",
+				dnsCredentialsSecretAnnotation: "missing",
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "openshift-ingress",
+			Name:       "router-default",
+			Finalizers: []string{loadBalancerServiceFinalizer},
+		},
+	}
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.cloudprovider.example.com"}}
+	dnsManager := &fakeDNSManager{}
+	client := newFakeClient(ci, service)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress", DNSManager: dnsManager, DNSManagerFactory: (&fakeDNSManagerFactory{}).create},
+		client: client,
+	}
+
+	if err := r.ensureDNS(ci, service, globalConfig); err != nil {
+		t.Fatalf("failed to ensure DNS: %v", err)
+	}
+	if len(dnsManager.ensured) != 0 || len(dnsManager.forced) != 0 {
+		t.Errorf("expected no records to be ensured, got %v and forced %v", dnsManager.ensured, dnsManager.forced)
+	}
+	if _, ok := ci.Annotations[forceDNSSyncAnnotation]; !ok || client.calls["update"] != 0 {
+		t.Errorf("expected the force DNS sync annotation to be kept, got annotations %v and %d updates", ci.Annotations, client.calls["update"])
+	}
+
+	problem, err := r.dnsCredentialsProblem(ci)
+	if err != nil || len(problem) != 0 {
+		t.Errorf("expected no DNS credentials problem, got %q (error: %v)", problem, err)
+	}
+
+	finalized, err := r.finalizeLoadBalancerService(ci, globalConfig)
+	if err != nil || !finalized {
+		t.Fatalf("expected the load balancer service to be finalized, got %t (error: %v)", finalized, err)
+	}
+	if len(dnsManager.deleted) != 0 {
+		t.Errorf("expected no records to be deleted, got %v", dnsManager.deleted)
+	}
+	current := &corev1.Service{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: service.Namespace, Name: service.Name}, current); err != nil {
+		t.Fatal(err)
+	}
+	if len(current.Finalizers) != 0 {
+		t.Errorf("expected the service finalizer to be removed, got %v", current.Finalizers)
+	}
+}
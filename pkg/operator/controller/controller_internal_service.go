@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
@@ -11,36 +12,85 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
 	// Annotation used to inform the certificate generation service to
 	// generate a cluster-signed certificate and populate the secret.
 	ServingCertSecretAnnotation = "service.alpha.openshift.io/serving-cert-secret-name"
+
+	// internalServiceSessionAffinityAnnotation specifies the session
+	// affinity of the ingresscontroller's internal service.  Allowed values
+	// are None and ClientIP.  If unset, the default is None.
+	internalServiceSessionAffinityAnnotation = "ingress.operator.openshift.io/internal-service-session-affinity"
+
+	// internalServiceSessionAffinityTimeoutAnnotation specifies the
+	// ClientIP session affinity timeout, in seconds, of the
+	// ingresscontroller's internal service.  The value must be between 1
+	// and 86400 and may only be set when the session affinity is ClientIP.
+	// If unset, the default is 10800 (3 hours).
+	internalServiceSessionAffinityTimeoutAnnotation = "ingress.operator.openshift.io/internal-service-session-affinity-timeout"
+
+	// internalServiceTopologyAwareHintsAnnotation, when set to "true" on
+	// an ingresscontroller, enables topology-aware hints on the
+	// ingresscontroller's internal service so that clients in the cluster
+	// are preferably routed to router pods in their own zone, which keeps
+	// traffic in-zone and avoids cross-zone charges.  Kubernetes only uses
+	// the hints when the router pods are spread evenly enough across zones;
+	// otherwise traffic is routed to all router pods as usual.  Allowed
+	// values are "true" and "false".  The cluster must support
+	// topology-aware hints.  If unset, hints are not enabled.
+	internalServiceTopologyAwareHintsAnnotation = "ingress.operator.openshift.io/internal-service-topology-aware-hints"
+
+	// topologyAwareHintsAnnotation is the service annotation that enables
+	// topology-aware hints for the service's endpoints.
+	topologyAwareHintsAnnotation = "service.kubernetes.io/topology-aware-hints"
+
+	// TopologyAwareHintsGroupVersion is the API group version of the
+	// endpointslices that carry topology-aware hints.  The cluster supports
+	// topology-aware hints if the API server serves it.
+	TopologyAwareHintsGroupVersion = "discovery.k8s.io/v1"
+
+	// maxClientIPServiceAffinitySeconds is the maximum ClientIP session
+	// affinity timeout allowed by the API.
+	maxClientIPServiceAffinitySeconds = 86400
 )
 
 // ensureInternalRouterServiceForIngress ensures that an internal service exists
 // for a given IngressController.
 func (r *reconciler) ensureInternalIngressControllerService(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) (*corev1.Service, error) {
-	desired := desiredInternalIngressControllerService(ic, deploymentRef)
+	desired := desiredInternalIngressControllerService(ic, r.OperandNamespace, deploymentRef)
+	applyOperandMetadata(desired, r.OperandLabels, r.OperandAnnotations)
 	current, err := r.currentInternalIngressControllerService(ic)
 	if err != nil {
 		return nil, err
 	}
-	if current != nil {
-		return current, nil
+	if current == nil {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return nil, fmt.Errorf("failed to create internal ingresscontroller service: %v", err)
+		}
+		stepLogger(ic, "internal-service").Info("created internal ingresscontroller service", "namespace", desired.Namespace, "name", desired.Name)
+		return desired, nil
 	}
 
-	if err := r.client.Create(context.TODO(), desired); err != nil {
-		return nil, fmt.Errorf("failed to create internal ingresscontroller service: %v", err)
+	changed, updated := internalServiceChanged(current, desired)
+	if !changed {
+		updated = current.DeepCopy()
 	}
-	log.Info("created internal ingresscontroller service", "service", desired)
-	return desired, nil
+	if metadataChanged := updateOperandMetadata(updated, desired, r.OperandLabels, r.OperandAnnotations); changed || metadataChanged {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return nil, fmt.Errorf("failed to update internal ingresscontroller service %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		stepLogger(ic, "internal-service").Info("updated internal ingresscontroller service", "namespace", updated.Namespace, "name", updated.Name)
+		return updated, nil
+	}
+	return current, nil
 }
 
 func (r *reconciler) currentInternalIngressControllerService(ic *operatorv1.IngressController) (*corev1.Service, error) {
 	current := &corev1.Service{}
-	err := r.client.Get(context.TODO(), InternalIngressControllerServiceName(ic), current)
+	err := r.client.Get(context.TODO(), InternalIngressControllerServiceName(ic, r.OperandNamespace), current)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return nil, nil
@@ -50,10 +100,10 @@ func (r *reconciler) currentInternalIngressControllerService(ic *operatorv1.Ingr
 	return current, nil
 }
 
-func desiredInternalIngressControllerService(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) *corev1.Service {
+func desiredInternalIngressControllerService(ic *operatorv1.IngressController, namespace string, deploymentRef metav1.OwnerReference) *corev1.Service {
 	s := manifests.InternalIngressControllerService()
 
-	name := InternalIngressControllerServiceName(ic)
+	name := InternalIngressControllerServiceName(ic, namespace)
 
 	s.Namespace = name.Namespace
 	s.Name = name.Name
@@ -67,9 +117,116 @@ func desiredInternalIngressControllerService(ic *operatorv1.IngressController, d
 		ServingCertSecretAnnotation: fmt.Sprintf("router-metrics-certs-%s", ic.Name),
 	}
 
+	if ic.Annotations[internalServiceTopologyAwareHintsAnnotation] == "true" {
+		s.Annotations[topologyAwareHintsAnnotation] = "Auto"
+	}
+
 	s.Spec.Selector = IngressControllerDeploymentPodSelector(ic).MatchLabels
 
+	s.Spec.SessionAffinity = corev1.ServiceAffinityNone
+	if affinity, ok := ic.Annotations[internalServiceSessionAffinityAnnotation]; ok && corev1.ServiceAffinity(affinity) == corev1.ServiceAffinityClientIP {
+		timeout := corev1.DefaultClientIPServiceAffinitySeconds
+		if v, ok := ic.Annotations[internalServiceSessionAffinityTimeoutAnnotation]; ok {
+			if seconds, err := strconv.ParseInt(v, 10, 32); err == nil {
+				timeout = int32(seconds)
+			}
+		}
+		s.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
+		s.Spec.SessionAffinityConfig = &corev1.SessionAffinityConfig{
+			ClientIP: &corev1.ClientIPConfig{
+				TimeoutSeconds: &timeout,
+			},
+		}
+	}
+
+	// The load balancer service has the external IPs if there is one.
+	if !usesLoadBalancer(ic) {
+		applyServiceExternalIPs(ic, s)
+	}
+
 	s.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
 
 	return s
 }
+
+// internalServiceChanged checks if the current internal service spec matches
+// the expected spec and if not returns an updated one.  The ports are compared
+// so that a change to the router's ports is reflected in the service; the
+// servicemonitor refers to the metrics port by name, so it does not need to be
+// updated as long as the port keeps its name.  Of the annotations, only the
+// topology-aware hints annotation is compared; others may be set by other
+// controllers.
+func internalServiceChanged(current, expected *corev1.Service) (bool, *corev1.Service) {
+	if effectiveSessionAffinity(current) == effectiveSessionAffinity(expected) &&
+		effectiveSessionAffinityTimeout(current) == effectiveSessionAffinityTimeout(expected) &&
+		servicePortsEqual(current.Spec.Ports, expected.Spec.Ports) &&
+		serviceExternalIPsEqual(current, expected) &&
+		current.Annotations[topologyAwareHintsAnnotation] == expected.Annotations[topologyAwareHintsAnnotation] {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Spec.SessionAffinity = expected.Spec.SessionAffinity
+	updated.Spec.SessionAffinityConfig = expected.Spec.SessionAffinityConfig
+	updated.Spec.Ports = expected.Spec.Ports
+	updated.Spec.ExternalIPs = expected.Spec.ExternalIPs
+	if v, ok := expected.Annotations[topologyAwareHintsAnnotation]; ok {
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[topologyAwareHintsAnnotation] = v
+	} else {
+		delete(updated.Annotations, topologyAwareHintsAnnotation)
+	}
+	return true, updated
+}
+
+// servicePortsEqual returns a Boolean indicating whether the given service
+// ports are the same, taking into account the API defaults.
+func servicePortsEqual(a, b []corev1.ServicePort) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if effectiveServicePort(a[i]) != effectiveServicePort(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// effectiveServicePort returns the given service port with the protocol and
+// target port that the API defaults when they are unset.  The node port is
+// cleared because the API allocates it.
+func effectiveServicePort(port corev1.ServicePort) corev1.ServicePort {
+	if len(port.Protocol) == 0 {
+		port.Protocol = corev1.ProtocolTCP
+	}
+	if port.TargetPort == (intstr.IntOrString{}) {
+		port.TargetPort = intstr.FromInt(int(port.Port))
+	}
+	port.NodePort = 0
+	return port
+}
+
+// effectiveSessionAffinity returns the given service's session affinity,
+// taking into account the API default of None.
+func effectiveSessionAffinity(service *corev1.Service) corev1.ServiceAffinity {
+	if len(service.Spec.SessionAffinity) == 0 {
+		return corev1.ServiceAffinityNone
+	}
+	return service.Spec.SessionAffinity
+}
+
+// effectiveSessionAffinityTimeout returns the given service's ClientIP session
+// affinity timeout, taking into account the API default, or 0 if the service
+// does not use ClientIP session affinity.
+func effectiveSessionAffinityTimeout(service *corev1.Service) int32 {
+	if effectiveSessionAffinity(service) != corev1.ServiceAffinityClientIP {
+		return 0
+	}
+	if config := service.Spec.SessionAffinityConfig; config != nil && config.ClientIP != nil && config.ClientIP.TimeoutSeconds != nil {
+		return *config.ClientIP.TimeoutSeconds
+	}
+	return corev1.DefaultClientIPServiceAffinitySeconds
+}
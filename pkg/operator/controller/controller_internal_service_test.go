@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestInternalServiceSessionAffinityToggle(t *testing.T) {
+	trueVar := true
+	deploymentRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       "router-default",
+		UID:        "1",
+		Controller: &trueVar,
+	}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	current := desiredInternalIngressControllerService(ic, "openshift-ingress", deploymentRef)
+	if current.Spec.SessionAffinity != corev1.ServiceAffinityNone {
+		t.Fatalf("expected default session affinity None, got %q", current.Spec.SessionAffinity)
+	}
+
+	// Simulate the API server clearing the defaulted field; this must not
+	// be considered a change.
+	current.Spec.SessionAffinity = ""
+	if changed, _ := internalServiceChanged(current, desiredInternalIngressControllerService(ic, "openshift-ingress", deploymentRef)); changed {
+		t.Fatal("expected no change for defaulted session affinity")
+	}
+
+	// Enable ClientIP affinity.
+	ic.Annotations = map[string]string{internalServiceSessionAffinityAnnotation: "ClientIP"}
+	changed, updated := internalServiceChanged(current, desiredInternalIngressControllerService(ic, "openshift-ingress", deploymentRef))
+	if !changed {
+		t.Fatal("expected enabling ClientIP session affinity to update the service")
+	}
+	if updated.Spec.SessionAffinity != corev1.ServiceAffinityClientIP {
+		t.Errorf("expected session affinity ClientIP, got %q", updated.Spec.SessionAffinity)
+	}
+	if timeout := effectiveSessionAffinityTimeout(updated); timeout != corev1.DefaultClientIPServiceAffinitySeconds {
+		t.Errorf("expected default timeout %d, got %d", corev1.DefaultClientIPServiceAffinitySeconds, timeout)
+	}
+	if changedAgain, _ := internalServiceChanged(updated, desiredInternalIngressControllerService(ic, "openshift-ingress", deploymentRef)); changedAgain {
+		t.Error("internalServiceChanged does not behave as a fixed point function")
+	}
+	current = updated
+
+	// Change the timeout.
+	ic.Annotations[internalServiceSessionAffinityTimeoutAnnotation] = "60"
+	changed, updated = internalServiceChanged(current, desiredInternalIngressControllerService(ic, "openshift-ingress", deploymentRef))
+	if !changed {
+		t.Fatal("expected changing the session affinity timeout to update the service")
+	}
+	if timeout := effectiveSessionAffinityTimeout(updated); timeout != 60 {
+		t.Errorf("expected timeout 60, got %d", timeout)
+	}
+	current = updated
+
+	// Disable affinity again.
+	ic.Annotations = nil
+	changed, updated = internalServiceChanged(current, desiredInternalIngressControllerService(ic, "openshift-ingress", deploymentRef))
+	if !changed {
+		t.Fatal("expected removing session affinity to update the service")
+	}
+	if updated.Spec.SessionAffinity != corev1.ServiceAffinityNone || updated.Spec.SessionAffinityConfig != nil {
+		t.Errorf("expected session affinity None with no config, got %q, %#v", updated.Spec.SessionAffinity, updated.Spec.SessionAffinityConfig)
+	}
+}
+
+// TestEnsureInternalServicePorts verifies that the internal service's ports
+// are updated when they differ from the router's ports, and that ports that
+// differ only in API defaults are not considered a change.
+func TestEnsureInternalServicePorts(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+	deploymentRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "router-default", UID: "1"}
+	client := newFakeClient()
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress"},
+		client: client,
+	}
+	svc, err := r.ensureInternalIngressControllerService(ic, deploymentRef)
+	if err != nil {
+		t.Fatalf("failed to create internal service: %v", err)
+	}
+	expectPorts := svc.Spec.Ports
+
+	// Simulate the API server defaulting the ports, which must not be
+	// considered a change.
+	defaulted := svc.DeepCopy()
+	for i := range defaulted.Spec.Ports {
+		defaulted.Spec.Ports[i].Protocol = ""
+		if defaulted.Spec.Ports[i].TargetPort == intstr.FromInt(int(defaulted.Spec.Ports[i].Port)) {
+			defaulted.Spec.Ports[i].TargetPort = intstr.IntOrString{}
+		}
+	}
+	if err := client.replace(defaulted); err != nil {
+		t.Fatal(err)
+	}
+	updates := client.calls["update"]
+	if _, err := r.ensureInternalIngressControllerService(ic, deploymentRef); err != nil {
+		t.Fatalf("failed to reconcile internal service: %v", err)
+	}
+	if client.calls["update"] != updates {
+		t.Errorf("expected no update for defaulted ports, got %d", client.calls["update"]-updates)
+	}
+
+	// Simulate a stale metrics port.
+	stale := svc.DeepCopy()
+	for i := range stale.Spec.Ports {
+		if stale.Spec.Ports[i].Name == "metrics" {
+			stale.Spec.Ports[i].Port = 1937
+			stale.Spec.Ports[i].TargetPort = intstr.FromInt(1937)
+		}
+	}
+	if err := client.replace(stale); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := r.ensureInternalIngressControllerService(ic, deploymentRef)
+	if err != nil {
+		t.Fatalf("failed to reconcile internal service: %v", err)
+	}
+	if !servicePortsEqual(updated.Spec.Ports, expectPorts) {
+		t.Errorf("expected ports %+v, got %+v", expectPorts, updated.Spec.Ports)
+	}
+	current, err := r.currentInternalIngressControllerService(ic)
+	if err != nil || current == nil {
+		t.Fatalf("expected internal service to exist, got %v, %v", current, err)
+	}
+	if !servicePortsEqual(current.Spec.Ports, expectPorts) {
+		t.Errorf("expected the stored service to have ports %+v, got %+v", expectPorts, current.Spec.Ports)
+	}
+}
+
+// TestInternalServiceTopologyAwareHintsToggle verifies that enabling and
+// disabling topology-aware hints updates the internal service's annotation
+// without disturbing annotations that other controllers set.
+func TestInternalServiceTopologyAwareHintsToggle(t *testing.T) {
+	deploymentRef := metav1.OwnerReference{Name: "router-default"}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+
+	current := desiredInternalIngressControllerService(ic, "openshift-ingress", deploymentRef)
+	if v, ok := current.Annotations[topologyAwareHintsAnnotation]; ok {
+		t.Fatalf("expected no %s annotation by default, got %q", topologyAwareHintsAnnotation, v)
+	}
+	current.Annotations["service.beta.openshift.io/serving-cert-signed-by"] = "openshift-service-serving-signer"
+
+	ic.Annotations = map[string]string{internalServiceTopologyAwareHintsAnnotation: "true"}
+	changed, updated := internalServiceChanged(current, desiredInternalIngressControllerService(ic, "openshift-ingress", deploymentRef))
+	if !changed {
+		t.Fatal("expected enabling topology-aware hints to update the service")
+	}
+	if v := updated.Annotations[topologyAwareHintsAnnotation]; v != "Auto" {
+		t.Errorf("expected annotation %s=Auto, got %q", topologyAwareHintsAnnotation, v)
+	}
+	if changedAgain, _ := internalServiceChanged(updated, desiredInternalIngressControllerService(ic, "openshift-ingress", deploymentRef)); changedAgain {
+		t.Error("internalServiceChanged does not behave as a fixed point function")
+	}
+	current = updated
+
+	ic.Annotations[internalServiceTopologyAwareHintsAnnotation] = "false"
+	changed, updated = internalServiceChanged(current, desiredInternalIngressControllerService(ic, "openshift-ingress", deploymentRef))
+	if !changed {
+		t.Fatal("expected disabling topology-aware hints to update the service")
+	}
+	if v, ok := updated.Annotations[topologyAwareHintsAnnotation]; ok {
+		t.Errorf("expected annotation %s to be removed, got %q", topologyAwareHintsAnnotation, v)
+	}
+	if v := updated.Annotations["service.beta.openshift.io/serving-cert-signed-by"]; v != "openshift-service-serving-signer" {
+		t.Errorf("expected other annotations to be kept, got %v", updated.Annotations)
+	}
+}
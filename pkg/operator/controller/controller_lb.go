@@ -3,6 +3,8 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
@@ -25,28 +27,108 @@ const (
 	// awsLBProxyProtocolAnnotation is used to enable the PROXY protocol on any
 	// AWS load balancer services created.
 	awsLBProxyProtocolAnnotation = "service.beta.kubernetes.io/aws-load-balancer-proxy-protocol"
+
+	// loadBalancerHealthCheckNodePortAnnotation specifies the health check
+	// node port of the ingresscontroller's load balancer service.  The
+	// value must be in the default NodePort range.  If unset, the port is
+	// assigned automatically.  The API does not allow the port of an
+	// existing service to be changed.
+	loadBalancerHealthCheckNodePortAnnotation = "ingress.operator.openshift.io/load-balancer-health-check-node-port"
+
+	// dnsCleanupTimeoutAnnotation specifies how long after the
+	// ingresscontroller is marked for deletion the operator keeps retrying
+	// to delete the DNS records of its load balancer before it deletes the
+	// load balancer anyway, as a duration such as "5m".  The load balancer
+	// is deleted only after its DNS records so that the records never
+	// resolve to the address of a deleted load balancer.  Once the timeout
+	// expires, a failure to delete the records no longer holds back the
+	// deletion of the load balancer and the other operands; it is reported
+	// with a warning event that names the records, which must then be
+	// deleted manually.  A value of "0s" deletes the load balancer right
+	// after the first failed attempt.  If unset, defaultDNSCleanupTimeout
+	// is used.
+	dnsCleanupTimeoutAnnotation = "ingress.operator.openshift.io/dns-cleanup-timeout"
+
+	// defaultDNSCleanupTimeout is the default DNS cleanup timeout.
+	defaultDNSCleanupTimeout = 5 * time.Minute
+
+	// minNodePort and maxNodePort bound the default NodePort range.
+	minNodePort = 30000
+	maxNodePort = 32767
 )
 
-// ensureLoadBalancerService creates an LB service if one is desired but absent.
-// Always returns the current LB service if one exists (whether it already
-// existed or was created during the course of the function).
+// ensureLoadBalancerService creates an LB service if one is desired but absent
+// and updates its external IPs and the configured extra operand labels and
+// annotations on it if they have drifted.  Always returns the current LB service if one exists (whether it already
+// existed or was created during the course of the function), except that a
+// service that was deleted out of band is finalized and nil is returned, so
+// that the service is recreated once its deletion completes, and likewise a
+// service whose load balancer IP differs from the desired one is deleted and
+// nil is returned.
 func (r *reconciler) ensureLoadBalancerService(ci *operatorv1.IngressController, deploymentRef metav1.OwnerReference, infraConfig *configv1.Infrastructure) (*corev1.Service, error) {
-	desiredLBService, err := desiredLoadBalancerService(ci, deploymentRef, infraConfig)
+	desiredLBService, err := desiredLoadBalancerService(ci, r.OperandNamespace, deploymentRef, infraConfig)
 	if err != nil {
 		return nil, err
 	}
+	if desiredLBService != nil {
+		applyOperandMetadata(desiredLBService, r.OperandLabels, r.OperandAnnotations)
+	}
 
 	currentLBService, err := r.currentLoadBalancerService(ci)
 	if err != nil {
 		return nil, err
 	}
+	if currentLBService != nil && currentLBService.DeletionTimestamp != nil {
+		// The service was deleted out of band.  Its finalizer would keep
+		// it terminating until the ingresscontroller is deleted, so remove
+		// the finalizer to let the deletion complete; the service's delete
+		// event requeues the ingresscontroller, and the service is then
+		// recreated.  The DNS records are left in place and are updated
+		// once the new load balancer is provisioned.
+		if slice.ContainsString(currentLBService.Finalizers, loadBalancerServiceFinalizer) {
+			updated := currentLBService.DeepCopy()
+			updated.Finalizers = slice.RemoveString(updated.Finalizers, loadBalancerServiceFinalizer)
+			if err := r.client.Update(context.TODO(), updated); err != nil {
+				return nil, fmt.Errorf("failed to remove finalizer from deleted load balancer service %s/%s: %v", updated.Namespace, updated.Name, err)
+			}
+			stepLogger(ci, "load-balancer").Info("removed finalizer from deleted load balancer service so that it can be recreated", "namespace", updated.Namespace, "name", updated.Name)
+		}
+		return nil, nil
+	}
 	if desiredLBService != nil && currentLBService == nil {
 		if err := r.client.Create(context.TODO(), desiredLBService); err != nil {
 			return nil, fmt.Errorf("failed to create load balancer service %s/%s: %v", desiredLBService.Namespace, desiredLBService.Name, err)
 		}
-		log.Info("created load balancer service", "namespace", desiredLBService.Namespace, "name", desiredLBService.Name)
+		stepLogger(ci, "load-balancer").Info("created load balancer service", "namespace", desiredLBService.Namespace, "name", desiredLBService.Name)
 		return desiredLBService, nil
 	}
+	if desiredLBService != nil && currentLBService != nil {
+		if loadBalancerIPChanged(currentLBService, desiredLBService) {
+			if err := r.deleteLoadBalancerServiceForRecreate(ci, currentLBService, desiredLBService); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		// The API rejects changes to the health check node port of an
+		// existing service, and recreating the service would replace the
+		// load balancer, so only report the mismatch.
+		if desired, current := desiredLBService.Spec.HealthCheckNodePort, currentLBService.Spec.HealthCheckNodePort; desired != 0 && desired != current {
+			stepLogger(ci, "load-balancer").Info("load balancer service health check node port does not match the ingresscontroller; the service must be recreated to change it", "namespace", currentLBService.Namespace, "name", currentLBService.Name, "current", current, "desired", desired)
+			r.recorder.Eventf(ci, "Warning", "HealthCheckNodePortMismatch", "The load balancer service has health check node port %d, but %d is requested; delete the service to apply the requested port", current, desired)
+		}
+		updated := currentLBService.DeepCopy()
+		externalIPsChanged := !serviceExternalIPsEqual(currentLBService, desiredLBService)
+		if externalIPsChanged {
+			updated.Spec.ExternalIPs = desiredLBService.Spec.ExternalIPs
+		}
+		if metadataChanged := updateOperandMetadata(updated, desiredLBService, r.OperandLabels, r.OperandAnnotations); externalIPsChanged || metadataChanged {
+			if err := r.client.Update(context.TODO(), updated); err != nil {
+				return nil, fmt.Errorf("failed to update load balancer service %s/%s: %v", updated.Namespace, updated.Name, err)
+			}
+			stepLogger(ci, "load-balancer").Info("updated load balancer service", "namespace", updated.Namespace, "name", updated.Name)
+			return updated, nil
+		}
+	}
 	return currentLBService, nil
 }
 
@@ -54,13 +136,13 @@ func (r *reconciler) ensureLoadBalancerService(ci *operatorv1.IngressController,
 // ingresscontroller, or nil if an LB service isn't desired. An LB service is
 // desired if the high availability type is Cloud. An LB service will declare an
 // owner reference to the given deployment.
-func desiredLoadBalancerService(ci *operatorv1.IngressController, deploymentRef metav1.OwnerReference, infraConfig *configv1.Infrastructure) (*corev1.Service, error) {
+func desiredLoadBalancerService(ci *operatorv1.IngressController, namespace string, deploymentRef metav1.OwnerReference, infraConfig *configv1.Infrastructure) (*corev1.Service, error) {
 	if ci.Status.EndpointPublishingStrategy.Type != operatorv1.LoadBalancerServiceStrategyType {
 		return nil, nil
 	}
 	service := manifests.LoadBalancerService()
 
-	name := LoadBalancerServiceName(ci)
+	name := LoadBalancerServiceName(ci, namespace)
 
 	service.Namespace = name.Namespace
 	service.Name = name.Name
@@ -79,6 +161,26 @@ func desiredLoadBalancerService(ci *operatorv1.IngressController, deploymentRef
 		}
 		service.Annotations[awsLBProxyProtocolAnnotation] = "*"
 	}
+	applyServiceExternalIPs(ci, service)
+	applyLoadBalancerIP(ci, service, infraConfig.Status.Platform)
+	if v, ok := ci.Annotations[loadBalancerHealthCheckNodePortAnnotation]; ok {
+		if port, err := strconv.ParseInt(v, 10, 32); err == nil {
+			service.Spec.HealthCheckNodePort = int32(port)
+		}
+	}
+	// The service does not specify a load balancer class: the Kubernetes API
+	// that the operator is built against predates spec.loadBalancerClass, so
+	// the load balancer is always provisioned by the cluster's default
+	// implementation.  Because the field is immutable, supporting it will
+	// require recreating the service, and thus the load balancer, when the
+	// class changes.
+	//
+	// For the same reason, node ports are always allocated for the service:
+	// spec.allocateLoadBalancerNodePorts is also newer than the API that the
+	// operator is built against, and updating the service through that API
+	// would drop the field.  The field is mutable, but turning allocation
+	// off does not release the node ports that the service already has, so
+	// supporting it will also require clearing the ports' nodePort values.
 	service.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
 	service.Finalizers = []string{loadBalancerServiceFinalizer}
 	return service, nil
@@ -88,7 +190,7 @@ func desiredLoadBalancerService(ci *operatorv1.IngressController, deploymentRef
 // ingresscontroller.
 func (r *reconciler) currentLoadBalancerService(ci *operatorv1.IngressController) (*corev1.Service, error) {
 	service := &corev1.Service{}
-	if err := r.client.Get(context.TODO(), LoadBalancerServiceName(ci), service); err != nil {
+	if err := r.client.Get(context.TODO(), LoadBalancerServiceName(ci, r.OperandNamespace), service); err != nil {
 		if errors.IsNotFound(err) {
 			return nil, nil
 		}
@@ -98,14 +200,63 @@ func (r *reconciler) currentLoadBalancerService(ci *operatorv1.IngressController
 }
 
 // finalizeLoadBalancerService deletes any DNS entries associated with any
-// current LB service associated with the ingresscontroller and then finalizes the
-// service.
-func (r *reconciler) finalizeLoadBalancerService(ci *operatorv1.IngressController, dnsConfig *configv1.DNS) error {
+// current LB service associated with the ingresscontroller and then finalizes
+// the service and, if the ingresscontroller is being deleted, deletes the
+// service, which deletes the load balancer.  If the DNS records cannot be
+// deleted, the service is kept until the DNS cleanup timeout expires; after
+// that, the service is finalized anyway and the DNS error is returned along
+// with any other error.  The returned Boolean indicates whether the service
+// was finalized, so that operands that must outlive the load balancer can be
+// deleted.
+func (r *reconciler) finalizeLoadBalancerService(ci *operatorv1.IngressController, dnsConfig *configv1.DNS) (bool, error) {
 	service, err := r.currentLoadBalancerService(ci)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if service == nil {
+		return true, nil
+	}
+	errs := []error{}
+	if err := r.deleteLoadBalancerDNSRecords(ci, dnsConfig, service); err != nil {
+		if !dnsCleanupTimeoutExpired(ci, time.Now()) {
+			return false, err
+		}
+		timeout := dnsCleanupTimeout(ci)
+		stepLogger(ci, "deletion").Info("DNS records were not deleted within the DNS cleanup timeout; deleting the load balancer anyway", "timeout", timeout, "error", err)
+		if r.recorder != nil {
+			r.recorder.Eventf(ci, "Warning", "DNSRecordCleanupFailed", "The DNS records of load balancer service %s/%s were not deleted within %s of deletion, so the load balancer is being deleted anyway; the records may resolve to a deleted load balancer and must be deleted manually: %v", service.Namespace, service.Name, timeout, err)
+		}
+		errs = append(errs, err)
+	}
+	// Mutate a copy to avoid assuming we know where the current one came from
+	// (i.e. it could have been from a cache).
+	updated := service.DeepCopy()
+	if slice.ContainsString(updated.Finalizers, loadBalancerServiceFinalizer) {
+		updated.Finalizers = slice.RemoveString(updated.Finalizers, loadBalancerServiceFinalizer)
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove finalizer from service %s for ingress %s/%s: %v", service.Namespace, service.Name, ci.Name, err))
+			return false, utilerrors.NewAggregate(errs)
+		}
+	}
+	// The service would be garbage-collected along with the router
+	// deployment, but delete it explicitly so that the load balancer is
+	// deleted after its DNS records and before the router pods.
+	if ci.DeletionTimestamp != nil {
+		if err := r.client.Delete(context.TODO(), updated); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete service %s/%s for ingress %s: %v", service.Namespace, service.Name, ci.Name, err))
+		} else {
+			stepLogger(ci, "deletion").Info("deleted load balancer service for ingress", "namespace", service.Namespace, "name", service.Name)
+		}
+	}
+	return true, utilerrors.NewAggregate(errs)
+}
+
+// deleteLoadBalancerDNSRecords deletes the DNS records that the given
+// ingresscontroller published for the given load balancer service, unless DNS
+// management is paused.
+func (r *reconciler) deleteLoadBalancerDNSRecords(ci *operatorv1.IngressController, dnsConfig *configv1.DNS, service *corev1.Service) error {
+	if dnsManagementPaused(ci) {
+		stepLogger(ci, "deletion").Info("DNS management is paused; not deleting DNS records")
 		return nil
 	}
 	// We cannot published DNS records for a load balancer till it has been
@@ -119,25 +270,51 @@ func (r *reconciler) finalizeLoadBalancerService(ci *operatorv1.IngressControlle
 	// that we have created for the ingresscontroller, for example by using
 	// an annotation on the ingresscontroller.
 	records := desiredDNSRecords(ci, dnsConfig, service)
+	// Also delete the records of aliases that were published but have
+	// since been removed from the ingresscontroller.
+	records = append(records, dnsRecords(staleDNSAliases(ci), dnsConfig, service)...)
+	// Likewise delete AAAA records that were published for IPv6 addresses
+	// that the load balancer no longer has.
+	records = append(records, staleAAAARecords(ci, dnsConfig, service)...)
+	// The records must be deleted with the credentials with which they
+	// were published, so the service keeps its finalizer until the
+	// ingresscontroller's DNS credentials can be used or the DNS cleanup
+	// timeout expires.
+	if err := r.ensureDNSProxy(); err != nil {
+		return err
+	}
+	manager, err := r.dnsManager(ci)
+	if err != nil {
+		return err
+	}
 	dnsErrors := []error{}
 	for _, record := range records {
-		if err := r.DNSManager.Delete(record); err != nil {
+		if err := manager.Delete(record); err != nil {
 			dnsErrors = append(dnsErrors, fmt.Errorf("failed to delete DNS record %v for ingress %s/%s: %v", record, ci.Namespace, ci.Name, err))
 		} else {
-			log.Info("deleted DNS record for ingress", "namespace", ci.Namespace, "name", ci.Name, "record", record)
+			stepLogger(ci, "deletion").Info("deleted DNS record for ingress", "record", record)
 		}
 	}
-	if err := utilerrors.NewAggregate(dnsErrors); err != nil {
-		return err
-	}
-	// Mutate a copy to avoid assuming we know where the current one came from
-	// (i.e. it could have been from a cache).
-	updated := service.DeepCopy()
-	if slice.ContainsString(updated.Finalizers, loadBalancerServiceFinalizer) {
-		updated.Finalizers = slice.RemoveString(updated.Finalizers, loadBalancerServiceFinalizer)
-		if err := r.client.Update(context.TODO(), updated); err != nil {
-			return fmt.Errorf("failed to remove finalizer from service %s for ingress %s/%s: %v", service.Namespace, service.Name, ci.Name, err)
+	return utilerrors.NewAggregate(dnsErrors)
+}
+
+// dnsCleanupTimeout returns the DNS cleanup timeout of the given
+// ingresscontroller.
+func dnsCleanupTimeout(ci *operatorv1.IngressController) time.Duration {
+	if v, ok := ci.Annotations[dnsCleanupTimeoutAnnotation]; ok {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
 		}
 	}
-	return nil
+	return defaultDNSCleanupTimeout
+}
+
+// dnsCleanupTimeoutExpired returns a Boolean indicating whether the given
+// ingresscontroller has been marked for deletion for at least its DNS cleanup
+// timeout at the given time.
+func dnsCleanupTimeoutExpired(ci *operatorv1.IngressController, now time.Time) bool {
+	if ci.DeletionTimestamp == nil {
+		return false
+	}
+	return now.Sub(ci.DeletionTimestamp.Time) >= dnsCleanupTimeout(ci)
 }
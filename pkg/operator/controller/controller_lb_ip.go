@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// loadBalancerIPAnnotation specifies the IP address, such as a static
+	// address reserved with the cloud provider, that the load balancer of
+	// the ingresscontroller uses.  It sets spec.loadBalancerIP on the load
+	// balancer service and, on Azure, whose cloud provider prefers it, the
+	// equivalent azureLoadBalancerIPv4Annotation or
+	// azureLoadBalancerIPv6Annotation.  The address must be reserved in the
+	// cluster's region; the AWS cloud provider ignores it.  The operator
+	// recreates the load balancer service, and thus the load balancer,
+	// when the annotation is set, changed, or removed, so the
+	// ingresscontroller is unreachable until the new load balancer is
+	// provisioned.  The annotation may only be set when the endpoint
+	// publishing strategy is LoadBalancerService.  If unset, the cloud
+	// provider assigns the address.
+	loadBalancerIPAnnotation = "ingress.operator.openshift.io/load-balancer-ip"
+
+	// azureLoadBalancerIPv4Annotation and azureLoadBalancerIPv6Annotation
+	// are the service annotations with which the Azure cloud provider
+	// takes the address of a load balancer, in place of the deprecated
+	// spec.loadBalancerIP.
+	azureLoadBalancerIPv4Annotation = "service.beta.kubernetes.io/azure-load-balancer-ipv4"
+	azureLoadBalancerIPv6Annotation = "service.beta.kubernetes.io/azure-load-balancer-ipv6"
+
+	// IngressControllerLoadBalancerIPConditionType is the type of the
+	// informational ingresscontroller condition that reports whether the
+	// load balancer has the address that the ingresscontroller requests.
+	IngressControllerLoadBalancerIPConditionType = "LoadBalancerIPAssigned"
+)
+
+// validateLoadBalancerIP verifies that the load balancer IP annotation, if
+// set, is an IP address and is only set with the LoadBalancerService endpoint
+// publishing strategy.
+func validateLoadBalancerIP(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[loadBalancerIPAnnotation]
+	if !ok {
+		return nil
+	}
+	if !usesLoadBalancer(ic) {
+		return fmt.Errorf("annotation %s may only be set when the endpoint publishing strategy is %s", loadBalancerIPAnnotation, operatorv1.LoadBalancerServiceStrategyType)
+	}
+	if net.ParseIP(v) == nil {
+		return fmt.Errorf("invalid value for annotation %s: %q is not an IP address", loadBalancerIPAnnotation, v)
+	}
+	return nil
+}
+
+// applyLoadBalancerIP sets the load balancer IP that the given
+// ingresscontroller requests on the given load balancer service, both in the
+// spec and in the annotation that the given platform's cloud provider
+// prefers, if any.
+func applyLoadBalancerIP(ic *operatorv1.IngressController, service *corev1.Service, platform configv1.PlatformType) {
+	v, ok := ic.Annotations[loadBalancerIPAnnotation]
+	if !ok {
+		return
+	}
+	ip := net.ParseIP(v)
+	if ip == nil {
+		return
+	}
+	service.Spec.LoadBalancerIP = ip.String()
+	if platform == configv1.AzurePlatformType {
+		if service.Annotations == nil {
+			service.Annotations = map[string]string{}
+		}
+		if ip.To4() != nil {
+			service.Annotations[azureLoadBalancerIPv4Annotation] = ip.String()
+		} else {
+			service.Annotations[azureLoadBalancerIPv6Annotation] = ip.String()
+		}
+	}
+}
+
+// loadBalancerIPChanged returns a Boolean indicating whether the load balancer
+// IP of the current load balancer service, in its spec or in a cloud provider
+// annotation, differs from that of the desired service.
+func loadBalancerIPChanged(current, desired *corev1.Service) bool {
+	if current.Spec.LoadBalancerIP != desired.Spec.LoadBalancerIP {
+		return true
+	}
+	for _, annotation := range []string{azureLoadBalancerIPv4Annotation, azureLoadBalancerIPv6Annotation} {
+		if current.Annotations[annotation] != desired.Annotations[annotation] {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteLoadBalancerServiceForRecreate deletes the given load balancer service
+// so that it is recreated with the desired load balancer IP: cloud providers
+// only apply the address when they provision the load balancer.  The finalizer
+// is removed first so that the deletion completes without deleting the DNS
+// records, which are updated once the new load balancer is provisioned.  The
+// service's delete event requeues the ingresscontroller, which then creates
+// the new service.
+func (r *reconciler) deleteLoadBalancerServiceForRecreate(ci *operatorv1.IngressController, current, desired *corev1.Service) error {
+	stepLogger(ci, "load-balancer").Info("recreating load balancer service to change its load balancer IP; this replaces the load balancer", "namespace", current.Namespace, "name", current.Name, "currentIP", current.Spec.LoadBalancerIP, "desiredIP", desired.Spec.LoadBalancerIP)
+	if r.recorder != nil {
+		r.recorder.Eventf(ci, "Normal", "RecreatingLoadBalancerService", "Recreating load balancer service %s/%s to change its load balancer IP; the ingresscontroller is unreachable until the new load balancer is provisioned", current.Namespace, current.Name)
+	}
+	if slice.ContainsString(current.Finalizers, loadBalancerServiceFinalizer) {
+		updated := current.DeepCopy()
+		updated.Finalizers = slice.RemoveString(updated.Finalizers, loadBalancerServiceFinalizer)
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to remove finalizer from load balancer service %s/%s for recreation: %v", updated.Namespace, updated.Name, err)
+		}
+		current = updated
+	}
+	if err := r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete load balancer service %s/%s for recreation: %v", current.Namespace, current.Name, err)
+	}
+	return nil
+}
+
+// computeLoadBalancerIPCondition computes the LoadBalancerIPAssigned condition
+// of the given ingresscontroller from its load balancer service.  The message
+// lists the addresses of the load balancer, which are the addresses that the
+// ingresscontroller's DNS records point to.
+func computeLoadBalancerIPCondition(ic *operatorv1.IngressController, service *corev1.Service) operatorv1.OperatorCondition {
+	requested, ok := ic.Annotations[loadBalancerIPAnnotation]
+	if !ok {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerLoadBalancerIPConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "NotRequested",
+			Message: "The ingresscontroller does not request a load balancer IP address.",
+		}
+	}
+	if ip := net.ParseIP(requested); ip != nil {
+		requested = ip.String()
+	}
+	addresses := []string{}
+	if service != nil {
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if len(ingress.IP) != 0 {
+				addresses = append(addresses, ingress.IP)
+			} else if len(ingress.Hostname) != 0 {
+				addresses = append(addresses, ingress.Hostname)
+			}
+		}
+	}
+	switch {
+	case len(addresses) == 0:
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerLoadBalancerIPConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "Pending",
+			Message: fmt.Sprintf("The load balancer has not been provisioned with the requested address %s yet.", requested),
+		}
+	case slice.ContainsString(addresses, requested):
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerLoadBalancerIPConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "Assigned",
+			Message: fmt.Sprintf("The load balancer has the requested address %s.", requested),
+		}
+	default:
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerLoadBalancerIPConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "Mismatch",
+			Message: fmt.Sprintf("The load balancer has address %s rather than the requested address %s; verify that the address is reserved in the cluster's region and that the cloud provider supports requesting it.", strings.Join(addresses, ", "), requested),
+		}
+	}
+}
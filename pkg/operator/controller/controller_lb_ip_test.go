@@ -0,0 +1,216 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/client-go/tools/record"
+)
+
+// TestDesiredLoadBalancerServiceLoadBalancerIP verifies that the requested
+// load balancer IP is set in the service's spec on every platform and in the
+// Azure annotation for the address family on Azure.
+func TestDesiredLoadBalancerServiceLoadBalancerIP(t *testing.T) {
+	testCases := []struct {
+		description       string
+		ip                string
+		platform          configv1.PlatformType
+		expectIP          string
+		expectAnnotations map[string]string
+	}{
+		{
+			description: "unset",
+			platform:    configv1.AzurePlatformType,
+		},
+		{
+			description: "GCP",
+			ip:          "203.0.113.10",
+			platform:    configv1.GCPPlatformType,
+			expectIP:    "203.0.113.10",
+		},
+		{
+			description:       "Azure IPv4",
+			ip:                "203.0.113.10",
+			platform:          configv1.AzurePlatformType,
+			expectIP:          "203.0.113.10",
+			expectAnnotations: map[string]string{azureLoadBalancerIPv4Annotation: "203.0.113.10"},
+		},
+		{
+			description:       "Azure IPv6",
+			ip:                "2001:DB8::10",
+			platform:          configv1.AzurePlatformType,
+			expectIP:          "2001:db8::10",
+			expectAnnotations: map[string]string{azureLoadBalancerIPv6Annotation: "2001:db8::10"},
+		},
+	}
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+				},
+			},
+		}
+		if len(tc.ip) != 0 {
+			ci.Annotations = map[string]string{loadBalancerIPAnnotation: tc.ip}
+		}
+		infraConfig := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: tc.platform}}
+		service, err := desiredLoadBalancerService(ci, "openshift-ingress", metav1.OwnerReference{}, infraConfig)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.description, err)
+		}
+		if service.Spec.LoadBalancerIP != tc.expectIP {
+			t.Errorf("%s: expected load balancer IP %q, got %q", tc.description, tc.expectIP, service.Spec.LoadBalancerIP)
+		}
+		for _, annotation := range []string{azureLoadBalancerIPv4Annotation, azureLoadBalancerIPv6Annotation} {
+			if expect, actual := tc.expectAnnotations[annotation], service.Annotations[annotation]; expect != actual {
+				t.Errorf("%s: expected annotation %s to be %q, got %q", tc.description, annotation, expect, actual)
+			}
+		}
+	}
+}
+
+// TestEnsureLoadBalancerServiceRecreatesForLoadBalancerIP verifies that a
+// change to the requested load balancer IP deletes the service, finalizer and
+// all, so that it is recreated with the new address, and that an unchanged
+// address leaves the service alone.
+func TestEnsureLoadBalancerServiceRecreatesForLoadBalancerIP(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{loadBalancerIPAnnotation: "203.0.113.10"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.GCPPlatformType}}
+	recorder := record.NewFakeRecorder(10)
+	client := newFakeClient()
+	r := &reconciler{Config: Config{OperandNamespace: "openshift-ingress"}, client: client, recorder: recorder}
+
+	service, err := r.ensureLoadBalancerService(ci, metav1.OwnerReference{}, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to ensure load balancer service: %v", err)
+	}
+	if service.Spec.LoadBalancerIP != "203.0.113.10" {
+		t.Errorf("expected load balancer IP 203.0.113.10, got %q", service.Spec.LoadBalancerIP)
+	}
+	if service, err = r.ensureLoadBalancerService(ci, metav1.OwnerReference{}, infraConfig); err != nil || service == nil {
+		t.Fatalf("expected the service to be kept, got %v, %v", service, err)
+	}
+	if client.calls["update"] != 0 || client.calls["delete"] != 0 {
+		t.Errorf("expected the service not to be updated or deleted, got %v", client.calls)
+	}
+
+	ci.Annotations[loadBalancerIPAnnotation] = "203.0.113.20"
+	service, err = r.ensureLoadBalancerService(ci, metav1.OwnerReference{}, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to ensure load balancer service: %v", err)
+	}
+	if service != nil {
+		t.Errorf("expected no service while it is recreated, got %v", service)
+	}
+	if client.calls["update"] != 1 || client.calls["delete"] != 1 {
+		t.Errorf("expected the finalizer to be removed and the service deleted, got %v", client.calls)
+	}
+	select {
+	case event := <-recorder.Events:
+		expect := "Normal RecreatingLoadBalancerService Recreating load balancer service openshift-ingress/router-default to change its load balancer IP; the ingresscontroller is unreachable until the new load balancer is provisioned"
+		if !strings.HasPrefix(event, expect) {
+			t.Errorf("expected event %q, got %q", expect, event)
+		}
+	default:
+		t.Error("expected a RecreatingLoadBalancerService event")
+	}
+
+	service, err = r.ensureLoadBalancerService(ci, metav1.OwnerReference{}, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to ensure load balancer service: %v", err)
+	}
+	if service == nil || service.Spec.LoadBalancerIP != "203.0.113.20" {
+		t.Errorf("expected the service to be recreated with load balancer IP 203.0.113.20, got %v", service)
+	}
+}
+
+// TestComputeLoadBalancerIPCondition verifies that the LoadBalancerIPAssigned
+// condition reports whether the load balancer has the requested address.
+func TestComputeLoadBalancerIPCondition(t *testing.T) {
+	provisioned := func(ips ...string) *corev1.Service {
+		service := &corev1.Service{}
+		for _, ip := range ips {
+			service.Status.LoadBalancer.Ingress = append(service.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ip})
+		}
+		return service
+	}
+	testCases := []struct {
+		description string
+		ip          string
+		service     *corev1.Service
+		expect      operatorv1.OperatorCondition
+	}{
+		{
+			description: "not requested",
+			service:     provisioned("198.51.100.1"),
+			expect: operatorv1.OperatorCondition{
+				Type:    IngressControllerLoadBalancerIPConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "NotRequested",
+				Message: "The ingresscontroller does not request a load balancer IP address.",
+			},
+		},
+		{
+			description: "no service",
+			ip:          "203.0.113.10",
+			expect: operatorv1.OperatorCondition{
+				Type:    IngressControllerLoadBalancerIPConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "Pending",
+				Message: "The load balancer has not been provisioned with the requested address 203.0.113.10 yet.",
+			},
+		},
+		{
+			description: "assigned",
+			ip:          "203.0.113.10",
+			service:     provisioned("203.0.113.10"),
+			expect: operatorv1.OperatorCondition{
+				Type:    IngressControllerLoadBalancerIPConditionType,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "Assigned",
+				Message: "The load balancer has the requested address 203.0.113.10.",
+			},
+		},
+		{
+			description: "mismatch",
+			ip:          "203.0.113.10",
+			service:     provisioned("198.51.100.1"),
+			expect: operatorv1.OperatorCondition{
+				Type:    IngressControllerLoadBalancerIPConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "Mismatch",
+				Message: "The load balancer has address 198.51.100.1 rather than the requested address 203.0.113.10; verify that the address is reserved in the cluster's region and that the cloud provider supports requesting it.",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{}
+		if len(tc.ip) != 0 {
+			ic.Annotations = map[string]string{loadBalancerIPAnnotation: tc.ip}
+		}
+		if condition := computeLoadBalancerIPCondition(ic, tc.service); !cmp.Equal(condition, tc.expect) {
+			t.Errorf("%s: expected condition %#v, got %#v", tc.description, tc.expect, condition)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// loadBalancerProvisioningFailedReason is the reason of the Degraded
+// condition and of the event when the cloud provider fails to provision the
+// ingresscontroller's load balancer.
+const loadBalancerProvisioningFailedReason = "LoadBalancerProvisioningFailed"
+
+// loadBalancerProvisioningFailureEventReasons are the reasons of the events
+// with which the service controller reports that it failed to provision or
+// update a load balancer.
+var loadBalancerProvisioningFailureEventReasons = []string{"CreatingLoadBalancerFailed", "SyncLoadBalancerFailed"}
+
+// loadBalancerQuotaMarkers are substrings of the cloud providers' error
+// messages that indicate that a quota or limit is exhausted.
+var loadBalancerQuotaMarkers = []string{"quota", "limitexceeded", "toomanyloadbalancers", "limit exceeded"}
+
+// loadBalancerProvisioningProblem returns a description of why the given
+// ingresscontroller's load balancer is not provisioned, taken from the most
+// recent warning event of the service controller about the given service, or
+// the empty string if the load balancer is provisioned or there is no such
+// event.  Events about an earlier service with the same name are ignored.
+func loadBalancerProvisioningProblem(ic *operatorv1.IngressController, service *corev1.Service, events []corev1.Event) string {
+	if !usesLoadBalancer(ic) || service == nil || isProvisioned(service) {
+		return ""
+	}
+	var latest *corev1.Event
+	for i := range events {
+		event := &events[i]
+		if event.Type != corev1.EventTypeWarning || event.Source.Component != "service-controller" {
+			continue
+		}
+		if !isLoadBalancerProvisioningFailureReason(event.Reason) {
+			continue
+		}
+		involved := event.InvolvedObject
+		if involved.Kind != "Service" || involved.Namespace != service.Namespace || involved.Name != service.Name {
+			continue
+		}
+		if len(involved.UID) != 0 && len(service.UID) != 0 && involved.UID != service.UID {
+			continue
+		}
+		if latest == nil || latest.LastTimestamp.Before(&event.LastTimestamp) {
+			latest = event
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	problem := fmt.Sprintf("the cloud provider failed to provision the load balancer for service %s/%s: %s: %s", service.Namespace, service.Name, latest.Reason, latest.Message)
+	message := strings.ToLower(latest.Message)
+	for _, marker := range loadBalancerQuotaMarkers {
+		if strings.Contains(message, marker) {
+			problem += "; the cloud account's load balancer quota may be exhausted, so delete unused load balancers or request a higher quota"
+			break
+		}
+	}
+	return problem
+}
+
+// isLoadBalancerProvisioningFailureReason returns a Boolean indicating whether
+// the given event reason reports a failure to provision a load balancer.
+func isLoadBalancerProvisioningFailureReason(reason string) bool {
+	for _, r := range loadBalancerProvisioningFailureEventReasons {
+		if reason == r {
+			return true
+		}
+	}
+	return false
+}
+
+// recordLoadBalancerProvisioningFailed emits a warning event on the given
+// ingresscontroller for the given load balancer provisioning problem unless
+// its previous Degraded condition already reported the same problem.
+func (r *reconciler) recordLoadBalancerProvisioningFailed(ic *operatorv1.IngressController, problem string) {
+	if r.recorder == nil {
+		return
+	}
+	if old := getIngressCondition(ic.Status.Conditions, operatorv1.OperatorStatusTypeDegraded); old != nil && old.Reason == loadBalancerProvisioningFailedReason && strings.Contains(old.Message, problem) {
+		return
+	}
+	r.recorder.Eventf(ic, "Warning", loadBalancerProvisioningFailedReason, "%s", problem)
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/client-go/tools/record"
+)
+
+// loadBalancerEvent returns a service controller event with the given type,
+// reason, and message about the given service at the given time.
+func loadBalancerEvent(service *corev1.Service, eventType, reason, message string, at time.Time) corev1.Event {
+	return corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Service", Namespace: service.Namespace, Name: service.Name, UID: service.UID},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         corev1.EventSource{Component: "service-controller"},
+		LastTimestamp:  metav1.NewTime(at),
+	}
+}
+
+// TestLoadBalancerProvisioningProblem verifies that the most recent service
+// controller warning about a pending load balancer is reported, with a hint
+// when it is a quota error, and that other events are ignored.
+func TestLoadBalancerProvisioningProblem(t *testing.T) {
+	ic := ingressController("default", operatorv1.LoadBalancerServiceStrategyType)
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default", UID: "1"}}
+	provisioned := service.DeepCopy()
+	provisioned.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}}
+	recreated := service.DeepCopy()
+	recreated.UID = "2"
+	now := time.Now()
+	quota := loadBalancerEvent(service, corev1.EventTypeWarning, "CreatingLoadBalancerFailed", "Error creating load balancer: TooManyLoadBalancers: Exceeded quota of account 123", now)
+	sync := loadBalancerEvent(service, corev1.EventTypeWarning, "SyncLoadBalancerFailed", "Error syncing load balancer: subnet not found", now.Add(-time.Minute))
+	normal := loadBalancerEvent(service, corev1.EventTypeNormal, "EnsuringLoadBalancer", "Ensuring load balancer", now.Add(time.Minute))
+
+	testCases := []struct {
+		description string
+		ic          *operatorv1.IngressController
+		service     *corev1.Service
+		events      []corev1.Event
+		expect      string
+	}{
+		{
+			description: "quota exhausted",
+			ic:          ic,
+			service:     service,
+			events:      []corev1.Event{sync, quota, normal},
+			expect:      "the cloud provider failed to provision the load balancer for service openshift-ingress/router-default: CreatingLoadBalancerFailed: Error creating load balancer: TooManyLoadBalancers: Exceeded quota of account 123; the cloud account's load balancer quota may be exhausted, so delete unused load balancers or request a higher quota",
+		},
+		{
+			description: "other provisioning failure",
+			ic:          ic,
+			service:     service,
+			events:      []corev1.Event{sync, normal},
+			expect:      "the cloud provider failed to provision the load balancer for service openshift-ingress/router-default: SyncLoadBalancerFailed: Error syncing load balancer: subnet not found",
+		},
+		{
+			description: "only normal events",
+			ic:          ic,
+			service:     service,
+			events:      []corev1.Event{normal},
+		},
+		{
+			description: "provisioned",
+			ic:          ic,
+			service:     provisioned,
+			events:      []corev1.Event{quota},
+		},
+		{
+			description: "events about an earlier service",
+			ic:          ic,
+			service:     recreated,
+			events:      []corev1.Event{quota},
+		},
+		{
+			description: "no load balancer",
+			ic:          ingressController("default", operatorv1.HostNetworkStrategyType),
+			service:     service,
+			events:      []corev1.Event{quota},
+		},
+	}
+	for _, tc := range testCases {
+		if actual := loadBalancerProvisioningProblem(tc.ic, tc.service, tc.events); actual != tc.expect {
+			t.Errorf("%s: expected %q, got %q", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+// TestSyncIngressControllerStatusLoadBalancerProvisioningFailed verifies that
+// an ingresscontroller whose load balancer cannot be provisioned is marked
+// degraded with the provider's message and that a warning event is emitted
+// once for the problem.
+func TestSyncIngressControllerStatusLoadBalancerProvisioningFailed(t *testing.T) {
+	deployment := manifests.RouterDeployment()
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "router"}}
+	deployment.Spec.Template.Spec.NodeSelector = nil
+	ic := ingressController("default", operatorv1.LoadBalancerServiceStrategyType)
+	ic.Namespace = "openshift-ingress-operator"
+	worker := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default"}}
+	events := []corev1.Event{
+		loadBalancerEvent(service, corev1.EventTypeWarning, "CreatingLoadBalancerFailed", "googleapi: Error 403: QUOTA_EXCEEDED - Quota 'FORWARDING_RULES' exceeded.", time.Now()),
+	}
+	client := newFakeClient(ic, worker)
+	recorder := record.NewFakeRecorder(10)
+	r := &reconciler{
+		Config:   Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client:   client,
+		recorder: recorder,
+	}
+	sync := func() operatorv1.OperatorCondition {
+		t.Helper()
+		current := &operatorv1.IngressController{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.syncIngressControllerStatus(current, deployment, nil, service, events, &configv1.DNS{}); err != nil {
+			t.Fatalf("failed to sync status: %v", err)
+		}
+		if err := client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+			t.Fatal(err)
+		}
+		condition := getIngressCondition(current.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+		if condition == nil {
+			t.Fatal("expected a Degraded condition")
+		}
+		return *condition
+	}
+
+	problem := "the cloud provider failed to provision the load balancer for service openshift-ingress/router-default: CreatingLoadBalancerFailed: googleapi: Error 403: QUOTA_EXCEEDED - Quota 'FORWARDING_RULES' exceeded.; the cloud account's load balancer quota may be exhausted, so delete unused load balancers or request a higher quota"
+	expect := operatorv1.OperatorCondition{
+		Type:    operatorv1.OperatorStatusTypeDegraded,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  loadBalancerProvisioningFailedReason,
+		Message: problem,
+	}
+	ignoreTime := cmpopts.IgnoreFields(operatorv1.OperatorCondition{}, "LastTransitionTime")
+	if condition := sync(); !cmp.Equal(condition, expect, ignoreTime) {
+		t.Errorf("expected %#v, got %#v", expect, condition)
+	}
+	select {
+	case event := <-recorder.Events:
+		if expectEvent := "Warning LoadBalancerProvisioningFailed " + problem; event != expectEvent {
+			t.Errorf("expected event %q, got %q", expectEvent, event)
+		}
+	default:
+		t.Error("expected a LoadBalancerProvisioningFailed event")
+	}
+
+	// The same problem is not reported again.
+	if condition := sync(); !cmp.Equal(condition, expect, ignoreTime) {
+		t.Errorf("expected %#v, got %#v", expect, condition)
+	}
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("expected no further event, got %q", event)
+	default:
+	}
+
+	// Once the load balancer is provisioned, the ingresscontroller is no
+	// longer degraded.
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}}
+	expect = operatorv1.OperatorCondition{
+		Type:   operatorv1.OperatorStatusTypeDegraded,
+		Status: operatorv1.ConditionFalse,
+	}
+	if condition := sync(); !cmp.Equal(condition, expect, ignoreTime) {
+		t.Errorf("expected %#v, got %#v", expect, condition)
+	}
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/client-go/tools/record"
+)
+
+func TestLoadBalancerServiceHealthCheckNodePort(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+
+	// When unset, the port is left for the API to assign.
+	service, err := desiredLoadBalancerService(ci, "openshift-ingress", metav1.OwnerReference{}, infraConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.Spec.HealthCheckNodePort != 0 {
+		t.Errorf("expected no health check node port, got %d", service.Spec.HealthCheckNodePort)
+	}
+
+	ci.Annotations = map[string]string{loadBalancerHealthCheckNodePortAnnotation: "32000"}
+	recorder := record.NewFakeRecorder(10)
+	client := newFakeClient()
+	r := &reconciler{Config: Config{OperandNamespace: "openshift-ingress"}, client: client, recorder: recorder}
+	service, err = r.ensureLoadBalancerService(ci, metav1.OwnerReference{}, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to ensure load balancer service: %v", err)
+	}
+	if service.Spec.HealthCheckNodePort != 32000 {
+		t.Errorf("expected health check node port 32000, got %d", service.Spec.HealthCheckNodePort)
+	}
+
+	// The API does not allow the port to be changed, so a mismatch is
+	// reported instead of updating the service.
+	ci.Annotations[loadBalancerHealthCheckNodePortAnnotation] = "32001"
+	service, err = r.ensureLoadBalancerService(ci, metav1.OwnerReference{}, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to ensure load balancer service: %v", err)
+	}
+	if service.Spec.HealthCheckNodePort != 32000 {
+		t.Errorf("expected health check node port to remain 32000, got %d", service.Spec.HealthCheckNodePort)
+	}
+	if client.calls["update"] != 0 || client.calls["delete"] != 0 {
+		t.Errorf("expected the service not to be updated or deleted, got %v", client.calls)
+	}
+	select {
+	case event := <-recorder.Events:
+		expect := "Warning HealthCheckNodePortMismatch The load balancer service has health check node port 32000, but 32001 is requested; delete the service to apply the requested port"
+		if !strings.HasPrefix(event, expect) {
+			t.Errorf("expected event %q, got %q", expect, event)
+		}
+	default:
+		t.Error("expected a HealthCheckNodePortMismatch event")
+	}
+}
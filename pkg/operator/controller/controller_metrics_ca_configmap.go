@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// InjectCABundleAnnotation is the annotation used to request that the
+	// service CA operator inject its CA bundle into a configmap.
+	InjectCABundleAnnotation = "service.alpha.openshift.io/inject-cabundle"
+
+	// serviceCABundleKey is the configmap key under which the service CA
+	// operator injects its CA bundle.
+	serviceCABundleKey = "service-ca.crt"
+)
+
+// ensureMetricsCABundleConfigMap ensures that a configmap exists for the given
+// ingresscontroller into which the service CA operator can inject the CA
+// bundle that signs the router's metrics certificate.  The injected data is
+// left alone.
+func (r *reconciler) ensureMetricsCABundleConfigMap(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) (*corev1.ConfigMap, error) {
+	desired := desiredMetricsCABundleConfigMap(ic, r.OperandNamespace, deploymentRef)
+	applyOperandMetadata(desired, r.OperandLabels, r.OperandAnnotations)
+	current, err := r.currentMetricsCABundleConfigMap(ic)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return nil, fmt.Errorf("failed to create metrics CA bundle configmap %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		stepLogger(ic, "metrics").Info("created metrics CA bundle configmap", "namespace", desired.Namespace, "name", desired.Name)
+		return desired, nil
+	}
+
+	updated := current.DeepCopy()
+	changed := updateOperandMetadata(updated, desired, r.OperandLabels, r.OperandAnnotations)
+	if updated.Annotations[InjectCABundleAnnotation] != "true" {
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[InjectCABundleAnnotation] = "true"
+		changed = true
+	}
+	if changed {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return nil, fmt.Errorf("failed to update metrics CA bundle configmap %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		stepLogger(ic, "metrics").Info("updated metrics CA bundle configmap", "namespace", updated.Namespace, "name", updated.Name)
+		return updated, nil
+	}
+	return current, nil
+}
+
+func desiredMetricsCABundleConfigMap(ic *operatorv1.IngressController, namespace string, deploymentRef metav1.OwnerReference) *corev1.ConfigMap {
+	name := MetricsCABundleConfigMapName(ic, namespace)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: ic.Name,
+			},
+			Annotations: map[string]string{
+				InjectCABundleAnnotation: "true",
+			},
+		},
+	}
+	cm.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
+	return cm
+}
+
+func (r *reconciler) currentMetricsCABundleConfigMap(ic *operatorv1.IngressController) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), MetricsCABundleConfigMapName(ic, r.OperandNamespace), cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cm, nil
+}
+
+// hasInjectedCABundle returns a Boolean indicating whether the service CA
+// operator has injected its CA bundle into the given configmap.
+func hasInjectedCABundle(cm *corev1.ConfigMap) bool {
+	return cm != nil && len(cm.Data[serviceCABundleKey]) != 0
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reservedOperandMetadataPrefix is the prefix of labels and annotations that
+// the operator uses to track its operands.  Extra operand labels and
+// annotations with this prefix are ignored.
+const reservedOperandMetadataPrefix = "ingresscontroller.operator.openshift.io/"
+
+// applyOperandMetadata merges the configured extra labels and annotations
+// into the given desired operand object.  Labels and annotations that the
+// operator already sets on the object take precedence over the extra ones.
+func applyOperandMetadata(obj metav1.Object, extraLabels, extraAnnotations map[string]string) {
+	obj.SetLabels(mergeOperandMetadata(obj.GetLabels(), extraLabels))
+	obj.SetAnnotations(mergeOperandMetadata(obj.GetAnnotations(), extraAnnotations))
+}
+
+// mergeOperandMetadata returns the union of the given maps, preferring values
+// from required on conflict and ignoring reserved keys in extra.
+func mergeOperandMetadata(required, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return required
+	}
+	merged := make(map[string]string, len(required)+len(extra))
+	for k, v := range extra {
+		if strings.HasPrefix(k, reservedOperandMetadataPrefix) {
+			continue
+		}
+		merged[k] = v
+	}
+	for k, v := range required {
+		merged[k] = v
+	}
+	return merged
+}
+
+// updateOperandMetadata sets the configured extra labels and annotations on
+// current to the values that they have on desired and returns a Boolean
+// indicating whether current was modified.  Other labels and annotations on
+// current are left alone so as not to fight with other controllers.
+func updateOperandMetadata(current, desired metav1.Object, extraLabels, extraAnnotations map[string]string) bool {
+	labels, labelsChanged := updateOperandMetadataMap(current.GetLabels(), desired.GetLabels(), extraLabels)
+	annotations, annotationsChanged := updateOperandMetadataMap(current.GetAnnotations(), desired.GetAnnotations(), extraAnnotations)
+	if labelsChanged {
+		current.SetLabels(labels)
+	}
+	if annotationsChanged {
+		current.SetAnnotations(annotations)
+	}
+	return labelsChanged || annotationsChanged
+}
+
+func updateOperandMetadataMap(current, desired, extra map[string]string) (map[string]string, bool) {
+	changed := false
+	for k := range extra {
+		v, ok := desired[k]
+		if !ok {
+			continue
+		}
+		if cv, ok := current[k]; ok && cv == v {
+			continue
+		}
+		if !changed {
+			updated := make(map[string]string, len(current)+len(extra))
+			for ck, cv := range current {
+				updated[ck] = cv
+			}
+			current = updated
+			changed = true
+		}
+		current[k] = v
+	}
+	return current, changed
+}
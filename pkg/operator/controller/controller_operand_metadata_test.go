@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TestOperandMetadataPropagation verifies that the configured extra labels and
+// annotations are set on every operand, that operator-required labels win on
+// conflict, and that drift is reverted.
+func TestOperandMetadataPropagation(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	client := newFakeClient()
+	r := &reconciler{
+		Config: Config{
+			OperandNamespace:       "openshift-ingress",
+			IngressControllerImage: "quay.io/openshift/router:latest",
+			OperandLabels: map[string]string{
+				"example.com/team":                     "network",
+				manifests.OwningIngressControllerLabel: "not-default",
+			},
+			OperandAnnotations: map[string]string{
+				"example.com/owner":         "ops",
+				ServingCertSecretAnnotation: "not-the-metrics-secret",
+			},
+		},
+		client: client,
+	}
+
+	// ensureAll ensures all operands and returns them.
+	ensureAll := func() map[string]runtime.Object {
+		deployment, err := r.ensureRouterDeployment(ci, infraConfig)
+		if err != nil {
+			t.Fatalf("failed to ensure router deployment: %v", err)
+		}
+		deploymentRef := metav1.OwnerReference{Name: deployment.Name}
+		lbService, err := r.ensureLoadBalancerService(ci, deploymentRef, infraConfig)
+		if err != nil {
+			t.Fatalf("failed to ensure load balancer service: %v", err)
+		}
+		internalService, err := r.ensureInternalIngressControllerService(ci, deploymentRef)
+		if err != nil {
+			t.Fatalf("failed to ensure internal service: %v", err)
+		}
+		caBundle, err := r.ensureMetricsCABundleConfigMap(ci, deploymentRef)
+		if err != nil {
+			t.Fatalf("failed to ensure metrics CA bundle configmap: %v", err)
+		}
+		serviceMonitor, err := r.ensureServiceMonitor(ci, internalService, caBundle, deploymentRef)
+		if err != nil {
+			t.Fatalf("failed to ensure servicemonitor: %v", err)
+		}
+		return map[string]runtime.Object{
+			"deployment":       deployment,
+			"lb service":       lbService,
+			"internal service": internalService,
+			"ca bundle":        caBundle,
+			"servicemonitor":   serviceMonitor,
+		}
+	}
+
+	accessor := func(obj runtime.Object) metav1.Object {
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	expectMetadata := func(objects map[string]runtime.Object) {
+		for name, o := range objects {
+			obj := accessor(o)
+			if v := obj.GetLabels()["example.com/team"]; v != "network" {
+				t.Errorf("%s: expected label example.com/team=network, got %q", name, v)
+			}
+			if v := obj.GetAnnotations()["example.com/owner"]; v != "ops" {
+				t.Errorf("%s: expected annotation example.com/owner=ops, got %q", name, v)
+			}
+			if v, ok := obj.GetLabels()[manifests.OwningIngressControllerLabel]; ok && v != "default" {
+				t.Errorf("%s: expected operator-required label %s=default, got %q", name, manifests.OwningIngressControllerLabel, v)
+			}
+		}
+		serviceMonitor := accessor(objects["servicemonitor"])
+		if v, ok := serviceMonitor.GetLabels()[manifests.OwningIngressControllerLabel]; ok {
+			t.Errorf("servicemonitor: expected reserved label %s to be ignored, got %q", manifests.OwningIngressControllerLabel, v)
+		}
+		internalService := accessor(objects["internal service"])
+		if v := internalService.GetAnnotations()[ServingCertSecretAnnotation]; v != "router-metrics-certs-default" {
+			t.Errorf("internal service: expected operator-required annotation %s=router-metrics-certs-default, got %q", ServingCertSecretAnnotation, v)
+		}
+	}
+
+	expectMetadata(ensureAll())
+	if client.calls["update"] != 0 {
+		t.Errorf("expected no updates after creating operands, got %d", client.calls["update"])
+	}
+
+	// Tamper with the extra metadata on every operand and verify that it
+	// is restored.
+	for _, o := range ensureAll() {
+		obj := accessor(o)
+		labels := obj.GetLabels()
+		delete(labels, "example.com/team")
+		obj.SetLabels(labels)
+		annotations := obj.GetAnnotations()
+		annotations["example.com/owner"] = "someone-else"
+		obj.SetAnnotations(annotations)
+		if err := client.replace(o); err != nil {
+			t.Fatalf("failed to tamper with operand: %v", err)
+		}
+	}
+	expectMetadata(ensureAll())
+	if client.calls["update"] != 5 {
+		t.Errorf("expected 5 updates to revert drift, got %d", client.calls["update"])
+	}
+
+	// Reconciling again makes no further updates.
+	expectMetadata(ensureAll())
+	if client.calls["update"] != 5 {
+		t.Errorf("expected no further updates, got %d", client.calls["update"]-5)
+	}
+}
+
+func TestMergeOperandMetadata(t *testing.T) {
+	var service corev1.Service
+	service.Labels = map[string]string{"required": "a"}
+	applyOperandMetadata(&service, map[string]string{"required": "b", "extra": "c"}, nil)
+	if service.Labels["required"] != "a" || service.Labels["extra"] != "c" {
+		t.Errorf("unexpected labels: %v", service.Labels)
+	}
+	if service.Annotations != nil {
+		t.Errorf("expected no annotations, got %v", service.Annotations)
+	}
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// routerErrorRatioThreshold is the ratio of HTTP responses with a 5xx status
+// code above which the router's error ratio alert fires.
+const routerErrorRatioThreshold = "0.05"
+
+// prometheusRuleSelectorLabels are the labels by which openshift-monitoring's
+// prometheus selects alerting rules.
+var prometheusRuleSelectorLabels = []string{"prometheus", "role"}
+
+// prometheusRuleGVK is the group, version, and kind of the prometheusrule
+// resource of the monitoring stack.
+var prometheusRuleGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Kind:    "PrometheusRule",
+	Version: "v1",
+}
+
+// ensurePrometheusRule ensures that a prometheusrule with the router's alerts
+// exists for the given ingresscontroller.  The alerts select the metrics that
+// prometheus scrapes through the ingresscontroller's servicemonitor, which
+// targets the given internal service.
+func (r *reconciler) ensurePrometheusRule(ic *operatorv1.IngressController, svc *corev1.Service, deploymentRef metav1.OwnerReference) (*unstructured.Unstructured, error) {
+	desired := desiredPrometheusRule(ic, r.OperandNamespace, svc, deploymentRef)
+	applyOperandMetadata(desired, r.OperandLabels, r.OperandAnnotations)
+
+	current, err := r.currentPrometheusRule(ic)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return nil, fmt.Errorf("failed to create prometheusrule %s/%s: %v", desired.GetNamespace(), desired.GetName(), err)
+		}
+		stepLogger(ic, "metrics").Info("created prometheusrule", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		return desired, nil
+	}
+	changed, updated := prometheusRuleChanged(current, desired)
+	if !changed {
+		updated = current.DeepCopy()
+	}
+	if metadataChanged := updateOperandMetadata(updated, desired, r.OperandLabels, r.OperandAnnotations); changed || metadataChanged {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return nil, fmt.Errorf("failed to update prometheusrule %s/%s: %v", updated.GetNamespace(), updated.GetName(), err)
+		}
+		stepLogger(ic, "metrics").Info("updated prometheusrule", "namespace", updated.GetNamespace(), "name", updated.GetName())
+		return updated, nil
+	}
+	return current, nil
+}
+
+// desiredPrometheusRule returns the prometheusrule with the alerts for the
+// given ingresscontroller's router.  IngressControllerRouterDown fires when
+// prometheus has not been able to scrape any of the router pods for 5 minutes,
+// and IngressControllerHighErrorRatio fires when more than 5% of the HTTP
+// responses from the routes' backends have had a 5xx status code for 10
+// minutes.  The rule carries the labels that openshift-monitoring's prometheus
+// uses to select alerting rules.
+func desiredPrometheusRule(ic *operatorv1.IngressController, namespace string, svc *corev1.Service, deploymentRef metav1.OwnerReference) *unstructured.Unstructured {
+	name := IngressControllerPrometheusRuleName(ic, namespace)
+	selector := fmt.Sprintf(`job=%q,namespace=%q`, svc.Name, namespace)
+	alertLabels := map[string]interface{}{
+		"severity":          "warning",
+		"ingresscontroller": ic.Name,
+	}
+	rule := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"namespace": name.Namespace,
+				"name":      name.Name,
+				"labels": map[string]interface{}{
+					"prometheus": "k8s",
+					"role":       "alert-rules",
+				},
+			},
+			"spec": map[string]interface{}{
+				"groups": []interface{}{
+					map[string]interface{}{
+						"name": "ingresscontroller-" + ic.Name,
+						"rules": []interface{}{
+							map[string]interface{}{
+								"alert":  "IngressControllerRouterDown",
+								"expr":   fmt.Sprintf(`absent(up{%s} == 1)`, selector),
+								"for":    "5m",
+								"labels": runtime.DeepCopyJSONValue(alertLabels),
+								"annotations": map[string]interface{}{
+									"message": fmt.Sprintf("No router pod of ingresscontroller %s has been reachable by prometheus for 5 minutes.", ic.Name),
+								},
+							},
+							map[string]interface{}{
+								"alert":  "IngressControllerHighErrorRatio",
+								"expr":   fmt.Sprintf(`sum(rate(haproxy_backend_http_responses_total{%[1]s,code="5xx"}[5m])) / sum(rate(haproxy_backend_http_responses_total{%[1]s}[5m])) > %[2]s`, selector, routerErrorRatioThreshold),
+								"for":    "10m",
+								"labels": runtime.DeepCopyJSONValue(alertLabels),
+								"annotations": map[string]interface{}{
+									"message": fmt.Sprintf("{{ $value | humanizePercentage }} of the HTTP responses served by ingresscontroller %s have a 5xx status code.", ic.Name),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	rule.SetGroupVersionKind(prometheusRuleGVK)
+	rule.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
+	return rule
+}
+
+// prometheusRuleChanged checks if the current prometheusrule spec and the
+// labels that prometheus selects it by match the expected ones and if not
+// returns an updated prometheusrule.
+func prometheusRuleChanged(current, expected *unstructured.Unstructured) (bool, *unstructured.Unstructured) {
+	labelsMatch := true
+	for _, k := range prometheusRuleSelectorLabels {
+		if current.GetLabels()[k] != expected.GetLabels()[k] {
+			labelsMatch = false
+		}
+	}
+	if labelsMatch && reflect.DeepEqual(current.Object["spec"], expected.Object["spec"]) {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Object["spec"] = runtime.DeepCopyJSONValue(expected.Object["spec"])
+	labels := map[string]string{}
+	for k, v := range updated.GetLabels() {
+		labels[k] = v
+	}
+	for _, k := range prometheusRuleSelectorLabels {
+		labels[k] = expected.GetLabels()[k]
+	}
+	updated.SetLabels(labels)
+	return true, updated
+}
+
+func (r *reconciler) currentPrometheusRule(ic *operatorv1.IngressController) (*unstructured.Unstructured, error) {
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(prometheusRuleGVK)
+	if err := r.client.Get(context.TODO(), IngressControllerPrometheusRuleName(ic, r.OperandNamespace), rule); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return rule, nil
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestEnsurePrometheusRule verifies that the prometheusrule's alerts are scoped
+// to the ingresscontroller's router metrics, that the rule is selected by
+// openshift-monitoring, and that a modified rule is reverted.
+func TestEnsurePrometheusRule(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "sharded",
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      "router-internal-sharded",
+		},
+	}
+	deploymentRef := metav1.OwnerReference{Name: "router-sharded"}
+	client := newFakeClient()
+	r := &reconciler{Config: Config{OperandNamespace: "openshift-ingress"}, client: client}
+
+	rule, err := r.ensurePrometheusRule(ic, svc, deploymentRef)
+	if err != nil {
+		t.Fatalf("failed to ensure prometheusrule: %v", err)
+	}
+	if rule.GetNamespace() != "openshift-ingress" || rule.GetName() != "router-sharded" {
+		t.Errorf("expected prometheusrule openshift-ingress/router-sharded, got %s/%s", rule.GetNamespace(), rule.GetName())
+	}
+	if labels := rule.GetLabels(); labels["prometheus"] != "k8s" || labels["role"] != "alert-rules" {
+		t.Errorf("expected labels prometheus=k8s and role=alert-rules, got %v", labels)
+	}
+	if refs := rule.GetOwnerReferences(); len(refs) != 1 || refs[0].Name != "router-sharded" {
+		t.Errorf("expected the prometheusrule to be owned by the router deployment, got %v", refs)
+	}
+	groups, _, err := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	if err != nil || len(groups) != 1 {
+		t.Fatalf("expected 1 rule group, got %v (error: %v)", groups, err)
+	}
+	rules, _, err := unstructured.NestedSlice(groups[0].(map[string]interface{}), "rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{
+		"IngressControllerRouterDown":     `absent(up{job="router-internal-sharded",namespace="openshift-ingress"} == 1)`,
+		"IngressControllerHighErrorRatio": `sum(rate(haproxy_backend_http_responses_total{job="router-internal-sharded",namespace="openshift-ingress",code="5xx"}[5m])) / sum(rate(haproxy_backend_http_responses_total{job="router-internal-sharded",namespace="openshift-ingress"}[5m])) > 0.05`,
+	}
+	actual := map[string]string{}
+	for _, rule := range rules {
+		rule := rule.(map[string]interface{})
+		actual[rule["alert"].(string)] = rule["expr"].(string)
+		if v, _, _ := unstructured.NestedString(rule, "labels", "ingresscontroller"); v != "sharded" {
+			t.Errorf("expected alert %s to have label ingresscontroller=sharded, got %q", rule["alert"], v)
+		}
+	}
+	if len(actual) != len(expect) {
+		t.Errorf("expected alerts %v, got %v", expect, actual)
+	}
+	for alert, expr := range expect {
+		if actual[alert] != expr {
+			t.Errorf("expected alert %s to have expression %q, got %q", alert, expr, actual[alert])
+		}
+	}
+
+	modified := rule.DeepCopy()
+	if err := unstructured.SetNestedSlice(modified.Object, []interface{}{}, "spec", "groups"); err != nil {
+		t.Fatal(err)
+	}
+	modified.SetLabels(map[string]string{"app": "custom"})
+	if err := client.replace(modified); err != nil {
+		t.Fatal(err)
+	}
+	reverted, err := r.ensurePrometheusRule(ic, svc, deploymentRef)
+	if err != nil {
+		t.Fatalf("failed to ensure prometheusrule: %v", err)
+	}
+	if client.calls["update"] != 1 {
+		t.Errorf("expected 1 update, got %d", client.calls["update"])
+	}
+	if changed, _ := prometheusRuleChanged(reverted, rule); changed {
+		t.Errorf("expected the prometheusrule to be reverted, got %v", reverted.Object)
+	}
+	if v := reverted.GetLabels()["app"]; v != "custom" {
+		t.Errorf("expected unrelated label app=custom to be kept, got %q", v)
+	}
+
+	if _, err := r.ensurePrometheusRule(ic, svc, deploymentRef); err != nil {
+		t.Fatalf("failed to ensure prometheusrule: %v", err)
+	}
+	if client.calls["update"] != 1 {
+		t.Errorf("expected no update of an unchanged prometheusrule, got %d updates", client.calls["update"])
+	}
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// DefaultResyncPeriod is the default interval at which every ingresscontroller
+// is reconciled even if no event triggers it.
+const DefaultResyncPeriod = 10 * time.Minute
+
+// resyncIngressControllers sends an event for every managed ingresscontroller
+// to the given channel once every given period until the stop channel is
+// closed, so that each ingresscontroller is reconciled periodically and drift
+// of its operands that no watch event reports is corrected.  The first events
+// are sent one period after the cache syncs, since every ingresscontroller is
+// reconciled when the operator starts.
+func (r *reconciler) resyncIngressControllers(events chan<- event.GenericEvent, period time.Duration, stop <-chan struct{}) error {
+	if !r.cache.WaitForCacheSync(stop) {
+		return fmt.Errorf("failed to sync cache before resyncing ingresscontrollers")
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			r.sendResyncEvents(events, stop)
+		}
+	}
+}
+
+// sendResyncEvents sends an event for every managed ingresscontroller to the
+// given channel, or as many as it can before the stop channel is closed.
+func (r *reconciler) sendResyncEvents(events chan<- event.GenericEvent, stop <-chan struct{}) {
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for resync")
+		return
+	}
+	for i := range ingresses.Items {
+		ic := &ingresses.Items[i]
+		if !r.isIngressControllerManaged(ic.Name) {
+			continue
+		}
+		select {
+		case events <- event.GenericEvent{Meta: ic, Object: ic}:
+		case <-stop:
+			return
+		}
+	}
+	log.V(1).Info("resynced ingresscontrollers", "count", len(ingresses.Items))
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// TestSendResyncEvents verifies that an event is sent for every managed
+// ingresscontroller in the operator namespace and none for unmanaged ones.
+func TestSendResyncEvents(t *testing.T) {
+	other := ingressController("other", operatorv1.PrivateStrategyType)
+	other.Namespace = "other"
+	objects := []*operatorv1.IngressController{
+		ingressController("default", operatorv1.LoadBalancerServiceStrategyType),
+		ingressController("sharded", operatorv1.PrivateStrategyType),
+		ingressController("external", operatorv1.PrivateStrategyType),
+		other,
+	}
+	objs := []runtime.Object{}
+	for _, ic := range objects {
+		if len(ic.Namespace) == 0 {
+			ic.Namespace = "openshift-ingress-operator"
+		}
+		objs = append(objs, ic)
+	}
+	client := newFakeClient(objs...)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", UnmanagedIngressControllers: []string{"external"}},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	events := make(chan event.GenericEvent, 10)
+	r.sendResyncEvents(events, make(chan struct{}))
+	close(events)
+	names := []string{}
+	for e := range events {
+		names = append(names, e.Meta.GetNamespace()+"/"+e.Meta.GetName())
+	}
+	sort.Strings(names)
+	if expect := []string{"openshift-ingress-operator/default", "openshift-ingress-operator/sharded"}; !cmp.Equal(names, expect) {
+		t.Errorf("expected events for %v, got %v", expect, names)
+	}
+}
+
+// TestResyncIngressControllers verifies that events are sent once per period
+// and that the resync stops when the stop channel is closed, even while it
+// waits to send an event.
+func TestResyncIngressControllers(t *testing.T) {
+	ic := ingressController("default", operatorv1.PrivateStrategyType)
+	ic.Namespace = "openshift-ingress-operator"
+	client := newFakeClient(ic)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	events := make(chan event.GenericEvent)
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- r.resyncIngressControllers(events, 10*time.Millisecond, stop) }()
+	for i := 0; i < 2; i++ {
+		select {
+		case e := <-events:
+			if e.Meta.GetName() != "default" {
+				t.Errorf("expected an event for default, got %s", e.Meta.GetName())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a resync event")
+		}
+	}
+	close(stop)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected the resync to stop without error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the resync to stop")
+	}
+}
@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// routerAutoscalingAnnotation, when set to "true" on an
+	// ingresscontroller, makes the operator maintain a
+	// horizontalpodautoscaler that scales the router deployment based on
+	// the CPU utilization of the router pods.  While autoscaling is enabled,
+	// the operator leaves the deployment's replica count to the autoscaler
+	// and ignores spec.replicas.  Allowed values are "true" and "false".  If
+	// unset, the router deployment is not autoscaled.
+	routerAutoscalingAnnotation = "ingress.operator.openshift.io/autoscaling"
+
+	// routerAutoscalingMinReplicasAnnotation specifies the minimum number
+	// of router replicas when autoscaling is enabled.  The value must be a
+	// positive integer.  If unset, the ingresscontroller's spec.replicas,
+	// or 2 if that is unset, is used.
+	routerAutoscalingMinReplicasAnnotation = "ingress.operator.openshift.io/autoscaling-min-replicas"
+
+	// routerAutoscalingMaxReplicasAnnotation specifies the maximum number
+	// of router replicas when autoscaling is enabled.  The value must be a
+	// positive integer no less than the minimum.  It is required when
+	// autoscaling is enabled.
+	routerAutoscalingMaxReplicasAnnotation = "ingress.operator.openshift.io/autoscaling-max-replicas"
+
+	// routerAutoscalingTargetCPUAnnotation specifies the target average
+	// CPU utilization of the router pods, as a percentage of their CPU
+	// request, when autoscaling is enabled.  The value must be a positive
+	// integer.  If unset, defaultRouterAutoscalingTargetCPU is used.
+	routerAutoscalingTargetCPUAnnotation = "ingress.operator.openshift.io/autoscaling-target-cpu-utilization"
+
+	// defaultRouterAutoscalingTargetCPU is the default target CPU
+	// utilization percentage.
+	defaultRouterAutoscalingTargetCPU = 80
+)
+
+// routerAutoscalingEnabled returns a Boolean indicating whether the router
+// deployment of the given ingresscontroller is autoscaled.
+func routerAutoscalingEnabled(ic *operatorv1.IngressController) bool {
+	return ic.Annotations[routerAutoscalingAnnotation] == "true"
+}
+
+// routerAutoscalingParameters returns the minimum and maximum number of
+// replicas and the target CPU utilization percentage for autoscaling the
+// router deployment of the given ingresscontroller.
+func routerAutoscalingParameters(ic *operatorv1.IngressController) (int32, int32, int32, error) {
+	parse := func(annotation string, defaultValue int32) (int32, error) {
+		v, ok := ic.Annotations[annotation]
+		if !ok {
+			return defaultValue, nil
+		}
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid value for annotation %s: %q; the value must be a positive integer", annotation, v)
+		}
+		return int32(n), nil
+	}
+	defaultMinReplicas := int32(2)
+	if ic.Spec.Replicas != nil && *ic.Spec.Replicas > 0 {
+		defaultMinReplicas = *ic.Spec.Replicas
+	}
+	minReplicas, err := parse(routerAutoscalingMinReplicasAnnotation, defaultMinReplicas)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	maxReplicas, err := parse(routerAutoscalingMaxReplicasAnnotation, 0)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	targetCPU, err := parse(routerAutoscalingTargetCPUAnnotation, defaultRouterAutoscalingTargetCPU)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if maxReplicas == 0 {
+		return 0, 0, 0, fmt.Errorf("annotation %s is required when annotation %s is true", routerAutoscalingMaxReplicasAnnotation, routerAutoscalingAnnotation)
+	}
+	if maxReplicas < minReplicas {
+		return 0, 0, 0, fmt.Errorf("the maximum number of replicas %d in annotation %s is less than the minimum %d", maxReplicas, routerAutoscalingMaxReplicasAnnotation, minReplicas)
+	}
+	return minReplicas, maxReplicas, targetCPU, nil
+}
+
+// applyRouterAutoscalingReplicas sets the replica count of the given desired
+// router deployment when autoscaling is enabled, so that the operator does not
+// fight the autoscaler: an existing deployment keeps its current replica count,
+// and a new one starts with the minimum.
+func applyRouterAutoscalingReplicas(ic *operatorv1.IngressController, current, desired *appsv1.Deployment) {
+	if !routerAutoscalingEnabled(ic) {
+		return
+	}
+	if current != nil && current.Spec.Replicas != nil {
+		replicas := *current.Spec.Replicas
+		desired.Spec.Replicas = &replicas
+		return
+	}
+	if minReplicas, _, _, err := routerAutoscalingParameters(ic); err == nil {
+		desired.Spec.Replicas = &minReplicas
+	}
+}
+
+// ensureRouterAutoscaler ensures that the horizontalpodautoscaler for the
+// router deployment of the given ingresscontroller exists and is up to date if
+// autoscaling is enabled, and that it does not exist otherwise.
+func (r *reconciler) ensureRouterAutoscaler(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) error {
+	if !routerAutoscalingEnabled(ic) {
+		name := RouterHorizontalPodAutoscalerName(ic, r.OperandNamespace)
+		return r.deleteIfExists(&autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name}})
+	}
+	desired, err := desiredRouterAutoscaler(ic, r.OperandNamespace, deploymentRef)
+	if err != nil {
+		return err
+	}
+	applyOperandMetadata(desired, r.OperandLabels, r.OperandAnnotations)
+	current, err := r.currentRouterAutoscaler(ic)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create router horizontalpodautoscaler %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		stepLogger(ic, "autoscaling").Info("created router horizontalpodautoscaler", "namespace", desired.Namespace, "name", desired.Name)
+		return nil
+	}
+
+	updated := current.DeepCopy()
+	changed := !cmp.Equal(current.Spec, desired.Spec, cmpopts.EquateEmpty())
+	updated.Spec = desired.Spec
+	if metadataChanged := updateOperandMetadata(updated, desired, r.OperandLabels, r.OperandAnnotations); changed || metadataChanged {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update router horizontalpodautoscaler %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		stepLogger(ic, "autoscaling").Info("updated router horizontalpodautoscaler", "namespace", updated.Namespace, "name", updated.Name)
+	}
+	return nil
+}
+
+// desiredRouterAutoscaler returns the desired horizontalpodautoscaler for the
+// router deployment of the given ingresscontroller.  The autoscaler is owned by
+// the router deployment, like the ingresscontroller's other operands, so that
+// it is deleted along with the deployment when the ingresscontroller is
+// deleted.
+func desiredRouterAutoscaler(ic *operatorv1.IngressController, namespace string, deploymentRef metav1.OwnerReference) (*autoscalingv1.HorizontalPodAutoscaler, error) {
+	minReplicas, maxReplicas, targetCPU, err := routerAutoscalingParameters(ic)
+	if err != nil {
+		return nil, err
+	}
+	name := RouterHorizontalPodAutoscalerName(ic, namespace)
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: ic.Name,
+			},
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       RouterDeploymentName(ic, namespace).Name,
+			},
+			MinReplicas:                    &minReplicas,
+			MaxReplicas:                    maxReplicas,
+			TargetCPUUtilizationPercentage: &targetCPU,
+		},
+	}
+	hpa.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
+	return hpa, nil
+}
+
+func (r *reconciler) currentRouterAutoscaler(ic *operatorv1.IngressController) (*autoscalingv1.HorizontalPodAutoscaler, error) {
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	if err := r.client.Get(context.TODO(), RouterHorizontalPodAutoscalerName(ic, r.OperandNamespace), hpa); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return hpa, nil
+}
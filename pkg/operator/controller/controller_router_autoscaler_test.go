@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestEnsureRouterAutoscaler verifies that the router autoscaler is created
+// with the parameters from the ingresscontroller's annotations, updated when
+// they change, and deleted when autoscaling is disabled.
+func TestEnsureRouterAutoscaler(t *testing.T) {
+	replicas := int32(3)
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+			Annotations: map[string]string{
+				routerAutoscalingAnnotation:            "true",
+				routerAutoscalingMaxReplicasAnnotation: "6",
+			},
+		},
+		Spec: operatorv1.IngressControllerSpec{
+			Replicas: &replicas,
+		},
+	}
+	deploymentRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "router-default", UID: "1"}
+	client := newFakeClient()
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress"},
+		client: client,
+	}
+	expectSpec := func(minReplicas, maxReplicas, targetCPU int32) autoscalingv1.HorizontalPodAutoscalerSpec {
+		return autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "router-default",
+			},
+			MinReplicas:                    &minReplicas,
+			MaxReplicas:                    maxReplicas,
+			TargetCPUUtilizationPercentage: &targetCPU,
+		}
+	}
+
+	if err := r.ensureRouterAutoscaler(ic, deploymentRef); err != nil {
+		t.Fatalf("failed to create router autoscaler: %v", err)
+	}
+	hpa, err := r.currentRouterAutoscaler(ic)
+	if err != nil || hpa == nil {
+		t.Fatalf("expected router autoscaler to exist, got %v, %v", hpa, err)
+	}
+	if expect := expectSpec(3, 6, 80); !cmp.Equal(hpa.Spec, expect) {
+		t.Errorf("expected spec %+v, got %+v", expect, hpa.Spec)
+	}
+	if !cmp.Equal(hpa.OwnerReferences, []metav1.OwnerReference{deploymentRef}) {
+		t.Errorf("expected router autoscaler to be owned by the router deployment, got %v", hpa.OwnerReferences)
+	}
+
+	updates := client.calls["update"]
+	if err := r.ensureRouterAutoscaler(ic, deploymentRef); err != nil {
+		t.Fatalf("failed to reconcile router autoscaler: %v", err)
+	}
+	if client.calls["update"] != updates {
+		t.Errorf("expected no update for unchanged parameters, got %d", client.calls["update"]-updates)
+	}
+
+	ic.Annotations[routerAutoscalingMinReplicasAnnotation] = "2"
+	ic.Annotations[routerAutoscalingTargetCPUAnnotation] = "60"
+	if err := r.ensureRouterAutoscaler(ic, deploymentRef); err != nil {
+		t.Fatalf("failed to update router autoscaler: %v", err)
+	}
+	if hpa, err = r.currentRouterAutoscaler(ic); err != nil || hpa == nil {
+		t.Fatalf("expected router autoscaler to exist, got %v, %v", hpa, err)
+	}
+	if expect := expectSpec(2, 6, 60); !cmp.Equal(hpa.Spec, expect) {
+		t.Errorf("expected spec %+v, got %+v", expect, hpa.Spec)
+	}
+
+	ic.Annotations[routerAutoscalingAnnotation] = "false"
+	if err := r.ensureRouterAutoscaler(ic, deploymentRef); err != nil {
+		t.Fatalf("failed to delete router autoscaler: %v", err)
+	}
+	if hpa, err = r.currentRouterAutoscaler(ic); err != nil || hpa != nil {
+		t.Errorf("expected router autoscaler to be deleted, got %v, %v", hpa, err)
+	}
+}
+
+// TestEnsureRouterDeploymentAutoscalingReplicas verifies that the operator
+// does not reset the replica count of an autoscaled router deployment, and
+// restores the static replica count when autoscaling is disabled.
+func TestEnsureRouterDeploymentAutoscalingReplicas(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+			Annotations: map[string]string{
+				routerAutoscalingAnnotation:            "true",
+				routerAutoscalingMinReplicasAnnotation: "3",
+				routerAutoscalingMaxReplicasAnnotation: "10",
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	client := newFakeClient()
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", IngressControllerImage: "quay.io/openshift/router:latest"},
+		client: client,
+	}
+
+	deployment, err := r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to create router deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 3 {
+		t.Errorf("expected new deployment to start with the minimum of 3 replicas, got %d", *deployment.Spec.Replicas)
+	}
+
+	// Simulate the autoscaler scaling the deployment.
+	scaled := deployment.DeepCopy()
+	five := int32(5)
+	scaled.Spec.Replicas = &five
+	if err := client.replace(scaled); err != nil {
+		t.Fatal(err)
+	}
+	updates := client.calls["update"]
+	if deployment, err = r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 5 || client.calls["update"] != updates {
+		t.Errorf("expected the autoscaled replica count 5 to be kept without an update, got %d replicas and %d updates", *deployment.Spec.Replicas, client.calls["update"]-updates)
+	}
+
+	ci.Annotations = nil
+	if deployment, err = r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 2 {
+		t.Errorf("expected the default of 2 replicas after disabling autoscaling, got %d", *deployment.Spec.Replicas)
+	}
+}
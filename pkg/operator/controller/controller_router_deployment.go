@@ -2,8 +2,14 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -15,41 +21,272 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	configv1 "github.com/openshift/api/config/v1"
 )
 
+const (
+	// defaultInsecureEdgeTerminationPolicyAnnotation specifies how the
+	// router handles insecure (HTTP) requests for edge-terminated routes
+	// that do not specify spec.tls.insecureEdgeTerminationPolicy.  Allowed
+	// values are Allow, Redirect, and Disable.  If unset, the router's
+	// default behavior is preserved.
+	defaultInsecureEdgeTerminationPolicyAnnotation = "ingress.operator.openshift.io/default-insecure-edge-termination-policy"
+
+	// uniqueIDHeaderNameAnnotation specifies the name of an HTTP header
+	// that the router sets on each request to a unique request ID.  If
+	// unset, the router does not set a unique ID header.
+	uniqueIDHeaderNameAnnotation = "ingress.operator.openshift.io/unique-id-header-name"
+
+	// uniqueIDFormatAnnotation specifies the HAProxy log format of the
+	// unique request ID.  It may only be set along with
+	// uniqueIDHeaderNameAnnotation.  If unset, defaultUniqueIDFormat is
+	// used.
+	uniqueIDFormatAnnotation = "ingress.operator.openshift.io/unique-id-format"
+
+	// maxConnectionsAnnotation specifies the maximum number of concurrent
+	// connections that each router pod accepts.  The value must be a
+	// positive integer.  If unset, the router's default is used.
+	maxConnectionsAnnotation = "ingress.operator.openshift.io/max-connections"
+
+	// tunnelTimeoutAnnotation specifies how long the router keeps an idle
+	// TCP tunnel open, as a duration such as "90s" or "2h".  Tunnels carry
+	// passthrough (TLS) routes and upgraded (WebSocket) connections; the
+	// timeout does not affect the server timeout that the router applies to
+	// ordinary HTTP requests.  If unset, the router's default of 1h is used.
+	tunnelTimeoutAnnotation = "ingress.operator.openshift.io/tunnel-timeout"
+
+	// sessionCookieNameAnnotation specifies the name of the cookie that the
+	// router sets to keep a client's requests to a route on the same
+	// endpoint.  The name must be a valid cookie name.  A route's
+	// router.openshift.io/cookie_name annotation takes precedence over this
+	// name for that route.  If unset, the router derives the cookie name
+	// from each route.
+	sessionCookieNameAnnotation = "ingress.operator.openshift.io/session-cookie-name"
+
+	// compressionMIMETypesAnnotation specifies a comma-separated list of
+	// MIME types, such as "text/html, application/json", of responses
+	// that the router compresses with gzip.  If unset or empty, the router
+	// does not compress responses.
+	compressionMIMETypesAnnotation = "ingress.operator.openshift.io/compression-mime-types"
+
+	// routerThreadsAnnotation specifies the number of HAProxy threads in
+	// each router pod.  The value is either a positive integer or "auto",
+	// which derives the number of threads from the router container's CPU
+	// limit.  If unset, defaultRouterThreads is used.
+	routerThreadsAnnotation = "ingress.operator.openshift.io/router-threads"
+
+	// routerCPULimitAnnotation specifies the CPU limit of the router
+	// container as a resource quantity, such as "4" or "2500m".  If unset,
+	// the router container has no CPU limit.
+	routerCPULimitAnnotation = "ingress.operator.openshift.io/router-cpu-limit"
+
+	// defaultRouterThreads is the number of router threads if the
+	// ingresscontroller does not specify a number, or specifies "auto"
+	// but the router container has no CPU limit.
+	defaultRouterThreads = 4
+
+	// maxRouterThreads is the largest number of threads that HAProxy
+	// supports.
+	maxRouterThreads = 64
+
+	// routerServiceAccountAnnotation specifies the name of an existing
+	// service account in the operand namespace under which the router pods
+	// run.  The service account must be granted the permissions of the
+	// openshift-ingress-router cluster role.  If unset, the router pods run
+	// under the operator-managed "router" service account.
+	routerServiceAccountAnnotation = "ingress.operator.openshift.io/router-service-account"
+
+	// defaultCertificateHashAnnotation is set on the router pod template to
+	// a hash of the data of the ingresscontroller's default certificate
+	// secret so that rotating the certificate rolls out the router pods.
+	defaultCertificateHashAnnotation = "ingress.operator.openshift.io/default-certificate-hash"
+
+	// defaultUniqueIDFormat is the default format of the unique request ID.
+	defaultUniqueIDFormat = `%{+X}o %ci:%cp_%fi:%fp_%Ts_%rt:%pid`
+)
+
+// insecureEdgeTerminationPolicies maps the allowed values of
+// defaultInsecureEdgeTerminationPolicyAnnotation to the corresponding router
+// setting.
+var insecureEdgeTerminationPolicies = map[string]string{
+	"Allow":    "Allow",
+	"Redirect": "Redirect",
+	"Disable":  "None",
+}
+
 // ensureRouterDeployment ensures the router deployment exists for a given
 // ingresscontroller.
 func (r *reconciler) ensureRouterDeployment(ci *operatorv1.IngressController, infraConfig *configv1.Infrastructure) (*appsv1.Deployment, error) {
-	desired, err := desiredRouterDeployment(ci, r.Config.IngressControllerImage, infraConfig)
+	desired, err := desiredRouterDeployment(ci, r.OperandNamespace, r.Config.IngressControllerImage, infraConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build router deployment: %v", err)
 	}
+	applyOperandMetadata(desired, r.OperandLabels, r.OperandAnnotations)
+	certHash, err := r.defaultCertificateHash(ci)
+	if err != nil {
+		return nil, err
+	}
+	backendCABundleHash, err := r.backendCABundleHash(ci)
+	if err != nil {
+		return nil, err
+	}
+	for annotation, hash := range map[string]string{defaultCertificateHashAnnotation: certHash, backendCABundleHashAnnotation: backendCABundleHash} {
+		if len(hash) == 0 {
+			continue
+		}
+		if desired.Spec.Template.Annotations == nil {
+			desired.Spec.Template.Annotations = map[string]string{}
+		}
+		desired.Spec.Template.Annotations[annotation] = hash
+	}
+	maintenancePage, err := r.maintenancePageConfigMap(ci)
+	if err != nil {
+		return nil, err
+	}
+	if maintenancePage != nil {
+		applyMaintenancePage(desired, maintenancePage)
+	}
+	routerTemplate, err := r.routerTemplateConfigMap(ci)
+	if err != nil {
+		return nil, err
+	}
+	if routerTemplate != nil && len(routerTemplateProblem(ci, r.OperandNamespace, routerTemplate)) == 0 {
+		applyRouterTemplate(desired, routerTemplate)
+	}
+	referencedConfigHash, err := r.referencedConfigHash(desired)
+	if err != nil {
+		return nil, err
+	}
+	if len(referencedConfigHash) != 0 {
+		if desired.Spec.Template.Annotations == nil {
+			desired.Spec.Template.Annotations = map[string]string{}
+		}
+		desired.Spec.Template.Annotations[referencedConfigHashAnnotation] = referencedConfigHash
+	}
+	if err := r.validateRouterServiceAccount(ci); err != nil {
+		return nil, err
+	}
 	current, err := r.currentRouterDeployment(ci)
 	if err != nil {
 		return nil, err
 	}
+	applyRouterAutoscalingReplicas(ci, current, desired)
+	_, recreateRequested := ci.Annotations[recreateRouterDeploymentAnnotation]
 	switch {
 	case desired != nil && current == nil:
-		if err := r.createRouterDeployment(desired); err != nil {
+		if err := r.createRouterDeployment(ci, desired); err != nil {
 			return nil, err
 		}
+		created, err := r.currentRouterDeployment(ci)
+		if err != nil {
+			return nil, err
+		}
+		if created != nil {
+			if err := r.adoptRouterDeploymentDependents(ci, created); err != nil {
+				return nil, err
+			}
+		}
+	case desired != nil && current != nil && routerDeploymentSelectorChanged(current, desired) && recreateRequested:
+		if err := r.deleteRouterDeploymentForRecreate(ci, current, desired); err != nil {
+			return nil, err
+		}
+		// The deletion completes asynchronously; the deployment is
+		// recreated once it is gone.
+		return current, nil
 	case desired != nil && current != nil:
-		if err := r.updateRouterDeployment(current, desired); err != nil {
+		if routerDeploymentSelectorChanged(current, desired) {
+			stepLogger(ci, "deployment").Info("router deployment pod selector does not match the desired selector, which is immutable; add annotation "+recreateRouterDeploymentAnnotation+" to recreate the deployment", "namespace", current.Namespace, "name", current.Name, "currentSelector", current.Spec.Selector, "desiredSelector", desired.Spec.Selector)
+		}
+		if err := r.updateRouterDeployment(ci, current, desired); err != nil {
+			return nil, err
+		}
+	}
+	deployment, err := r.currentRouterDeployment(ci)
+	if err != nil {
+		return nil, err
+	}
+	if recreateRequested && deployment != nil {
+		if err := r.finishRouterDeploymentRecreate(ci, deployment, desired); err != nil {
 			return nil, err
 		}
 	}
-	return r.currentRouterDeployment(ci)
+	return deployment, nil
+}
+
+// defaultCertificateHash returns a hash of the data of the ingresscontroller's
+// default certificate secret, or the empty string if the secret does not
+// exist.
+func (r *reconciler) defaultCertificateHash(ci *operatorv1.IngressController) (string, error) {
+	secret := &corev1.Secret{}
+	name := RouterEffectiveDefaultCertificateSecretName(ci, r.OperandNamespace)
+	if err := r.client.Get(context.TODO(), name, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get default certificate secret %s: %v", name, err)
+	}
+	return secretDataHash(secret), nil
+}
+
+// secretDataHash returns a hex-encoded SHA-256 hash of the given secret's
+// data.  The hash does not depend on the order of the keys.
+func secretDataHash(secret *corev1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(hash, "%s\x00%d\x00", k, len(secret.Data[k]))
+		hash.Write(secret.Data[k])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// validateRouterServiceAccount verifies that the service account that the
+// ingresscontroller specifies for its router pods, if any, exists.
+func (r *reconciler) validateRouterServiceAccount(ci *operatorv1.IngressController) error {
+	name, ok := ci.Annotations[routerServiceAccountAnnotation]
+	if !ok {
+		return nil
+	}
+	sa := &corev1.ServiceAccount{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: r.OperandNamespace, Name: name}, sa); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("service account %s/%s specified by annotation %s does not exist", r.OperandNamespace, name, routerServiceAccountAnnotation)
+		}
+		return fmt.Errorf("failed to get router service account %s/%s: %v", r.OperandNamespace, name, err)
+	}
+	return nil
+}
+
+// compressionMIMETypes returns the lowercased MIME types in the
+// ingresscontroller's compression annotation, without duplicates.
+func compressionMIMETypes(ci *operatorv1.IngressController) []string {
+	mimeTypes := []string{}
+	seen := map[string]bool{}
+	for _, mimeType := range strings.Split(ci.Annotations[compressionMIMETypesAnnotation], ",") {
+		mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+		if len(mimeType) == 0 || seen[mimeType] {
+			continue
+		}
+		seen[mimeType] = true
+		mimeTypes = append(mimeTypes, mimeType)
+	}
+	return mimeTypes
 }
 
 // ensureRouterDeleted ensures that any router resources associated with the
 // ingresscontroller are deleted.
 func (r *reconciler) ensureRouterDeleted(ci *operatorv1.IngressController) error {
 	deployment := &appsv1.Deployment{}
-	name := RouterDeploymentName(ci)
+	name := RouterDeploymentName(ci, r.OperandNamespace)
 	deployment.Name = name.Name
 	deployment.Namespace = name.Namespace
 	if err := r.client.Delete(context.TODO(), deployment); err != nil {
@@ -61,9 +298,9 @@ func (r *reconciler) ensureRouterDeleted(ci *operatorv1.IngressController) error
 }
 
 // desiredRouterDeployment returns the desired router deployment.
-func desiredRouterDeployment(ci *operatorv1.IngressController, ingressControllerImage string, infraConfig *configv1.Infrastructure) (*appsv1.Deployment, error) {
+func desiredRouterDeployment(ci *operatorv1.IngressController, namespace string, ingressControllerImage string, infraConfig *configv1.Infrastructure) (*appsv1.Deployment, error) {
 	deployment := manifests.RouterDeployment()
-	name := RouterDeploymentName(ci)
+	name := RouterDeploymentName(ci, namespace)
 	deployment.Name = name.Name
 	deployment.Namespace = name.Namespace
 
@@ -109,26 +346,30 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 			MaxSurge:       pointerTo(intstr.FromInt(0)),
 		},
 	}
+	deployment.Spec.MinReadySeconds = routerMinReadySeconds(ci)
 
-	statsSecretName := fmt.Sprintf("router-stats-%s", ci.Name)
 	env := []corev1.EnvVar{
 		{Name: "ROUTER_SERVICE_NAME", Value: ci.Name},
-		{Name: "STATS_USERNAME", ValueFrom: &corev1.EnvVarSource{
+		{Name: "ROUTER_SERVICE_NAMESPACE", Value: namespace},
+	}
+	if metricsIntegrationEnabled(ci) {
+		statsSecretName := fmt.Sprintf("router-stats-%s", ci.Name)
+		env = append(env, corev1.EnvVar{Name: "STATS_USERNAME", ValueFrom: &corev1.EnvVarSource{
 			SecretKeyRef: &corev1.SecretKeySelector{
 				LocalObjectReference: corev1.LocalObjectReference{
 					Name: statsSecretName,
 				},
 				Key: "statsUsername",
 			},
-		}},
-		{Name: "STATS_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+		}})
+		env = append(env, corev1.EnvVar{Name: "STATS_PASSWORD", ValueFrom: &corev1.EnvVarSource{
 			SecretKeyRef: &corev1.SecretKeySelector{
 				LocalObjectReference: corev1.LocalObjectReference{
 					Name: statsSecretName,
 				},
 				Key: "statsPassword",
 			},
-		}},
+		}})
 	}
 
 	// Enable prometheus metrics
@@ -153,7 +394,9 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
 	deployment.Spec.Template.Spec.Containers[0].VolumeMounts = append(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, volumeMount)
 
-	env = append(env, corev1.EnvVar{Name: "ROUTER_METRICS_TYPE", Value: "haproxy"})
+	if metricsIntegrationEnabled(ci) {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_METRICS_TYPE", Value: "haproxy"})
+	}
 	env = append(env, corev1.EnvVar{Name: "ROUTER_METRICS_TLS_CERT_FILE", Value: filepath.Join(certsVolumeMountPath, "tls.crt")})
 	env = append(env, corev1.EnvVar{Name: "ROUTER_METRICS_TLS_KEY_FILE", Value: filepath.Join(certsVolumeMountPath, "tls.key")})
 
@@ -161,6 +404,15 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		env = append(env, corev1.EnvVar{Name: "ROUTER_CANONICAL_HOSTNAME", Value: ci.Status.Domain})
 	}
 
+	// The router always enforces the haproxy.router.openshift.io/ip_whitelist
+	// route annotation against the source address of the connection; it
+	// does not consult X-Forwarded-For or other forwarded headers, which
+	// clients can forge.  The whitelist is therefore only meaningful if the
+	// router sees the client's address.  With the HostNetwork strategy, and
+	// with load balancers that preserve the source address because the
+	// service uses externalTrafficPolicy Local, it does.  AWS ELBs proxy
+	// connections, so the router must use the PROXY protocol to learn the
+	// client's address there.
 	if ci.Status.EndpointPublishingStrategy.Type == operatorv1.LoadBalancerServiceStrategyType {
 		// For now, check if we are on AWS. This can really be done for
 		// for any external [cloud] LBs that support the proxy protocol.
@@ -169,27 +421,87 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		}
 	}
 
-	env = append(env, corev1.EnvVar{Name: "ROUTER_THREADS", Value: "4"})
+	if v, ok := ci.Annotations[routerCPULimitAnnotation]; ok {
+		if limit, err := resource.ParseQuantity(v); err == nil {
+			container := &deployment.Spec.Template.Spec.Containers[0]
+			if container.Resources.Limits == nil {
+				container.Resources.Limits = corev1.ResourceList{}
+			}
+			container.Resources.Limits[corev1.ResourceCPU] = limit
+		}
+	}
 
-	nodeSelector := map[string]string{
-		"beta.kubernetes.io/os":          "linux",
-		"node-role.kubernetes.io/worker": "",
+	threads := routerThreads(ci, deployment.Spec.Template.Spec.Containers[0].Resources)
+	env = append(env, corev1.EnvVar{Name: "ROUTER_THREADS", Value: strconv.Itoa(threads)})
+
+	if policy, ok := ci.Annotations[defaultInsecureEdgeTerminationPolicyAnnotation]; ok {
+		if value, ok := insecureEdgeTerminationPolicies[policy]; ok {
+			env = append(env, corev1.EnvVar{Name: "ROUTER_DEFAULT_INSECURE_EDGE_TERMINATION_POLICY", Value: value})
+		}
 	}
-	if ci.Spec.NodePlacement != nil {
-		if ci.Spec.NodePlacement.NodeSelector != nil {
-			var err error
-			nodeSelector, err = metav1.LabelSelectorAsMap(ci.Spec.NodePlacement.NodeSelector)
-			if err != nil {
-				return nil, fmt.Errorf("ingresscontroller %q has invalid spec.nodePlacement.nodeSelector: %v",
-					ci.Name, err)
-			}
+
+	if name, ok := ci.Annotations[uniqueIDHeaderNameAnnotation]; ok {
+		format := defaultUniqueIDFormat
+		if v, ok := ci.Annotations[uniqueIDFormatAnnotation]; ok {
+			format = v
 		}
-		if ci.Spec.NodePlacement.Tolerations != nil {
-			deployment.Spec.Template.Spec.Tolerations = ci.Spec.NodePlacement.Tolerations
+		env = append(env,
+			corev1.EnvVar{Name: "ROUTER_UNIQUE_ID_HEADER_NAME", Value: name},
+			corev1.EnvVar{Name: "ROUTER_UNIQUE_ID_FORMAT", Value: format},
+		)
+	}
+
+	if v, ok := ci.Annotations[maxConnectionsAnnotation]; ok {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_MAX_CONNECTIONS", Value: v})
+	}
+
+	env = append(env, headerBufferEnv(ci)...)
+	env = append(env, dynamicServersEnv(ci)...)
+
+	if v, ok := ci.Annotations[tunnelTimeoutAnnotation]; ok {
+		if d, err := time.ParseDuration(v); err == nil {
+			env = append(env, corev1.EnvVar{Name: "ROUTER_DEFAULT_TUNNEL_TIMEOUT", Value: haproxyDuration(d)})
 		}
 	}
+
+	env = append(env, idleConnectionTimeoutEnv(ci)...)
+
+	env = append(env, perRouteMetricsEnv(ci)...)
+
+	if v, ok := ci.Annotations[sessionCookieNameAnnotation]; ok {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_COOKIE_NAME", Value: v})
+	}
+
+	env = append(env, defaultRouteSettingsEnv(ci)...)
+
+	env = append(env, weightedBalancingEnv(ci)...)
+
+	if mimeTypes := compressionMIMETypes(ci); len(mimeTypes) != 0 {
+		env = append(env,
+			corev1.EnvVar{Name: "ROUTER_ENABLE_COMPRESSION", Value: "true"},
+			corev1.EnvVar{Name: "ROUTER_COMPRESSION_MIME", Value: strings.Join(mimeTypes, " ")},
+		)
+	}
+
+	if name, ok := ci.Annotations[backendCABundleConfigMapAnnotation]; ok {
+		applyBackendCABundle(deployment, name)
+	}
+
+	nodeSelector, err := routerNodeSelector(ci)
+	if err != nil {
+		return nil, err
+	}
+	if ci.Spec.NodePlacement != nil && ci.Spec.NodePlacement.Tolerations != nil {
+		deployment.Spec.Template.Spec.Tolerations = ci.Spec.NodePlacement.Tolerations
+	}
 	deployment.Spec.Template.Spec.NodeSelector = nodeSelector
 
+	if name, ok := ci.Annotations[routerServiceAccountAnnotation]; ok {
+		deployment.Spec.Template.Spec.ServiceAccountName = name
+	}
+
+	setPodSysctls(deployment, routerSysctls(ci))
+
 	if ci.Spec.NamespaceSelector != nil {
 		namespaceSelector, err := metav1.LabelSelectorAsSelector(ci.Spec.NamespaceSelector)
 		if err != nil {
@@ -209,7 +521,12 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	}
 	deployment.Spec.Replicas = &desiredReplicas
 
-	if ci.Spec.RouteSelector != nil {
+	switch {
+	case maintenanceModeEnabled(ci):
+		// Select no routes so that the router responds to every
+		// request with 503.
+		env = append(env, corev1.EnvVar{Name: "ROUTE_LABELS", Value: maintenanceRouteSelector})
+	case ci.Spec.RouteSelector != nil:
 		routeSelector, err := metav1.LabelSelectorAsSelector(ci.Spec.RouteSelector)
 		if err != nil {
 			return nil, fmt.Errorf("ingresscontroller %q has invalid spec.routeSelector: %v", ci.Name, err)
@@ -221,6 +538,8 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 
 	deployment.Spec.Template.Spec.Containers[0].Image = ingressControllerImage
 
+	applyRouterSecurityContext(deployment, routerSecurityContext(ci))
+
 	if ci.Status.EndpointPublishingStrategy.Type == operatorv1.HostNetworkStrategyType {
 		// Expose ports 80 and 443 on the host to provide endpoints for
 		// the user's HA solution.
@@ -234,6 +553,10 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		deployment.Spec.Template.Spec.Containers[0].ReadinessProbe.Handler.HTTPGet.Host = "localhost"
 	}
 
+	applyRouterProbes(ci, deployment)
+
+	applyRouterPodDNS(ci, deployment)
+
 	// Fill in the default certificate secret name.
 	secretName := RouterEffectiveDefaultCertificateSecretName(ci, deployment.Namespace)
 	deployment.Spec.Template.Spec.Volumes[0].Secret.SecretName = secretName.Name
@@ -241,10 +564,59 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	return deployment, nil
 }
 
+// routerNodeSelector returns the node selector of the router pods of the given
+// ingresscontroller.
+func routerNodeSelector(ci *operatorv1.IngressController) (map[string]string, error) {
+	if ci.Spec.NodePlacement == nil || ci.Spec.NodePlacement.NodeSelector == nil {
+		return map[string]string{
+			"beta.kubernetes.io/os":          "linux",
+			"node-role.kubernetes.io/worker": "",
+		}, nil
+	}
+	nodeSelector, err := metav1.LabelSelectorAsMap(ci.Spec.NodePlacement.NodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("ingresscontroller %q has invalid spec.nodePlacement.nodeSelector: %v",
+			ci.Name, err)
+	}
+	return nodeSelector, nil
+}
+
+// routerThreads returns the number of router threads for the given
+// ingresscontroller and router container resources.  With "auto", each whole
+// or partial CPU of the limit gets one thread, up to maxRouterThreads.
+func routerThreads(ci *operatorv1.IngressController, resources corev1.ResourceRequirements) int {
+	v, ok := ci.Annotations[routerThreadsAnnotation]
+	if !ok {
+		return defaultRouterThreads
+	}
+	if v != "auto" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+		return defaultRouterThreads
+	}
+	limit, ok := resources.Limits[corev1.ResourceCPU]
+	if !ok || limit.IsZero() {
+		return defaultRouterThreads
+	}
+	threads := int((limit.MilliValue() + 999) / 1000)
+	if threads > maxRouterThreads {
+		threads = maxRouterThreads
+	}
+	return threads
+}
+
+// haproxyDuration formats the given duration as a whole number of
+// milliseconds, which HAProxy accepts for any timeout.  Go's duration format
+// is not understood by HAProxy for compound values such as "1h30m".
+func haproxyDuration(d time.Duration) string {
+	return fmt.Sprintf("%dms", int64(d/time.Millisecond))
+}
+
 // currentRouterDeployment returns the current router deployment.
 func (r *reconciler) currentRouterDeployment(ci *operatorv1.IngressController) (*appsv1.Deployment, error) {
 	deployment := &appsv1.Deployment{}
-	if err := r.client.Get(context.TODO(), RouterDeploymentName(ci), deployment); err != nil {
+	if err := r.client.Get(context.TODO(), RouterDeploymentName(ci, r.OperandNamespace), deployment); err != nil {
 		if errors.IsNotFound(err) {
 			return nil, nil
 		}
@@ -254,45 +626,60 @@ func (r *reconciler) currentRouterDeployment(ci *operatorv1.IngressController) (
 }
 
 // createRouterDeployment creates a router deployment.
-func (r *reconciler) createRouterDeployment(deployment *appsv1.Deployment) error {
+func (r *reconciler) createRouterDeployment(ci *operatorv1.IngressController, deployment *appsv1.Deployment) error {
 	if err := r.client.Create(context.TODO(), deployment); err != nil {
 		return fmt.Errorf("failed to create router deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
 	}
-	log.Info("created router deployment", "namespace", deployment.Namespace, "name", deployment.Name)
+	stepLogger(ci, "deployment").Info("created router deployment", "namespace", deployment.Namespace, "name", deployment.Name)
 	return nil
 }
 
 // updateRouterDeployment updates a router deployment.
-func (r *reconciler) updateRouterDeployment(current, desired *appsv1.Deployment) error {
+func (r *reconciler) updateRouterDeployment(ci *operatorv1.IngressController, current, desired *appsv1.Deployment) error {
 	changed, updated := deploymentConfigChanged(current, desired)
 	if !changed {
+		updated = current.DeepCopy()
+	}
+	if metadataChanged := updateOperandMetadata(updated, desired, r.OperandLabels, r.OperandAnnotations); !changed && !metadataChanged {
 		return nil
 	}
 
 	if err := r.client.Update(context.TODO(), updated); err != nil {
 		return fmt.Errorf("failed to update router deployment %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
-	log.Info("updated router deployment", "namespace", updated.Namespace, "name", updated.Name)
+	stepLogger(ci, "deployment").Info("updated router deployment", "namespace", updated.Namespace, "name", updated.Name)
 	return nil
 }
 
 // deploymentConfigChanged checks if current config matches the expected config
 // for the ingress controller deployment and if not returns the updated config.
 func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv1.Deployment) {
-	if cmp.Equal(current.Spec.Template.Spec.Volumes, expected.Spec.Template.Spec.Volumes, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpVolumes), cmp.Comparer(cmpSecretVolumeSource)) &&
+	if cmp.Equal(current.Spec.Template.Spec.Volumes, expected.Spec.Template.Spec.Volumes, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpVolumes), cmp.Comparer(cmpSecretVolumeSource), cmp.Comparer(cmpConfigMapVolumeSource)) &&
 		cmp.Equal(current.Spec.Template.Spec.NodeSelector, expected.Spec.Template.Spec.NodeSelector, cmpopts.EquateEmpty()) &&
 		cmp.Equal(current.Spec.Template.Spec.Containers[0].Env, expected.Spec.Template.Spec.Containers[0].Env, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpEnvs)) &&
+		cmp.Equal(current.Spec.Template.Spec.Containers[0].VolumeMounts, expected.Spec.Template.Spec.Containers[0].VolumeMounts, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpVolumeMounts)) &&
 		current.Spec.Template.Spec.Containers[0].Image == expected.Spec.Template.Spec.Containers[0].Image &&
-		cmp.Equal(current.Spec.Template.Spec.Tolerations, expected.Spec.Template.Spec.Tolerations, cmpopts.EquateEmpty(), cmpopts.SortSlices(cmpTolerations)) &&
+		cmp.Equal(current.Spec.Template.Spec.Containers[0].Resources, expected.Spec.Template.Spec.Containers[0].Resources, cmpopts.EquateEmpty(), cmp.Comparer(cmpQuantities)) &&
+		cmpProbes(current.Spec.Template.Spec.Containers[0].LivenessProbe, expected.Spec.Template.Spec.Containers[0].LivenessProbe) &&
+		cmpProbes(current.Spec.Template.Spec.Containers[0].ReadinessProbe, expected.Spec.Template.Spec.Containers[0].ReadinessProbe) &&
+		cmp.Equal(current.Spec.Template.Spec.Tolerations, expected.Spec.Template.Spec.Tolerations, cmpopts.EquateEmpty(), cmpopts.SortSlices(lessTolerations), cmp.Comparer(cmpTolerations)) &&
 		cmp.Equal(current.Spec.Template.Spec.Affinity, expected.Spec.Template.Spec.Affinity, cmpopts.EquateEmpty()) &&
+		cmp.Equal(podSysctls(current), podSysctls(expected), cmpopts.EquateEmpty()) &&
+		routerSecurityContextEqual(current, expected) &&
+		current.Spec.Template.Spec.ServiceAccountName == expected.Spec.Template.Spec.ServiceAccountName &&
+		current.Spec.Template.Spec.DNSPolicy == expected.Spec.Template.Spec.DNSPolicy &&
+		cmp.Equal(current.Spec.Template.Spec.DNSConfig, expected.Spec.Template.Spec.DNSConfig, cmpopts.EquateEmpty()) &&
 		cmp.Equal(current.Spec.Strategy, expected.Spec.Strategy, cmpopts.EquateEmpty()) &&
+		current.Spec.MinReadySeconds == expected.Spec.MinReadySeconds &&
 		current.Spec.Replicas != nil &&
-		*current.Spec.Replicas == *expected.Spec.Replicas {
+		*current.Spec.Replicas == *expected.Spec.Replicas &&
+		podTemplateHashAnnotationsEqual(current, expected) {
 		return false, nil
 	}
 
 	updated := current.DeepCopy()
 	updated.Spec.Strategy = expected.Spec.Strategy
+	updated.Spec.MinReadySeconds = expected.Spec.MinReadySeconds
 	volumes := make([]corev1.Volume, len(expected.Spec.Template.Spec.Volumes))
 	for i, vol := range expected.Spec.Template.Spec.Volumes {
 		volumes[i] = *vol.DeepCopy()
@@ -300,9 +687,29 @@ func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv
 	updated.Spec.Template.Spec.Volumes = volumes
 	updated.Spec.Template.Spec.NodeSelector = expected.Spec.Template.Spec.NodeSelector
 	updated.Spec.Template.Spec.Containers[0].Env = expected.Spec.Template.Spec.Containers[0].Env
+	updated.Spec.Template.Spec.Containers[0].VolumeMounts = expected.Spec.Template.Spec.Containers[0].VolumeMounts
 	updated.Spec.Template.Spec.Containers[0].Image = expected.Spec.Template.Spec.Containers[0].Image
+	updated.Spec.Template.Spec.Containers[0].Resources = expected.Spec.Template.Spec.Containers[0].Resources
+	updated.Spec.Template.Spec.Containers[0].LivenessProbe = expected.Spec.Template.Spec.Containers[0].LivenessProbe
+	updated.Spec.Template.Spec.Containers[0].ReadinessProbe = expected.Spec.Template.Spec.Containers[0].ReadinessProbe
 	updated.Spec.Template.Spec.Tolerations = expected.Spec.Template.Spec.Tolerations
 	updated.Spec.Template.Spec.Affinity = expected.Spec.Template.Spec.Affinity
+	updated.Spec.Template.Spec.ServiceAccountName = expected.Spec.Template.Spec.ServiceAccountName
+	updated.Spec.Template.Spec.DNSPolicy = expected.Spec.Template.Spec.DNSPolicy
+	updated.Spec.Template.Spec.DNSConfig = expected.Spec.Template.Spec.DNSConfig
+	updated.Spec.Template.Spec.Containers[0].SecurityContext = expected.Spec.Template.Spec.Containers[0].SecurityContext
+	updated.Spec.Template.Spec.InitContainers = expected.Spec.Template.Spec.InitContainers
+	setPodSysctls(updated, podSysctls(expected))
+	for _, annotation := range podTemplateHashAnnotations {
+		if hash, ok := expected.Spec.Template.Annotations[annotation]; ok {
+			if updated.Spec.Template.Annotations == nil {
+				updated.Spec.Template.Annotations = map[string]string{}
+			}
+			updated.Spec.Template.Annotations[annotation] = hash
+		} else {
+			delete(updated.Spec.Template.Annotations, annotation)
+		}
+	}
 	replicas := int32(1)
 	if expected.Spec.Replicas != nil {
 		replicas = *expected.Spec.Replicas
@@ -337,6 +744,81 @@ func cmpSecretVolumeSource(a, b corev1.SecretVolumeSource) bool {
 	return true
 }
 
+func cmpVolumeMounts(a, b corev1.VolumeMount) bool { return a.Name < b.Name }
+
+// cmpConfigMapVolumeSource compares two configmap volume sources, ignoring the
+// default mode when it is left unspecified and defaulted by the API server.
+func cmpConfigMapVolumeSource(a, b corev1.ConfigMapVolumeSource) bool {
+	if a.Name != b.Name {
+		return false
+	}
+	if !cmp.Equal(a.Items, b.Items, cmpopts.EquateEmpty()) {
+		return false
+	}
+	aDefaultMode := int32(420)
+	if a.DefaultMode != nil {
+		aDefaultMode = *a.DefaultMode
+	}
+	bDefaultMode := int32(420)
+	if b.DefaultMode != nil {
+		bDefaultMode = *b.DefaultMode
+	}
+	if aDefaultMode != bDefaultMode {
+		return false
+	}
+	return cmp.Equal(a.Optional, b.Optional, cmpopts.EquateEmpty())
+}
+
+func cmpQuantities(a, b resource.Quantity) bool { return a.Cmp(b) == 0 }
+
+// cmpProbes compares two probes, ignoring fields that are defaulted by the
+// API server when they are left unspecified.
+func cmpProbes(a, b *corev1.Probe) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return cmp.Equal(withProbeDefaults(a), withProbeDefaults(b), cmpopts.EquateEmpty())
+}
+
+// withProbeDefaults returns a copy of the given probe with the API server's
+// defaults filled in for any unspecified fields.
+func withProbeDefaults(probe *corev1.Probe) *corev1.Probe {
+	p := probe.DeepCopy()
+	if p.TimeoutSeconds == 0 {
+		p.TimeoutSeconds = 1
+	}
+	if p.PeriodSeconds == 0 {
+		p.PeriodSeconds = 10
+	}
+	if p.SuccessThreshold == 0 {
+		p.SuccessThreshold = 1
+	}
+	if p.FailureThreshold == 0 {
+		p.FailureThreshold = 3
+	}
+	if p.Handler.HTTPGet != nil && len(p.Handler.HTTPGet.Scheme) == 0 {
+		p.Handler.HTTPGet.Scheme = corev1.URISchemeHTTP
+	}
+	return p
+}
+
+// lessTolerations orders tolerations by key, value, operator, and effect so
+// that tolerations can be compared irrespective of their order.
+func lessTolerations(a, b corev1.Toleration) bool {
+	if a.Key != b.Key {
+		return a.Key < b.Key
+	}
+	if a.Value != b.Value {
+		return a.Value < b.Value
+	}
+	if a.Operator != b.Operator {
+		return a.Operator < b.Operator
+	}
+	return a.Effect < b.Effect
+}
+
+// cmpTolerations compares two tolerations, ignoring tolerationSeconds unless
+// the effect is NoExecute.
 func cmpTolerations(a, b corev1.Toleration) bool {
 	if a.Key != b.Key {
 		return false
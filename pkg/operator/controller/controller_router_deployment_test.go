@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -56,7 +58,7 @@ func TestDesiredRouterDeployment(t *testing.T) {
 		},
 	}
 
-	deployment, err := desiredRouterDeployment(ci, ingressControllerImage, infraConfig)
+	deployment, err := desiredRouterDeployment(ci, "openshift-ingress", ingressControllerImage, infraConfig)
 	if err != nil {
 		t.Errorf("invalid router Deployment: %v", err)
 	}
@@ -151,7 +153,7 @@ func TestDesiredRouterDeployment(t *testing.T) {
 
 	ci.Status.Domain = "example.com"
 	ci.Status.EndpointPublishingStrategy.Type = operatorv1.LoadBalancerServiceStrategyType
-	deployment, err = desiredRouterDeployment(ci, ingressControllerImage, infraConfig)
+	deployment, err = desiredRouterDeployment(ci, "openshift-ingress", ingressControllerImage, infraConfig)
 	if err != nil {
 		t.Errorf("invalid router Deployment: %v", err)
 	}
@@ -206,7 +208,7 @@ func TestDesiredRouterDeployment(t *testing.T) {
 	var expectedReplicas int32 = 3
 	ci.Spec.Replicas = &expectedReplicas
 	ci.Status.EndpointPublishingStrategy.Type = operatorv1.HostNetworkStrategyType
-	deployment, err = desiredRouterDeployment(ci, ingressControllerImage, infraConfig)
+	deployment, err = desiredRouterDeployment(ci, "openshift-ingress", ingressControllerImage, infraConfig)
 	if err != nil {
 		t.Errorf("invalid router Deployment: %v", err)
 	}
@@ -312,6 +314,22 @@ func TestDeploymentConfigChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if .spec.template.spec.tolerations change ordering",
+			mutate: func(deployment *appsv1.Deployment) {
+				tolerations := deployment.Spec.Template.Spec.Tolerations
+				tolerations[1], tolerations[0] = tolerations[0], tolerations[1]
+			},
+			expect: false,
+		},
+		{
+			description: "if tolerationSeconds is set on a NoSchedule toleration",
+			mutate: func(deployment *appsv1.Deployment) {
+				seconds := int64(60)
+				deployment.Spec.Template.Spec.Tolerations[1].TolerationSeconds = &seconds
+			},
+			expect: false,
+		},
 		{
 			description: "if ROUTER_CANONICAL_HOSTNAME changes",
 			mutate: func(deployment *appsv1.Deployment) {
@@ -387,6 +405,87 @@ func TestDeploymentConfigChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if an env value is changed",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].Env[2].Value = "foo=baz"
+			},
+			expect: true,
+		},
+		{
+			description: "if .spec.replicas is changed",
+			mutate: func(deployment *appsv1.Deployment) {
+				replicas := int32(2)
+				deployment.Spec.Replicas = &replicas
+			},
+			expect: true,
+		},
+		{
+			description: "if the container resources are changed",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("200m")
+			},
+			expect: true,
+		},
+		{
+			description: "if the container resources are removed",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{}
+			},
+			expect: true,
+		},
+		{
+			description: "if the container resources are equal but formatted differently",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("0.1")
+			},
+			expect: false,
+		},
+		{
+			description: "if the liveness probe path is changed",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].LivenessProbe.Handler.HTTPGet.Path = "/foo"
+			},
+			expect: true,
+		},
+		{
+			description: "if the readiness probe is removed",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].ReadinessProbe = nil
+			},
+			expect: true,
+		},
+		{
+			description: "if the readiness probe failure threshold is changed",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].ReadinessProbe.FailureThreshold = 10
+			},
+			expect: true,
+		},
+		{
+			description: "if probe fields are defaulted by the API server",
+			mutate: func(deployment *appsv1.Deployment) {
+				for _, probe := range []*corev1.Probe{
+					deployment.Spec.Template.Spec.Containers[0].LivenessProbe,
+					deployment.Spec.Template.Spec.Containers[0].ReadinessProbe,
+				} {
+					probe.TimeoutSeconds = 1
+					probe.PeriodSeconds = 10
+					probe.SuccessThreshold = 1
+					probe.FailureThreshold = 3
+					probe.Handler.HTTPGet.Scheme = corev1.URISchemeHTTP
+				}
+			},
+			expect: false,
+		},
+		{
+			description: "if the deployment status changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Status.AvailableReplicas = 5
+				deployment.Status.ObservedGeneration = 7
+			},
+			expect: false,
+		},
 		{
 			description: "if the deployment template affinity is changed",
 			mutate: func(deployment *appsv1.Deployment) {
@@ -394,6 +493,50 @@ func TestDeploymentConfigChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if the service account name changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.ServiceAccountName = "custom-router"
+			},
+			expect: true,
+		},
+		{
+			description: "if sysctls are added",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{
+					Sysctls: []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "4096"}},
+				}
+			},
+			expect: true,
+		},
+		{
+			description: "if an empty security context is added",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+			},
+			expect: false,
+		},
+		{
+			description: "if min ready seconds changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.MinReadySeconds = 30
+			},
+			expect: true,
+		},
+		{
+			description: "if the DNS policy changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+			},
+			expect: true,
+		},
+		{
+			description: "if the DNS config changes",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.DNSConfig = &corev1.PodDNSConfig{Nameservers: []string{"192.0.2.53"}}
+			},
+			expect: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -451,6 +594,42 @@ func TestDeploymentConfigChanged(t *testing.T) {
 									},
 								},
 								Image: "openshift/origin-cluster-ingress-operator:v4.0",
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("100m"),
+										corev1.ResourceMemory: resource.MustParse("256Mi"),
+									},
+								},
+								LivenessProbe: &corev1.Probe{
+									Handler: corev1.Handler{
+										HTTPGet: &corev1.HTTPGetAction{
+											Path: "/healthz",
+											Port: intstr.FromInt(1936),
+										},
+									},
+									InitialDelaySeconds: 10,
+								},
+								ReadinessProbe: &corev1.Probe{
+									Handler: corev1.Handler{
+										HTTPGet: &corev1.HTTPGetAction{
+											Path: "/healthz",
+											Port: intstr.FromInt(1936),
+										},
+									},
+									InitialDelaySeconds: 10,
+								},
+							},
+						},
+						Tolerations: []corev1.Toleration{
+							{
+								Key:      "node-role.kubernetes.io/infra",
+								Operator: corev1.TolerationOpExists,
+								Effect:   corev1.TaintEffectNoSchedule,
+							},
+							{
+								Key:      "node-role.kubernetes.io/master",
+								Operator: corev1.TolerationOpExists,
+								Effect:   corev1.TaintEffectNoSchedule,
 							},
 						},
 						Affinity: &corev1.Affinity{
@@ -487,3 +666,678 @@ func TestDeploymentConfigChanged(t *testing.T) {
 		}
 	}
 }
+
+func TestDesiredRouterDeploymentDefaultInsecureEdgeTerminationPolicy(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expect      string
+	}{
+		{"unset", nil, ""},
+		{"Allow", map[string]string{defaultInsecureEdgeTerminationPolicyAnnotation: "Allow"}, "Allow"},
+		{"Redirect", map[string]string{defaultInsecureEdgeTerminationPolicyAnnotation: "Redirect"}, "Redirect"},
+		{"Disable", map[string]string{defaultInsecureEdgeTerminationPolicyAnnotation: "Disable"}, "None"},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: tc.annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("%q: invalid router Deployment: %v", tc.description, err)
+		}
+		actual := ""
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == "ROUTER_DEFAULT_INSECURE_EDGE_TERMINATION_POLICY" {
+				actual = envVar.Value
+				break
+			}
+		}
+		if actual != tc.expect {
+			t.Errorf("%q: expected ROUTER_DEFAULT_INSECURE_EDGE_TERMINATION_POLICY to be %q, got %q", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+// TestEnsureRouterDeploymentIsIdempotent verifies that reconciling an unchanged
+// ingresscontroller does not update the router deployment, even after the API
+// server has defaulted and reordered fields in the stored deployment.
+func TestEnsureRouterDeploymentIsIdempotent(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Spec: operatorv1.IngressControllerSpec{
+			NodePlacement: &operatorv1.NodePlacement{
+				Tolerations: []corev1.Toleration{
+					{
+						Key:      "node-role.kubernetes.io/infra",
+						Operator: corev1.TolerationOpExists,
+						Effect:   corev1.TaintEffectNoSchedule,
+					},
+					toleration,
+				},
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	client := newFakeClient()
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", IngressControllerImage: "quay.io/openshift/router:latest"},
+		client: client,
+	}
+
+	if _, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to create router deployment: %v", err)
+	}
+	if client.calls["create"] != 1 {
+		t.Fatalf("expected 1 create, got %d", client.calls["create"])
+	}
+
+	// Simulate the API server's defaulting and normalization of the
+	// stored deployment.
+	deployment, err := r.currentRouterDeployment(ci)
+	if err != nil || deployment == nil {
+		t.Fatalf("failed to get router deployment: %v", err)
+	}
+	podSpec := &deployment.Spec.Template.Spec
+	container := &podSpec.Containers[0]
+	for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe} {
+		probe.TimeoutSeconds = 1
+		probe.PeriodSeconds = 10
+		probe.SuccessThreshold = 1
+		probe.FailureThreshold = 3
+		probe.Handler.HTTPGet.Scheme = corev1.URISchemeHTTP
+	}
+	container.Resources.Requests[corev1.ResourceCPU] = resource.MustParse("0.1")
+	env := container.Env
+	env[0], env[len(env)-1] = env[len(env)-1], env[0]
+	for i := range podSpec.Volumes {
+		if podSpec.Volumes[i].Secret != nil && podSpec.Volumes[i].Secret.DefaultMode == nil {
+			mode := int32(420)
+			podSpec.Volumes[i].Secret.DefaultMode = &mode
+		}
+	}
+	podSpec.Volumes[0], podSpec.Volumes[1] = podSpec.Volumes[1], podSpec.Volumes[0]
+	podSpec.Tolerations[0], podSpec.Tolerations[1] = podSpec.Tolerations[1], podSpec.Tolerations[0]
+	if err := client.replace(deployment); err != nil {
+		t.Fatalf("failed to store defaulted router deployment: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+			t.Fatalf("failed to reconcile router deployment: %v", err)
+		}
+	}
+	if client.calls["update"] != 0 {
+		t.Errorf("expected reconciling an unchanged ingresscontroller to make no deployment updates, got %d", client.calls["update"])
+	}
+
+	// A real change must still be applied.
+	replicas := int32(3)
+	ci.Spec.Replicas = &replicas
+	if _, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if client.calls["update"] != 1 {
+		t.Errorf("expected 1 deployment update after changing replicas, got %d", client.calls["update"])
+	}
+}
+
+// TestEnsureRouterDeploymentDefaultCertificateRotation verifies that changing
+// the data of the default certificate secret rolls out the router pods and
+// that reconciling an unchanged secret does not.
+func TestEnsureRouterDeploymentDefaultCertificateRotation(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Spec: operatorv1.IngressControllerSpec{
+			DefaultCertificate: &corev1.LocalObjectReference{Name: "custom-cert"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "custom-cert"},
+		Data: map[string][]byte{
+			"tls.crt": []byte("cert-1"),
+			"tls.key": []byte("key-1"),
+		},
+	}
+	client := newFakeClient()
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", IngressControllerImage: "quay.io/openshift/router:latest"},
+		client: client,
+	}
+	certificateHash := func() (string, bool) {
+		deployment, err := r.currentRouterDeployment(ci)
+		if err != nil || deployment == nil {
+			t.Fatalf("failed to get router deployment: %v", err)
+		}
+		hash, ok := deployment.Spec.Template.Annotations[defaultCertificateHashAnnotation]
+		return hash, ok
+	}
+
+	// Without the secret, the pod template has no hash.
+	if _, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to create router deployment: %v", err)
+	}
+	if hash, ok := certificateHash(); ok {
+		t.Errorf("expected no annotation %s, got %q", defaultCertificateHashAnnotation, hash)
+	}
+
+	if err := client.Create(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	original, ok := certificateHash()
+	if !ok || original != secretDataHash(secret) {
+		t.Fatalf("expected annotation %s to be %q, got %q", defaultCertificateHashAnnotation, secretDataHash(secret), original)
+	}
+	updates := client.calls["update"]
+	if _, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if client.calls["update"] != updates {
+		t.Errorf("expected no deployment update for an unchanged secret, got %d", client.calls["update"]-updates)
+	}
+
+	rotated := secret.DeepCopy()
+	rotated.Data["tls.crt"] = []byte("cert-2")
+	if err := client.Update(context.TODO(), rotated); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if hash, _ := certificateHash(); hash == original || hash != secretDataHash(rotated) {
+		t.Errorf("expected annotation %s to be updated to %q, got %q", defaultCertificateHashAnnotation, secretDataHash(rotated), hash)
+	}
+}
+
+// TestEnsureRouterDeploymentServiceAccount verifies that the router pods run
+// under the service account that the ingresscontroller specifies only if it
+// exists, and under the operator-managed service account otherwise.
+func TestEnsureRouterDeploymentServiceAccount(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{routerServiceAccountAnnotation: "custom-router"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	client := newFakeClient()
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", IngressControllerImage: "quay.io/openshift/router:latest"},
+		client: client,
+	}
+
+	expectErr := "service account openshift-ingress/custom-router specified by annotation ingress.operator.openshift.io/router-service-account does not exist"
+	if _, err := r.ensureRouterDeployment(ci, infraConfig); err == nil || err.Error() != expectErr {
+		t.Fatalf("expected error %q, got %v", expectErr, err)
+	}
+	if client.calls["create"] != 0 {
+		t.Errorf("expected no deployment to be created, got %d creates", client.calls["create"])
+	}
+
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "custom-router"}}
+	if err := client.Create(context.TODO(), sa); err != nil {
+		t.Fatal(err)
+	}
+	deployment, err := r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to create router deployment: %v", err)
+	}
+	if name := deployment.Spec.Template.Spec.ServiceAccountName; name != "custom-router" {
+		t.Errorf("expected service account custom-router, got %q", name)
+	}
+
+	delete(ci.Annotations, routerServiceAccountAnnotation)
+	deployment, err = r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to update router deployment: %v", err)
+	}
+	if name := deployment.Spec.Template.Spec.ServiceAccountName; name != "router" {
+		t.Errorf("expected the operator-managed service account router, got %q", name)
+	}
+}
+
+func TestDesiredRouterDeploymentUniqueID(t *testing.T) {
+	testCases := []struct {
+		description  string
+		annotations  map[string]string
+		expectName   string
+		expectFormat string
+	}{
+		{"unset", nil, "", ""},
+		{
+			"header name only",
+			map[string]string{uniqueIDHeaderNameAnnotation: "X-Request-Id"},
+			"X-Request-Id",
+			defaultUniqueIDFormat,
+		},
+		{
+			"header name and format",
+			map[string]string{uniqueIDHeaderNameAnnotation: "X-Request-Id", uniqueIDFormatAnnotation: "%ci:%cp_%Ts"},
+			"X-Request-Id",
+			"%ci:%cp_%Ts",
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: tc.annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("%q: invalid router Deployment: %v", tc.description, err)
+		}
+		var name, format string
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			switch envVar.Name {
+			case "ROUTER_UNIQUE_ID_HEADER_NAME":
+				name = envVar.Value
+			case "ROUTER_UNIQUE_ID_FORMAT":
+				format = envVar.Value
+			}
+		}
+		if name != tc.expectName {
+			t.Errorf("%q: expected ROUTER_UNIQUE_ID_HEADER_NAME to be %q, got %q", tc.description, tc.expectName, name)
+		}
+		if format != tc.expectFormat {
+			t.Errorf("%q: expected ROUTER_UNIQUE_ID_FORMAT to be %q, got %q", tc.description, tc.expectFormat, format)
+		}
+	}
+}
+
+func TestDesiredRouterDeploymentMaxConnections(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	for _, expect := range []string{"", "50000"} {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		if len(expect) != 0 {
+			ci.Annotations = map[string]string{maxConnectionsAnnotation: expect}
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		actual := ""
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == "ROUTER_MAX_CONNECTIONS" {
+				actual = envVar.Value
+			}
+		}
+		if actual != expect {
+			t.Errorf("expected ROUTER_MAX_CONNECTIONS to be %q, got %q", expect, actual)
+		}
+	}
+}
+
+func TestDesiredRouterDeploymentSessionCookieName(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	for _, expect := range []string{"", "APP_SESSION"} {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		if len(expect) != 0 {
+			ci.Annotations = map[string]string{sessionCookieNameAnnotation: expect}
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		actual := ""
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == "ROUTER_COOKIE_NAME" {
+				actual = envVar.Value
+			}
+		}
+		if actual != expect {
+			t.Errorf("expected ROUTER_COOKIE_NAME to be %q, got %q", expect, actual)
+		}
+	}
+}
+
+func TestDesiredRouterDeploymentTunnelTimeout(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	testCases := []struct {
+		annotation string
+		expect     string
+	}{
+		{"", ""},
+		{"90s", "90000ms"},
+		{"1h30m", "5400000ms"},
+		{"250ms", "250ms"},
+	}
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		if len(tc.annotation) != 0 {
+			ci.Annotations = map[string]string{tunnelTimeoutAnnotation: tc.annotation}
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		actual := ""
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			switch envVar.Name {
+			case "ROUTER_DEFAULT_TUNNEL_TIMEOUT":
+				actual = envVar.Value
+			case "ROUTER_DEFAULT_SERVER_TIMEOUT":
+				t.Errorf("%q: expected the HTTP server timeout to be left unset, got %q", tc.annotation, envVar.Value)
+			}
+		}
+		if actual != tc.expect {
+			t.Errorf("%q: expected ROUTER_DEFAULT_TUNNEL_TIMEOUT to be %q, got %q", tc.annotation, tc.expect, actual)
+		}
+	}
+}
+
+// TestDesiredRouterDeploymentThreads verifies that the number of router
+// threads is the explicit value, is derived from the CPU limit for "auto", and
+// falls back to the default when "auto" is used without a CPU limit.
+func TestDesiredRouterDeploymentThreads(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	testCases := []struct {
+		description string
+		threads     string
+		cpuLimit    string
+		expect      string
+	}{
+		{"default", "", "", "4"},
+		{"explicit", "2", "", "2"},
+		{"explicit with a CPU limit", "2", "8", "2"},
+		{"auto without a CPU limit", "auto", "", "4"},
+		{"auto with whole CPUs", "auto", "8", "8"},
+		{"auto with a partial CPU", "auto", "2500m", "3"},
+		{"auto with less than one CPU", "auto", "500m", "1"},
+		{"auto with more CPUs than HAProxy supports", "auto", "100", "64"},
+	}
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: map[string]string{},
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		if len(tc.threads) != 0 {
+			ci.Annotations[routerThreadsAnnotation] = tc.threads
+		}
+		if len(tc.cpuLimit) != 0 {
+			ci.Annotations[routerCPULimitAnnotation] = tc.cpuLimit
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("%s: invalid router Deployment: %v", tc.description, err)
+		}
+		actual := ""
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == "ROUTER_THREADS" {
+				actual = envVar.Value
+			}
+		}
+		if actual != tc.expect {
+			t.Errorf("%s: expected ROUTER_THREADS to be %q, got %q", tc.description, tc.expect, actual)
+		}
+		limit, ok := deployment.Spec.Template.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU]
+		switch {
+		case len(tc.cpuLimit) == 0 && ok:
+			t.Errorf("%s: expected no CPU limit, got %s", tc.description, limit.String())
+		case len(tc.cpuLimit) != 0 && limit.String() != tc.cpuLimit:
+			t.Errorf("%s: expected CPU limit %s, got %s", tc.description, tc.cpuLimit, limit.String())
+		}
+	}
+}
+
+// TestDesiredRouterDeploymentCompression verifies that the compression MIME
+// types annotation enables compression for the listed types and that removing
+// every type disables compression.
+func TestDesiredRouterDeploymentCompression(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	desired := func(annotations map[string]string) *appsv1.Deployment {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				Domain: "apps.example.com",
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+				},
+			},
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		return deployment
+	}
+	compressionEnv := func(deployment *appsv1.Deployment) map[string]string {
+		env := map[string]string{}
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == "ROUTER_ENABLE_COMPRESSION" || envVar.Name == "ROUTER_COMPRESSION_MIME" {
+				env[envVar.Name] = envVar.Value
+			}
+		}
+		return env
+	}
+
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expect      map[string]string
+	}{
+		{
+			description: "annotation unset",
+			expect:      map[string]string{},
+		},
+		{
+			description: "no MIME types",
+			annotations: map[string]string{compressionMIMETypesAnnotation: " , "},
+			expect:      map[string]string{},
+		},
+		{
+			description: "MIME types",
+			annotations: map[string]string{compressionMIMETypesAnnotation: "text/html, Application/JSON,text/html"},
+			expect: map[string]string{
+				"ROUTER_ENABLE_COMPRESSION": "true",
+				"ROUTER_COMPRESSION_MIME":   "text/html application/json",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		if actual := compressionEnv(desired(tc.annotations)); !reflect.DeepEqual(actual, tc.expect) {
+			t.Errorf("%s: expected %v, got %v", tc.description, tc.expect, actual)
+		}
+	}
+
+	enabled := desired(map[string]string{compressionMIMETypesAnnotation: "text/html"})
+	disabled := desired(map[string]string{compressionMIMETypesAnnotation: ""})
+	changed, updated := deploymentConfigChanged(enabled, disabled)
+	if !changed {
+		t.Fatal("expected removing every MIME type to change the deployment")
+	}
+	if env := compressionEnv(updated); len(env) != 0 {
+		t.Errorf("expected compression to be disabled, got %v", env)
+	}
+}
+
+func TestDesiredRouterDeploymentMetricsIntegrationDisabled(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	for _, disabled := range []bool{false, true} {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		if disabled {
+			ci.Annotations = map[string]string{disableMetricsIntegrationAnnotation: "true"}
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("invalid router Deployment: %v", err)
+		}
+		found := map[string]bool{}
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			found[envVar.Name] = true
+		}
+		for _, name := range []string{"STATS_USERNAME", "STATS_PASSWORD", "ROUTER_METRICS_TYPE"} {
+			if found[name] == disabled {
+				t.Errorf("metrics integration disabled=%t: expected %s to be set=%t", disabled, name, !disabled)
+			}
+		}
+	}
+}
+
+func TestDesiredRouterDeploymentOperandNamespace(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	deployment, err := desiredRouterDeployment(ci, "custom-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if deployment.Namespace != "custom-ingress" {
+		t.Errorf("expected deployment namespace %q, got %q", "custom-ingress", deployment.Namespace)
+	}
+	var values []string
+	for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+		if envVar.Name == "ROUTER_SERVICE_NAMESPACE" {
+			values = append(values, envVar.Value)
+		}
+	}
+	if len(values) != 1 || values[0] != "custom-ingress" {
+		t.Errorf("expected ROUTER_SERVICE_NAMESPACE to be set once to %q, got %v", "custom-ingress", values)
+	}
+}
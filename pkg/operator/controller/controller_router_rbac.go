@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ensureRouterClusterRole ensures that the router cluster role exists and that
+// its rules match the desired rules.
+func (r *reconciler) ensureRouterClusterRole() error {
+	desired := manifests.RouterClusterRole()
+	current := &rbacv1.ClusterRole{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get router cluster role %s: %v", desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create router cluster role %s: %v", desired.Name, err)
+		}
+		log.Info("created router cluster role", "name", desired.Name)
+		return nil
+	}
+
+	if changed, updated := routerClusterRoleChanged(current, desired); changed {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update router cluster role %s: %v", updated.Name, err)
+		}
+		log.Info("updated router cluster role", "name", updated.Name)
+	}
+	return nil
+}
+
+// ensureRouterClusterRoleBinding ensures that the router cluster role binding
+// exists and that its subjects and role reference match the desired ones.
+// Because the role reference of a binding cannot be changed, a binding with a
+// different role reference is deleted and recreated.
+func (r *reconciler) ensureRouterClusterRoleBinding() error {
+	desired := manifests.RouterClusterRoleBinding(r.OperandNamespace)
+	current := &rbacv1.ClusterRoleBinding{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get router cluster role binding %s: %v", desired.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create router cluster role binding %s: %v", desired.Name, err)
+		}
+		log.Info("created router cluster role binding", "name", desired.Name)
+		return nil
+	}
+
+	if current.RoleRef != desired.RoleRef {
+		if err := r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete router cluster role binding %s: %v", current.Name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to recreate router cluster role binding %s: %v", desired.Name, err)
+		}
+		log.Info("recreated router cluster role binding", "name", desired.Name)
+		return nil
+	}
+
+	if changed, updated := routerClusterRoleBindingChanged(current, desired); changed {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update router cluster role binding %s: %v", updated.Name, err)
+		}
+		log.Info("updated router cluster role binding", "name", updated.Name)
+	}
+	return nil
+}
+
+// routerClusterRoleChanged checks if the current cluster role's rules match the
+// expected rules and if not returns an updated cluster role.
+func routerClusterRoleChanged(current, expected *rbacv1.ClusterRole) (bool, *rbacv1.ClusterRole) {
+	if cmp.Equal(current.Rules, expected.Rules, cmpopts.EquateEmpty()) {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Rules = expected.Rules
+	return true, updated
+}
+
+// routerClusterRoleBindingChanged checks if the current cluster role binding's
+// subjects match the expected subjects and if not returns an updated cluster
+// role binding.
+func routerClusterRoleBindingChanged(current, expected *rbacv1.ClusterRoleBinding) (bool, *rbacv1.ClusterRoleBinding) {
+	if cmp.Equal(current.Subjects, expected.Subjects, cmpopts.EquateEmpty()) {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Subjects = expected.Subjects
+	return true, updated
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestEnsureRouterNamespaceRestoresRBAC verifies that ensureRouterNamespace
+// restores a tampered router cluster role and cluster role binding and leaves
+// them alone once they match the desired state.
+func TestEnsureRouterNamespaceRestoresRBAC(t *testing.T) {
+	desiredRole := manifests.RouterClusterRole()
+	desiredBinding := manifests.RouterClusterRoleBinding("openshift-ingress")
+
+	testCases := []struct {
+		description   string
+		tamper        func(*rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding)
+		expectUpdates int
+		expectDeletes int
+	}{
+		{
+			description: "no drift",
+			tamper:      func(*rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding) {},
+		},
+		{
+			description: "rule removed",
+			tamper: func(cr *rbacv1.ClusterRole, _ *rbacv1.ClusterRoleBinding) {
+				cr.Rules = cr.Rules[1:]
+			},
+			expectUpdates: 1,
+		},
+		{
+			description: "verb removed",
+			tamper: func(cr *rbacv1.ClusterRole, _ *rbacv1.ClusterRoleBinding) {
+				cr.Rules[0].Verbs = cr.Rules[0].Verbs[:1]
+			},
+			expectUpdates: 1,
+		},
+		{
+			description: "subject changed",
+			tamper: func(_ *rbacv1.ClusterRole, crb *rbacv1.ClusterRoleBinding) {
+				crb.Subjects[0].Name = "someone-else"
+			},
+			expectUpdates: 1,
+		},
+		{
+			description: "role reference changed",
+			tamper: func(_ *rbacv1.ClusterRole, crb *rbacv1.ClusterRoleBinding) {
+				crb.RoleRef.Name = "cluster-admin"
+			},
+			expectDeletes: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		cr, crb := desiredRole.DeepCopy(), desiredBinding.DeepCopy()
+		cr.ResourceVersion, crb.ResourceVersion = "1", "1"
+		tc.tamper(cr, crb)
+		client := newFakeClient(cr, crb, manifests.RouterNamespace("openshift-ingress"), manifests.RouterServiceAccount("openshift-ingress"))
+		r := &reconciler{
+			Config: Config{OperandNamespace: "openshift-ingress"},
+			client: client,
+		}
+
+		for i := 0; i < 2; i++ {
+			if err := r.ensureRouterNamespace(); err != nil {
+				t.Fatalf("%s: failed to ensure router namespace: %v", tc.description, err)
+			}
+		}
+
+		currentRole := &rbacv1.ClusterRole{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: desiredRole.Name}, currentRole); err != nil {
+			t.Fatalf("%s: %v", tc.description, err)
+		}
+		if !reflect.DeepEqual(currentRole.Rules, desiredRole.Rules) {
+			t.Errorf("%s: expected cluster role rules to be restored, got %v", tc.description, currentRole.Rules)
+		}
+		if tc.expectUpdates != 0 && currentRole.ResourceVersion != "1" {
+			t.Errorf("%s: expected update to preserve resourceVersion, got %q", tc.description, currentRole.ResourceVersion)
+		}
+		currentBinding := &rbacv1.ClusterRoleBinding{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: desiredBinding.Name}, currentBinding); err != nil {
+			t.Fatalf("%s: %v", tc.description, err)
+		}
+		if !reflect.DeepEqual(currentBinding.Subjects, desiredBinding.Subjects) || currentBinding.RoleRef != desiredBinding.RoleRef {
+			t.Errorf("%s: expected cluster role binding to be restored, got subjects %v and role reference %v", tc.description, currentBinding.Subjects, currentBinding.RoleRef)
+		}
+		if client.calls["update"] != tc.expectUpdates {
+			t.Errorf("%s: expected %d updates, got %d", tc.description, tc.expectUpdates, client.calls["update"])
+		}
+		if client.calls["delete"] != tc.expectDeletes {
+			t.Errorf("%s: expected %d deletes, got %d", tc.description, tc.expectDeletes, client.calls["delete"])
+		}
+	}
+}
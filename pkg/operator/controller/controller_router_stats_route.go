@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// exposeRouterStatsAnnotation, when set to "true" on an
+	// ingresscontroller, makes the operator create a route that exposes
+	// the router's stats page, so that it can be viewed without port
+	// forwarding.  The route reencrypts connections to the stats port of
+	// the ingresscontroller's internal service, and the router requires
+	// the credentials in the router stats secret (see
+	// manifests.RouterStatsSecret) for every request.  Like any route, it
+	// is served by the ingresscontrollers that admit it, so it may be
+	// reachable from wherever those are; use
+	// routerStatsAllowedSourceRangesAnnotation to restrict the clients.
+	// Allowed values are "true" and "false".  The stats credentials only
+	// exist with metrics integration, so the annotation cannot be set to
+	// "true" if metrics integration is disabled.  If unset, the stats page
+	// is not exposed.
+	exposeRouterStatsAnnotation = "ingress.operator.openshift.io/expose-router-stats"
+
+	// routerStatsAllowedSourceRangesAnnotation specifies a comma-separated
+	// list of networks in CIDR notation from which the route that exposes
+	// the router stats page accepts connections.  If unset, the route
+	// accepts connections from any address.
+	routerStatsAllowedSourceRangesAnnotation = "ingress.operator.openshift.io/router-stats-allowed-source-ranges"
+
+	// routeIPWhitelistAnnotation is the route annotation with the
+	// space-separated networks from which the router accepts connections
+	// for the route.
+	routeIPWhitelistAnnotation = "haproxy.router.openshift.io/ip_whitelist"
+)
+
+// routeGVK is the group, version, and kind of routes.
+var routeGVK = schema.GroupVersionKind{
+	Group:   "route.openshift.io",
+	Kind:    "Route",
+	Version: "v1",
+}
+
+// routerStatsExposed returns a Boolean indicating whether the given
+// ingresscontroller's router stats page is exposed through a route.
+func routerStatsExposed(ic *operatorv1.IngressController) bool {
+	return metricsIntegrationEnabled(ic) && ic.Annotations[exposeRouterStatsAnnotation] == "true"
+}
+
+// validateExposeRouterStats verifies that the expose router stats annotation,
+// if set, is "true" or "false", that it is not "true" when metrics integration
+// is disabled, and that the allowed source ranges annotation, if set, lists
+// networks.
+func validateExposeRouterStats(ic *operatorv1.IngressController) error {
+	if v, ok := ic.Annotations[exposeRouterStatsAnnotation]; ok {
+		if v != "true" && v != "false" {
+			return fmt.Errorf("invalid value for annotation %s: %q; allowed values are true and false", exposeRouterStatsAnnotation, v)
+		}
+		if v == "true" && !metricsIntegrationEnabled(ic) {
+			return fmt.Errorf("annotation %s is set to true, but annotation %s disables metrics integration, without which the router stats page has no credentials", exposeRouterStatsAnnotation, disableMetricsIntegrationAnnotation)
+		}
+	}
+	return validateNetworksAnnotation(ic, routerStatsAllowedSourceRangesAnnotation)
+}
+
+// networksAnnotation returns the networks that the given comma-separated
+// annotation of the given ingresscontroller lists, in canonical CIDR
+// notation, in order and without duplicates, or nil if it lists none.
+func networksAnnotation(ic *operatorv1.IngressController, annotation string) []string {
+	v, ok := ic.Annotations[annotation]
+	if !ok {
+		return nil
+	}
+	var networks []string
+	for _, s := range strings.Split(v, ",") {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(s))
+		if err != nil {
+			continue
+		}
+		duplicate := false
+		for _, other := range networks {
+			if other == network.String() {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			networks = append(networks, network.String())
+		}
+	}
+	return networks
+}
+
+// validateNetworksAnnotation verifies that the given comma-separated
+// annotation of the given ingresscontroller, if set, lists only networks in
+// CIDR notation.
+func validateNetworksAnnotation(ic *operatorv1.IngressController, annotation string) error {
+	v, ok := ic.Annotations[annotation]
+	if !ok {
+		return nil
+	}
+	if len(strings.TrimSpace(v)) == 0 {
+		return fmt.Errorf("invalid value for annotation %s: %q; must list at least one network", annotation, v)
+	}
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		if _, _, err := net.ParseCIDR(s); err != nil {
+			return fmt.Errorf("invalid value for annotation %s: %q is not a network in CIDR notation, such as 192.0.2.0/24", annotation, s)
+		}
+	}
+	return nil
+}
+
+// routerStatsHost returns the host of the route that exposes the given
+// ingresscontroller's router stats page, or the empty string if the
+// ingresscontroller has no domain yet, in which case the host is generated
+// when the route is admitted.
+func routerStatsHost(ic *operatorv1.IngressController) string {
+	if len(ic.Status.Domain) == 0 {
+		return ""
+	}
+	return "router-stats-" + ic.Name + "." + ic.Status.Domain
+}
+
+// ensureRouterStatsRoute ensures that the route that exposes the router stats
+// page exists and is up to date if the given ingresscontroller exposes the
+// stats page and that it does not exist otherwise.
+func (r *reconciler) ensureRouterStatsRoute(ic *operatorv1.IngressController, deploymentRef metav1.OwnerReference) error {
+	current, err := r.currentRouterStatsRoute(ic)
+	if err != nil {
+		return err
+	}
+	if !routerStatsExposed(ic) {
+		if current == nil {
+			return nil
+		}
+		if err := r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete router stats route %s/%s: %v", current.GetNamespace(), current.GetName(), err)
+		}
+		stepLogger(ic, "router-stats-route").Info("deleted router stats route", "namespace", current.GetNamespace(), "name", current.GetName())
+		return nil
+	}
+
+	desired := desiredRouterStatsRoute(ic, r.OperandNamespace, deploymentRef)
+	applyOperandMetadata(desired, r.OperandLabels, r.OperandAnnotations)
+	if current == nil {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create router stats route %s/%s: %v", desired.GetNamespace(), desired.GetName(), err)
+		}
+		stepLogger(ic, "router-stats-route").Info("created router stats route", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		return nil
+	}
+	changed, updated := routerStatsRouteChanged(current, desired)
+	if !changed {
+		updated = current.DeepCopy()
+	}
+	if metadataChanged := updateOperandMetadata(updated, desired, r.OperandLabels, r.OperandAnnotations); changed || metadataChanged {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return fmt.Errorf("failed to update router stats route %s/%s: %v", updated.GetNamespace(), updated.GetName(), err)
+		}
+		stepLogger(ic, "router-stats-route").Info("updated router stats route", "namespace", updated.GetNamespace(), "name", updated.GetName())
+	}
+	return nil
+}
+
+// desiredRouterStatsRoute returns the route that exposes the given
+// ingresscontroller's router stats page.  The route reencrypts connections to
+// the stats port of the internal service, whose certificate is signed by the
+// service CA, which the router trusts for reencrypt routes without a
+// destination CA certificate.
+func desiredRouterStatsRoute(ic *operatorv1.IngressController, namespace string, deploymentRef metav1.OwnerReference) *unstructured.Unstructured {
+	name := RouterStatsRouteName(ic, namespace)
+	spec := map[string]interface{}{
+		"to": map[string]interface{}{
+			"kind":   "Service",
+			"name":   InternalIngressControllerServiceName(ic, namespace).Name,
+			"weight": int64(100),
+		},
+		"port": map[string]interface{}{
+			"targetPort": "metrics",
+		},
+		"tls": map[string]interface{}{
+			"termination":                   "reencrypt",
+			"insecureEdgeTerminationPolicy": "None",
+		},
+		"wildcardPolicy": "None",
+	}
+	if host := routerStatsHost(ic); len(host) != 0 {
+		spec["host"] = host
+	}
+	route := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"namespace": name.Namespace,
+				"name":      name.Name,
+			},
+			"spec": spec,
+		},
+	}
+	route.SetGroupVersionKind(routeGVK)
+	route.SetLabels(map[string]string{manifests.OwningIngressControllerLabel: ic.Name})
+	if networks := networksAnnotation(ic, routerStatsAllowedSourceRangesAnnotation); len(networks) != 0 {
+		route.SetAnnotations(map[string]string{routeIPWhitelistAnnotation: strings.Join(networks, " ")})
+	}
+	route.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
+	return route
+}
+
+// routerStatsRouteChanged checks if the current router stats route matches
+// the expected route and if not returns an updated route.  Only the fields of
+// the spec that the expected route sets are compared so that fields that the
+// API fills in, such as a generated host, are left alone.
+func routerStatsRouteChanged(current, expected *unstructured.Unstructured) (bool, *unstructured.Unstructured) {
+	currentSpec, _, _ := unstructured.NestedMap(current.Object, "spec")
+	expectedSpec, _, _ := unstructured.NestedMap(expected.Object, "spec")
+	specChanged := false
+	for k, v := range expectedSpec {
+		if !reflect.DeepEqual(currentSpec[k], v) {
+			specChanged = true
+			break
+		}
+	}
+	whitelist := expected.GetAnnotations()[routeIPWhitelistAnnotation]
+	whitelistChanged := current.GetAnnotations()[routeIPWhitelistAnnotation] != whitelist
+	if !specChanged && !whitelistChanged {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	if specChanged {
+		spec := runtime.DeepCopyJSON(currentSpec)
+		if spec == nil {
+			spec = map[string]interface{}{}
+		}
+		for k, v := range expectedSpec {
+			spec[k] = runtime.DeepCopyJSONValue(v)
+		}
+		updated.Object["spec"] = spec
+	}
+	if whitelistChanged {
+		annotations := updated.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		if len(whitelist) != 0 {
+			annotations[routeIPWhitelistAnnotation] = whitelist
+		} else {
+			delete(annotations, routeIPWhitelistAnnotation)
+		}
+		updated.SetAnnotations(annotations)
+	}
+	return true, updated
+}
+
+// currentRouterStatsRoute returns the route that exposes the given
+// ingresscontroller's router stats page, or nil if it does not exist.
+func (r *reconciler) currentRouterStatsRoute(ic *operatorv1.IngressController) (*unstructured.Unstructured, error) {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(routeGVK)
+	if err := r.client.Get(context.TODO(), RouterStatsRouteName(ic, r.OperandNamespace), route); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return route, nil
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestEnsureRouterStatsRoute verifies that the router stats route is created
+// only when the stats page is exposed, that it reencrypts connections to the
+// stats port of the internal service, that it follows changes to the allowed
+// source ranges without overwriting fields that the API fills in, and that it
+// is deleted when the stats page is no longer exposed.
+func TestEnsureRouterStatsRoute(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	client := newFakeClient()
+	r := &reconciler{Config: Config{OperandNamespace: "openshift-ingress"}, client: client}
+	ensure := func() *unstructured.Unstructured {
+		t.Helper()
+		if err := r.ensureRouterStatsRoute(ic, metav1.OwnerReference{Name: "router-default"}); err != nil {
+			t.Fatalf("failed to ensure router stats route: %v", err)
+		}
+		route, err := r.currentRouterStatsRoute(ic)
+		if err != nil {
+			t.Fatalf("failed to get router stats route: %v", err)
+		}
+		return route
+	}
+
+	if route := ensure(); route != nil {
+		t.Fatalf("expected no router stats route by default, got %v", route.Object)
+	}
+	if client.calls["create"] != 0 {
+		t.Errorf("expected no create, got %d", client.calls["create"])
+	}
+
+	ic.Annotations = map[string]string{exposeRouterStatsAnnotation: "true"}
+	route := ensure()
+	if route == nil {
+		t.Fatal("expected a router stats route")
+	}
+	expectSpec := map[string]interface{}{
+		"to": map[string]interface{}{
+			"kind":   "Service",
+			"name":   "router-internal-default",
+			"weight": int64(100),
+		},
+		"port": map[string]interface{}{
+			"targetPort": "metrics",
+		},
+		"tls": map[string]interface{}{
+			"termination":                   "reencrypt",
+			"insecureEdgeTerminationPolicy": "None",
+		},
+		"wildcardPolicy": "None",
+	}
+	if spec := route.Object["spec"]; !cmp.Equal(spec, expectSpec) {
+		t.Errorf("expected spec %v, got %v", expectSpec, spec)
+	}
+	if v, ok := route.GetAnnotations()[routeIPWhitelistAnnotation]; ok {
+		t.Errorf("expected no IP whitelist, got %q", v)
+	}
+
+	// A host that the API generates is kept.
+	if err := unstructured.SetNestedField(route.Object, "router-stats-default-openshift-ingress.apps.example.com", "spec", "host"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.replace(route); err != nil {
+		t.Fatal(err)
+	}
+	updates := client.calls["update"]
+	route = ensure()
+	if client.calls["update"] != updates {
+		t.Errorf("expected no update, got %d", client.calls["update"]-updates)
+	}
+
+	ic.Annotations[routerStatsAllowedSourceRangesAnnotation] = "192.0.2.0/24,198.51.100.7/32"
+	route = ensure()
+	if client.calls["update"] != updates+1 {
+		t.Errorf("expected 1 update, got %d", client.calls["update"]-updates)
+	}
+	if v := route.GetAnnotations()[routeIPWhitelistAnnotation]; v != "192.0.2.0/24 198.51.100.7/32" {
+		t.Errorf("expected IP whitelist %q, got %q", "192.0.2.0/24 198.51.100.7/32", v)
+	}
+	if host, _, _ := unstructured.NestedString(route.Object, "spec", "host"); host != "router-stats-default-openshift-ingress.apps.example.com" {
+		t.Errorf("expected the generated host to be kept, got %q", host)
+	}
+
+	// Once the ingresscontroller has a domain, the route uses a host in it.
+	ic.Status.Domain = "apps.example.com"
+	route = ensure()
+	if host, _, _ := unstructured.NestedString(route.Object, "spec", "host"); host != "router-stats-default.apps.example.com" {
+		t.Errorf("expected host %q, got %q", "router-stats-default.apps.example.com", host)
+	}
+
+	delete(ic.Annotations, routerStatsAllowedSourceRangesAnnotation)
+	route = ensure()
+	if v, ok := route.GetAnnotations()[routeIPWhitelistAnnotation]; ok {
+		t.Errorf("expected the IP whitelist to be removed, got %q", v)
+	}
+
+	ic.Annotations[exposeRouterStatsAnnotation] = "false"
+	if route := ensure(); route != nil {
+		t.Errorf("expected the router stats route to be deleted, got %v", route.Object)
+	}
+}
+
+// TestValidateExposeRouterStats verifies that the expose router stats
+// annotation must be "true" or "false", requires metrics integration, and that
+// the allowed source ranges must be networks.
+func TestValidateExposeRouterStats(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		expect      string
+	}{
+		{},
+		{
+			annotations: map[string]string{exposeRouterStatsAnnotation: "true", routerStatsAllowedSourceRangesAnnotation: "192.0.2.0/24"},
+		},
+		{
+			annotations: map[string]string{exposeRouterStatsAnnotation: "false", disableMetricsIntegrationAnnotation: "true"},
+		},
+		{
+			annotations: map[string]string{exposeRouterStatsAnnotation: "yes"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/expose-router-stats: "yes"; allowed values are true and false`,
+		},
+		{
+			annotations: map[string]string{exposeRouterStatsAnnotation: "true", disableMetricsIntegrationAnnotation: "true"},
+			expect:      "annotation ingress.operator.openshift.io/expose-router-stats is set to true, but annotation ingress.operator.openshift.io/disable-metrics-integration disables metrics integration, without which the router stats page has no credentials",
+		},
+		{
+			annotations: map[string]string{exposeRouterStatsAnnotation: "true", routerStatsAllowedSourceRangesAnnotation: "192.0.2.1"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-stats-allowed-source-ranges: "192.0.2.1" is not a network in CIDR notation, such as 192.0.2.0/24`,
+		},
+		{
+			annotations: map[string]string{exposeRouterStatsAnnotation: "true", routerStatsAllowedSourceRangesAnnotation: "192.0.2.0/24,192.0.2.0/33"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-stats-allowed-source-ranges: "192.0.2.0/33" is not a network in CIDR notation, such as 192.0.2.0/24`,
+		},
+		{
+			annotations: map[string]string{exposeRouterStatsAnnotation: "true", routerStatsAllowedSourceRangesAnnotation: " "},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-stats-allowed-source-ranges: " "; must list at least one network`,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		err := validateExposeRouterStats(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%v: expected no error, got %v", tc.annotations, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%v: expected error %q, got %v", tc.annotations, tc.expect, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
@@ -12,11 +13,20 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-func (r *reconciler) ensureServiceMonitor(ic *operatorv1.IngressController, svc *corev1.Service, deploymentRef metav1.OwnerReference) (*unstructured.Unstructured, error) {
-	desired := desiredServiceMonitor(ic, svc, deploymentRef)
+// ensureServiceMonitor ensures that a servicemonitor exists for the given
+// ingresscontroller.  The servicemonitor verifies the router's metrics
+// certificate using the CA bundle in the given configmap once the service CA
+// operator has injected it, and otherwise falls back to the service CA bundle
+// that the monitoring stack mounts into prometheus.  Either way, prometheus
+// authenticates with its service account's bearer token.  Per-route metrics
+// are dropped unless they are enabled for the ingresscontroller.
+func (r *reconciler) ensureServiceMonitor(ic *operatorv1.IngressController, svc *corev1.Service, caBundle *corev1.ConfigMap, deploymentRef metav1.OwnerReference) (*unstructured.Unstructured, error) {
+	desired := desiredServiceMonitor(ic, r.OperandNamespace, svc, caBundle, deploymentRef)
+	applyOperandMetadata(desired, r.OperandLabels, r.OperandAnnotations)
 
 	current, err := r.currentServiceMonitor(ic)
 	if err != nil {
@@ -27,14 +37,53 @@ func (r *reconciler) ensureServiceMonitor(ic *operatorv1.IngressController, svc
 		if err := r.client.Create(context.TODO(), desired); err != nil {
 			return nil, fmt.Errorf("failed to create servicemonitor %s/%s: %v", desired.GetNamespace(), desired.GetName(), err)
 		}
-		log.Info("created servicemonitor", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		stepLogger(ic, "metrics").Info("created servicemonitor", "namespace", desired.GetNamespace(), "name", desired.GetName())
 		return desired, nil
 	}
+	if desired != nil && current != nil {
+		changed, updated := serviceMonitorChanged(current, desired)
+		if !changed {
+			updated = current.DeepCopy()
+		}
+		if metadataChanged := updateOperandMetadata(updated, desired, r.OperandLabels, r.OperandAnnotations); changed || metadataChanged {
+			if err := r.client.Update(context.TODO(), updated); err != nil {
+				return nil, fmt.Errorf("failed to update servicemonitor %s/%s: %v", updated.GetNamespace(), updated.GetName(), err)
+			}
+			stepLogger(ic, "metrics").Info("updated servicemonitor", "namespace", updated.GetNamespace(), "name", updated.GetName())
+			return updated, nil
+		}
+	}
 	return current, nil
 }
 
-func desiredServiceMonitor(ic *operatorv1.IngressController, svc *corev1.Service, deploymentRef metav1.OwnerReference) *unstructured.Unstructured {
-	name := IngressControllerServiceMonitorName(ic)
+func desiredServiceMonitor(ic *operatorv1.IngressController, namespace string, svc *corev1.Service, caBundle *corev1.ConfigMap, deploymentRef metav1.OwnerReference) *unstructured.Unstructured {
+	name := IngressControllerServiceMonitorName(ic, namespace)
+	tlsConfig := map[string]interface{}{
+		"caFile":     "/etc/prometheus/configmaps/serving-certs-ca-bundle/service-ca.crt",
+		"serverName": fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace),
+	}
+	if hasInjectedCABundle(caBundle) {
+		tlsConfig = map[string]interface{}{
+			"ca": map[string]interface{}{
+				"configMap": map[string]interface{}{
+					"name": caBundle.Name,
+					"key":  serviceCABundleKey,
+				},
+			},
+			"serverName": fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace),
+		}
+	}
+	endpoint := map[string]interface{}{
+		"bearerTokenFile": "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		"interval":        "30s",
+		"port":            "metrics",
+		"scheme":          "https",
+		"path":            "/metrics",
+		"tlsConfig":       tlsConfig,
+	}
+	if relabelings := perRouteMetricsRelabelings(ic); len(relabelings) != 0 {
+		endpoint["metricRelabelings"] = relabelings
+	}
 	sm := &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"metadata": map[string]interface{}{
@@ -44,7 +93,7 @@ func desiredServiceMonitor(ic *operatorv1.IngressController, svc *corev1.Service
 			"spec": map[string]interface{}{
 				"namespaceSelector": map[string]interface{}{
 					"matchNames": []interface{}{
-						"openshift-ingress",
+						namespace,
 					},
 				},
 				"selector": map[string]interface{}{
@@ -52,19 +101,7 @@ func desiredServiceMonitor(ic *operatorv1.IngressController, svc *corev1.Service
 						manifests.OwningIngressControllerLabel: ic.Name,
 					},
 				},
-				"endpoints": []map[string]interface{}{
-					{
-						"bearerTokenFile": "/var/run/secrets/kubernetes.io/serviceaccount/token",
-						"interval":        "30s",
-						"port":            "metrics",
-						"scheme":          "https",
-						"path":            "/metrics",
-						"tlsConfig": map[string]interface{}{
-							"caFile":     "/etc/prometheus/configmaps/serving-certs-ca-bundle/service-ca.crt",
-							"serverName": fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace),
-						},
-					},
-				},
+				"endpoints": []interface{}{endpoint},
 			},
 		},
 	}
@@ -77,6 +114,18 @@ func desiredServiceMonitor(ic *operatorv1.IngressController, svc *corev1.Service
 	return sm
 }
 
+// serviceMonitorChanged checks if the current servicemonitor spec matches the
+// expected spec and if not returns an updated servicemonitor.
+func serviceMonitorChanged(current, expected *unstructured.Unstructured) (bool, *unstructured.Unstructured) {
+	if reflect.DeepEqual(current.Object["spec"], expected.Object["spec"]) {
+		return false, nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Object["spec"] = runtime.DeepCopyJSONValue(expected.Object["spec"])
+	return true, updated
+}
+
 func (r *reconciler) currentServiceMonitor(ic *operatorv1.IngressController) (*unstructured.Unstructured, error) {
 	sm := &unstructured.Unstructured{}
 	sm.SetGroupVersionKind(schema.GroupVersionKind{
@@ -84,7 +133,7 @@ func (r *reconciler) currentServiceMonitor(ic *operatorv1.IngressController) (*u
 		Kind:    "ServiceMonitor",
 		Version: "v1",
 	})
-	if err := r.client.Get(context.TODO(), IngressControllerServiceMonitorName(ic), sm); err != nil {
+	if err := r.client.Get(context.TODO(), IngressControllerServiceMonitorName(ic, r.OperandNamespace), sm); err != nil {
 		if errors.IsNotFound(err) {
 			return nil, nil
 		}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestEnsureServiceMonitorCABundle verifies that the servicemonitor falls back
+// to the CA bundle mounted into prometheus until the service CA operator
+// injects the CA bundle into the ingresscontroller's metrics CA configmap and
+// that it then switches to the injected CA bundle.
+func TestEnsureServiceMonitorCABundle(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      "router-internal-default",
+		},
+	}
+	deploymentRef := metav1.OwnerReference{Name: "router-default"}
+	client := newFakeClient()
+	r := &reconciler{Config: Config{OperandNamespace: "openshift-ingress"}, client: client}
+
+	tlsConfig := func(sm *unstructured.Unstructured) map[string]interface{} {
+		endpoints, _, err := unstructured.NestedSlice(sm.Object, "spec", "endpoints")
+		if err != nil || len(endpoints) != 1 {
+			t.Fatalf("expected 1 endpoint, got %v (error: %v)", endpoints, err)
+		}
+		config, _, err := unstructured.NestedMap(endpoints[0].(map[string]interface{}), "tlsConfig")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := config["serverName"]; v != "router-internal-default.openshift-ingress.svc" {
+			t.Errorf("unexpected serverName: %v", v)
+		}
+		if v := endpoints[0].(map[string]interface{})["bearerTokenFile"]; v != "/var/run/secrets/kubernetes.io/serviceaccount/token" {
+			t.Errorf("unexpected bearerTokenFile: %v", v)
+		}
+		return config
+	}
+
+	caBundle, err := r.ensureMetricsCABundleConfigMap(ic, deploymentRef)
+	if err != nil {
+		t.Fatalf("failed to ensure metrics CA bundle configmap: %v", err)
+	}
+	if v := caBundle.Annotations[InjectCABundleAnnotation]; v != "true" {
+		t.Errorf("expected annotation %s=true, got %q", InjectCABundleAnnotation, v)
+	}
+	sm, err := r.ensureServiceMonitor(ic, svc, caBundle, deploymentRef)
+	if err != nil {
+		t.Fatalf("failed to ensure servicemonitor: %v", err)
+	}
+	if v := tlsConfig(sm)["caFile"]; v != "/etc/prometheus/configmaps/serving-certs-ca-bundle/service-ca.crt" {
+		t.Errorf("expected fallback caFile before CA injection, got %v", v)
+	}
+
+	// Simulate the service CA operator injecting the CA bundle.
+	caBundle.Data = map[string]string{serviceCABundleKey: "-----BEGIN CERTIFICATE-----"}
+	if err := client.replace(caBundle); err != nil {
+		t.Fatal(err)
+	}
+	caBundle, err = r.ensureMetricsCABundleConfigMap(ic, deploymentRef)
+	if err != nil {
+		t.Fatalf("failed to ensure metrics CA bundle configmap: %v", err)
+	}
+	if client.calls["update"] != 0 {
+		t.Errorf("expected the injected CA bundle configmap not to be updated, got %d updates", client.calls["update"])
+	}
+	sm, err = r.ensureServiceMonitor(ic, svc, caBundle, deploymentRef)
+	if err != nil {
+		t.Fatalf("failed to ensure servicemonitor: %v", err)
+	}
+	config := tlsConfig(sm)
+	if _, ok := config["caFile"]; ok {
+		t.Errorf("expected caFile to be removed after CA injection, got %v", config)
+	}
+	name, _, _ := unstructured.NestedString(config, "ca", "configMap", "name")
+	key, _, _ := unstructured.NestedString(config, "ca", "configMap", "key")
+	if name != "router-metrics-ca-default" || key != serviceCABundleKey {
+		t.Errorf("expected tlsConfig to reference configmap router-metrics-ca-default key %s, got %v", serviceCABundleKey, config)
+	}
+	if client.calls["update"] != 1 {
+		t.Errorf("expected 1 update to the servicemonitor, got %d", client.calls["update"])
+	}
+
+	if _, err := r.ensureServiceMonitor(ic, svc, caBundle, deploymentRef); err != nil {
+		t.Fatalf("failed to ensure servicemonitor: %v", err)
+	}
+	if client.calls["update"] != 1 {
+		t.Errorf("expected no further updates, got %d", client.calls["update"]-1)
+	}
+}
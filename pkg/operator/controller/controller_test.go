@@ -0,0 +1,1230 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestAdmitLoadBalancerLimit(t *testing.T) {
+	ingressController := func(name string, admitted bool) *operatorv1.IngressController {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-ingress-operator",
+				Name:      name,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+				},
+			},
+		}
+		if admitted {
+			ic.Status.Conditions = []operatorv1.OperatorCondition{{
+				Type:   IngressControllerAdmittedConditionType,
+				Status: operatorv1.ConditionTrue,
+				Reason: "Valid",
+			}}
+		}
+		return ic
+	}
+	existing := ingressController("default", true)
+	ic := ingressController("new", false)
+	client := newFakeClient(existing, ic)
+	r := &reconciler{
+		Config: Config{
+			Namespace:                         "openshift-ingress-operator",
+			MaxLoadBalancerIngressControllers: 1,
+		},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+
+	admitted, err := r.admit(ic, &configv1.Infrastructure{})
+	if err != nil {
+		t.Fatalf("failed to admit ingresscontroller: %v", err)
+	}
+	if admitted {
+		t.Fatal("expected ingresscontroller not to be admitted")
+	}
+	cond := getIngressCondition(ic.Status.Conditions, IngressControllerAdmittedConditionType)
+	if cond == nil {
+		t.Fatal("expected an Admitted condition")
+	}
+	expectMessage := "the number of ingresscontrollers using the LoadBalancerService endpoint publishing strategy is limited to 1, and 1 are already admitted"
+	if cond.Status != operatorv1.ConditionFalse || cond.Reason != "LoadBalancerLimitExceeded" || cond.Message != expectMessage {
+		t.Errorf("expected Admitted=False with reason LoadBalancerLimitExceeded and message %q, got %s with reason %q and message %q", expectMessage, cond.Status, cond.Reason, cond.Message)
+	}
+
+	// The existing ingresscontroller stays admitted.
+	if admitted, err := r.admit(existing, &configv1.Infrastructure{}); err != nil {
+		t.Fatalf("failed to admit ingresscontroller: %v", err)
+	} else if !admitted {
+		t.Error("expected the existing ingresscontroller to stay admitted")
+	}
+
+	// Raising the limit admits the new ingresscontroller.
+	r.MaxLoadBalancerIngressControllers = 2
+	if admitted, err := r.admit(ic, &configv1.Infrastructure{}); err != nil {
+		t.Fatalf("failed to admit ingresscontroller: %v", err)
+	} else if !admitted {
+		t.Error("expected ingresscontroller to be admitted after raising the limit")
+	}
+	cond = getIngressCondition(ic.Status.Conditions, IngressControllerAdmittedConditionType)
+	if cond == nil || cond.Status != operatorv1.ConditionTrue || cond.Reason != "Valid" || len(cond.Message) != 0 {
+		t.Errorf("expected Admitted=True with reason Valid, got %#v", cond)
+	}
+}
+
+// TestAdmitTopologyAwareHints verifies that an ingresscontroller that enables
+// topology-aware hints on its internal service is only admitted if the cluster
+// supports them.
+func TestAdmitTopologyAwareHints(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "openshift-ingress-operator",
+			Name:        "default",
+			Annotations: map[string]string{internalServiceTopologyAwareHintsAnnotation: "true"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	client := newFakeClient(ic)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+
+	if admitted, err := r.admit(ic, &configv1.Infrastructure{}); err != nil {
+		t.Fatalf("failed to admit ingresscontroller: %v", err)
+	} else if admitted {
+		t.Fatal("expected ingresscontroller not to be admitted")
+	}
+	cond := getIngressCondition(ic.Status.Conditions, IngressControllerAdmittedConditionType)
+	expectMessage := "annotation ingress.operator.openshift.io/internal-service-topology-aware-hints is set to true, but the cluster does not support topology-aware hints; the API server does not serve discovery.k8s.io/v1"
+	if cond == nil || cond.Status != operatorv1.ConditionFalse || cond.Reason != "TopologyAwareHintsUnsupported" || cond.Message != expectMessage {
+		t.Errorf("expected Admitted=False with reason TopologyAwareHintsUnsupported and message %q, got %#v", expectMessage, cond)
+	}
+
+	r.TopologyAwareHintsSupported = true
+	if admitted, err := r.admit(ic, &configv1.Infrastructure{}); err != nil {
+		t.Fatalf("failed to admit ingresscontroller: %v", err)
+	} else if !admitted {
+		t.Error("expected ingresscontroller to be admitted when the cluster supports topology-aware hints")
+	}
+}
+
+// TestAdmitUnsupportedEndpointPublishingStrategy verifies that a
+// LoadBalancerService ingresscontroller is not admitted on a platform without
+// a load balancer integration and that a HostNetwork ingresscontroller is.
+func TestAdmitUnsupportedEndpointPublishingStrategy(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.NonePlatformType,
+		},
+	}
+	lb := ingressController("default", operatorv1.LoadBalancerServiceStrategyType)
+	lb.Namespace = "openshift-ingress-operator"
+	hostNetwork := ingressController("hostnetwork", operatorv1.HostNetworkStrategyType)
+	hostNetwork.Namespace = "openshift-ingress-operator"
+	client := newFakeClient(lb, hostNetwork)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+
+	if admitted, err := r.admit(lb, infraConfig); err != nil {
+		t.Fatalf("failed to admit ingresscontroller: %v", err)
+	} else if admitted {
+		t.Fatal("expected ingresscontroller not to be admitted")
+	}
+	cond := getIngressCondition(lb.Status.Conditions, IngressControllerAdmittedConditionType)
+	expectMessage := "the LoadBalancerService endpoint publishing strategy is not supported on platform None, which has no load balancer integration; use the HostNetwork or Private strategy instead"
+	if cond == nil || cond.Status != operatorv1.ConditionFalse || cond.Reason != "UnsupportedEndpointPublishingStrategy" || cond.Message != expectMessage {
+		t.Errorf("expected Admitted=False with reason UnsupportedEndpointPublishingStrategy and message %q, got %#v", expectMessage, cond)
+	}
+
+	if admitted, err := r.admit(hostNetwork, infraConfig); err != nil {
+		t.Fatalf("failed to admit ingresscontroller: %v", err)
+	} else if !admitted {
+		t.Error("expected the HostNetwork ingresscontroller to be admitted")
+	}
+}
+
+// TestAdmitHostNetworkPortConflict verifies that a HostNetwork
+// ingresscontroller whose router pods may land on the same nodes as those of
+// an admitted HostNetwork ingresscontroller is not admitted.
+func TestAdmitHostNetworkPortConflict(t *testing.T) {
+	existing := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.HostNetworkStrategyType,
+			},
+			Conditions: []operatorv1.OperatorCondition{{
+				Type:   IngressControllerAdmittedConditionType,
+				Status: operatorv1.ConditionTrue,
+				Reason: "Valid",
+			}},
+		},
+	}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "new",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.HostNetworkStrategyType,
+			},
+		},
+	}
+	client := newFakeClient(existing, ic)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+
+	if admitted, err := r.admit(ic, &configv1.Infrastructure{}); err != nil {
+		t.Fatalf("failed to admit ingresscontroller: %v", err)
+	} else if admitted {
+		t.Fatal("expected ingresscontroller not to be admitted")
+	}
+	cond := getIngressCondition(ic.Status.Conditions, IngressControllerAdmittedConditionType)
+	expectMessage := `ingresscontroller default already uses host ports 80/TCP, 443/TCP, 1936/TCP on nodes matching node selector "beta.kubernetes.io/os=linux,node-role.kubernetes.io/worker=", which may also match node selector "beta.kubernetes.io/os=linux,node-role.kubernetes.io/worker="`
+	if cond == nil || cond.Status != operatorv1.ConditionFalse || cond.Reason != "HostNetworkPortConflict" || cond.Message != expectMessage {
+		t.Errorf("expected Admitted=False with reason HostNetworkPortConflict and message %q, got %#v", expectMessage, cond)
+	}
+
+	// The existing ingresscontroller stays admitted.
+	if admitted, err := r.admit(existing, &configv1.Infrastructure{}); err != nil {
+		t.Fatalf("failed to admit ingresscontroller: %v", err)
+	} else if !admitted {
+		t.Error("expected the existing ingresscontroller to stay admitted")
+	}
+}
+
+func TestRotateRouterStatsCredentials(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+	}
+	secret := manifests.RouterStatsSecret(ci, "openshift-ingress")
+	deployment := manifests.RouterDeployment()
+	name := RouterDeploymentName(ci, "openshift-ingress")
+	deployment.Namespace, deployment.Name = name.Namespace, name.Name
+	replicas := int32(2)
+	deployment.Spec.Replicas = &replicas
+	client := newFakeClient(ci, secret, deployment)
+	r := &reconciler{Config: Config{OperandNamespace: "openshift-ingress"}, client: client}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-internal-default"}}
+	deploymentRef := metav1.OwnerReference{Name: deployment.Name}
+
+	currentSecret := func() *corev1.Secret {
+		s := &corev1.Secret{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, s); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	currentDeployment := func() *appsv1.Deployment {
+		d := &appsv1.Deployment{}
+		if err := client.Get(context.TODO(), name, d); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	// Without the annotation, the credentials are left alone.
+	if err := r.ensureMetricsIntegration(ci, svc, deploymentRef); err != nil {
+		t.Fatalf("failed to ensure metrics integration: %v", err)
+	}
+	if !reflect.DeepEqual(currentSecret().Data, secret.Data) {
+		t.Error("expected the stats credentials not to change")
+	}
+	if _, ok := currentDeployment().Spec.Template.Annotations[statsCredentialsRotatedAnnotation]; ok {
+		t.Error("expected the router deployment not to be restarted")
+	}
+
+	ci.Annotations = map[string]string{rotateStatsCredentialsAnnotation: ""}
+	if err := client.Update(context.TODO(), ci); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ensureMetricsIntegration(ci, svc, deploymentRef); err != nil {
+		t.Fatalf("failed to ensure metrics integration: %v", err)
+	}
+	rotated := currentSecret()
+	if reflect.DeepEqual(rotated.Data, secret.Data) {
+		t.Error("expected the stats credentials to be rotated")
+	}
+	for _, key := range []string{"statsUsername", "statsPassword"} {
+		if len(rotated.Data[key]) == 0 {
+			t.Errorf("expected rotated secret to have %s", key)
+		}
+	}
+	if _, ok := currentDeployment().Spec.Template.Annotations[statsCredentialsRotatedAnnotation]; !ok {
+		t.Errorf("expected the router pod template to have annotation %s", statsCredentialsRotatedAnnotation)
+	}
+	if _, ok := ci.Annotations[rotateStatsCredentialsAnnotation]; ok {
+		t.Errorf("expected annotation %s to be removed", rotateStatsCredentialsAnnotation)
+	}
+
+	// The rotation annotation on the pod template survives reconciliation
+	// of the router deployment.
+	restarted := currentDeployment()
+	expected := restarted.DeepCopy()
+	delete(expected.Spec.Template.Annotations, statsCredentialsRotatedAnnotation)
+	if changed, _ := deploymentConfigChanged(restarted, expected); changed {
+		t.Error("expected the pod template annotation not to be treated as drift")
+	}
+}
+
+// TestEnsureMetricsIntegrationDeleted verifies that disabling metrics
+// integration for an ingresscontroller deletes its metrics objects and that
+// the shared metrics roles are deleted only once no ingresscontroller
+// integrates metrics.
+func TestEnsureMetricsIntegrationDeleted(t *testing.T) {
+	newIngressController := func(name string) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "openshift-ingress-operator",
+				Name:        name,
+				Annotations: map[string]string{disableMetricsIntegrationAnnotation: "true"},
+			},
+		}
+	}
+	defaultIC, otherIC := newIngressController("default"), newIngressController("other")
+	otherIC.Annotations = nil
+	client := newFakeClient(defaultIC, otherIC)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-internal-default"}}
+	for _, ic := range []*operatorv1.IngressController{defaultIC, otherIC} {
+		if err := r.ensureMetricsIntegration(ic, svc, metav1.OwnerReference{Name: "router-" + ic.Name}); err != nil {
+			t.Fatalf("failed to ensure metrics integration for %s: %v", ic.Name, err)
+		}
+	}
+
+	exists := func(o runtime.Object, name types.NamespacedName) bool {
+		if err := client.Get(context.TODO(), name, o); err != nil {
+			if errors.IsNotFound(err) {
+				return false
+			}
+			t.Fatal(err)
+		}
+		return true
+	}
+	perIngressObjectsExist := func(ic *operatorv1.IngressController) []string {
+		var found []string
+		sm := &unstructured.Unstructured{}
+		sm.SetAPIVersion("monitoring.coreos.com/v1")
+		sm.SetKind("ServiceMonitor")
+		if exists(sm, IngressControllerServiceMonitorName(ic, "openshift-ingress")) {
+			found = append(found, "servicemonitor")
+		}
+		statsSecret := manifests.RouterStatsSecret(ic, "openshift-ingress")
+		if exists(&corev1.Secret{}, types.NamespacedName{Namespace: statsSecret.Namespace, Name: statsSecret.Name}) {
+			found = append(found, "stats secret")
+		}
+		if exists(&corev1.ConfigMap{}, MetricsCABundleConfigMapName(ic, "openshift-ingress")) {
+			found = append(found, "CA bundle configmap")
+		}
+		return found
+	}
+	sharedRolesExist := func() bool {
+		cr := manifests.MetricsClusterRole()
+		return exists(&rbacv1.ClusterRole{}, types.NamespacedName{Name: cr.Name})
+	}
+
+	if err := r.ensureMetricsIntegrationDeleted(defaultIC); err != nil {
+		t.Fatalf("failed to delete metrics integration: %v", err)
+	}
+	if found := perIngressObjectsExist(defaultIC); len(found) != 0 {
+		t.Errorf("expected metrics objects for default to be deleted, found %v", found)
+	}
+	if found := perIngressObjectsExist(otherIC); len(found) != 3 {
+		t.Errorf("expected metrics objects for other to remain, found only %v", found)
+	}
+	if !sharedRolesExist() {
+		t.Error("expected shared metrics roles to remain while other integrates metrics")
+	}
+
+	otherIC.Annotations = map[string]string{disableMetricsIntegrationAnnotation: "true"}
+	if err := client.Update(context.TODO(), otherIC); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ensureMetricsIntegrationDeleted(otherIC); err != nil {
+		t.Fatalf("failed to delete metrics integration: %v", err)
+	}
+	if found := perIngressObjectsExist(otherIC); len(found) != 0 {
+		t.Errorf("expected metrics objects for other to be deleted, found %v", found)
+	}
+	if sharedRolesExist() {
+		t.Error("expected shared metrics roles to be deleted once no ingresscontroller integrates metrics")
+	}
+
+	// Deleting again is a no-op.
+	if err := r.ensureMetricsIntegrationDeleted(otherIC); err != nil {
+		t.Errorf("expected deleting metrics integration again to succeed, got %v", err)
+	}
+}
+
+// TestEnsureMetricsIntegrationMonitoringNamespace verifies that the metrics
+// role bindings grant access to the prometheus service account in the
+// configured monitoring namespace and are updated when it changes.
+func TestEnsureMetricsIntegrationMonitoringNamespace(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"},
+	}
+	client := newFakeClient(ic)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-internal-default"}}
+	subjectNamespaces := func() []string {
+		t.Helper()
+		crb := &rbacv1.ClusterRoleBinding{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: "router-monitoring"}, crb); err != nil {
+			t.Fatal(err)
+		}
+		rb := &rbacv1.RoleBinding{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Namespace: "openshift-ingress", Name: "prometheus-k8s"}, rb); err != nil {
+			t.Fatal(err)
+		}
+		namespaces := []string{}
+		for _, subject := range append(crb.Subjects, rb.Subjects...) {
+			namespaces = append(namespaces, subject.Kind+"/"+subject.Namespace+"/"+subject.Name)
+		}
+		return namespaces
+	}
+
+	if err := r.ensureMetricsIntegration(ic, svc, metav1.OwnerReference{Name: "router-default"}); err != nil {
+		t.Fatalf("failed to ensure metrics integration: %v", err)
+	}
+	expect := []string{"ServiceAccount/openshift-monitoring/prometheus-k8s", "ServiceAccount/openshift-monitoring/prometheus-k8s"}
+	if actual := subjectNamespaces(); !reflect.DeepEqual(actual, expect) {
+		t.Errorf("expected subjects %v, got %v", expect, actual)
+	}
+
+	r.MonitoringNamespace = "custom-monitoring"
+	if err := r.ensureMetricsIntegration(ic, svc, metav1.OwnerReference{Name: "router-default"}); err != nil {
+		t.Fatalf("failed to ensure metrics integration: %v", err)
+	}
+	expect = []string{"ServiceAccount/custom-monitoring/prometheus-k8s", "ServiceAccount/custom-monitoring/prometheus-k8s"}
+	if actual := subjectNamespaces(); !reflect.DeepEqual(actual, expect) {
+		t.Errorf("expected subjects %v after changing the monitoring namespace, got %v", expect, actual)
+	}
+
+	// Ensuring again does not update the bindings.
+	updates := client.calls["update"]
+	if err := r.ensureMetricsIntegration(ic, svc, metav1.OwnerReference{Name: "router-default"}); err != nil {
+		t.Fatalf("failed to ensure metrics integration: %v", err)
+	}
+	if client.calls["update"] != updates {
+		t.Errorf("expected no further updates, got %d", client.calls["update"]-updates)
+	}
+}
+
+// secretDeleteFailingClient is a fakeClient that fails to delete secrets.
+type secretDeleteFailingClient struct {
+	*fakeClient
+}
+
+func (c *secretDeleteFailingClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOptionFunc) error {
+	if _, ok := obj.(*corev1.Secret); ok {
+		return fmt.Errorf("simulated failure")
+	}
+	return c.fakeClient.Delete(ctx, obj, opts...)
+}
+
+// TestEnsureIngressDeletedCleansUpMetrics verifies that deleting an
+// ingresscontroller deletes its servicemonitor and stats secret before the
+// finalizer is removed, and that the finalizer is kept if cleanup fails.
+func TestEnsureIngressDeletedCleansUpMetrics(t *testing.T) {
+	now := metav1.Now()
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "openshift-ingress-operator",
+			Name:              "default",
+			Finalizers:        []string{IngressControllerFinalizer},
+			DeletionTimestamp: &now,
+		},
+	}
+	fake := newFakeClient(ic)
+	failing := &secretDeleteFailingClient{fake}
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client: failing,
+		cache:  &fakeCache{client: fake},
+	}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-internal-default"}}
+	if err := r.ensureMetricsIntegration(ic, svc, metav1.OwnerReference{Name: "router-default"}); err != nil {
+		t.Fatalf("failed to ensure metrics integration: %v", err)
+	}
+
+	serviceMonitorExists := func() bool {
+		err := fake.Get(context.TODO(), IngressControllerServiceMonitorName(ic, "openshift-ingress"), &unstructured.Unstructured{})
+		return err == nil
+	}
+	statsSecret := manifests.RouterStatsSecret(ic, "openshift-ingress")
+	statsSecretExists := func() bool {
+		err := fake.Get(context.TODO(), types.NamespacedName{Namespace: statsSecret.Namespace, Name: statsSecret.Name}, &corev1.Secret{})
+		return err == nil
+	}
+	hasFinalizer := func() bool {
+		current := &operatorv1.IngressController{}
+		if err := fake.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+			t.Fatal(err)
+		}
+		return slice.ContainsString(current.Finalizers, IngressControllerFinalizer)
+	}
+
+	err := r.ensureIngressDeleted(ic, &configv1.DNS{}, &configv1.Infrastructure{})
+	if err == nil || !strings.Contains(err.Error(), "simulated failure") {
+		t.Fatalf("expected the secret deletion failure to be reported, got %v", err)
+	}
+	if serviceMonitorExists() {
+		t.Error("expected the servicemonitor to be deleted despite the secret deletion failure")
+	}
+	if !hasFinalizer() {
+		t.Error("expected the finalizer to be kept when cleanup fails")
+	}
+
+	r.client = fake
+	if err := r.ensureIngressDeleted(ic, &configv1.DNS{}, &configv1.Infrastructure{}); err != nil {
+		t.Fatalf("failed to delete ingresscontroller: %v", err)
+	}
+	if statsSecretExists() {
+		t.Error("expected the stats secret to be deleted")
+	}
+	if hasFinalizer() {
+		t.Error("expected the finalizer to be removed after cleanup succeeds")
+	}
+}
+
+// TestEnsureIngressDeletedFinalizerTimeout verifies that the finalizer of a
+// deleted ingresscontroller whose cleanup keeps failing is kept until the
+// finalizer timeout expires, and is then removed with a warning event.
+func TestEnsureIngressDeletedFinalizerTimeout(t *testing.T) {
+	testCases := []struct {
+		description  string
+		timeout      time.Duration
+		deletedAgo   time.Duration
+		expectRemove bool
+	}{
+		{
+			description: "no timeout",
+			deletedAgo:  24 * time.Hour,
+		},
+		{
+			description: "timeout not yet expired",
+			timeout:     time.Hour,
+			deletedAgo:  time.Minute,
+		},
+		{
+			description:  "timeout expired",
+			timeout:      time.Hour,
+			deletedAgo:   2 * time.Hour,
+			expectRemove: true,
+		},
+	}
+	for _, tc := range testCases {
+		deleted := metav1.NewTime(time.Now().Add(-tc.deletedAgo))
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "openshift-ingress-operator",
+				Name:              "default",
+				Finalizers:        []string{IngressControllerFinalizer},
+				DeletionTimestamp: &deleted,
+			},
+		}
+		fake := newFakeClient(ic, manifests.RouterStatsSecret(ic, "openshift-ingress"))
+		recorder := record.NewFakeRecorder(10)
+		r := &reconciler{
+			Config:   Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress", FinalizerTimeout: tc.timeout},
+			client:   &secretDeleteFailingClient{fake},
+			cache:    &fakeCache{client: fake},
+			recorder: recorder,
+		}
+
+		err := r.ensureIngressDeleted(ic, &configv1.DNS{}, &configv1.Infrastructure{})
+		current := &operatorv1.IngressController{}
+		if err := fake.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+			t.Fatal(err)
+		}
+		hasFinalizer := slice.ContainsString(current.Finalizers, IngressControllerFinalizer)
+		if !tc.expectRemove {
+			if err == nil || !strings.Contains(err.Error(), "simulated failure") {
+				t.Errorf("%s: expected the cleanup failure to be reported, got %v", tc.description, err)
+			}
+			if !hasFinalizer {
+				t.Errorf("%s: expected the finalizer to be kept", tc.description)
+			}
+			if len(recorder.Events) != 0 {
+				t.Errorf("%s: expected no event, got %q", tc.description, <-recorder.Events)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", tc.description, err)
+		}
+		if hasFinalizer {
+			t.Errorf("%s: expected the finalizer to be removed", tc.description)
+		}
+		select {
+		case event := <-recorder.Events:
+			expect := "Warning FinalizerForceRemoved Cleanup did not succeed within 1h0m0s of deletion, so the finalizer was removed anyway; the load balancer, its DNS records, and other operands may be orphaned and must be deleted manually: failed to delete metrics integration for ingress default: failed to delete *v1.Secret openshift-ingress/router-stats-default: simulated failure"
+			if event != expect {
+				t.Errorf("%s: expected event %q, got %q", tc.description, expect, event)
+			}
+		default:
+			t.Errorf("%s: expected a FinalizerForceRemoved event", tc.description)
+		}
+	}
+}
+
+// deletionOrderClient is a fakeClient that records the deletion of services
+// and deployments.
+type deletionOrderClient struct {
+	*fakeClient
+	log *[]string
+}
+
+func (c *deletionOrderClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOptionFunc) error {
+	switch o := obj.(type) {
+	case *corev1.Service:
+		*c.log = append(*c.log, "delete service "+o.Name)
+	case *appsv1.Deployment:
+		*c.log = append(*c.log, "delete deployment "+o.Name)
+	}
+	return c.fakeClient.Delete(ctx, obj, opts...)
+}
+
+// deletionOrderDNSManager is a DNS manager that records the deletion of DNS
+// records and fails to delete them if err is set.
+type deletionOrderDNSManager struct {
+	fakeDNSManager
+	log *[]string
+	err error
+}
+
+func (m *deletionOrderDNSManager) Delete(record *dns.Record) error {
+	*m.log = append(*m.log, fmt.Sprintf("delete DNS record %s in zone %s", record.Alias.Domain, record.Zone.ID))
+	return m.err
+}
+
+// TestEnsureIngressDeletedOrder verifies that deleting an ingresscontroller
+// deletes the DNS records of its load balancer before the load balancer
+// service and the load balancer service before the router deployment, that a
+// failure to delete the DNS records holds back the deletion of the load
+// balancer until the DNS cleanup timeout expires, and that the failure is
+// then reported while the load balancer and router deployment are deleted.
+func TestEnsureIngressDeletedOrder(t *testing.T) {
+	dnsRecords := []string{
+		"delete DNS record *.apps.example.com in zone private",
+		"delete DNS record *.apps.example.com in zone public",
+	}
+	testCases := []struct {
+		description     string
+		annotations     map[string]string
+		dnsErr          error
+		expectLog       []string
+		expectErr       string
+		expectService   bool
+		expectFinalizer bool
+		expectEvent     string
+	}{
+		{
+			description: "DNS records deleted",
+			expectLog:   append(append([]string{}, dnsRecords...), "delete service router-default", "delete deployment router-default"),
+		},
+		{
+			description:     "DNS failure within the DNS cleanup timeout",
+			dnsErr:          fmt.Errorf("simulated DNS failure"),
+			expectLog:       dnsRecords,
+			expectErr:       "failed to finalize load balancer service for default: [failed to delete DNS record",
+			expectService:   true,
+			expectFinalizer: true,
+		},
+		{
+			description:     "DNS failure after the DNS cleanup timeout",
+			annotations:     map[string]string{dnsCleanupTimeoutAnnotation: "0s"},
+			dnsErr:          fmt.Errorf("simulated DNS failure"),
+			expectLog:       append(append([]string{}, dnsRecords...), "delete service router-default", "delete deployment router-default"),
+			expectErr:       "failed to finalize load balancer service for default: [failed to delete DNS record",
+			expectFinalizer: true,
+			expectEvent:     "Warning DNSRecordCleanupFailed The DNS records of load balancer service openshift-ingress/router-default were not deleted within 0s of deletion, so the load balancer is being deleted anyway; the records may resolve to a deleted load balancer and must be deleted manually: [failed to delete DNS record",
+		},
+	}
+	for _, tc := range testCases {
+		deleted := metav1.NewTime(time.Now().Add(-time.Minute))
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "openshift-ingress-operator",
+				Name:              "default",
+				Annotations:       tc.annotations,
+				Finalizers:        []string{IngressControllerFinalizer},
+				DeletionTimestamp: &deleted,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				Domain: "apps.example.com",
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+				},
+			},
+		}
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "openshift-ingress",
+				Name:       "router-default",
+				Finalizers: []string{loadBalancerServiceFinalizer},
+			},
+		}
+		service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.cloudprovider.example.com"}}
+		fake := newFakeClient(ic, service)
+		log := []string{}
+		recorder := record.NewFakeRecorder(10)
+		r := &reconciler{
+			Config: Config{
+				Namespace:        "openshift-ingress-operator",
+				OperandNamespace: "openshift-ingress",
+				DNSManager:       &deletionOrderDNSManager{log: &log, err: tc.dnsErr},
+			},
+			client:   &deletionOrderClient{fakeClient: fake, log: &log},
+			cache:    &fakeCache{client: fake},
+			recorder: recorder,
+		}
+
+		err := r.ensureIngressDeleted(ic, globalConfig, &configv1.Infrastructure{})
+		switch {
+		case len(tc.expectErr) == 0 && err != nil:
+			t.Errorf("%s: expected no error, got %v", tc.description, err)
+		case len(tc.expectErr) != 0 && (err == nil || !strings.HasPrefix(err.Error(), tc.expectErr) || !strings.Contains(err.Error(), "simulated DNS failure")):
+			t.Errorf("%s: expected error starting with %q, got %v", tc.description, tc.expectErr, err)
+		}
+		if !reflect.DeepEqual(log, tc.expectLog) {
+			t.Errorf("%s: expected deletions %q, got %q", tc.description, tc.expectLog, log)
+		}
+		serviceExists := fake.Get(context.TODO(), types.NamespacedName{Namespace: service.Namespace, Name: service.Name}, &corev1.Service{}) == nil
+		if serviceExists != tc.expectService {
+			t.Errorf("%s: expected load balancer service to exist to be %t, got %t", tc.description, tc.expectService, serviceExists)
+		}
+		current := &operatorv1.IngressController{}
+		if err := fake.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+			t.Fatal(err)
+		}
+		if hasFinalizer := slice.ContainsString(current.Finalizers, IngressControllerFinalizer); hasFinalizer != tc.expectFinalizer {
+			t.Errorf("%s: expected finalizer to be kept to be %t, got %t", tc.description, tc.expectFinalizer, hasFinalizer)
+		}
+		select {
+		case event := <-recorder.Events:
+			if len(tc.expectEvent) == 0 || !strings.HasPrefix(event, tc.expectEvent) || !strings.Contains(event, "simulated DNS failure") {
+				t.Errorf("%s: expected event starting with %q, got %q", tc.description, tc.expectEvent, event)
+			}
+		default:
+			if len(tc.expectEvent) != 0 {
+				t.Errorf("%s: expected event starting with %q", tc.description, tc.expectEvent)
+			}
+		}
+	}
+}
+
+// TestReconcileUnmanagedIngressController verifies that the operator makes no
+// changes for an unmanaged ingresscontroller other than removing its own
+// finalizer when the ingresscontroller is deleted.
+func TestReconcileUnmanagedIngressController(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "openshift-ingress-operator",
+			Name:       "default",
+			Finalizers: []string{IngressControllerFinalizer},
+		},
+	}
+	client := newFakeClient(ic)
+	r := &reconciler{
+		Config: Config{
+			Namespace:                   "openshift-ingress-operator",
+			OperandNamespace:            "openshift-ingress",
+			UnmanagedIngressControllers: []string{"default"},
+		},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}}
+
+	// The cluster configs do not exist, so reconciling a managed
+	// ingresscontroller would fail.
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("expected reconciling an unmanaged ingresscontroller to succeed, got %v", err)
+	}
+	if len(client.calls) != 0 {
+		t.Errorf("expected no changes, got %v", client.calls)
+	}
+
+	now := metav1.Now()
+	ic.DeletionTimestamp = &now
+	if err := client.replace(ic); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("failed to reconcile deleted ingresscontroller: %v", err)
+	}
+	current := &operatorv1.IngressController{}
+	if err := client.Get(context.TODO(), request.NamespacedName, current); err != nil {
+		t.Fatal(err)
+	}
+	if len(current.Finalizers) != 0 {
+		t.Errorf("expected the finalizer to be removed, got %v", current.Finalizers)
+	}
+	if client.calls["update"] != 1 || len(client.calls) != 1 {
+		t.Errorf("expected only the finalizer update, got %v", client.calls)
+	}
+}
+
+// TestRouterPodEvents verifies that status changes of router pods in the
+// operand namespace enqueue the ingresscontroller that the pods belong to and
+// that other pod events are ignored.
+func TestRouterPodEvents(t *testing.T) {
+	pod := func(namespace string, labels map[string]string, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "router-sharded-1",
+				Labels:    labels,
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	routerLabels := map[string]string{controllerDeploymentLabel: "sharded"}
+	update := func(old, new *corev1.Pod) event.UpdateEvent {
+		return event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: new, ObjectNew: new}
+	}
+
+	p := routerPodPredicate("openshift-ingress")
+	notReady := pod("openshift-ingress", routerLabels, corev1.ConditionFalse)
+	ready := pod("openshift-ingress", routerLabels, corev1.ConditionTrue)
+	relabeled := ready.DeepCopy()
+	relabeled.Labels["example.com/team"] = "network"
+	otherNamespace := pod("openshift-ingress-operator", routerLabels, corev1.ConditionTrue)
+	otherPod := pod("openshift-ingress", map[string]string{"app": "other"}, corev1.ConditionTrue)
+	testCases := []struct {
+		description string
+		accepted    bool
+		expect      bool
+	}{
+		{"router pod created", p.Create(event.CreateEvent{Meta: notReady, Object: notReady}), true},
+		{"router pod became ready", p.Update(update(notReady, ready)), true},
+		{"router pod metadata changed", p.Update(update(ready, relabeled)), false},
+		{"router pod deleted", p.Delete(event.DeleteEvent{Meta: ready, Object: ready}), true},
+		{"pod in another namespace created", p.Create(event.CreateEvent{Meta: otherNamespace, Object: otherNamespace}), false},
+		{"other pod became ready", p.Update(update(pod("openshift-ingress", otherPod.Labels, corev1.ConditionFalse), otherPod)), false},
+	}
+	for _, tc := range testCases {
+		if tc.accepted != tc.expect {
+			t.Errorf("%s: expected the predicate to return %t, got %t", tc.description, tc.expect, tc.accepted)
+		}
+	}
+
+	q := &fakeQueue{delayed: map[reconcile.Request][]time.Duration{}}
+	h := enqueueRequestForOwningIngressController("openshift-ingress-operator", 0, 1)
+	h.Update(update(notReady, ready), q)
+	expect := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "sharded"}}
+	if len(q.added) != 1 || q.added[0] != expect {
+		t.Errorf("expected a request for %s, got %v", expect, q.added)
+	}
+}
+
+// TestRecreateDeletedOperands verifies that deleting the router deployment, the
+// load balancer service, or the internal service out of band enqueues the
+// ingresscontroller and that reconciling it recreates the deleted operand, and
+// that the load balancer service's finalizer does not keep a deleted service
+// from being recreated.
+func TestRecreateDeletedOperands(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	client := newFakeClient()
+	r := &reconciler{
+		Config: Config{
+			OperandNamespace:       "openshift-ingress",
+			IngressControllerImage: "quay.io/openshift/router:latest",
+		},
+		client: client,
+	}
+	// ensureAll ensures the operands and returns them by name.
+	ensureAll := func() map[string]runtime.Object {
+		t.Helper()
+		deployment, err := r.ensureRouterDeployment(ci, infraConfig)
+		if err != nil {
+			t.Fatalf("failed to ensure router deployment: %v", err)
+		}
+		deploymentRef := metav1.OwnerReference{Name: deployment.Name}
+		lbService, err := r.ensureLoadBalancerService(ci, deploymentRef, infraConfig)
+		if err != nil {
+			t.Fatalf("failed to ensure load balancer service: %v", err)
+		}
+		internalService, err := r.ensureInternalIngressControllerService(ci, deploymentRef)
+		if err != nil {
+			t.Fatalf("failed to ensure internal service: %v", err)
+		}
+		operands := map[string]runtime.Object{
+			"deployment":       deployment,
+			"internal service": internalService,
+		}
+		if lbService != nil {
+			operands["lb service"] = lbService
+		}
+		return operands
+	}
+	exists := func(obj runtime.Object) bool {
+		t.Helper()
+		key, err := fakeClientKey(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, ok := client.objects[key]
+		return ok
+	}
+
+	expect := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "default"}}
+	h := enqueueRequestForOwningIngressController("openshift-ingress-operator", 0, 1)
+	for _, name := range []string{"deployment", "lb service", "internal service"} {
+		operand := ensureAll()[name]
+		objMeta, err := meta.Accessor(operand)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Delete(context.TODO(), operand); err != nil {
+			t.Fatalf("%s: failed to delete: %v", name, err)
+		}
+		q := &fakeQueue{delayed: map[reconcile.Request][]time.Duration{}}
+		h.Delete(event.DeleteEvent{Meta: objMeta, Object: operand}, q)
+		if len(q.added) != 1 || q.added[0] != expect {
+			t.Errorf("%s: expected the delete event to enqueue %s, got %v", name, expect, q.added)
+		}
+		creates := client.calls["create"]
+		recreated := ensureAll()[name]
+		if !exists(operand) || recreated == nil {
+			t.Errorf("%s: expected the operand to be recreated", name)
+		}
+		if client.calls["create"] != creates+1 {
+			t.Errorf("%s: expected 1 create, got %d", name, client.calls["create"]-creates)
+		}
+	}
+
+	// A deleted load balancer service is held by the operator's finalizer,
+	// which must be removed so that the deletion completes and the service
+	// can be recreated.
+	lbService := ensureAll()["lb service"].(*corev1.Service)
+	now := metav1.Now()
+	lbService.DeletionTimestamp = &now
+	if err := client.replace(lbService); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ensureAll()["lb service"]; ok {
+		t.Error("expected no load balancer service to be returned while it is being deleted")
+	}
+	current := &corev1.Service{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: lbService.Namespace, Name: lbService.Name}, current); err != nil {
+		t.Fatal(err)
+	}
+	if slice.ContainsString(current.Finalizers, loadBalancerServiceFinalizer) {
+		t.Errorf("expected finalizer %s to be removed from the deleted service, got %v", loadBalancerServiceFinalizer, current.Finalizers)
+	}
+	if err := client.Delete(context.TODO(), current); err != nil {
+		t.Fatal(err)
+	}
+	recreated, ok := ensureAll()["lb service"].(*corev1.Service)
+	if !ok || recreated.DeletionTimestamp != nil || !slice.ContainsString(recreated.Finalizers, loadBalancerServiceFinalizer) {
+		t.Errorf("expected the load balancer service to be recreated with finalizer %s, got %v", loadBalancerServiceFinalizer, recreated)
+	}
+}
+
+// TestDefaultCertificateSecretEvents verifies that events for a secret in the
+// operand namespace are mapped to the ingresscontrollers that use the secret
+// as their default certificate.
+func TestDefaultCertificateSecretEvents(t *testing.T) {
+	secret := func(namespace, name string, data string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Data:       map[string][]byte{"tls.crt": []byte(data)},
+		}
+	}
+	update := func(old, new *corev1.Secret) event.UpdateEvent {
+		return event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: new, ObjectNew: new}
+	}
+
+	p := operandSecretPredicate("openshift-ingress")
+	original := secret("openshift-ingress", "custom-cert", "cert-1")
+	rotated := secret("openshift-ingress", "custom-cert", "cert-2")
+	relabeled := original.DeepCopy()
+	relabeled.Labels = map[string]string{"example.com/team": "network"}
+	otherNamespace := secret("openshift-config", "custom-cert", "cert-1")
+	testCases := []struct {
+		description string
+		accepted    bool
+		expect      bool
+	}{
+		{"secret created", p.Create(event.CreateEvent{Meta: original, Object: original}), true},
+		{"secret data changed", p.Update(update(original, rotated)), true},
+		{"secret metadata changed", p.Update(update(original, relabeled)), false},
+		{"secret deleted", p.Delete(event.DeleteEvent{Meta: rotated, Object: rotated}), true},
+		{"secret in another namespace created", p.Create(event.CreateEvent{Meta: otherNamespace, Object: otherNamespace}), false},
+	}
+	for _, tc := range testCases {
+		if tc.accepted != tc.expect {
+			t.Errorf("%s: expected the predicate to return %t, got %t", tc.description, tc.expect, tc.accepted)
+		}
+	}
+
+	ingressController := func(name, certName string) *operatorv1.IngressController {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: name},
+		}
+		if certName != "-" {
+			ic.Spec.DefaultCertificate = &corev1.LocalObjectReference{Name: certName}
+		}
+		return ic
+	}
+	client := newFakeClient(
+		ingressController("default", "custom-cert"),
+		ingressController("sharded", "custom-cert"),
+		ingressController("internal", "-"),
+		ingressController("legacy", ""),
+	)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	request := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: name}}
+	}
+	mapCases := []struct {
+		secret string
+		expect []reconcile.Request
+	}{
+		{"custom-cert", []reconcile.Request{request("default"), request("sharded")}},
+		{"router-certs-internal", []reconcile.Request{request("internal")}},
+		{"router-certs-legacy", []reconcile.Request{request("legacy")}},
+		{"router-certs-default", []reconcile.Request{}},
+	}
+	for _, tc := range mapCases {
+		s := secret("openshift-ingress", tc.secret, "cert-1")
+		requests := r.secretToIngressControllers(handler.MapObject{Meta: s, Object: s})
+		if len(requests) != len(tc.expect) {
+			t.Errorf("secret %s: expected requests %v, got %v", tc.secret, tc.expect, requests)
+			continue
+		}
+		for _, expect := range tc.expect {
+			found := false
+			for _, req := range requests {
+				if req == expect {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("secret %s: expected requests %v, got %v", tc.secret, tc.expect, requests)
+			}
+		}
+	}
+}
+
+func TestEffectiveIngressDomain(t *testing.T) {
+	testCases := []struct {
+		description         string
+		specDomain          string
+		clusterDomain       string
+		preferClusterConfig bool
+		expect              string
+	}{
+		{"spec.domain takes precedence", "shard.example.com", "apps.example.com", false, "shard.example.com"},
+		{"cluster config domain as fallback", "", "apps.example.com", false, "apps.example.com"},
+		{"cluster config domain takes precedence", "shard.example.com", "apps.example.com", true, "apps.example.com"},
+		{"spec.domain as fallback", "shard.example.com", "", true, "shard.example.com"},
+		{"no domain", "", "", false, ""},
+		{"no domain with cluster config precedence", "", "", true, ""},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{Spec: operatorv1.IngressControllerSpec{Domain: tc.specDomain}}
+		ingressConfig := &configv1.Ingress{Spec: configv1.IngressSpec{Domain: tc.clusterDomain}}
+		if actual := effectiveIngressDomain(ic, ingressConfig, tc.preferClusterConfig); actual != tc.expect {
+			t.Errorf("%s: expected %q, got %q", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+// TestEnforceEffectiveIngressDomain verifies that the configured precedence
+// determines the published domain and that an empty effective domain is
+// either deferred, leaving the status unchanged, or reported as an error.
+func TestEnforceEffectiveIngressDomain(t *testing.T) {
+	testCases := []struct {
+		description   string
+		config        Config
+		specDomain    string
+		clusterDomain string
+		expectDomain  string
+		expectError   string
+	}{
+		{
+			description:   "spec.domain takes precedence",
+			specDomain:    "shard.example.com",
+			clusterDomain: "apps.example.com",
+			expectDomain:  "shard.example.com",
+		},
+		{
+			description:   "cluster config domain takes precedence",
+			config:        Config{PreferClusterIngressDomain: true},
+			specDomain:    "shard.example.com",
+			clusterDomain: "apps.example.com",
+			expectDomain:  "apps.example.com",
+		},
+		{
+			description: "empty domain is deferred",
+		},
+		{
+			description: "empty domain is an error",
+			config:      Config{FailOnEmptyIngressDomain: true},
+			expectError: "neither spec.domain nor the cluster ingress config specifies a domain",
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "sharded"},
+			Spec:       operatorv1.IngressControllerSpec{Domain: tc.specDomain},
+		}
+		client := newFakeClient(ic)
+		tc.config.Namespace = "openshift-ingress-operator"
+		r := &reconciler{Config: tc.config, client: client, cache: &fakeCache{client: client}}
+		ingressConfig := &configv1.Ingress{Spec: configv1.IngressSpec{Domain: tc.clusterDomain}}
+
+		err := r.enforceEffectiveIngressDomain(ic, ingressConfig)
+		switch {
+		case len(tc.expectError) == 0 && err != nil:
+			t.Errorf("%s: expected no error, got %v", tc.description, err)
+		case len(tc.expectError) != 0 && (err == nil || err.Error() != tc.expectError):
+			t.Errorf("%s: expected error %q, got %v", tc.description, tc.expectError, err)
+		}
+		if ic.Status.Domain != tc.expectDomain {
+			t.Errorf("%s: expected status domain %q, got %q", tc.description, tc.expectDomain, ic.Status.Domain)
+		}
+		if len(tc.expectDomain) == 0 && client.calls["status-update"] != 0 {
+			t.Errorf("%s: expected no status update, got %d", tc.description, client.calls["status-update"])
+		}
+	}
+}
+
+// TestConfigMapEventToIngressControllers verifies that a configmap event
+// enqueues the ingresscontroller that owns or references the configmap once,
+// and that configmap events share the operand event rate limit.
+func TestConfigMapEventToIngressControllers(t *testing.T) {
+	client := newFakeClient(&operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "openshift-ingress-operator",
+			Name:        "default",
+			Annotations: map[string]string{backendCABundleConfigMapAnnotation: "backend-ca"},
+		},
+	})
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	operandEventHandler := enqueueRequestForOwningIngressController("openshift-ingress-operator", 1, 2)
+	h := operandEventHandler.withMapper(handler.ToRequestsFunc(r.configMapEventToIngressControllers))
+	configMap := func(namespace, name string, owned bool) event.CreateEvent {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		if owned {
+			cm.Labels = map[string]string{manifests.OwningIngressControllerLabel: "default"}
+		}
+		return event.CreateEvent{Meta: cm, Object: cm}
+	}
+	expect := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "default"}}
+
+	// A configmap that the ingresscontroller both owns and references
+	// enqueues it once.
+	q := &fakeQueue{delayed: map[reconcile.Request][]time.Duration{}}
+	h.Create(configMap("openshift-ingress", "backend-ca", true), q)
+	if len(q.added) != 1 || q.added[0] != expect {
+		t.Errorf("expected a single request for %s, got %v", expect, q.added)
+	}
+
+	// A configmap with the referenced name outside the operand namespace
+	// is ignored.
+	q = &fakeQueue{delayed: map[reconcile.Request][]time.Duration{}}
+	h.Create(configMap("openshift-config", "backend-ca", false), q)
+	if len(q.added) != 0 || len(q.delayed) != 0 {
+		t.Errorf("expected no requests, got %v immediate and %v delayed", q.added, q.delayed)
+	}
+
+	// Configmap events count against the same limit as the other operand
+	// events, so the burst of 2 is used up by one event of each kind.
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "openshift-ingress",
+		Name:      "router-internal-default",
+		Labels:    map[string]string{manifests.OwningIngressControllerLabel: "default"},
+	}}
+	operandEventHandler.Create(event.CreateEvent{Meta: service, Object: service}, q)
+	h.Create(configMap("openshift-ingress", "backend-ca", false), q)
+	if len(q.added) != 1 || len(q.delayed[expect]) != 1 {
+		t.Errorf("expected 1 immediate and 1 delayed request, got %v immediate and %v delayed", q.added, q.delayed)
+	}
+}
@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeClient is a minimal in-memory client.Client for unit tests.  Objects
+// are stored by type, namespace, and name, and every call is counted by verb
+// so that tests can assert which API requests a reconciler made.
+type fakeClient struct {
+	objects map[string]runtime.Object
+	// calls counts the number of calls for each verb ("create", "update",
+	// "delete", "patch", "status-update", "status-patch").
+	calls map[string]int
+}
+
+var _ client.Client = &fakeClient{}
+
+// newFakeClient returns a fakeClient that contains the given objects.
+func newFakeClient(objs ...runtime.Object) *fakeClient {
+	c := &fakeClient{
+		objects: map[string]runtime.Object{},
+		calls:   map[string]int{},
+	}
+	for _, obj := range objs {
+		key, err := fakeClientKey(obj)
+		if err != nil {
+			panic(err)
+		}
+		c.objects[key] = fakeClientCopy(obj)
+	}
+	return c
+}
+
+func fakeClientKey(obj runtime.Object) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	return fakeClientTypeKey(obj, accessor.GetNamespace(), accessor.GetName()), nil
+}
+
+// fakeClientTypeKey returns the key of the object of the given object's type
+// with the given namespace and name.  Unstructured objects are distinguished
+// by their kind, as they would be by a real client.
+func fakeClientTypeKey(obj runtime.Object, namespace, name string) string {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return fmt.Sprintf("%T/%s/%s/%s", obj, u.GroupVersionKind().GroupKind(), namespace, name)
+	}
+	return fmt.Sprintf("%T/%s/%s", obj, namespace, name)
+}
+
+// fakeClientCopy returns a deep copy of the given object.  Unstructured
+// objects are round-tripped through JSON, as they would be by a real client,
+// because they may contain values that runtime.DeepCopyJSON cannot copy.
+func fakeClientCopy(obj runtime.Object) runtime.Object {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj.DeepCopyObject()
+	}
+	data, err := u.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+	copied := &unstructured.Unstructured{}
+	if err := copied.UnmarshalJSON(data); err != nil {
+		panic(err)
+	}
+	return copied
+}
+
+func fakeClientNotFound(obj runtime.Object, name string) error {
+	return errors.NewNotFound(schema.GroupResource{Resource: fmt.Sprintf("%T", obj)}, name)
+}
+
+func (c *fakeClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	stored, ok := c.objects[fakeClientTypeKey(obj, key.Namespace, key.Name)]
+	if !ok {
+		return fakeClientNotFound(obj, key.Name)
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(fakeClientCopy(stored)).Elem())
+	return nil
+}
+
+func (c *fakeClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOptionFunc) error {
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	items := reflect.ValueOf(list).Elem().FieldByName("Items")
+	if !items.IsValid() {
+		return fmt.Errorf("unsupported list type %T", list)
+	}
+	itemType := fmt.Sprintf("%T", reflect.New(items.Type().Elem()).Interface())
+	result := reflect.MakeSlice(items.Type(), 0, 0)
+	for _, obj := range c.objects {
+		if fmt.Sprintf("%T", obj) != itemType {
+			continue
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		if len(listOpts.Namespace) != 0 && accessor.GetNamespace() != listOpts.Namespace {
+			continue
+		}
+		if listOpts.LabelSelector != nil && !listOpts.LabelSelector.Matches(labels.Set(accessor.GetLabels())) {
+			continue
+		}
+		result = reflect.Append(result, reflect.ValueOf(fakeClientCopy(obj)).Elem())
+	}
+	items.Set(result)
+	return nil
+}
+
+func (c *fakeClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOptionFunc) error {
+	c.calls["create"]++
+	key, err := fakeClientKey(obj)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.objects[key]; ok {
+		accessor, _ := meta.Accessor(obj)
+		return errors.NewAlreadyExists(schema.GroupResource{Resource: fmt.Sprintf("%T", obj)}, accessor.GetName())
+	}
+	c.objects[key] = fakeClientCopy(obj)
+	return nil
+}
+
+func (c *fakeClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOptionFunc) error {
+	c.calls["delete"]++
+	key, err := fakeClientKey(obj)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.objects[key]; !ok {
+		accessor, _ := meta.Accessor(obj)
+		return fakeClientNotFound(obj, accessor.GetName())
+	}
+	delete(c.objects, key)
+	return nil
+}
+
+func (c *fakeClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOptionFunc) error {
+	c.calls["update"]++
+	return c.replace(obj)
+}
+
+func (c *fakeClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOptionFunc) error {
+	c.calls["patch"]++
+	return fmt.Errorf("patch is not supported by the fake client")
+}
+
+func (c *fakeClient) Status() client.StatusWriter {
+	return &fakeStatusWriter{c}
+}
+
+func (c *fakeClient) replace(obj runtime.Object) error {
+	key, err := fakeClientKey(obj)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.objects[key]; !ok {
+		accessor, _ := meta.Accessor(obj)
+		return fakeClientNotFound(obj, accessor.GetName())
+	}
+	c.objects[key] = fakeClientCopy(obj)
+	return nil
+}
+
+// fakeStatusWriter implements client.StatusWriter for fakeClient.
+type fakeStatusWriter struct {
+	client *fakeClient
+}
+
+// Update replaces the stored object's status.  Like the API's status
+// subresource, it ignores changes to anything else.
+func (w *fakeStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOptionFunc) error {
+	w.client.calls["status-update"]++
+	key, err := fakeClientKey(obj)
+	if err != nil {
+		return err
+	}
+	stored, ok := w.client.objects[key]
+	if !ok {
+		accessor, _ := meta.Accessor(obj)
+		return fakeClientNotFound(obj, accessor.GetName())
+	}
+	status := reflect.ValueOf(obj).Elem().FieldByName("Status")
+	if !status.IsValid() {
+		return w.client.replace(obj)
+	}
+	updated := fakeClientCopy(stored)
+	reflect.ValueOf(updated).Elem().FieldByName("Status").Set(reflect.ValueOf(fakeClientCopy(obj)).Elem().FieldByName("Status"))
+	w.client.objects[key] = updated
+	return nil
+}
+
+func (w *fakeStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOptionFunc) error {
+	w.client.calls["status-patch"]++
+	return fmt.Errorf("patch is not supported by the fake client")
+}
+
+// fakeCache is a cache.Cache that serves reads from a fakeClient.  Only Get,
+// List, and WaitForCacheSync are implemented.
+type fakeCache struct {
+	cache.Cache
+	client *fakeClient
+}
+
+func (c *fakeCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return c.client.Get(ctx, key, obj)
+}
+
+func (c *fakeCache) List(ctx context.Context, list runtime.Object, opts ...client.ListOptionFunc) error {
+	return c.client.List(ctx, list, opts...)
+}
+
+func (c *fakeCache) WaitForCacheSync(stop <-chan struct{}) bool {
+	return true
+}
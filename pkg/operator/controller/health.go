@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Health tracks whether the operator's cache has synced and whether the last
+// reconciliation of each ingresscontroller succeeded.  A nil Health tracks
+// nothing.
+type Health struct {
+	lock        sync.Mutex
+	cacheSynced bool
+	reconciles  map[string]IngressControllerHealth
+}
+
+// HealthStatus is the response of the health handler.
+type HealthStatus struct {
+	// CacheSynced indicates whether the operator's cache has synced.
+	CacheSynced bool `json:"cacheSynced"`
+	// IngressControllers describes the last reconciliation of each
+	// ingresscontroller that the operator has reconciled.
+	IngressControllers []IngressControllerHealth `json:"ingressControllers"`
+}
+
+// IngressControllerHealth describes the last reconciliation of an
+// ingresscontroller.
+type IngressControllerHealth struct {
+	// Name is the name of the ingresscontroller.
+	Name string `json:"name"`
+	// LastReconcileSucceeded indicates whether the last reconciliation
+	// succeeded.
+	LastReconcileSucceeded bool `json:"lastReconcileSucceeded"`
+	// LastReconcileTime is the time at which the last reconciliation
+	// finished.
+	LastReconcileTime metav1.Time `json:"lastReconcileTime"`
+	// Error is the error of the last reconciliation, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// NewHealth returns a new Health.
+func NewHealth() *Health {
+	return &Health{reconciles: map[string]IngressControllerHealth{}}
+}
+
+// SetCacheSynced records that the operator's cache has synced.
+func (h *Health) SetCacheSynced() {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.cacheSynced = true
+}
+
+// recordReconcile records the outcome of a reconciliation of the
+// ingresscontroller with the given name.
+func (h *Health) recordReconcile(name string, err error) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	health := IngressControllerHealth{
+		Name:                   name,
+		LastReconcileSucceeded: err == nil,
+		LastReconcileTime:      metav1.NewTime(time.Now()),
+	}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	h.reconciles[name] = health
+}
+
+// forget stops tracking the ingresscontroller with the given name, for
+// example because it has been deleted.
+func (h *Health) forget(name string) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.reconciles, name)
+}
+
+// status returns the current health status.
+func (h *Health) status() HealthStatus {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	status := HealthStatus{
+		CacheSynced:        h.cacheSynced,
+		IngressControllers: make([]IngressControllerHealth, 0, len(h.reconciles)),
+	}
+	for _, health := range h.reconciles {
+		status.IngressControllers = append(status.IngressControllers, health)
+	}
+	sort.Slice(status.IngressControllers, func(i, j int) bool {
+		return status.IngressControllers[i].Name < status.IngressControllers[j].Name
+	})
+	return status
+}
+
+// NewHealthHandler returns an HTTP handler that responds with the given
+// health status as JSON.  The status code is 200 if the cache has synced and,
+// if requireReconciles is true, the last reconciliation of every
+// ingresscontroller succeeded; otherwise it is 503.  Use requireReconciles
+// for readiness but not for liveness, since a failing reconciliation, for
+// example because a cloud API is unavailable, is not fixed by restarting the
+// operator.
+func NewHealthHandler(health *Health, requireReconciles bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		status := health.status()
+		healthy := status.CacheSynced
+		if requireReconciles {
+			for _, ic := range status.IngressControllers {
+				healthy = healthy && ic.LastReconcileSucceeded
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			log.Error(err, "failed to write health status")
+		}
+	})
+}
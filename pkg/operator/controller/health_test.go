@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestHealth verifies that reconciliations of managed ingresscontrollers are
+// recorded, that unmanaged and deleted ingresscontrollers are not, and that
+// the liveness and readiness handlers report the recorded health.
+func TestHealth(t *testing.T) {
+	ingressController := func(name string) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: name},
+		}
+	}
+	client := newFakeClient(ingressController("default"), ingressController("external"))
+	health := NewHealth()
+	r := &reconciler{
+		Config: Config{
+			Namespace:                   "openshift-ingress-operator",
+			OperandNamespace:            "openshift-ingress",
+			UnmanagedIngressControllers: []string{"external"},
+			Health:                      health,
+		},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	request := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: name}}
+	}
+	get := func(handler http.Handler) (int, HealthStatus) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		var status HealthStatus
+		if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+			t.Fatalf("failed to decode response %q: %v", w.Body.String(), err)
+		}
+		return w.Code, status
+	}
+	liveness := NewHealthHandler(health, false)
+	readiness := NewHealthHandler(health, true)
+
+	if code, status := get(liveness); code != http.StatusServiceUnavailable || status.CacheSynced {
+		t.Errorf("expected liveness to fail before the cache has synced, got %d %+v", code, status)
+	}
+
+	// The cluster configs do not exist, so reconciling the managed
+	// ingresscontroller fails.
+	reconcileErr := func(name string) error {
+		_, err := r.Reconcile(request(name))
+		return err
+	}
+	defaultErr := reconcileErr("default")
+	if defaultErr == nil {
+		t.Fatal("expected reconciling without cluster configs to fail")
+	}
+	if err := reconcileErr("external"); err != nil {
+		t.Fatalf("failed to reconcile unmanaged ingresscontroller: %v", err)
+	}
+	if err := reconcileErr("deleted"); err != nil {
+		t.Fatalf("failed to reconcile deleted ingresscontroller: %v", err)
+	}
+	health.SetCacheSynced()
+
+	if code, _ := get(liveness); code != http.StatusOK {
+		t.Errorf("expected liveness to succeed once the cache has synced, got %d", code)
+	}
+	code, status := get(readiness)
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("expected readiness to fail after a failed reconciliation, got %d", code)
+	}
+	if len(status.IngressControllers) != 1 {
+		t.Fatalf("expected only ingresscontroller default to be tracked, got %+v", status.IngressControllers)
+	}
+	ic := status.IngressControllers[0]
+	if ic.Name != "default" || ic.LastReconcileSucceeded || ic.LastReconcileTime.IsZero() || ic.Error != defaultErr.Error() {
+		t.Errorf("expected a failed reconciliation of default with error %q, got %+v", defaultErr, ic)
+	}
+
+	health.recordReconcile("default", nil)
+	if code, status := get(readiness); code != http.StatusOK || !status.IngressControllers[0].LastReconcileSucceeded || len(status.IngressControllers[0].Error) != 0 {
+		t.Errorf("expected readiness to succeed after a successful reconciliation, got %d %+v", code, status)
+	}
+
+	w := httptest.NewRecorder()
+	readiness.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 for POST, got %d", w.Code)
+	}
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IngressAddresses is the response of the ingress addresses handler.
+type IngressAddresses struct {
+	IngressControllers []IngressControllerAddresses `json:"ingressControllers"`
+}
+
+// IngressControllerAddresses describes where an ingresscontroller can be
+// reached.
+type IngressControllerAddresses struct {
+	// Name is the name of the ingresscontroller.
+	Name string `json:"name"`
+	// Domain is the ingresscontroller's status.domain.
+	Domain string `json:"domain"`
+	// Addresses are the hostnames and IP addresses of the
+	// ingresscontroller's load balancer.  Addresses is empty if the
+	// ingresscontroller does not use a load balancer or the load balancer
+	// has not been provisioned yet.
+	Addresses []string `json:"addresses"`
+}
+
+// NewIngressAddressesHandler returns a read-only HTTP handler that responds
+// with the domain and load balancer addresses of every ingresscontroller in
+// the given namespace as JSON.  It is intended for tooling that cannot read
+// ingresscontrollers and services itself.
+func NewIngressAddressesHandler(cache cache.Cache, namespace, operandNamespace string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		addresses, err := ingressAddresses(cache, namespace, operandNamespace)
+		if err != nil {
+			log.Error(err, "failed to get ingress addresses")
+			http.Error(w, "failed to get ingress addresses", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(addresses); err != nil {
+			log.Error(err, "failed to write ingress addresses")
+		}
+	})
+}
+
+// ingressAddresses returns the domain and load balancer addresses of every
+// ingresscontroller in the given namespace, sorted by name.
+func ingressAddresses(cache cache.Cache, namespace, operandNamespace string) (*IngressAddresses, error) {
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := cache.List(context.TODO(), ingresses, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list ingresscontrollers: %v", err)
+	}
+	result := &IngressAddresses{IngressControllers: []IngressControllerAddresses{}}
+	for i := range ingresses.Items {
+		ic := &ingresses.Items[i]
+		entry := IngressControllerAddresses{
+			Name:      ic.Name,
+			Domain:    ic.Status.Domain,
+			Addresses: []string{},
+		}
+		if usesLoadBalancer(ic) {
+			service := &corev1.Service{}
+			if err := cache.Get(context.TODO(), LoadBalancerServiceName(ic, operandNamespace), service); err != nil {
+				if !errors.IsNotFound(err) {
+					return nil, fmt.Errorf("failed to get load balancer service for ingresscontroller %s: %v", ic.Name, err)
+				}
+			}
+			for _, ingress := range service.Status.LoadBalancer.Ingress {
+				if len(ingress.Hostname) != 0 {
+					entry.Addresses = append(entry.Addresses, ingress.Hostname)
+				}
+				if len(ingress.IP) != 0 {
+					entry.Addresses = append(entry.Addresses, ingress.IP)
+				}
+			}
+		}
+		result.IngressControllers = append(result.IngressControllers, entry)
+	}
+	sort.Slice(result.IngressControllers, func(i, j int) bool {
+		return result.IngressControllers[i].Name < result.IngressControllers[j].Name
+	})
+	return result, nil
+}
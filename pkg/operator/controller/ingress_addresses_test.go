@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIngressAddressesHandler(t *testing.T) {
+	ingressController := func(name, domain string, strategy operatorv1.EndpointPublishingStrategyType) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: name},
+			Status: operatorv1.IngressControllerStatus{
+				Domain:                     domain,
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: strategy},
+			},
+		}
+	}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default"}}
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{
+		{Hostname: "lb.cloudprovider.example.com"},
+		{IP: "192.0.2.1"},
+	}
+	client := newFakeClient(
+		ingressController("default", "apps.example.com", operatorv1.LoadBalancerServiceStrategyType),
+		ingressController("pending", "pending.example.com", operatorv1.LoadBalancerServiceStrategyType),
+		ingressController("internal", "internal.example.com", operatorv1.PrivateStrategyType),
+		service,
+	)
+	handler := NewIngressAddressesHandler(&fakeCache{client: client}, "openshift-ingress-operator", "openshift-ingress")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ingresscontrollers", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if v := w.Header().Get("Content-Type"); v != "application/json" {
+		t.Errorf("expected content type application/json, got %q", v)
+	}
+	var actual IngressAddresses
+	if err := json.Unmarshal(w.Body.Bytes(), &actual); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected := IngressAddresses{IngressControllers: []IngressControllerAddresses{
+		{Name: "default", Domain: "apps.example.com", Addresses: []string{"lb.cloudprovider.example.com", "192.0.2.1"}},
+		{Name: "internal", Domain: "internal.example.com", Addresses: []string{}},
+		{Name: "pending", Domain: "pending.example.com", Addresses: []string{}},
+	}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ingresscontrollers", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 for POST, got %d", w.Code)
+	}
+}
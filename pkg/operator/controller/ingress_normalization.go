@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// getIngressController gets the ingresscontroller with the given name into ic
+// and normalizes its spec so that reconciliation only has to handle the
+// canonical form of each field.
+func (r *reconciler) getIngressController(name types.NamespacedName, ic *operatorv1.IngressController) error {
+	if err := r.client.Get(context.TODO(), name, ic); err != nil {
+		return err
+	}
+	normalizeIngressController(ic)
+	return nil
+}
+
+// normalizeIngressController rewrites legacy shapes of the given
+// ingresscontroller's spec into their canonical form, in memory only.  The
+// normalized spec is never written back: status updates ignore the spec, and
+// metadata updates go through updateIngressControllerMetadata, which applies
+// them to a fresh, unnormalized copy of the ingresscontroller.
+// The following legacy shapes are handled:
+//
+//   - A spec.domain with uppercase letters or a trailing dot is lowercased and
+//     the trailing dot is removed.
+//   - A spec.endpointPublishingStrategy with an empty type is treated as unset,
+//     so that the platform default strategy is used.
+//   - A spec.defaultCertificate with an empty name is treated as unset, so that
+//     the operator-generated default certificate is used.
+//   - An empty spec.namespaceSelector or spec.routeSelector, which selects
+//     everything, is treated as unset.
+func normalizeIngressController(ic *operatorv1.IngressController) {
+	spec := &ic.Spec
+	spec.Domain = strings.TrimSuffix(strings.ToLower(spec.Domain), ".")
+	if spec.EndpointPublishingStrategy != nil && len(spec.EndpointPublishingStrategy.Type) == 0 {
+		spec.EndpointPublishingStrategy = nil
+	}
+	if spec.DefaultCertificate != nil && len(spec.DefaultCertificate.Name) == 0 {
+		spec.DefaultCertificate = nil
+	}
+	if spec.NamespaceSelector != nil && len(spec.NamespaceSelector.MatchLabels) == 0 && len(spec.NamespaceSelector.MatchExpressions) == 0 {
+		spec.NamespaceSelector = nil
+	}
+	if spec.RouteSelector != nil && len(spec.RouteSelector.MatchLabels) == 0 && len(spec.RouteSelector.MatchExpressions) == 0 {
+		spec.RouteSelector = nil
+	}
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestNormalizeIngressController(t *testing.T) {
+	testCases := []struct {
+		description string
+		spec        operatorv1.IngressControllerSpec
+		expect      operatorv1.IngressControllerSpec
+	}{
+		{
+			description: "empty spec",
+		},
+		{
+			description: "canonical spec",
+			spec: operatorv1.IngressControllerSpec{
+				Domain:                     "apps.example.com",
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: operatorv1.PrivateStrategyType},
+				DefaultCertificate:         &corev1.LocalObjectReference{Name: "custom-cert"},
+				NamespaceSelector:          &metav1.LabelSelector{MatchLabels: map[string]string{"type": "shard"}},
+				RouteSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "type",
+					Operator: metav1.LabelSelectorOpExists,
+				}}},
+			},
+			expect: operatorv1.IngressControllerSpec{
+				Domain:                     "apps.example.com",
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: operatorv1.PrivateStrategyType},
+				DefaultCertificate:         &corev1.LocalObjectReference{Name: "custom-cert"},
+				NamespaceSelector:          &metav1.LabelSelector{MatchLabels: map[string]string{"type": "shard"}},
+				RouteSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "type",
+					Operator: metav1.LabelSelectorOpExists,
+				}}},
+			},
+		},
+		{
+			description: "legacy spec",
+			spec: operatorv1.IngressControllerSpec{
+				Domain:                     "Apps.Example.com.",
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{},
+				DefaultCertificate:         &corev1.LocalObjectReference{},
+				NamespaceSelector:          &metav1.LabelSelector{},
+				RouteSelector:              &metav1.LabelSelector{MatchLabels: map[string]string{}},
+			},
+			expect: operatorv1.IngressControllerSpec{
+				Domain: "apps.example.com",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{Spec: tc.spec}
+		normalizeIngressController(ic)
+		if !cmp.Equal(ic.Spec, tc.expect) {
+			t.Errorf("%q: expected %#v, got %#v", tc.description, tc.expect, ic.Spec)
+		}
+	}
+}
+
+// TestReconcileLegacyIngressController verifies that the effective domain and
+// endpoint publishing strategy are computed from the canonical form of a
+// legacy spec and that the spec is not written back.
+func TestReconcileLegacyIngressController(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "legacy",
+		},
+		Spec: operatorv1.IngressControllerSpec{
+			Domain:                     "Apps.Example.com.",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{},
+		},
+	}
+	client := newFakeClient(ic)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	name := types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}
+	ingressConfig := &configv1.Ingress{
+		Spec: configv1.IngressSpec{Domain: "apps.cluster.example.com"},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
+	}
+
+	current := &operatorv1.IngressController{}
+	if err := r.getIngressController(name, current); err != nil {
+		t.Fatalf("failed to get ingresscontroller: %v", err)
+	}
+	if err := r.enforceEffectiveIngressDomain(current, ingressConfig); err != nil {
+		t.Fatalf("failed to enforce effective ingress domain: %v", err)
+	}
+	if err := r.enforceEffectiveEndpointPublishingStrategy(current, infraConfig); err != nil {
+		t.Fatalf("failed to enforce effective endpoint publishing strategy: %v", err)
+	}
+	if err := r.enforceIngressFinalizer(current); err != nil {
+		t.Fatalf("failed to enforce ingress finalizer: %v", err)
+	}
+
+	if current.Status.Domain != "apps.example.com" {
+		t.Errorf("expected status domain apps.example.com, got %q", current.Status.Domain)
+	}
+	if strategy := current.Status.EndpointPublishingStrategy; strategy == nil || strategy.Type != operatorv1.LoadBalancerServiceStrategyType {
+		t.Errorf("expected the platform default endpoint publishing strategy %s, got %#v", operatorv1.LoadBalancerServiceStrategyType, strategy)
+	}
+	if current.Spec.EndpointPublishingStrategy != nil {
+		t.Errorf("expected the refreshed ingresscontroller to be normalized, got spec.endpointPublishingStrategy %#v", current.Spec.EndpointPublishingStrategy)
+	}
+
+	stored := &operatorv1.IngressController{}
+	if err := client.Get(context.TODO(), name, stored); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(stored.Spec, ic.Spec) {
+		t.Errorf("expected the stored spec to be unchanged, got %#v", stored.Spec)
+	}
+	if !cmp.Equal(stored.Finalizers, []string{IngressControllerFinalizer}) {
+		t.Errorf("expected the stored finalizers to be %v, got %v", []string{IngressControllerFinalizer}, stored.Finalizers)
+	}
+	if !cmp.Equal(current.Finalizers, []string{IngressControllerFinalizer}) {
+		t.Errorf("expected the refreshed finalizers to be %v, got %v", []string{IngressControllerFinalizer}, current.Finalizers)
+	}
+	if client.calls["update"] != 1 {
+		t.Errorf("expected only the finalizer update, got %d updates", client.calls["update"])
+	}
+}
@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IngressControllerResources is the response of the ingresscontroller
+// resources handler.
+type IngressControllerResources struct {
+	// Name is the name of the ingresscontroller.
+	Name string `json:"name"`
+	// Resources are the resources that the operator manages for the
+	// ingresscontroller, sorted by kind, namespace, and name.
+	Resources []OperandResource `json:"resources"`
+}
+
+// OperandResource identifies a resource that the operator manages for an
+// ingresscontroller.
+type OperandResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+}
+
+// NewIngressControllerResourcesHandler returns a read-only HTTP handler that
+// responds to requests for /ingresscontrollers/<name>/resources with the
+// resources that the operator manages for the ingresscontroller with the given
+// name in the given namespace as JSON.  It is intended for auditing and for
+// verifying that an ingresscontroller's resources were cleaned up; an
+// ingresscontroller that does not exist yields 404 with any resources that
+// remain.
+func NewIngressControllerResourcesHandler(reader client.Reader, namespace, operandNamespace string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/ingresscontrollers/"), "/")
+		if len(parts) != 2 || len(parts[0]) == 0 || parts[1] != "resources" {
+			http.NotFound(w, req)
+			return
+		}
+		name := parts[0]
+		exists := true
+		ic := &operatorv1.IngressController{}
+		if err := reader.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, ic); err != nil {
+			if !errors.IsNotFound(err) {
+				log.Error(err, "failed to get ingresscontroller", "name", name)
+				http.Error(w, "failed to get ingresscontroller", http.StatusInternalServerError)
+				return
+			}
+			exists = false
+			ic = &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		}
+		resources, err := ingressControllerResources(reader, ic, operandNamespace)
+		if err != nil {
+			log.Error(err, "failed to get ingresscontroller resources", "name", name)
+			http.Error(w, "failed to get ingresscontroller resources", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		if err := json.NewEncoder(w).Encode(resources); err != nil {
+			log.Error(err, "failed to write ingresscontroller resources")
+		}
+	})
+}
+
+// ingressControllerResources returns the resources that the operator manages
+// for the given ingresscontroller: the router deployment and the resources in
+// the operand namespace that have the ingresscontroller's owning label or are
+// owned by the router deployment.  Resources whose API the cluster does not
+// serve, such as servicemonitors without the monitoring stack, are skipped.
+func ingressControllerResources(reader client.Reader, ic *operatorv1.IngressController, operandNamespace string) (*IngressControllerResources, error) {
+	result := &IngressControllerResources{Name: ic.Name, Resources: []OperandResource{}}
+	add := func(gvk schema.GroupVersionKind, obj metav1.Object) {
+		result.Resources = append(result.Resources, OperandResource{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		})
+	}
+
+	var deploymentUID types.UID
+	deployment := &appsv1.Deployment{}
+	if err := reader.Get(context.TODO(), RouterDeploymentName(ic, operandNamespace), deployment); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get router deployment: %v", err)
+		}
+	} else {
+		deploymentUID = deployment.UID
+		add(appsv1.SchemeGroupVersion.WithKind("Deployment"), deployment)
+	}
+	managed := func(obj metav1.Object) bool {
+		if obj.GetLabels()[manifests.OwningIngressControllerLabel] == ic.Name {
+			return true
+		}
+		if len(deploymentUID) == 0 {
+			return false
+		}
+		for _, ref := range obj.GetOwnerReferences() {
+			if ref.UID == deploymentUID {
+				return true
+			}
+		}
+		return false
+	}
+
+	lists := []struct {
+		gvk  schema.GroupVersionKind
+		list runtime.Object
+	}{
+		{corev1.SchemeGroupVersion.WithKind("Service"), &corev1.ServiceList{}},
+		{corev1.SchemeGroupVersion.WithKind("Secret"), &corev1.SecretList{}},
+		{corev1.SchemeGroupVersion.WithKind("ConfigMap"), &corev1.ConfigMapList{}},
+		{autoscalingv1.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"), &autoscalingv1.HorizontalPodAutoscalerList{}},
+	}
+	for _, l := range lists {
+		if err := reader.List(context.TODO(), l.list, client.InNamespace(operandNamespace)); err != nil {
+			return nil, fmt.Errorf("failed to list %s resources in namespace %s: %v", l.gvk.Kind, operandNamespace, err)
+		}
+		items, err := meta.ExtractList(l.list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			obj, err := meta.Accessor(item)
+			if err != nil {
+				return nil, err
+			}
+			if managed(obj) {
+				add(l.gvk, obj)
+			}
+		}
+	}
+
+	// The monitoring resources are unstructured, so get them by name
+	// rather than listing every resource of their kinds.
+	serviceMonitorGVK := schema.GroupVersionKind{Group: "monitoring.coreos.com", Kind: "ServiceMonitor", Version: "v1"}
+	monitoring := []struct {
+		gvk  schema.GroupVersionKind
+		name types.NamespacedName
+	}{
+		{serviceMonitorGVK, IngressControllerServiceMonitorName(ic, operandNamespace)},
+		{prometheusRuleGVK, IngressControllerPrometheusRuleName(ic, operandNamespace)},
+	}
+	for _, m := range monitoring {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(m.gvk)
+		if err := reader.Get(context.TODO(), m.name, obj); err != nil {
+			if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get %s %s: %v", m.gvk.Kind, m.name, err)
+		}
+		if managed(obj) {
+			add(m.gvk, obj)
+		}
+	}
+
+	sort.Slice(result.Resources, func(i, j int) bool {
+		a, b := result.Resources[i], result.Resources[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return result, nil
+}
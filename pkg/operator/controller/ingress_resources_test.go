@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestIngressControllerResourcesHandler verifies that the handler lists the
+// router deployment and the resources that have the ingresscontroller's owning
+// label or are owned by the router deployment, and nothing of other
+// ingresscontrollers.
+func TestIngressControllerResourcesHandler(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"},
+	}
+	deploymentRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "router-default", UID: "1"}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default", UID: "1"}}
+	labeled := func(name, owner string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      name,
+			Labels:    map[string]string{manifests.OwningIngressControllerLabel: owner},
+		}
+	}
+	owned := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "openshift-ingress", Name: name, OwnerReferences: []metav1.OwnerReference{deploymentRef}}
+	}
+	serviceMonitor := &unstructured.Unstructured{}
+	serviceMonitor.SetAPIVersion("monitoring.coreos.com/v1")
+	serviceMonitor.SetKind("ServiceMonitor")
+	serviceMonitor.SetNamespace("openshift-ingress")
+	serviceMonitor.SetName("router-default")
+	serviceMonitor.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
+	client := newFakeClient(
+		ic,
+		deployment,
+		&corev1.Service{ObjectMeta: labeled("router-internal-default", "default")},
+		&corev1.Service{ObjectMeta: labeled("router-internal-external", "external")},
+		&corev1.Secret{ObjectMeta: owned("router-certs-default")},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "custom-cert"}},
+		&corev1.ConfigMap{ObjectMeta: labeled("router-default-metrics-ca", "default")},
+		serviceMonitor,
+	)
+	handler := NewIngressControllerResourcesHandler(client, "openshift-ingress-operator", "openshift-ingress")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ingresscontrollers/default/resources", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if v := w.Header().Get("Content-Type"); v != "application/json" {
+		t.Errorf("expected content type application/json, got %q", v)
+	}
+	var actual IngressControllerResources
+	if err := json.Unmarshal(w.Body.Bytes(), &actual); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected := IngressControllerResources{Name: "default", Resources: []OperandResource{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "openshift-ingress", Name: "router-default-metrics-ca"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "openshift-ingress", Name: "router-default"},
+		{APIVersion: "v1", Kind: "Secret", Namespace: "openshift-ingress", Name: "router-certs-default"},
+		{APIVersion: "v1", Kind: "Service", Namespace: "openshift-ingress", Name: "router-internal-default"},
+		{APIVersion: "monitoring.coreos.com/v1", Kind: "ServiceMonitor", Namespace: "openshift-ingress", Name: "router-default"},
+	}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+
+	// A deleted ingresscontroller yields 404 with the resources that
+	// remain, so that cleanup can be verified.
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ingresscontrollers/external/resources", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for a deleted ingresscontroller, got %d: %s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &actual); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected = IngressControllerResources{Name: "external", Resources: []OperandResource{
+		{APIVersion: "v1", Kind: "Service", Namespace: "openshift-ingress", Name: "router-internal-external"},
+	}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+
+	for _, path := range []string{"/ingresscontrollers/default", "/ingresscontrollers//resources", "/ingresscontrollers/default/resources/extra"} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 for %s, got %d", path, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ingresscontrollers/default/resources", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 for POST, got %d", w.Code)
+	}
+}
@@ -3,36 +3,159 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
+	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// IngressControllerAdmittedConditionType indicates whether the
+	// ingresscontroller's configuration is valid and the operator has
+	// accepted it for processing.
+	IngressControllerAdmittedConditionType = "Admitted"
+
+	// operandVersionSkewReason is the reason of the Degraded condition or
+	// the warning event that reports router pods that run an image other
+	// than the one that the operator's release expects, for example
+	// because an upgrade is stuck.
+	operandVersionSkewReason = "OperandVersionSkew"
+
+	// immutableSpecIgnoredReason is the reason of the warning event that
+	// reports a change to spec.domain or spec.endpointPublishingStrategy
+	// that has no effect because the value in status cannot change.
+	immutableSpecIgnoredReason = "ImmutableSpecIgnored"
 )
 
 // syncIngressControllerStatus computes the current status of ic and
-// updates status upon any changes since last sync.
-func (r *reconciler) syncIngressControllerStatus(ic *operatorv1.IngressController, deployment *appsv1.Deployment, service *corev1.Service, operandEvents []corev1.Event) error {
+// updates status upon any changes since last sync.  The returned result asks
+// for a requeue when a pending Degraded condition's grace period expires.
+func (r *reconciler) syncIngressControllerStatus(ic *operatorv1.IngressController, deployment *appsv1.Deployment, pods []corev1.Pod, service *corev1.Service, operandEvents []corev1.Event, dnsConfig *configv1.DNS) (reconcile.Result, error) {
+	result := reconcile.Result{}
 	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
 	if err != nil {
-		return fmt.Errorf("deployment has invalid spec.selector: %v", err)
+		return result, fmt.Errorf("deployment has invalid spec.selector: %v", err)
 	}
 
 	updated := ic.DeepCopy()
 	updated.Status.AvailableReplicas = deployment.Status.AvailableReplicas
 	updated.Status.Selector = selector.String()
 
+	loadBalancerConditions := computeLoadBalancerStatus(ic, service, operandEvents)
+
 	updated.Status.Conditions = []operatorv1.OperatorCondition{}
-	updated.Status.Conditions = append(updated.Status.Conditions, computeIngressStatusConditions(updated.Status.Conditions, deployment)...)
-	updated.Status.Conditions = append(updated.Status.Conditions, computeLoadBalancerStatus(ic, service, operandEvents)...)
+	updated.Status.Conditions = append(updated.Status.Conditions, computeIngressStatusConditions(updated.Status.Conditions, deployment, loadBalancerConditions)...)
+	updated.Status.Conditions = append(updated.Status.Conditions, loadBalancerConditions...)
+	updated.Status.Conditions = append(updated.Status.Conditions, computeLoadBalancerIPCondition(ic, service))
+	updated.Status.Conditions = append(updated.Status.Conditions, computeDNSZonesConfiguredCondition(ic, dnsConfig))
+	// The event recorder aggregates repeated identical events, so the
+	// warnings below are emitted on every sync while they apply.
+	if ignored := immutableSpecIgnored(ic, r.PreferClusterIngressDomain); len(ignored) != 0 && r.recorder != nil {
+		r.recorder.Eventf(ic, "Warning", immutableSpecIgnoredReason, "%s", ignored)
+	}
+	degradedCondition := computeIngressDegradedCondition(ic, pods)
+	if skew := operandVersionSkew(pods, r.IngressControllerImage, r.OperatorReleaseVersion); len(skew) != 0 {
+		if !r.DegradeOnOperandVersionSkew {
+			if r.recorder != nil {
+				r.recorder.Eventf(ic, "Warning", operandVersionSkewReason, "%s", skew)
+			}
+		} else if degradedCondition.Status != operatorv1.ConditionTrue {
+			degradedCondition.Status = operatorv1.ConditionTrue
+			degradedCondition.Reason = operandVersionSkewReason
+			degradedCondition.Message = skew
+		}
+	}
+	if degradedCondition.Status != operatorv1.ConditionTrue {
+		problem, err := r.defaultCertificateProblem(ic)
+		if err != nil {
+			return result, err
+		}
+		if len(problem) != 0 {
+			degradedCondition.Status = operatorv1.ConditionTrue
+			degradedCondition.Reason = defaultCertificateInvalidReason
+			degradedCondition.Message = fmt.Sprintf("the default certificate from spec.defaultCertificate cannot be used: %s; the router pods roll out when the secret changes", problem)
+		}
+	}
+	if degradedCondition.Status != operatorv1.ConditionTrue {
+		problem, err := r.backendCABundleProblem(ic)
+		if err != nil {
+			return result, err
+		}
+		if len(problem) != 0 {
+			degradedCondition.Status = operatorv1.ConditionTrue
+			degradedCondition.Reason = backendCABundleInvalidReason
+			degradedCondition.Message = fmt.Sprintf("the backend CA bundle from annotation %s cannot be used: %s; the router cannot verify the backends of reencrypt routes without a destination CA certificate", backendCABundleConfigMapAnnotation, problem)
+		}
+	}
+	if degradedCondition.Status != operatorv1.ConditionTrue {
+		cm, err := r.routerTemplateConfigMap(ic)
+		if err != nil {
+			return result, err
+		}
+		if problem := routerTemplateProblem(ic, r.OperandNamespace, cm); len(problem) != 0 {
+			degradedCondition.Status = operatorv1.ConditionTrue
+			degradedCondition.Reason = routerTemplateInvalidReason
+			degradedCondition.Message = fmt.Sprintf("the router template from annotation %s cannot be used: %s; the router uses the template in the router image", routerTemplateConfigMapAnnotation, problem)
+		}
+	}
+	if degradedCondition.Status != operatorv1.ConditionTrue {
+		problem, err := r.dnsCredentialsProblem(ic)
+		if err != nil {
+			return result, err
+		}
+		if len(problem) != 0 {
+			degradedCondition.Status = operatorv1.ConditionTrue
+			degradedCondition.Reason = dnsCredentialsInvalidReason
+			degradedCondition.Message = fmt.Sprintf("the DNS credentials from annotation %s cannot be used: %s; the ingresscontroller's DNS records are not managed", dnsCredentialsSecretAnnotation, problem)
+		}
+	}
+	if degradedCondition.Status != operatorv1.ConditionTrue {
+		problem, err := r.noSchedulableNodesProblem(deployment)
+		if err != nil {
+			return result, err
+		}
+		if len(problem) != 0 {
+			degradedCondition.Status = operatorv1.ConditionTrue
+			degradedCondition.Reason = noSchedulableNodesReason
+			degradedCondition.Message = fmt.Sprintf("%s; the router pods cannot be scheduled until spec.nodePlacement.nodeSelector matches a schedulable node", problem)
+		}
+	}
+	if degradedCondition.Status != operatorv1.ConditionTrue {
+		if problem := loadBalancerProvisioningProblem(ic, service, operandEvents); len(problem) != 0 {
+			degradedCondition.Status = operatorv1.ConditionTrue
+			degradedCondition.Reason = loadBalancerProvisioningFailedReason
+			degradedCondition.Message = problem
+			r.recordLoadBalancerProvisioningFailed(ic, problem)
+		}
+	}
+	oldDegradedCondition := getIngressCondition(ic.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+	degradedCondition, requeueAfter := debounceIngressDegradedCondition(degradedCondition, oldDegradedCondition, r.DegradedGracePeriod, time.Now())
+	result.RequeueAfter = requeueAfter
+	updated.Status.Conditions = append(updated.Status.Conditions, degradedCondition)
+	// The Admitted condition is computed by admit prior to syncing status.
+	if admittedCondition := getIngressCondition(ic.Status.Conditions, IngressControllerAdmittedConditionType); admittedCondition != nil {
+		updated.Status.Conditions = append(updated.Status.Conditions, *admittedCondition)
+	}
 
 	for i := range updated.Status.Conditions {
 		newCondition := &updated.Status.Conditions[i]
+		// A condition that already has a transition time has tracked
+		// it itself.
+		if !newCondition.LastTransitionTime.IsZero() {
+			continue
+		}
 		var oldCondition *operatorv1.OperatorCondition
 		for j, possibleOldCondition := range ic.Status.Conditions {
 			if possibleOldCondition.Type == newCondition.Type {
@@ -46,51 +169,234 @@ func (r *reconciler) syncIngressControllerStatus(ic *operatorv1.IngressControlle
 
 	if !ingressStatusesEqual(updated.Status, ic.Status) {
 		if err := r.client.Status().Update(context.TODO(), updated); err != nil {
-			return fmt.Errorf("failed to update ingresscontroller status: %v", err)
+			return result, fmt.Errorf("failed to update ingresscontroller status: %v", err)
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
 // computeIngressStatusConditions computes the ingress controller's current state.
-func computeIngressStatusConditions(oldConditions []operatorv1.OperatorCondition, deployment *appsv1.Deployment) []operatorv1.OperatorCondition {
+func computeIngressStatusConditions(oldConditions []operatorv1.OperatorCondition, deployment *appsv1.Deployment, loadBalancerConditions []operatorv1.OperatorCondition) []operatorv1.OperatorCondition {
 	oldAvailableCondition := getIngressAvailableCondition(oldConditions)
 
 	return []operatorv1.OperatorCondition{
-		computeIngressAvailableCondition(oldAvailableCondition, deployment),
+		computeIngressAvailableCondition(oldAvailableCondition, deployment, loadBalancerConditions),
 	}
 }
 
-// computeIngressAvailableCondition computes the ingress controller's current Available status state.
-func computeIngressAvailableCondition(oldAvailableCondition *operatorv1.OperatorCondition, deployment *appsv1.Deployment) operatorv1.OperatorCondition {
+// computeIngressAvailableCondition computes the ingress controller's current
+// Available status state.  The ingress controller is available if its
+// deployment has available replicas and, if a load balancer is managed for the
+// ingress controller, the load balancer is ready.
+func computeIngressAvailableCondition(oldAvailableCondition *operatorv1.OperatorCondition, deployment *appsv1.Deployment, loadBalancerConditions []operatorv1.OperatorCondition) operatorv1.OperatorCondition {
 	availableCondition := operatorv1.OperatorCondition{
 		Type: operatorv1.IngressControllerAvailableConditionType,
 	}
 
-	if deployment.Status.AvailableReplicas > 0 {
-		availableCondition.Status = operatorv1.ConditionTrue
-	} else {
+	lbReadyCondition := getIngressCondition(loadBalancerConditions, operatorv1.LoadBalancerReadyIngressConditionType)
+
+	switch {
+	case deployment.Status.AvailableReplicas == 0:
 		availableCondition.Status = operatorv1.ConditionFalse
 		availableCondition.Reason = "DeploymentUnavailable"
 		availableCondition.Message = "no Deployment replicas available"
+	case lbReadyCondition != nil && lbReadyCondition.Status != operatorv1.ConditionTrue:
+		availableCondition.Status = operatorv1.ConditionFalse
+		availableCondition.Reason = "LoadBalancerNotReady"
+		availableCondition.Message = fmt.Sprintf("the load balancer is not ready: %s", lbReadyCondition.Message)
+	default:
+		availableCondition.Status = operatorv1.ConditionTrue
 	}
 
 	return availableCondition
 }
 
+// computeIngressDegradedCondition computes the ingress controller's current
+// Degraded status state.  The ingress controller is degraded if any of its
+// router pods cannot pull their container image or, failing that, if the
+// kubelet rejected its router pods because of their sysctls or, failing that,
+// if its router pods fail with the hardened security context.
+func computeIngressDegradedCondition(ic *operatorv1.IngressController, pods []corev1.Pod) operatorv1.OperatorCondition {
+	degradedCondition := operatorv1.OperatorCondition{
+		Type:   operatorv1.OperatorStatusTypeDegraded,
+		Status: operatorv1.ConditionFalse,
+	}
+
+	// Sort the pods so that the message is stable across syncs.
+	sorted := make([]corev1.Pod, len(pods))
+	copy(sorted, pods)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	failing := []string{}
+	image := ""
+	for _, pod := range sorted {
+		for _, status := range pod.Status.ContainerStatuses {
+			if isImagePullFailure(status) {
+				failing = append(failing, pod.Name)
+				if len(image) == 0 {
+					image = status.Image
+				}
+				break
+			}
+		}
+	}
+	if len(failing) == 0 {
+		if forbidden, message := sysctlForbiddenPods(ic, sorted); len(forbidden) != 0 {
+			degradedCondition.Status = operatorv1.ConditionTrue
+			degradedCondition.Reason = sysctlForbiddenReason
+			degradedCondition.Message = fmt.Sprintf("router pod %s was rejected by its node: %s; allow the sysctls on the nodes with the kubelet's --allowed-unsafe-sysctls flag or remove them from annotation %s", forbidden[0], message, routerSysctlsAnnotation)
+			if len(forbidden) > 1 {
+				degradedCondition.Message = fmt.Sprintf("%d router pods, including %s, were rejected by their nodes: %s; allow the sysctls on the nodes with the kubelet's --allowed-unsafe-sysctls flag or remove them from annotation %s", len(forbidden), forbidden[0], message, routerSysctlsAnnotation)
+			}
+			return degradedCondition
+		}
+		if failed, message := routerSecurityContextFailedPods(ic, sorted); len(failed) != 0 {
+			degradedCondition.Status = operatorv1.ConditionTrue
+			degradedCondition.Reason = routerSecurityContextFailedReason
+			degradedCondition.Message = fmt.Sprintf("router pod %s fails with the security context from annotation %s: %s; relax the settings in the annotation", failed[0], routerSecurityContextAnnotation, message)
+			if len(failed) > 1 {
+				degradedCondition.Message = fmt.Sprintf("%d router pods, including %s, fail with the security context from annotation %s: %s; relax the settings in the annotation", len(failed), failed[0], routerSecurityContextAnnotation, message)
+			}
+			return degradedCondition
+		}
+		return degradedCondition
+	}
+
+	degradedCondition.Status = operatorv1.ConditionTrue
+	degradedCondition.Reason = "ImagePullFailed"
+	degradedCondition.Message = fmt.Sprintf("router pod %s cannot pull image %q; verify that the image exists and that the cluster can reach its registry", failing[0], image)
+	if len(failing) > 1 {
+		degradedCondition.Message = fmt.Sprintf("%d router pods, including %s, cannot pull image %q; verify that the image exists and that the cluster can reach its registry", len(failing), failing[0], image)
+	}
+	return degradedCondition
+}
+
+// operandVersionSkew returns a description of the skew if any of the given
+// router pods, ignoring pods that are being deleted, runs an image other than
+// the expected one, or the empty string otherwise.
+func operandVersionSkew(pods []corev1.Pod, expectedImage, releaseVersion string) string {
+	// Sort the pods so that the message is stable across syncs.
+	sorted := make([]corev1.Pod, len(pods))
+	copy(sorted, pods)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	skewed := []string{}
+	image := ""
+	for _, pod := range sorted {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			if container.Name == "router" && container.Image != expectedImage {
+				skewed = append(skewed, pod.Name)
+				if len(image) == 0 {
+					image = container.Image
+				}
+			}
+		}
+	}
+	switch len(skewed) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("router pod %s runs image %q, but release %s expects image %q", skewed[0], image, releaseVersion, expectedImage)
+	}
+	return fmt.Sprintf("%d router pods, including %s, run image %q, but release %s expects image %q", len(skewed), skewed[0], image, releaseVersion, expectedImage)
+}
+
+// debounceIngressDegradedCondition delays reporting the given Degraded
+// condition until the degraded state has persisted for the grace period.
+// While the state is pending, the condition is False with the reason of the
+// degraded state, and its lastTransitionTime records when the state began.
+// Returns the condition to publish and, for a pending state, the time until
+// the grace period expires.  An ingresscontroller that is already degraded
+// stays degraded without a new grace period.
+func debounceIngressDegradedCondition(degraded operatorv1.OperatorCondition, old *operatorv1.OperatorCondition, gracePeriod time.Duration, now time.Time) (operatorv1.OperatorCondition, time.Duration) {
+	if degraded.Status != operatorv1.ConditionTrue || gracePeriod <= 0 {
+		return degraded, 0
+	}
+	if old != nil && old.Status == operatorv1.ConditionTrue {
+		return degraded, 0
+	}
+	since := now
+	if old != nil && old.Status == operatorv1.ConditionFalse && len(old.Reason) != 0 && !old.LastTransitionTime.IsZero() {
+		since = old.LastTransitionTime.Time
+	}
+	remaining := gracePeriod - now.Sub(since)
+	if remaining <= 0 {
+		return degraded, 0
+	}
+	return operatorv1.OperatorCondition{
+		Type:               operatorv1.OperatorStatusTypeDegraded,
+		Status:             operatorv1.ConditionFalse,
+		Reason:             degraded.Reason,
+		Message:            fmt.Sprintf("%s; the ingresscontroller will be marked degraded if this persists for %s", degraded.Message, gracePeriod),
+		LastTransitionTime: metav1.NewTime(since),
+	}, remaining
+}
+
+// immutableSpecIgnored returns a description of the changes if the given
+// ingresscontroller's spec asks for a domain or endpoint publishing strategy
+// other than the one in status, or the empty string otherwise.  Both are
+// published to status once and cannot be changed afterwards, so such a spec
+// change has no effect.  If preferClusterIngressDomain is true, spec.domain is
+// overridden by the cluster ingress config by design, so it is not compared.
+func immutableSpecIgnored(ic *operatorv1.IngressController, preferClusterIngressDomain bool) string {
+	ignored := []string{}
+	if !preferClusterIngressDomain && len(ic.Spec.Domain) != 0 && len(ic.Status.Domain) != 0 && ic.Spec.Domain != ic.Status.Domain {
+		ignored = append(ignored, fmt.Sprintf("spec.domain is %q, but the ingresscontroller uses domain %q", ic.Spec.Domain, ic.Status.Domain))
+	}
+	if spec, status := ic.Spec.EndpointPublishingStrategy, ic.Status.EndpointPublishingStrategy; spec != nil && status != nil && spec.Type != status.Type {
+		ignored = append(ignored, fmt.Sprintf("spec.endpointPublishingStrategy.type is %s, but the ingresscontroller uses %s", spec.Type, status.Type))
+	}
+	if len(ignored) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s.  These fields cannot be changed after the ingresscontroller is created; delete and recreate the ingresscontroller to apply them.", strings.Join(ignored, "; "))
+}
+
+// isImagePullFailure returns a Boolean indicating whether the given container
+// is waiting because its image cannot be pulled.
+func isImagePullFailure(status corev1.ContainerStatus) bool {
+	if status.State.Waiting == nil {
+		return false
+	}
+	switch status.State.Waiting.Reason {
+	case "ErrImagePull", "ImagePullBackOff":
+		return true
+	}
+	return false
+}
+
+// getIngressCondition returns the condition of the given type from the given
+// conditions, or nil if no such condition exists.
+func getIngressCondition(conditions []operatorv1.OperatorCondition, conditionType string) *operatorv1.OperatorCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
 // getIngressAvailableCondition fetches ingress controller's available condition from the given conditions.
 func getIngressAvailableCondition(conditions []operatorv1.OperatorCondition) *operatorv1.OperatorCondition {
-	var availableCondition *operatorv1.OperatorCondition
+	return getIngressCondition(conditions, operatorv1.IngressControllerAvailableConditionType)
+}
+
+// setIngressCondition adds or replaces the condition of the given condition's
+// type in the given conditions, preserving the last transition time if the
+// condition has not changed, and returns the resulting conditions.
+func setIngressCondition(conditions []operatorv1.OperatorCondition, condition operatorv1.OperatorCondition) []operatorv1.OperatorCondition {
+	updated := []operatorv1.OperatorCondition{}
 	for i := range conditions {
-		switch conditions[i].Type {
-		case operatorv1.IngressControllerAvailableConditionType:
-			availableCondition = &conditions[i]
-			break
+		if conditions[i].Type != condition.Type {
+			updated = append(updated, conditions[i])
 		}
 	}
-
-	return availableCondition
+	setIngressLastTransitionTime(&condition, getIngressCondition(conditions, condition.Type))
+	return append(updated, condition)
 }
 
 // setIngressLastTransitionTime sets LastTransitionTime for the given ingress controller condition.
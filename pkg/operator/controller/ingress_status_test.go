@@ -1,20 +1,26 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 
+	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/client-go/tools/record"
 )
 
 func ingressController(name string, t operatorv1.EndpointPublishingStrategyType) *operatorv1.IngressController {
@@ -191,15 +197,50 @@ func TestComputeLoadBalancerStatus(t *testing.T) {
 }
 
 func TestComputeIngressStatusConditions(t *testing.T) {
+	lbConditions := func(c *operatorv1.IngressController, s *corev1.Service) []operatorv1.OperatorCondition {
+		return computeLoadBalancerStatus(c, s, nil)
+	}
+	lbController := ingressController("default", operatorv1.LoadBalancerServiceStrategyType)
+	hostNetworkController := ingressController("default", operatorv1.HostNetworkStrategyType)
+	available := operatorv1.OperatorCondition{
+		Type:   operatorv1.IngressControllerAvailableConditionType,
+		Status: operatorv1.ConditionTrue,
+	}
+	deploymentUnavailable := operatorv1.OperatorCondition{
+		Type:    operatorv1.IngressControllerAvailableConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "DeploymentUnavailable",
+		Message: "no Deployment replicas available",
+	}
 	testCases := []struct {
 		description     string
 		availRepl, repl int32
-		condType        string
-		condStatus      operatorv1.ConditionStatus
+		lbConditions    []operatorv1.OperatorCondition
+		expect          operatorv1.OperatorCondition
 	}{
-		{"0/2 deployment replicas available", 0, 2, operatorv1.OperatorStatusTypeAvailable, operatorv1.ConditionFalse},
-		{"1/2 deployment replicas available", 1, 2, operatorv1.OperatorStatusTypeAvailable, operatorv1.ConditionTrue},
-		{"2/2 deployment replicas available", 2, 2, operatorv1.OperatorStatusTypeAvailable, operatorv1.ConditionTrue},
+		{"0/2 deployment replicas available", 0, 2, lbConditions(hostNetworkController, nil), deploymentUnavailable},
+		{"1/2 deployment replicas available", 1, 2, lbConditions(hostNetworkController, nil), available},
+		{"2/2 deployment replicas available", 2, 2, lbConditions(hostNetworkController, nil), available},
+		{"2/2 deployment replicas available, lb ready", 2, 2, lbConditions(lbController, provisionedLBservice("default")), available},
+		{
+			"2/2 deployment replicas available, lb pending", 2, 2, lbConditions(lbController, pendingLBService("default")),
+			operatorv1.OperatorCondition{
+				Type:    operatorv1.IngressControllerAvailableConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "LoadBalancerNotReady",
+				Message: "the load balancer is not ready: The LoadBalancer service is pending",
+			},
+		},
+		{
+			"2/2 deployment replicas available, lb service missing", 2, 2, lbConditions(lbController, nil),
+			operatorv1.OperatorCondition{
+				Type:    operatorv1.IngressControllerAvailableConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "LoadBalancerNotReady",
+				Message: "the load balancer is not ready: The LoadBalancer service resource is missing",
+			},
+		},
+		{"0/2 deployment replicas available, lb ready", 0, 2, lbConditions(lbController, provisionedLBservice("default")), deploymentUnavailable},
 	}
 
 	for i, tc := range testCases {
@@ -213,15 +254,10 @@ func TestComputeIngressStatusConditions(t *testing.T) {
 			},
 		}
 
-		expected := []operatorv1.OperatorCondition{
-			{
-				Type:   tc.condType,
-				Status: tc.condStatus,
-			},
-		}
-		actual := computeIngressStatusConditions([]operatorv1.OperatorCondition{}, deploy)
+		expected := []operatorv1.OperatorCondition{tc.expect}
+		actual := computeIngressStatusConditions([]operatorv1.OperatorCondition{}, deploy, tc.lbConditions)
 		conditionsCmpOpts := []cmp.Option{
-			cmpopts.IgnoreFields(operatorv1.OperatorCondition{}, "LastTransitionTime", "Reason", "Message"),
+			cmpopts.IgnoreFields(operatorv1.OperatorCondition{}, "LastTransitionTime"),
 			cmpopts.EquateEmpty(),
 			cmpopts.SortSlices(func(a, b operatorv1.OperatorCondition) bool { return a.Type < b.Type }),
 		}
@@ -231,6 +267,42 @@ func TestComputeIngressStatusConditions(t *testing.T) {
 	}
 }
 
+// TestIngressAvailableFollowsLoadBalancerProvisioning verifies that an
+// ingresscontroller that publishes its endpoints using a load balancer is
+// reported as unavailable until the cloud assigns the load balancer an address
+// and becomes available once status.loadBalancer.ingress is populated.
+func TestIngressAvailableFollowsLoadBalancerProvisioning(t *testing.T) {
+	ic := ingressController("default", operatorv1.LoadBalancerServiceStrategyType)
+	deploy := &appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{
+			Replicas:          2,
+			AvailableReplicas: 2,
+		},
+	}
+	service := pendingLBService("default")
+
+	lbConditions := computeLoadBalancerStatus(ic, service, nil)
+	conditions := computeIngressStatusConditions(ic.Status.Conditions, deploy, lbConditions)
+	if c := getIngressAvailableCondition(conditions); c == nil || c.Status != operatorv1.ConditionFalse || c.Reason != "LoadBalancerNotReady" {
+		t.Fatalf("expected Available=False with reason LoadBalancerNotReady while the load balancer is pending, got %#v", c)
+	}
+	if c := getIngressCondition(lbConditions, operatorv1.LoadBalancerReadyIngressConditionType); c == nil || c.Status != operatorv1.ConditionFalse || c.Reason != "LoadBalancerPending" {
+		t.Fatalf("expected LoadBalancerReady=False with reason LoadBalancerPending, got %#v", c)
+	}
+	ic.Status.Conditions = append(conditions, lbConditions...)
+
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.cloudprovider.example.com"}}
+
+	lbConditions = computeLoadBalancerStatus(ic, service, nil)
+	conditions = computeIngressStatusConditions(ic.Status.Conditions, deploy, lbConditions)
+	if c := getIngressAvailableCondition(conditions); c == nil || c.Status != operatorv1.ConditionTrue {
+		t.Fatalf("expected Available=True once the load balancer is provisioned, got %#v", c)
+	}
+	if c := getIngressCondition(lbConditions, operatorv1.LoadBalancerReadyIngressConditionType); c == nil || c.Status != operatorv1.ConditionTrue {
+		t.Fatalf("expected LoadBalancerReady=True once the load balancer is provisioned, got %#v", c)
+	}
+}
+
 func TestIngressStatusesEqual(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -350,3 +422,401 @@ func TestIngressStatusesEqual(t *testing.T) {
 		}
 	}
 }
+
+func TestSetIngressCondition(t *testing.T) {
+	old := metav1.Unix(0, 0)
+	conditions := []operatorv1.OperatorCondition{
+		{
+			Type:               operatorv1.IngressControllerAvailableConditionType,
+			Status:             operatorv1.ConditionTrue,
+			LastTransitionTime: old,
+		},
+		{
+			Type:               IngressControllerAdmittedConditionType,
+			Status:             operatorv1.ConditionTrue,
+			Reason:             "Valid",
+			LastTransitionTime: old,
+		},
+	}
+
+	unchanged := setIngressCondition(conditions, operatorv1.OperatorCondition{
+		Type:   IngressControllerAdmittedConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "Valid",
+	})
+	if len(unchanged) != 2 {
+		t.Fatalf("expected 2 conditions, got %d", len(unchanged))
+	}
+	if c := getIngressCondition(unchanged, IngressControllerAdmittedConditionType); c == nil || c.LastTransitionTime != old {
+		t.Errorf("expected unchanged condition to keep its last transition time, got %#v", c)
+	}
+
+	changed := setIngressCondition(conditions, operatorv1.OperatorCondition{
+		Type:    IngressControllerAdmittedConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "Invalid",
+		Message: "invalid",
+	})
+	if len(changed) != 2 {
+		t.Fatalf("expected 2 conditions, got %d", len(changed))
+	}
+	if c := getIngressCondition(changed, IngressControllerAdmittedConditionType); c == nil || c.Status != operatorv1.ConditionFalse || c.LastTransitionTime == old {
+		t.Errorf("expected changed condition with a new last transition time, got %#v", c)
+	}
+	if c := getIngressCondition(changed, operatorv1.IngressControllerAvailableConditionType); c == nil || c.LastTransitionTime != old {
+		t.Errorf("expected other conditions to be preserved, got %#v", c)
+	}
+}
+
+func TestComputeIngressDegradedCondition(t *testing.T) {
+	pod := func(name, waitingReason string) corev1.Pod {
+		status := corev1.ContainerStatus{
+			Name:  "router",
+			Image: "registry.example.com/openshift/router:latest",
+		}
+		if len(waitingReason) != 0 {
+			status.State.Waiting = &corev1.ContainerStateWaiting{Reason: waitingReason}
+		} else {
+			status.State.Running = &corev1.ContainerStateRunning{}
+		}
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{status},
+			},
+		}
+	}
+	notDegraded := operatorv1.OperatorCondition{
+		Type:   operatorv1.OperatorStatusTypeDegraded,
+		Status: operatorv1.ConditionFalse,
+	}
+	testCases := []struct {
+		description string
+		pods        []corev1.Pod
+		expect      operatorv1.OperatorCondition
+	}{
+		{
+			description: "no pods",
+			expect:      notDegraded,
+		},
+		{
+			description: "running pods",
+			pods:        []corev1.Pod{pod("router-default-1", ""), pod("router-default-2", "")},
+			expect:      notDegraded,
+		},
+		{
+			description: "crash looping pod",
+			pods:        []corev1.Pod{pod("router-default-1", "CrashLoopBackOff")},
+			expect:      notDegraded,
+		},
+		{
+			description: "one pod cannot pull its image",
+			pods:        []corev1.Pod{pod("router-default-1", ""), pod("router-default-2", "ErrImagePull")},
+			expect: operatorv1.OperatorCondition{
+				Type:    operatorv1.OperatorStatusTypeDegraded,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "ImagePullFailed",
+				Message: `router pod router-default-2 cannot pull image "registry.example.com/openshift/router:latest"; verify that the image exists and that the cluster can reach its registry`,
+			},
+		},
+		{
+			description: "several pods cannot pull their image",
+			pods:        []corev1.Pod{pod("router-default-2", "ImagePullBackOff"), pod("router-default-1", "ErrImagePull")},
+			expect: operatorv1.OperatorCondition{
+				Type:    operatorv1.OperatorStatusTypeDegraded,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "ImagePullFailed",
+				Message: `2 router pods, including router-default-1, cannot pull image "registry.example.com/openshift/router:latest"; verify that the image exists and that the cluster can reach its registry`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			Status: operatorv1.IngressControllerStatus{
+				Domain: "apps.example.com",
+			},
+		}
+		actual := computeIngressDegradedCondition(ic, tc.pods)
+		if !cmp.Equal(actual, tc.expect) {
+			t.Errorf("%q: expected %#v, got %#v", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestOperandVersionSkew(t *testing.T) {
+	pod := func(name, image string, deleted bool) corev1.Pod {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "router", Image: image}},
+			},
+		}
+		if deleted {
+			now := metav1.Now()
+			pod.DeletionTimestamp = &now
+		}
+		return pod
+	}
+	testCases := []struct {
+		description string
+		pods        []corev1.Pod
+		expect      string
+	}{
+		{
+			description: "no pods",
+		},
+		{
+			description: "pods on the expected image",
+			pods:        []corev1.Pod{pod("router-default-1", "router:v2", false), pod("router-default-2", "router:v2", false)},
+		},
+		{
+			description: "terminating pod on an old image",
+			pods:        []corev1.Pod{pod("router-default-1", "router:v1", true), pod("router-default-2", "router:v2", false)},
+		},
+		{
+			description: "one pod on an old image",
+			pods:        []corev1.Pod{pod("router-default-2", "router:v1", false), pod("router-default-1", "router:v2", false)},
+			expect:      `router pod router-default-2 runs image "router:v1", but release 4.2.0 expects image "router:v2"`,
+		},
+		{
+			description: "several pods on an old image",
+			pods:        []corev1.Pod{pod("router-default-2", "router:v1", false), pod("router-default-1", "router:v1", false)},
+			expect:      `2 router pods, including router-default-1, run image "router:v1", but release 4.2.0 expects image "router:v2"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		if actual := operandVersionSkew(tc.pods, "router:v2", "4.2.0"); actual != tc.expect {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+// TestSyncIngressControllerStatusOperandVersionSkew verifies that operand
+// version skew marks the ingresscontroller degraded if the operator is
+// configured to degrade on skew, and is reported by a warning event otherwise.
+func TestSyncIngressControllerStatusOperandVersionSkew(t *testing.T) {
+	deployment := manifests.RouterDeployment()
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "router"}}
+	deployment.Status.AvailableReplicas = 1
+	pods := []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "router-default-1"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "router", Image: "router:v1"}},
+		},
+	}}
+	skewMessage := `router pod router-default-1 runs image "router:v1", but release 4.2.0 expects image "router:v2"`
+	testCases := []struct {
+		degradeOnSkew bool
+		expect        operatorv1.OperatorCondition
+		expectEvent   string
+	}{
+		{
+			degradeOnSkew: false,
+			expectEvent:   "Warning OperandVersionSkew " + skewMessage,
+			expect: operatorv1.OperatorCondition{
+				Type:   operatorv1.OperatorStatusTypeDegraded,
+				Status: operatorv1.ConditionFalse,
+			},
+		},
+		{
+			degradeOnSkew: true,
+			expect: operatorv1.OperatorCondition{
+				Type:    operatorv1.OperatorStatusTypeDegraded,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "OperandVersionSkew",
+				Message: skewMessage,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		ic := ingressController("default", operatorv1.PrivateStrategyType)
+		ic.Namespace = "openshift-ingress-operator"
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}}
+		client := newFakeClient(ic, node)
+		recorder := record.NewFakeRecorder(10)
+		r := &reconciler{
+			Config: Config{
+				OperandNamespace:            "openshift-ingress",
+				IngressControllerImage:      "router:v2",
+				OperatorReleaseVersion:      "4.2.0",
+				DegradeOnOperandVersionSkew: tc.degradeOnSkew,
+			},
+			client:   client,
+			recorder: recorder,
+		}
+		if _, err := r.syncIngressControllerStatus(ic, deployment, pods, nil, nil, &configv1.DNS{}); err != nil {
+			t.Fatalf("degrade on skew %t: failed to sync status: %v", tc.degradeOnSkew, err)
+		}
+		current := &operatorv1.IngressController{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case event := <-recorder.Events:
+			if event != tc.expectEvent {
+				t.Errorf("degrade on skew %t: expected event %q, got %q", tc.degradeOnSkew, tc.expectEvent, event)
+			}
+		default:
+			if len(tc.expectEvent) != 0 {
+				t.Errorf("degrade on skew %t: expected event %q", tc.degradeOnSkew, tc.expectEvent)
+			}
+		}
+		degraded := getIngressCondition(current.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+		if degraded == nil {
+			t.Fatalf("degrade on skew %t: expected a Degraded condition", tc.degradeOnSkew)
+		}
+		if !cmp.Equal(*degraded, tc.expect, cmpopts.IgnoreFields(operatorv1.OperatorCondition{}, "LastTransitionTime")) {
+			t.Errorf("degrade on skew %t: expected %#v, got %#v", tc.degradeOnSkew, tc.expect, *degraded)
+		}
+	}
+}
+
+func TestDebounceIngressDegradedCondition(t *testing.T) {
+	now := time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)
+	minutesAgo := func(n int) metav1.Time { return metav1.NewTime(now.Add(-time.Duration(n) * time.Minute)) }
+	degraded := operatorv1.OperatorCondition{
+		Type:    operatorv1.OperatorStatusTypeDegraded,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "ImagePullFailed",
+		Message: "router pod router-default-1 cannot pull image",
+	}
+	notDegraded := operatorv1.OperatorCondition{
+		Type:   operatorv1.OperatorStatusTypeDegraded,
+		Status: operatorv1.ConditionFalse,
+	}
+	pending := func(since metav1.Time) operatorv1.OperatorCondition {
+		return operatorv1.OperatorCondition{
+			Type:               operatorv1.OperatorStatusTypeDegraded,
+			Status:             operatorv1.ConditionFalse,
+			Reason:             "ImagePullFailed",
+			Message:            "router pod router-default-1 cannot pull image; the ingresscontroller will be marked degraded if this persists for 5m0s",
+			LastTransitionTime: since,
+		}
+	}
+	withTime := func(condition operatorv1.OperatorCondition, t metav1.Time) *operatorv1.OperatorCondition {
+		condition.LastTransitionTime = t
+		return &condition
+	}
+	testCases := []struct {
+		description   string
+		gracePeriod   time.Duration
+		computed      operatorv1.OperatorCondition
+		old           *operatorv1.OperatorCondition
+		expect        operatorv1.OperatorCondition
+		expectRequeue time.Duration
+	}{
+		{
+			description: "no grace period",
+			computed:    degraded,
+			old:         withTime(notDegraded, minutesAgo(60)),
+			expect:      degraded,
+		},
+		{
+			description: "not degraded",
+			gracePeriod: 5 * time.Minute,
+			computed:    notDegraded,
+			old:         withTime(pending(minutesAgo(2)), minutesAgo(2)),
+			expect:      notDegraded,
+		},
+		{
+			description:   "newly degraded",
+			gracePeriod:   5 * time.Minute,
+			computed:      degraded,
+			old:           withTime(notDegraded, minutesAgo(60)),
+			expect:        pending(metav1.NewTime(now)),
+			expectRequeue: 5 * time.Minute,
+		},
+		{
+			description:   "newly degraded without a previous condition",
+			gracePeriod:   5 * time.Minute,
+			computed:      degraded,
+			expect:        pending(metav1.NewTime(now)),
+			expectRequeue: 5 * time.Minute,
+		},
+		{
+			description:   "degraded within the grace period",
+			gracePeriod:   5 * time.Minute,
+			computed:      degraded,
+			old:           withTime(pending(minutesAgo(2)), minutesAgo(2)),
+			expect:        pending(minutesAgo(2)),
+			expectRequeue: 3 * time.Minute,
+		},
+		{
+			description: "degraded past the grace period",
+			gracePeriod: 5 * time.Minute,
+			computed:    degraded,
+			old:         withTime(pending(minutesAgo(5)), minutesAgo(5)),
+			expect:      degraded,
+		},
+		{
+			description: "already degraded",
+			gracePeriod: 5 * time.Minute,
+			computed:    degraded,
+			old:         withTime(degraded, minutesAgo(1)),
+			expect:      degraded,
+		},
+	}
+
+	for _, tc := range testCases {
+		actual, requeue := debounceIngressDegradedCondition(tc.computed, tc.old, tc.gracePeriod, now)
+		if !cmp.Equal(actual, tc.expect) {
+			t.Errorf("%q: expected %#v, got %#v", tc.description, tc.expect, actual)
+		}
+		if requeue != tc.expectRequeue {
+			t.Errorf("%q: expected requeue after %v, got %v", tc.description, tc.expectRequeue, requeue)
+		}
+	}
+}
+
+// TestImmutableSpecIgnored verifies that spec changes to the domain and
+// endpoint publishing strategy that cannot take effect because the values in
+// status are immutable are reported.
+func TestImmutableSpecIgnored(t *testing.T) {
+	ic := func(specDomain, statusDomain string, specStrategy, statusStrategy operatorv1.EndpointPublishingStrategyType) *operatorv1.IngressController {
+		ic := ingressController("default", statusStrategy)
+		ic.Spec.Domain = specDomain
+		ic.Status.Domain = statusDomain
+		if len(specStrategy) != 0 {
+			ic.Spec.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{Type: specStrategy}
+		}
+		return ic
+	}
+	testCases := []struct {
+		description                string
+		ic                         *operatorv1.IngressController
+		preferClusterIngressDomain bool
+		expect                     string
+	}{
+		{
+			description: "spec matches status",
+			ic:          ic("apps.example.com", "apps.example.com", operatorv1.HostNetworkStrategyType, operatorv1.HostNetworkStrategyType),
+		},
+		{
+			description: "spec leaves domain and strategy unset",
+			ic:          ic("", "apps.example.com", "", operatorv1.LoadBalancerServiceStrategyType),
+		},
+		{
+			description: "domain changed",
+			ic:          ic("new.example.com", "apps.example.com", "", operatorv1.LoadBalancerServiceStrategyType),
+			expect:      `spec.domain is "new.example.com", but the ingresscontroller uses domain "apps.example.com".  These fields cannot be changed after the ingresscontroller is created; delete and recreate the ingresscontroller to apply them.`,
+		},
+		{
+			description:                "domain changed while the cluster config domain takes precedence",
+			ic:                         ic("new.example.com", "apps.example.com", "", operatorv1.LoadBalancerServiceStrategyType),
+			preferClusterIngressDomain: true,
+		},
+		{
+			description: "domain and strategy changed",
+			ic:          ic("new.example.com", "apps.example.com", operatorv1.HostNetworkStrategyType, operatorv1.LoadBalancerServiceStrategyType),
+			expect:      `spec.domain is "new.example.com", but the ingresscontroller uses domain "apps.example.com"; spec.endpointPublishingStrategy.type is HostNetwork, but the ingresscontroller uses LoadBalancerService.  These fields cannot be changed after the ingresscontroller is created; delete and recreate the ingresscontroller to apply them.`,
+		},
+	}
+	for _, tc := range testCases {
+		if actual := immutableSpecIgnored(tc.ic, tc.preferClusterIngressDomain); actual != tc.expect {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expect, actual)
+		}
+	}
+}
@@ -0,0 +1,601 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxHAProxyTimeout is the longest timeout that HAProxy accepts, which is
+// 2^31-1 milliseconds.
+const maxHAProxyTimeout = (1<<31 - 1) * time.Millisecond
+
+// mimeTypeRegexp matches a MIME type of the form type/subtype, where type and
+// subtype are tokens as defined by RFC 7231.
+var mimeTypeRegexp = regexp.MustCompile(`^[A-Za-z0-9!#$%&'*+.^_|~-]+/[A-Za-z0-9!#$%&'*+.^_|~-]+$`)
+
+// validateIngressController checks the user-provided configuration of the
+// given ingresscontroller and returns an error describing every invalid value,
+// or nil if the configuration is valid.
+func validateIngressController(ic *operatorv1.IngressController) error {
+	errs := []error{}
+
+	if err := validateDefaultInsecureEdgeTerminationPolicy(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateInternalServiceSessionAffinity(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateLoadBalancerHealthCheckNodePort(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateLoadBalancerIP(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateUniqueID(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if v, ok := ic.Annotations[sessionCookieNameAnnotation]; ok && !isHTTPToken(v) {
+		errs = append(errs, fmt.Errorf("invalid value for annotation %s: %q; must be a valid cookie name", sessionCookieNameAnnotation, v))
+	}
+
+	if err := validateMaxConnections(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateHeaderBufferSizes(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateDynamicServers(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateServiceExternalIPs(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateRouterProbes(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateDisableMetricsIntegration(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateCompressionMIMETypes(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateMaintenanceMode(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if v, ok := ic.Annotations[backendCABundleConfigMapAnnotation]; ok {
+		if msgs := validation.IsDNS1123Subdomain(v); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid value for annotation %s: %q: %s", backendCABundleConfigMapAnnotation, v, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if v, ok := ic.Annotations[routerTemplateConfigMapAnnotation]; ok {
+		if msgs := validation.IsDNS1123Subdomain(v); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid value for annotation %s: %q: %s", routerTemplateConfigMapAnnotation, v, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if v, ok := ic.Annotations[dnsCredentialsSecretAnnotation]; ok {
+		if msgs := validation.IsDNS1123Subdomain(v); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid value for annotation %s: %q: %s", dnsCredentialsSecretAnnotation, v, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if v, ok := ic.Annotations[internalServiceTopologyAwareHintsAnnotation]; ok && v != "true" && v != "false" {
+		errs = append(errs, fmt.Errorf("invalid value for annotation %s: %q; allowed values are true and false", internalServiceTopologyAwareHintsAnnotation, v))
+	}
+
+	if v, ok := ic.Annotations[dnsManagementPausedAnnotation]; ok && v != "true" && v != "false" {
+		errs = append(errs, fmt.Errorf("invalid value for annotation %s: %q; allowed values are true and false", dnsManagementPausedAnnotation, v))
+	}
+
+	if err := validateRecordReconcileSummary(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateTunnelTimeout(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateIdleConnectionTimeout(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validatePerRouteMetrics(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateMinReadySeconds(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateDNSCleanupTimeout(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateDNSAliases(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateRouterThreads(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateRouterAutoscaling(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateRouterPodDNS(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateExposeRouterStats(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if v, ok := ic.Annotations[routerServiceAccountAnnotation]; ok {
+		if msgs := validation.IsDNS1123Subdomain(v); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid value for annotation %s: %q is not a valid service account name: %s", routerServiceAccountAnnotation, v, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if err := validateRouterSecurityContext(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	if v, ok := ic.Annotations[routerSysctlsAnnotation]; ok {
+		if _, err := parseRouterSysctls(v); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for annotation %s: %v", routerSysctlsAnnotation, err))
+		}
+	}
+
+	if v, ok := ic.Annotations[dumpRouteConfigAnnotation]; ok {
+		if _, _, err := parseRouteName(v); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for annotation %s: %v", dumpRouteConfigAnnotation, err))
+		}
+	}
+
+	if v, ok := ic.Annotations[defaultRouteSettingsAnnotation]; ok {
+		if _, err := parseDefaultRouteSettings(v); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for annotation %s: %v", defaultRouteSettingsAnnotation, err))
+		}
+	}
+
+	if err := validateWeightedBalancing(ic); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateDefaultInsecureEdgeTerminationPolicy verifies that the default
+// insecure edge termination policy annotation, if set, has an allowed value.
+func validateDefaultInsecureEdgeTerminationPolicy(ic *operatorv1.IngressController) error {
+	policy, ok := ic.Annotations[defaultInsecureEdgeTerminationPolicyAnnotation]
+	if !ok {
+		return nil
+	}
+	if _, ok := insecureEdgeTerminationPolicies[policy]; !ok {
+		return fmt.Errorf("invalid value for annotation %s: %q; allowed values are Allow, Redirect, and Disable", defaultInsecureEdgeTerminationPolicyAnnotation, policy)
+	}
+	return nil
+}
+
+// validateInternalServiceSessionAffinity verifies that the internal service
+// session affinity annotations, if set, have allowed values.
+func validateInternalServiceSessionAffinity(ic *operatorv1.IngressController) error {
+	affinity := corev1.ServiceAffinityNone
+	if v, ok := ic.Annotations[internalServiceSessionAffinityAnnotation]; ok {
+		switch corev1.ServiceAffinity(v) {
+		case corev1.ServiceAffinityNone, corev1.ServiceAffinityClientIP:
+			affinity = corev1.ServiceAffinity(v)
+		default:
+			return fmt.Errorf("invalid value for annotation %s: %q; allowed values are None and ClientIP", internalServiceSessionAffinityAnnotation, v)
+		}
+	}
+	v, ok := ic.Annotations[internalServiceSessionAffinityTimeoutAnnotation]
+	if !ok {
+		return nil
+	}
+	if affinity != corev1.ServiceAffinityClientIP {
+		return fmt.Errorf("annotation %s may only be set when annotation %s is ClientIP", internalServiceSessionAffinityTimeoutAnnotation, internalServiceSessionAffinityAnnotation)
+	}
+	seconds, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || seconds < 1 || seconds > maxClientIPServiceAffinitySeconds {
+		return fmt.Errorf("invalid value for annotation %s: %q; must be an integer between 1 and %d", internalServiceSessionAffinityTimeoutAnnotation, v, maxClientIPServiceAffinitySeconds)
+	}
+	return nil
+}
+
+// validateLoadBalancerHealthCheckNodePort verifies that the load balancer
+// health check node port annotation, if set, is a port in the default NodePort
+// range and that the ingresscontroller uses a load balancer.
+func validateLoadBalancerHealthCheckNodePort(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[loadBalancerHealthCheckNodePortAnnotation]
+	if !ok {
+		return nil
+	}
+	if !usesLoadBalancer(ic) {
+		return fmt.Errorf("annotation %s may only be set when the endpoint publishing strategy is %s", loadBalancerHealthCheckNodePortAnnotation, operatorv1.LoadBalancerServiceStrategyType)
+	}
+	port, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || port < minNodePort || port > maxNodePort {
+		return fmt.Errorf("invalid value for annotation %s: %q; must be an integer between %d and %d", loadBalancerHealthCheckNodePortAnnotation, v, minNodePort, maxNodePort)
+	}
+	return nil
+}
+
+// validateUniqueID verifies that the unique ID header name annotation, if set,
+// is a valid HTTP header name and that the unique ID format annotation, if
+// set, accompanies a header name and can be safely passed to the router.
+func validateUniqueID(ic *operatorv1.IngressController) error {
+	name, hasName := ic.Annotations[uniqueIDHeaderNameAnnotation]
+	if hasName && !isHTTPToken(name) {
+		return fmt.Errorf("invalid value for annotation %s: %q; must be a valid HTTP header name", uniqueIDHeaderNameAnnotation, name)
+	}
+	format, ok := ic.Annotations[uniqueIDFormatAnnotation]
+	if !ok {
+		return nil
+	}
+	if !hasName {
+		return fmt.Errorf("annotation %s may only be set when annotation %s is set", uniqueIDFormatAnnotation, uniqueIDHeaderNameAnnotation)
+	}
+	if len(strings.TrimSpace(format)) == 0 || strings.ContainsAny(format, "\"\r\n") {
+		return fmt.Errorf("invalid value for annotation %s: %q; must be non-empty and must not contain quotes or line breaks", uniqueIDFormatAnnotation, format)
+	}
+	return nil
+}
+
+// validateInternalServiceTopologyAwareHintsSupported verifies that the
+// ingresscontroller does not enable topology-aware hints on its internal
+// service unless the cluster supports them.  Otherwise the service annotation
+// would be silently ignored.
+func validateInternalServiceTopologyAwareHintsSupported(ic *operatorv1.IngressController, supported bool) error {
+	if supported || ic.Annotations[internalServiceTopologyAwareHintsAnnotation] != "true" {
+		return nil
+	}
+	return fmt.Errorf("annotation %s is set to true, but the cluster does not support topology-aware hints; the API server does not serve %s", internalServiceTopologyAwareHintsAnnotation, TopologyAwareHintsGroupVersion)
+}
+
+// validateMaxConnections verifies that the max connections annotation, if set,
+// is a positive integer.
+func validateMaxConnections(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[maxConnectionsAnnotation]
+	if !ok {
+		return nil
+	}
+	if n, err := strconv.ParseInt(v, 10, 32); err != nil || n < 1 || strconv.FormatInt(n, 10) != v {
+		return fmt.Errorf("invalid value for annotation %s: %q; must be a positive integer", maxConnectionsAnnotation, v)
+	}
+	return nil
+}
+
+// validateDisableMetricsIntegration verifies that the disable metrics
+// integration annotation, if set, is "true" or "false".
+func validateDisableMetricsIntegration(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[disableMetricsIntegrationAnnotation]
+	if !ok {
+		return nil
+	}
+	if v != "true" && v != "false" {
+		return fmt.Errorf("invalid value for annotation %s: %q; allowed values are true and false", disableMetricsIntegrationAnnotation, v)
+	}
+	return nil
+}
+
+// validateCompressionMIMETypes verifies that each entry in the compression
+// MIME types annotation, if set, is a MIME type of the form type/subtype
+// without parameters or wildcards.
+func validateCompressionMIMETypes(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[compressionMIMETypesAnnotation]
+	if !ok {
+		return nil
+	}
+	for _, mimeType := range strings.Split(v, ",") {
+		mimeType = strings.TrimSpace(mimeType)
+		if len(mimeType) == 0 {
+			continue
+		}
+		if !mimeTypeRegexp.MatchString(mimeType) {
+			return fmt.Errorf("invalid value for annotation %s: %q is not a MIME type of the form type/subtype", compressionMIMETypesAnnotation, mimeType)
+		}
+	}
+	return nil
+}
+
+// validateMaintenanceMode verifies that the maintenance mode annotation, if
+// set, is "true" or "false" and that the maintenance page configmap
+// annotation, if set, is a valid configmap name.
+func validateMaintenanceMode(ic *operatorv1.IngressController) error {
+	if v, ok := ic.Annotations[maintenanceModeAnnotation]; ok && v != "true" && v != "false" {
+		return fmt.Errorf("invalid value for annotation %s: %q; allowed values are true and false", maintenanceModeAnnotation, v)
+	}
+	if v, ok := ic.Annotations[maintenancePageConfigMapAnnotation]; ok {
+		if errs := validation.IsDNS1123Subdomain(v); len(errs) != 0 {
+			return fmt.Errorf("invalid value for annotation %s: %q: %s", maintenancePageConfigMapAnnotation, v, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// validateRecordReconcileSummary verifies that the record reconcile summary
+// annotation, if set, is "true" or "false".
+func validateRecordReconcileSummary(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[recordReconcileSummaryAnnotation]
+	if !ok {
+		return nil
+	}
+	if v != "true" && v != "false" {
+		return fmt.Errorf("invalid value for annotation %s: %q; allowed values are true and false", recordReconcileSummaryAnnotation, v)
+	}
+	return nil
+}
+
+// validateDNSCleanupTimeout verifies that the DNS cleanup timeout annotation,
+// if set, is a non-negative duration.
+func validateDNSCleanupTimeout(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[dnsCleanupTimeoutAnnotation]
+	if !ok {
+		return nil
+	}
+	if d, err := time.ParseDuration(v); err != nil || d < 0 {
+		return fmt.Errorf("invalid value for annotation %s: %q; must be a non-negative duration, such as 0s or 5m", dnsCleanupTimeoutAnnotation, v)
+	}
+	return nil
+}
+
+// validateTunnelTimeout verifies that the tunnel timeout annotation, if set,
+// is a duration between 1ms and the maximum timeout that HAProxy supports.
+func validateTunnelTimeout(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[tunnelTimeoutAnnotation]
+	if !ok {
+		return nil
+	}
+	if d, err := time.ParseDuration(v); err != nil || d < time.Millisecond || d > maxHAProxyTimeout {
+		return fmt.Errorf("invalid value for annotation %s: %q; must be a duration between 1ms and %s, such as 90s or 2h", tunnelTimeoutAnnotation, v, maxHAProxyTimeout)
+	}
+	return nil
+}
+
+// validateDNSAliases verifies that the DNS aliases annotation, if set, lists
+// only valid DNS names that are subdomains of the ingresscontroller's domain,
+// and that the ingresscontroller uses a load balancer, for which the operator
+// publishes DNS records.
+func validateDNSAliases(ic *operatorv1.IngressController) error {
+	if _, ok := ic.Annotations[dnsAliasesAnnotation]; !ok {
+		return nil
+	}
+	if !usesLoadBalancer(ic) {
+		return fmt.Errorf("annotation %s may only be set when the endpoint publishing strategy is %s", dnsAliasesAnnotation, operatorv1.LoadBalancerServiceStrategyType)
+	}
+	suffix := "." + strings.ToLower(ic.Status.Domain)
+	for _, name := range dnsAliases(ic) {
+		if len(validation.IsDNS1123Subdomain(name)) != 0 || !strings.HasSuffix(name, suffix) {
+			return fmt.Errorf("invalid value for annotation %s: %q is not a subdomain of the ingresscontroller's domain %s", dnsAliasesAnnotation, name, ic.Status.Domain)
+		}
+	}
+	return nil
+}
+
+// validateRouterThreads verifies that the router threads annotation, if set,
+// is "auto" or an integer between 1 and the maximum number of threads that
+// HAProxy supports, and that the router CPU limit annotation, if set, is a
+// quantity no less than the router container's CPU request.
+func validateRouterThreads(ic *operatorv1.IngressController) error {
+	if v, ok := ic.Annotations[routerThreadsAnnotation]; ok && v != "auto" {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 || n > maxRouterThreads {
+			return fmt.Errorf("invalid value for annotation %s: %q; must be auto or an integer between 1 and %d", routerThreadsAnnotation, v, maxRouterThreads)
+		}
+	}
+	v, ok := ic.Annotations[routerCPULimitAnnotation]
+	if !ok {
+		return nil
+	}
+	request := manifests.RouterDeployment().Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+	if limit, err := resource.ParseQuantity(v); err != nil || limit.Cmp(request) < 0 {
+		return fmt.Errorf("invalid value for annotation %s: %q; must be a CPU quantity of at least %s", routerCPULimitAnnotation, v, request.String())
+	}
+	return nil
+}
+
+// isHTTPToken returns a Boolean indicating whether the given string is a
+// token as defined by RFC 7230, section 3.2.6, which HTTP header names must
+// be.
+func isHTTPToken(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validateHealthCheckNodePortConflict verifies that no other ingresscontroller
+// requests the same load balancer health check node port as the given one.
+func validateHealthCheckNodePortConflict(ic *operatorv1.IngressController, ingresses []operatorv1.IngressController) error {
+	port, ok := ic.Annotations[loadBalancerHealthCheckNodePortAnnotation]
+	if !ok {
+		return nil
+	}
+	for i := range ingresses {
+		other := &ingresses[i]
+		if other.Name == ic.Name || other.DeletionTimestamp != nil {
+			continue
+		}
+		if other.Annotations[loadBalancerHealthCheckNodePortAnnotation] == port && isAdmitted(other) {
+			return fmt.Errorf("health check node port %s is already requested by ingresscontroller %s", port, other.Name)
+		}
+	}
+	return nil
+}
+
+// validateHostNetworkPortConflict verifies that no other ingresscontroller
+// that uses the HostNetwork endpoint publishing strategy may schedule router
+// pods on the same nodes as the given one, in which case the routers would
+// compete for the same host ports.  Node sets may overlap unless the node
+// selectors require different values for the same label.  If both
+// ingresscontrollers have been admitted, the older one keeps its ports so
+// that only one of them is rejected.
+func validateHostNetworkPortConflict(ic *operatorv1.IngressController, ingresses []operatorv1.IngressController) error {
+	if !usesHostNetwork(ic) {
+		return nil
+	}
+	nodeSelector, err := routerNodeSelector(ic)
+	if err != nil {
+		return nil
+	}
+	for i := range ingresses {
+		other := &ingresses[i]
+		if other.Name == ic.Name || other.DeletionTimestamp != nil || !usesHostNetwork(other) || !isAdmitted(other) {
+			continue
+		}
+		if isAdmitted(ic) && !createdBefore(other, ic) {
+			continue
+		}
+		otherNodeSelector, err := routerNodeSelector(other)
+		if err != nil || !nodeSelectorsOverlap(nodeSelector, otherNodeSelector) {
+			continue
+		}
+		ports := []string{}
+		for _, port := range manifests.RouterDeployment().Spec.Template.Spec.Containers[0].Ports {
+			ports = append(ports, fmt.Sprintf("%d/%s", port.ContainerPort, port.Protocol))
+		}
+		return fmt.Errorf("ingresscontroller %s already uses host ports %s on nodes matching node selector %q, which may also match node selector %q", other.Name, strings.Join(ports, ", "), labels.SelectorFromSet(otherNodeSelector).String(), labels.SelectorFromSet(nodeSelector).String())
+	}
+	return nil
+}
+
+// nodeSelectorsOverlap returns a Boolean indicating whether a node could match
+// both of the given node selectors.
+func nodeSelectorsOverlap(a, b map[string]string) bool {
+	for k, v := range a {
+		if w, ok := b[k]; ok && w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// createdBefore returns a Boolean indicating whether ingresscontroller a was
+// created before ingresscontroller b, using the name to break ties.
+func createdBefore(a, b *operatorv1.IngressController) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// validateLoadBalancerLimit verifies that admitting the given ingresscontroller
+// would not exceed the given maximum number of admitted ingresscontrollers that
+// use the LoadBalancerService endpoint publishing strategy.  An
+// ingresscontroller that has already been admitted remains admitted so that
+// lowering the limit does not disrupt existing load balancers.
+func validateLoadBalancerLimit(ic *operatorv1.IngressController, ingresses []operatorv1.IngressController, limit int) error {
+	if !usesLoadBalancer(ic) || isAdmitted(ic) {
+		return nil
+	}
+	count := 0
+	for i := range ingresses {
+		other := &ingresses[i]
+		if other.Name == ic.Name || other.DeletionTimestamp != nil {
+			continue
+		}
+		if usesLoadBalancer(other) && isAdmitted(other) {
+			count++
+		}
+	}
+	if count >= limit {
+		return fmt.Errorf("the number of ingresscontrollers using the %s endpoint publishing strategy is limited to %d, and %d are already admitted", operatorv1.LoadBalancerServiceStrategyType, limit, count)
+	}
+	return nil
+}
+
+// validateEndpointPublishingStrategyForPlatform verifies that the given
+// ingresscontroller's effective endpoint publishing strategy is supported on
+// the given platform.  Platforms without a cloud load balancer integration
+// never assign an address to a LoadBalancer-type service, so a
+// LoadBalancerService ingresscontroller on such a platform would never be
+// reachable.  An ingresscontroller that has already been admitted remains
+// admitted so that load balancers that a cluster administrator has provided
+// by other means are not disrupted.  Unknown platforms are not restricted.
+func validateEndpointPublishingStrategyForPlatform(ic *operatorv1.IngressController, platform configv1.PlatformType) error {
+	if !usesLoadBalancer(ic) || isAdmitted(ic) {
+		return nil
+	}
+	switch platform {
+	case configv1.BareMetalPlatformType, configv1.LibvirtPlatformType, configv1.NonePlatformType, configv1.VSpherePlatformType:
+		return fmt.Errorf("the %s endpoint publishing strategy is not supported on platform %s, which has no load balancer integration; use the %s or %s strategy instead", operatorv1.LoadBalancerServiceStrategyType, platform, operatorv1.HostNetworkStrategyType, operatorv1.PrivateStrategyType)
+	}
+	return nil
+}
+
+// usesLoadBalancer returns a Boolean indicating whether the given
+// ingresscontroller uses the LoadBalancerService endpoint publishing strategy.
+func usesLoadBalancer(ic *operatorv1.IngressController) bool {
+	return ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.LoadBalancerServiceStrategyType
+}
+
+// usesHostNetwork returns a Boolean indicating whether the given
+// ingresscontroller uses the HostNetwork endpoint publishing strategy.
+func usesHostNetwork(ic *operatorv1.IngressController) bool {
+	return ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.HostNetworkStrategyType
+}
+
+// isAdmitted returns a Boolean indicating whether the given ingresscontroller
+// has been admitted.
+func isAdmitted(ic *operatorv1.IngressController) bool {
+	condition := getIngressCondition(ic.Status.Conditions, IngressControllerAdmittedConditionType)
+	return condition != nil && condition.Status == operatorv1.ConditionTrue
+}
+
+// validateRouterAutoscaling verifies that the autoscaling annotation, if set,
+// is "true" or "false" and, if autoscaling is enabled, that the autoscaling
+// parameters are valid.
+func validateRouterAutoscaling(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[routerAutoscalingAnnotation]
+	if !ok {
+		return nil
+	}
+	if v != "true" && v != "false" {
+		return fmt.Errorf("invalid value for annotation %s: %q; allowed values are true and false", routerAutoscalingAnnotation, v)
+	}
+	if v == "true" {
+		if _, _, _, err := routerAutoscalingParameters(ic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
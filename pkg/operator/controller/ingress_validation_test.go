@@ -0,0 +1,922 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateIngressController(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		expectValid bool
+	}{
+		{
+			description: "no annotations",
+			expectValid: true,
+		},
+		{
+			description: "default insecure edge termination policy Allow",
+			annotations: map[string]string{defaultInsecureEdgeTerminationPolicyAnnotation: "Allow"},
+			expectValid: true,
+		},
+		{
+			description: "default insecure edge termination policy Redirect",
+			annotations: map[string]string{defaultInsecureEdgeTerminationPolicyAnnotation: "Redirect"},
+			expectValid: true,
+		},
+		{
+			description: "default insecure edge termination policy Disable",
+			annotations: map[string]string{defaultInsecureEdgeTerminationPolicyAnnotation: "Disable"},
+			expectValid: true,
+		},
+		{
+			description: "invalid default insecure edge termination policy",
+			annotations: map[string]string{defaultInsecureEdgeTerminationPolicyAnnotation: "redirect"},
+			expectValid: false,
+		},
+		{
+			description: "empty default insecure edge termination policy",
+			annotations: map[string]string{defaultInsecureEdgeTerminationPolicyAnnotation: ""},
+			expectValid: false,
+		},
+		{
+			description: "internal service ClientIP session affinity",
+			annotations: map[string]string{internalServiceSessionAffinityAnnotation: "ClientIP"},
+			expectValid: true,
+		},
+		{
+			description: "internal service ClientIP session affinity with timeout",
+			annotations: map[string]string{
+				internalServiceSessionAffinityAnnotation:        "ClientIP",
+				internalServiceSessionAffinityTimeoutAnnotation: "3600",
+			},
+			expectValid: true,
+		},
+		{
+			description: "invalid internal service session affinity",
+			annotations: map[string]string{internalServiceSessionAffinityAnnotation: "Cookie"},
+			expectValid: false,
+		},
+		{
+			description: "internal service session affinity timeout without ClientIP",
+			annotations: map[string]string{internalServiceSessionAffinityTimeoutAnnotation: "3600"},
+			expectValid: false,
+		},
+		{
+			description: "internal service session affinity timeout out of range",
+			annotations: map[string]string{
+				internalServiceSessionAffinityAnnotation:        "ClientIP",
+				internalServiceSessionAffinityTimeoutAnnotation: "86401",
+			},
+			expectValid: false,
+		},
+		{
+			description: "load balancer health check node port",
+			annotations: map[string]string{loadBalancerHealthCheckNodePortAnnotation: "32000"},
+			expectValid: true,
+		},
+		{
+			description: "load balancer health check node port below the NodePort range",
+			annotations: map[string]string{loadBalancerHealthCheckNodePortAnnotation: "10256"},
+			expectValid: false,
+		},
+		{
+			description: "load balancer health check node port above the NodePort range",
+			annotations: map[string]string{loadBalancerHealthCheckNodePortAnnotation: "32768"},
+			expectValid: false,
+		},
+		{
+			description: "non-numeric load balancer health check node port",
+			annotations: map[string]string{loadBalancerHealthCheckNodePortAnnotation: "http"},
+			expectValid: false,
+		},
+		{
+			description: "load balancer IP",
+			annotations: map[string]string{loadBalancerIPAnnotation: "203.0.113.10"},
+			expectValid: true,
+		},
+		{
+			description: "invalid load balancer IP",
+			annotations: map[string]string{loadBalancerIPAnnotation: "203.0.113.0/24"},
+			expectValid: false,
+		},
+		{
+			description: "unique ID header name",
+			annotations: map[string]string{uniqueIDHeaderNameAnnotation: "X-Request-Id"},
+			expectValid: true,
+		},
+		{
+			description: "unique ID header name and format",
+			annotations: map[string]string{
+				uniqueIDHeaderNameAnnotation: "X-Request-Id",
+				uniqueIDFormatAnnotation:     "%{+X}o %ci:%cp_%fi:%fp_%Ts_%rt:%pid",
+			},
+			expectValid: true,
+		},
+		{
+			description: "invalid unique ID header name",
+			annotations: map[string]string{uniqueIDHeaderNameAnnotation: "X Request Id"},
+			expectValid: false,
+		},
+		{
+			description: "empty unique ID header name",
+			annotations: map[string]string{uniqueIDHeaderNameAnnotation: ""},
+			expectValid: false,
+		},
+		{
+			description: "default route settings",
+			annotations: map[string]string{defaultRouteSettingsAnnotation: "haproxy.router.openshift.io/balance=leastconn"},
+			expectValid: true,
+		},
+		{
+			description: "default route settings with an unknown route annotation",
+			annotations: map[string]string{defaultRouteSettingsAnnotation: "haproxy.router.openshift.io/ip_whitelist=10.0.0.0/8"},
+			expectValid: false,
+		},
+		{
+			description: "internal service topology-aware hints enabled",
+			annotations: map[string]string{internalServiceTopologyAwareHintsAnnotation: "true"},
+			expectValid: true,
+		},
+		{
+			description: "invalid internal service topology-aware hints value",
+			annotations: map[string]string{internalServiceTopologyAwareHintsAnnotation: "Auto"},
+			expectValid: false,
+		},
+		{
+			description: "DNS management paused",
+			annotations: map[string]string{dnsManagementPausedAnnotation: "true"},
+			expectValid: true,
+		},
+		{
+			description: "invalid DNS management paused value",
+			annotations: map[string]string{dnsManagementPausedAnnotation: "external"},
+			expectValid: false,
+		},
+		{
+			description: "router security context",
+			annotations: map[string]string{routerSecurityContextAnnotation: "drop-capabilities"},
+			expectValid: true,
+		},
+		{
+			description: "unknown router security context setting",
+			annotations: map[string]string{routerSecurityContextAnnotation: "privileged"},
+			expectValid: false,
+		},
+		{
+			description: "backend CA bundle configmap",
+			annotations: map[string]string{backendCABundleConfigMapAnnotation: "backend-ca"},
+			expectValid: true,
+		},
+		{
+			description: "invalid backend CA bundle configmap name",
+			annotations: map[string]string{backendCABundleConfigMapAnnotation: "Backend_CA"},
+			expectValid: false,
+		},
+		{
+			description: "session cookie name",
+			annotations: map[string]string{sessionCookieNameAnnotation: "APP_SESSION"},
+			expectValid: true,
+		},
+		{
+			description: "session cookie name with a separator",
+			annotations: map[string]string{sessionCookieNameAnnotation: "app;session"},
+			expectValid: false,
+		},
+		{
+			description: "empty session cookie name",
+			annotations: map[string]string{sessionCookieNameAnnotation: ""},
+			expectValid: false,
+		},
+		{
+			description: "unique ID format without a header name",
+			annotations: map[string]string{uniqueIDFormatAnnotation: "%ci"},
+			expectValid: false,
+		},
+		{
+			description: "unique ID format with a line break",
+			annotations: map[string]string{
+				uniqueIDHeaderNameAnnotation: "X-Request-Id",
+				uniqueIDFormatAnnotation:     "%ci\nhttp-request deny",
+			},
+			expectValid: false,
+		},
+		{
+			description: "max connections",
+			annotations: map[string]string{maxConnectionsAnnotation: "50000"},
+			expectValid: true,
+		},
+		{
+			description: "zero max connections",
+			annotations: map[string]string{maxConnectionsAnnotation: "0"},
+			expectValid: false,
+		},
+		{
+			description: "non-canonical max connections",
+			annotations: map[string]string{maxConnectionsAnnotation: "+50000"},
+			expectValid: false,
+		},
+		{
+			description: "header buffer sizes",
+			annotations: map[string]string{
+				headerBufferSizeAnnotation:           "64Ki",
+				headerBufferMaxRewriteSizeAnnotation: "16384",
+			},
+			expectValid: true,
+		},
+		{
+			description: "fractional header buffer size",
+			annotations: map[string]string{headerBufferSizeAnnotation: "1.5"},
+			expectValid: false,
+		},
+		{
+			description: "header buffer max rewrite size exceeding the default buffer size",
+			annotations: map[string]string{headerBufferMaxRewriteSizeAnnotation: "32Ki"},
+			expectValid: false,
+		},
+		{
+			description: "dump route config",
+			annotations: map[string]string{dumpRouteConfigAnnotation: "shop/frontend"},
+			expectValid: true,
+		},
+		{
+			description: "dump route config without a namespace",
+			annotations: map[string]string{dumpRouteConfigAnnotation: "frontend"},
+			expectValid: false,
+		},
+		{
+			description: "dump route config with an invalid namespace",
+			annotations: map[string]string{dumpRouteConfigAnnotation: "Shop/frontend"},
+			expectValid: false,
+		},
+		{
+			description: "load balancer IP without a load balancer",
+			annotations: map[string]string{loadBalancerIPAnnotation: "203.0.113.10"},
+			strategy:    operatorv1.HostNetworkStrategyType,
+			expectValid: false,
+		},
+		{
+			description: "load balancer health check node port without a load balancer",
+			annotations: map[string]string{loadBalancerHealthCheckNodePortAnnotation: "32000"},
+			strategy:    operatorv1.HostNetworkStrategyType,
+			expectValid: false,
+		},
+		{
+			description: "metrics integration disabled",
+			annotations: map[string]string{disableMetricsIntegrationAnnotation: "true"},
+			expectValid: true,
+		},
+		{
+			description: "metrics integration explicitly enabled",
+			annotations: map[string]string{disableMetricsIntegrationAnnotation: "false"},
+			expectValid: true,
+		},
+		{
+			description: "invalid disable metrics integration value",
+			annotations: map[string]string{disableMetricsIntegrationAnnotation: "yes"},
+			expectValid: false,
+		},
+		{
+			description: "compression MIME types",
+			annotations: map[string]string{compressionMIMETypesAnnotation: "text/html, application/vnd.api+json"},
+			expectValid: true,
+		},
+		{
+			description: "no compression MIME types",
+			annotations: map[string]string{compressionMIMETypesAnnotation: ""},
+			expectValid: true,
+		},
+		{
+			description: "compression MIME type with parameters",
+			annotations: map[string]string{compressionMIMETypesAnnotation: "text/html; charset=utf-8"},
+			expectValid: false,
+		},
+		{
+			description: "compression MIME type without a subtype",
+			annotations: map[string]string{compressionMIMETypesAnnotation: "text"},
+			expectValid: false,
+		},
+		{
+			description: "autoscaling with parameters",
+			annotations: map[string]string{routerAutoscalingAnnotation: "true", routerAutoscalingMinReplicasAnnotation: "2", routerAutoscalingMaxReplicasAnnotation: "8", routerAutoscalingTargetCPUAnnotation: "70"},
+			expectValid: true,
+		},
+		{
+			description: "autoscaling disabled without parameters",
+			annotations: map[string]string{routerAutoscalingAnnotation: "false"},
+			expectValid: true,
+		},
+		{
+			description: "invalid autoscaling value",
+			annotations: map[string]string{routerAutoscalingAnnotation: "yes"},
+			expectValid: false,
+		},
+		{
+			description: "autoscaling without maximum replicas",
+			annotations: map[string]string{routerAutoscalingAnnotation: "true"},
+			expectValid: false,
+		},
+		{
+			description: "autoscaling maximum below minimum",
+			annotations: map[string]string{routerAutoscalingAnnotation: "true", routerAutoscalingMinReplicasAnnotation: "4", routerAutoscalingMaxReplicasAnnotation: "3"},
+			expectValid: false,
+		},
+		{
+			description: "invalid autoscaling target CPU utilization",
+			annotations: map[string]string{routerAutoscalingAnnotation: "true", routerAutoscalingMaxReplicasAnnotation: "3", routerAutoscalingTargetCPUAnnotation: "0"},
+			expectValid: false,
+		},
+		{
+			description: "router service account",
+			annotations: map[string]string{routerServiceAccountAnnotation: "custom-router"},
+			expectValid: true,
+		},
+		{
+			description: "invalid router service account name",
+			annotations: map[string]string{routerServiceAccountAnnotation: "Custom_Router"},
+			expectValid: false,
+		},
+		{
+			description: "safe and unsafe namespaced sysctls",
+			annotations: map[string]string{routerSysctlsAnnotation: "net.core.somaxconn=4096, net.ipv4.ip_local_port_range=1024 65000"},
+			expectValid: true,
+		},
+		{
+			description: "node-level sysctl",
+			annotations: map[string]string{routerSysctlsAnnotation: "vm.max_map_count=262144"},
+			expectValid: false,
+		},
+		{
+			description: "sysctl without a value",
+			annotations: map[string]string{routerSysctlsAnnotation: "net.core.somaxconn"},
+			expectValid: false,
+		},
+		{
+			description: "duplicate sysctl",
+			annotations: map[string]string{routerSysctlsAnnotation: "net.core.somaxconn=4096,net.core.somaxconn=8192"},
+			expectValid: false,
+		},
+		{
+			description: "maintenance mode with a custom page",
+			annotations: map[string]string{maintenanceModeAnnotation: "true", maintenancePageConfigMapAnnotation: "maintenance"},
+			expectValid: true,
+		},
+		{
+			description: "invalid maintenance mode value",
+			annotations: map[string]string{maintenanceModeAnnotation: "on"},
+			expectValid: false,
+		},
+		{
+			description: "invalid maintenance page configmap name",
+			annotations: map[string]string{maintenancePageConfigMapAnnotation: "Maintenance_Page"},
+			expectValid: false,
+		},
+		{
+			description: "reconcile summary recorded",
+			annotations: map[string]string{recordReconcileSummaryAnnotation: "true"},
+			expectValid: true,
+		},
+		{
+			description: "invalid record reconcile summary value",
+			annotations: map[string]string{recordReconcileSummaryAnnotation: "Enabled"},
+			expectValid: false,
+		},
+		{
+			description: "tunnel timeout",
+			annotations: map[string]string{tunnelTimeoutAnnotation: "2h"},
+			expectValid: true,
+		},
+		{
+			description: "tunnel timeout without a unit",
+			annotations: map[string]string{tunnelTimeoutAnnotation: "90"},
+			expectValid: false,
+		},
+		{
+			description: "sub-millisecond tunnel timeout",
+			annotations: map[string]string{tunnelTimeoutAnnotation: "500us"},
+			expectValid: false,
+		},
+		{
+			description: "tunnel timeout longer than HAProxy supports",
+			annotations: map[string]string{tunnelTimeoutAnnotation: "600h"},
+			expectValid: false,
+		},
+		{
+			description: "router threads",
+			annotations: map[string]string{routerThreadsAnnotation: "8"},
+			expectValid: true,
+		},
+		{
+			description: "automatic router threads without a CPU limit",
+			annotations: map[string]string{routerThreadsAnnotation: "auto"},
+			expectValid: true,
+		},
+		{
+			description: "zero router threads",
+			annotations: map[string]string{routerThreadsAnnotation: "0"},
+			expectValid: false,
+		},
+		{
+			description: "more router threads than HAProxy supports",
+			annotations: map[string]string{routerThreadsAnnotation: "65"},
+			expectValid: false,
+		},
+		{
+			description: "router CPU limit",
+			annotations: map[string]string{routerThreadsAnnotation: "auto", routerCPULimitAnnotation: "2500m"},
+			expectValid: true,
+		},
+		{
+			description: "router CPU limit below the CPU request",
+			annotations: map[string]string{routerCPULimitAnnotation: "50m"},
+			expectValid: false,
+		},
+		{
+			description: "malformed router CPU limit",
+			annotations: map[string]string{routerCPULimitAnnotation: "two"},
+			expectValid: false,
+		},
+		{
+			description: "router DNS nameservers",
+			annotations: map[string]string{routerDNSPolicyAnnotation: "None", routerDNSNameserversAnnotation: "192.0.2.53"},
+			expectValid: true,
+		},
+		{
+			description: "router DNS policy None without nameservers",
+			annotations: map[string]string{routerDNSPolicyAnnotation: "None"},
+			expectValid: false,
+		},
+		{
+			description: "router stats exposed",
+			annotations: map[string]string{exposeRouterStatsAnnotation: "true"},
+			expectValid: true,
+		},
+		{
+			description: "router stats exposed without metrics integration",
+			annotations: map[string]string{exposeRouterStatsAnnotation: "true", disableMetricsIntegrationAnnotation: "true"},
+			expectValid: false,
+		},
+		{
+			description: "weighted balancing",
+			annotations: map[string]string{weightedBalancingAnnotation: "true", defaultRouteSettingsAnnotation: "haproxy.router.openshift.io/balance=roundrobin"},
+			expectValid: true,
+		},
+		{
+			description: "invalid weighted balancing",
+			annotations: map[string]string{weightedBalancingAnnotation: "on"},
+			expectValid: false,
+		},
+		{
+			description: "weighted balancing with a conflicting default balance algorithm",
+			annotations: map[string]string{weightedBalancingAnnotation: "true", defaultRouteSettingsAnnotation: "haproxy.router.openshift.io/balance=leastconn"},
+			expectValid: false,
+		},
+		{
+			description: "router template configmap",
+			annotations: map[string]string{routerTemplateConfigMapAnnotation: "router-template"},
+			expectValid: true,
+		},
+		{
+			description: "invalid router template configmap name",
+			annotations: map[string]string{routerTemplateConfigMapAnnotation: "Router_Template"},
+			expectValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		strategy := tc.strategy
+		if len(strategy) == 0 {
+			strategy = operatorv1.LoadBalancerServiceStrategyType
+		}
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: tc.annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: strategy,
+				},
+			},
+		}
+		err := validateIngressController(ic)
+		if tc.expectValid && err != nil {
+			t.Errorf("%q: expected valid, got error: %v", tc.description, err)
+		}
+		if !tc.expectValid && err == nil {
+			t.Errorf("%q: expected an error, got nil", tc.description)
+		}
+	}
+}
+
+func TestValidateLoadBalancerLimit(t *testing.T) {
+	ingressController := func(name string, strategy operatorv1.EndpointPublishingStrategyType, admitted operatorv1.ConditionStatus, deleted bool) operatorv1.IngressController {
+		ic := operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: strategy},
+			},
+		}
+		if len(admitted) != 0 {
+			ic.Status.Conditions = []operatorv1.OperatorCondition{{
+				Type:   IngressControllerAdmittedConditionType,
+				Status: admitted,
+			}}
+		}
+		if deleted {
+			now := metav1.Now()
+			ic.DeletionTimestamp = &now
+		}
+		return ic
+	}
+	lb := operatorv1.LoadBalancerServiceStrategyType
+	private := operatorv1.PrivateStrategyType
+	testCases := []struct {
+		description   string
+		ic            operatorv1.IngressController
+		others        []operatorv1.IngressController
+		limit         int
+		expectMessage string
+	}{
+		{
+			description: "under the limit",
+			ic:          ingressController("new", lb, "", false),
+			others:      []operatorv1.IngressController{ingressController("a", lb, operatorv1.ConditionTrue, false)},
+			limit:       2,
+		},
+		{
+			description:   "at the limit",
+			ic:            ingressController("new", lb, "", false),
+			others:        []operatorv1.IngressController{ingressController("a", lb, operatorv1.ConditionTrue, false), ingressController("b", lb, operatorv1.ConditionTrue, false)},
+			limit:         2,
+			expectMessage: "the number of ingresscontrollers using the LoadBalancerService endpoint publishing strategy is limited to 2, and 2 are already admitted",
+		},
+		{
+			description: "other strategies, unadmitted, and deleted ingresscontrollers are not counted",
+			ic:          ingressController("new", lb, "", false),
+			others: []operatorv1.IngressController{
+				ingressController("new", lb, operatorv1.ConditionTrue, false),
+				ingressController("a", private, operatorv1.ConditionTrue, false),
+				ingressController("b", lb, operatorv1.ConditionFalse, false),
+				ingressController("c", lb, operatorv1.ConditionTrue, true),
+			},
+			limit: 1,
+		},
+		{
+			description: "an already admitted ingresscontroller stays admitted",
+			ic:          ingressController("old", lb, operatorv1.ConditionTrue, false),
+			others:      []operatorv1.IngressController{ingressController("a", lb, operatorv1.ConditionTrue, false)},
+			limit:       1,
+		},
+		{
+			description: "ingresscontrollers without a load balancer are not limited",
+			ic:          ingressController("new", private, "", false),
+			others:      []operatorv1.IngressController{ingressController("a", lb, operatorv1.ConditionTrue, false)},
+			limit:       1,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateLoadBalancerLimit(&tc.ic, tc.others, tc.limit)
+		switch {
+		case len(tc.expectMessage) == 0 && err != nil:
+			t.Errorf("%q: expected no error, got %v", tc.description, err)
+		case len(tc.expectMessage) != 0 && err == nil:
+			t.Errorf("%q: expected error %q, got nil", tc.description, tc.expectMessage)
+		case len(tc.expectMessage) != 0 && err.Error() != tc.expectMessage:
+			t.Errorf("%q: expected error %q, got %q", tc.description, tc.expectMessage, err.Error())
+		}
+	}
+}
+
+// TestValidateEndpointPublishingStrategyForPlatform verifies that the
+// LoadBalancerService strategy is rejected on platforms without a load
+// balancer integration unless the ingresscontroller was already admitted, and
+// that the HostNetwork and Private strategies are allowed on every platform.
+func TestValidateEndpointPublishingStrategyForPlatform(t *testing.T) {
+	platforms := []configv1.PlatformType{
+		configv1.AWSPlatformType,
+		configv1.AzurePlatformType,
+		configv1.BareMetalPlatformType,
+		configv1.GCPPlatformType,
+		configv1.LibvirtPlatformType,
+		configv1.OpenStackPlatformType,
+		configv1.NonePlatformType,
+		configv1.VSpherePlatformType,
+		"",
+	}
+	unsupported := map[configv1.PlatformType]bool{
+		configv1.BareMetalPlatformType: true,
+		configv1.LibvirtPlatformType:   true,
+		configv1.NonePlatformType:      true,
+		configv1.VSpherePlatformType:   true,
+	}
+	strategies := []operatorv1.EndpointPublishingStrategyType{
+		operatorv1.LoadBalancerServiceStrategyType,
+		operatorv1.HostNetworkStrategyType,
+		operatorv1.PrivateStrategyType,
+	}
+	for _, platform := range platforms {
+		for _, strategy := range strategies {
+			for _, admitted := range []bool{false, true} {
+				ic := &operatorv1.IngressController{
+					Status: operatorv1.IngressControllerStatus{
+						EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: strategy},
+					},
+				}
+				if admitted {
+					ic.Status.Conditions = []operatorv1.OperatorCondition{{
+						Type:   IngressControllerAdmittedConditionType,
+						Status: operatorv1.ConditionTrue,
+					}}
+				}
+				expect := ""
+				if strategy == operatorv1.LoadBalancerServiceStrategyType && unsupported[platform] && !admitted {
+					expect = fmt.Sprintf("the LoadBalancerService endpoint publishing strategy is not supported on platform %s, which has no load balancer integration; use the HostNetwork or Private strategy instead", platform)
+				}
+				err := validateEndpointPublishingStrategyForPlatform(ic, platform)
+				switch {
+				case len(expect) == 0 && err != nil:
+					t.Errorf("platform %q, strategy %s, admitted %t: expected no error, got %v", platform, strategy, admitted, err)
+				case len(expect) != 0 && (err == nil || err.Error() != expect):
+					t.Errorf("platform %q, strategy %s, admitted %t: expected error %q, got %v", platform, strategy, admitted, expect, err)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateHealthCheckNodePortConflict(t *testing.T) {
+	ingressController := func(name, port string, admitted bool) operatorv1.IngressController {
+		ic := operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		}
+		if len(port) != 0 {
+			ic.Annotations = map[string]string{loadBalancerHealthCheckNodePortAnnotation: port}
+		}
+		if admitted {
+			ic.Status.Conditions = []operatorv1.OperatorCondition{{
+				Type:   IngressControllerAdmittedConditionType,
+				Status: operatorv1.ConditionTrue,
+			}}
+		}
+		return ic
+	}
+	testCases := []struct {
+		description   string
+		ic            operatorv1.IngressController
+		others        []operatorv1.IngressController
+		expectMessage string
+	}{
+		{
+			description: "no port requested",
+			ic:          ingressController("new", "", false),
+			others:      []operatorv1.IngressController{ingressController("a", "32000", true)},
+		},
+		{
+			description: "different ports",
+			ic:          ingressController("new", "32001", false),
+			others:      []operatorv1.IngressController{ingressController("a", "32000", true)},
+		},
+		{
+			description:   "same port as an admitted ingresscontroller",
+			ic:            ingressController("new", "32000", false),
+			others:        []operatorv1.IngressController{ingressController("a", "32000", true)},
+			expectMessage: "health check node port 32000 is already requested by ingresscontroller a",
+		},
+		{
+			description: "same port as an unadmitted ingresscontroller or itself",
+			ic:          ingressController("new", "32000", false),
+			others:      []operatorv1.IngressController{ingressController("a", "32000", false), ingressController("new", "32000", true)},
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateHealthCheckNodePortConflict(&tc.ic, tc.others)
+		switch {
+		case len(tc.expectMessage) == 0 && err != nil:
+			t.Errorf("%q: expected no error, got %v", tc.description, err)
+		case len(tc.expectMessage) != 0 && err == nil:
+			t.Errorf("%q: expected error %q, got nil", tc.description, tc.expectMessage)
+		case len(tc.expectMessage) != 0 && err.Error() != tc.expectMessage:
+			t.Errorf("%q: expected error %q, got %q", tc.description, tc.expectMessage, err.Error())
+		}
+	}
+}
+
+func TestValidateHostNetworkPortConflict(t *testing.T) {
+	created := time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)
+	ingressController := func(name string, strategy operatorv1.EndpointPublishingStrategyType, nodeSelector map[string]string, minutes int, admitted bool) operatorv1.IngressController {
+		ic := operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created.Add(time.Duration(minutes) * time.Minute)),
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: strategy},
+			},
+		}
+		if nodeSelector != nil {
+			ic.Spec.NodePlacement = &operatorv1.NodePlacement{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: nodeSelector},
+			}
+		}
+		if admitted {
+			ic.Status.Conditions = []operatorv1.OperatorCondition{{
+				Type:   IngressControllerAdmittedConditionType,
+				Status: operatorv1.ConditionTrue,
+			}}
+		}
+		return ic
+	}
+	hostNetwork := operatorv1.HostNetworkStrategyType
+	edge := map[string]string{"node-role.kubernetes.io/edge": ""}
+	zoneA := map[string]string{"node-role.kubernetes.io/edge": "", "zone": "a"}
+	zoneB := map[string]string{"node-role.kubernetes.io/edge": "", "zone": "b"}
+	testCases := []struct {
+		description   string
+		ic            operatorv1.IngressController
+		others        []operatorv1.IngressController
+		expectMessage string
+	}{
+		{
+			description:   "default node selectors",
+			ic:            ingressController("new", hostNetwork, nil, 1, false),
+			others:        []operatorv1.IngressController{ingressController("a", hostNetwork, nil, 0, true)},
+			expectMessage: `ingresscontroller a already uses host ports 80/TCP, 443/TCP, 1936/TCP on nodes matching node selector "beta.kubernetes.io/os=linux,node-role.kubernetes.io/worker=", which may also match node selector "beta.kubernetes.io/os=linux,node-role.kubernetes.io/worker="`,
+		},
+		{
+			description:   "node selectors that may match the same nodes",
+			ic:            ingressController("new", hostNetwork, zoneA, 1, false),
+			others:        []operatorv1.IngressController{ingressController("a", hostNetwork, edge, 0, true)},
+			expectMessage: `ingresscontroller a already uses host ports 80/TCP, 443/TCP, 1936/TCP on nodes matching node selector "node-role.kubernetes.io/edge=", which may also match node selector "node-role.kubernetes.io/edge=,zone=a"`,
+		},
+		{
+			description: "disjoint node selectors",
+			ic:          ingressController("new", hostNetwork, zoneA, 1, false),
+			others:      []operatorv1.IngressController{ingressController("a", hostNetwork, zoneB, 0, true)},
+		},
+		{
+			description: "other ingresscontroller not admitted",
+			ic:          ingressController("new", hostNetwork, nil, 1, false),
+			others:      []operatorv1.IngressController{ingressController("a", hostNetwork, nil, 0, false)},
+		},
+		{
+			description: "other ingresscontroller not using host network",
+			ic:          ingressController("new", hostNetwork, nil, 1, false),
+			others:      []operatorv1.IngressController{ingressController("a", operatorv1.PrivateStrategyType, nil, 0, true)},
+		},
+		{
+			description: "ingresscontroller not using host network",
+			ic:          ingressController("new", operatorv1.PrivateStrategyType, nil, 1, false),
+			others:      []operatorv1.IngressController{ingressController("a", hostNetwork, nil, 0, true)},
+		},
+		{
+			description: "both admitted, the older one keeps its ports",
+			ic:          ingressController("old", hostNetwork, nil, 0, true),
+			others:      []operatorv1.IngressController{ingressController("a", hostNetwork, nil, 1, true)},
+		},
+		{
+			description:   "both admitted, the newer one is rejected",
+			ic:            ingressController("new", hostNetwork, nil, 1, true),
+			others:        []operatorv1.IngressController{ingressController("a", hostNetwork, nil, 0, true)},
+			expectMessage: `ingresscontroller a already uses host ports 80/TCP, 443/TCP, 1936/TCP on nodes matching node selector "beta.kubernetes.io/os=linux,node-role.kubernetes.io/worker=", which may also match node selector "beta.kubernetes.io/os=linux,node-role.kubernetes.io/worker="`,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateHostNetworkPortConflict(&tc.ic, tc.others)
+		switch {
+		case len(tc.expectMessage) == 0 && err != nil:
+			t.Errorf("%q: expected no error, got %v", tc.description, err)
+		case len(tc.expectMessage) != 0 && err == nil:
+			t.Errorf("%q: expected error %q, got nil", tc.description, tc.expectMessage)
+		case len(tc.expectMessage) != 0 && err.Error() != tc.expectMessage:
+			t.Errorf("%q: expected error %q, got %q", tc.description, tc.expectMessage, err.Error())
+		}
+	}
+}
+
+func TestValidateDNSAliases(t *testing.T) {
+	testCases := []struct {
+		description string
+		aliases     string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		expectError string
+	}{
+		{
+			description: "subdomains of the domain",
+			aliases:     "console.apps.example.com,a.b.apps.example.com",
+		},
+		{
+			description: "mixed case",
+			aliases:     "Console.Apps.Example.com",
+		},
+		{
+			description: "the domain itself",
+			aliases:     "apps.example.com",
+			expectError: `"apps.example.com" is not a subdomain of the ingresscontroller's domain apps.example.com`,
+		},
+		{
+			description: "another domain",
+			aliases:     "console.example.org",
+			expectError: `"console.example.org" is not a subdomain of the ingresscontroller's domain apps.example.com`,
+		},
+		{
+			description: "suffix without a dot",
+			aliases:     "consoleapps.example.com",
+			expectError: `"consoleapps.example.com" is not a subdomain`,
+		},
+		{
+			description: "wildcard",
+			aliases:     "*.foo.apps.example.com",
+			expectError: `"*.foo.apps.example.com" is not a subdomain`,
+		},
+		{
+			description: "without a load balancer",
+			aliases:     "console.apps.example.com",
+			strategy:    operatorv1.HostNetworkStrategyType,
+			expectError: "may only be set when the endpoint publishing strategy is LoadBalancerService",
+		},
+	}
+	for _, tc := range testCases {
+		strategy := tc.strategy
+		if len(strategy) == 0 {
+			strategy = operatorv1.LoadBalancerServiceStrategyType
+		}
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: map[string]string{dnsAliasesAnnotation: tc.aliases},
+			},
+			Status: operatorv1.IngressControllerStatus{
+				Domain:                     "apps.example.com",
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: strategy},
+			},
+		}
+		err := validateDNSAliases(ic)
+		switch {
+		case len(tc.expectError) == 0 && err != nil:
+			t.Errorf("%q: expected valid, got error: %v", tc.description, err)
+		case len(tc.expectError) != 0 && err == nil:
+			t.Errorf("%q: expected an error, got nil", tc.description)
+		case len(tc.expectError) != 0 && !strings.Contains(err.Error(), tc.expectError):
+			t.Errorf("%q: expected error to contain %q, got %q", tc.description, tc.expectError, err.Error())
+		}
+	}
+}
+
+func TestValidateSessionCookieNameMessage(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{sessionCookieNameAnnotation: "app session"},
+		},
+	}
+	expect := `invalid value for annotation ingress.operator.openshift.io/session-cookie-name: "app session"; must be a valid cookie name`
+	if err := validateIngressController(ic); err == nil || err.Error() != expect {
+		t.Errorf("expected error %q, got %v", expect, err)
+	}
+}
+
+// TestValidateDNSCleanupTimeout verifies that the DNS cleanup timeout must be
+// a non-negative duration.
+func TestValidateDNSCleanupTimeout(t *testing.T) {
+	testCases := []struct {
+		value  string
+		expect string
+	}{
+		{value: "0s"},
+		{value: "10m"},
+		{
+			value:  "-1s",
+			expect: `invalid value for annotation ingress.operator.openshift.io/dns-cleanup-timeout: "-1s"; must be a non-negative duration, such as 0s or 5m`,
+		},
+		{
+			value:  "5",
+			expect: `invalid value for annotation ingress.operator.openshift.io/dns-cleanup-timeout: "5"; must be a non-negative duration, such as 0s or 5m`,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{dnsCleanupTimeoutAnnotation: tc.value},
+			},
+		}
+		err := validateIngressController(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%s: expected no error, got %v", tc.value, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%s: expected error %q, got %v", tc.value, tc.expect, err)
+		}
+	}
+}
@@ -10,6 +10,14 @@ import (
 )
 
 const (
+	// DefaultOperandNamespace is the default namespace in which the
+	// operator manages the router deployments and related resources.
+	DefaultOperandNamespace = "openshift-ingress"
+
+	// DefaultMonitoringNamespace is the default namespace of the monitoring
+	// stack that scrapes router metrics.
+	DefaultMonitoringNamespace = "openshift-monitoring"
+
 	// GlobalMachineSpecifiedConfigNamespace is the location for global
 	// config.  In particular, the operator will put the configmap with the
 	// CA certificate in this namespace.
@@ -36,9 +44,9 @@ const (
 )
 
 // RouterDeploymentName returns the namespaced name for the router deployment.
-func RouterDeploymentName(ci *operatorv1.IngressController) types.NamespacedName {
+func RouterDeploymentName(ci *operatorv1.IngressController, namespace string) types.NamespacedName {
 	return types.NamespacedName{
-		Namespace: "openshift-ingress",
+		Namespace: namespace,
 		Name:      "router-" + ci.Name,
 	}
 }
@@ -98,18 +106,60 @@ func IngressControllerDeploymentPodSelector(ic *operatorv1.IngressController) *m
 	}
 }
 
-func InternalIngressControllerServiceName(ic *operatorv1.IngressController) types.NamespacedName {
-	// TODO: remove hard-coded namespace
-	return types.NamespacedName{Namespace: "openshift-ingress", Name: "router-internal-" + ic.Name}
+func InternalIngressControllerServiceName(ic *operatorv1.IngressController, namespace string) types.NamespacedName {
+	return types.NamespacedName{Namespace: namespace, Name: "router-internal-" + ic.Name}
+}
+
+func IngressControllerServiceMonitorName(ic *operatorv1.IngressController, namespace string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: namespace,
+		Name:      "router-" + ic.Name,
+	}
 }
 
-func IngressControllerServiceMonitorName(ic *operatorv1.IngressController) types.NamespacedName {
+// IngressControllerPrometheusRuleName returns the namespaced name for the
+// prometheusrule with the alerts for the given ingresscontroller's router.
+func IngressControllerPrometheusRuleName(ic *operatorv1.IngressController, namespace string) types.NamespacedName {
 	return types.NamespacedName{
-		Namespace: "openshift-ingress",
+		Namespace: namespace,
 		Name:      "router-" + ic.Name,
 	}
 }
 
-func LoadBalancerServiceName(ic *operatorv1.IngressController) types.NamespacedName {
-	return types.NamespacedName{Namespace: "openshift-ingress", Name: "router-" + ic.Name}
+// MetricsCABundleConfigMapName returns the namespaced name for the configmap
+// into which the service CA bundle for verifying the router's metrics
+// certificate is injected.
+func MetricsCABundleConfigMapName(ic *operatorv1.IngressController, namespace string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: namespace,
+		Name:      "router-metrics-ca-" + ic.Name,
+	}
+}
+
+// RouteConfigDumpConfigMapName returns the namespaced name for the configmap to
+// which the operator writes the route dump that an ingresscontroller requests.
+func RouteConfigDumpConfigMapName(ic *operatorv1.IngressController, namespace string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: namespace,
+		Name:      "router-route-dump-" + ic.Name,
+	}
+}
+
+func LoadBalancerServiceName(ic *operatorv1.IngressController, namespace string) types.NamespacedName {
+	return types.NamespacedName{Namespace: namespace, Name: "router-" + ic.Name}
+}
+
+// RouterHorizontalPodAutoscalerName returns the namespaced name for the
+// horizontalpodautoscaler that scales the router deployment.
+func RouterHorizontalPodAutoscalerName(ic *operatorv1.IngressController, namespace string) types.NamespacedName {
+	return RouterDeploymentName(ic, namespace)
+}
+
+// RouterStatsRouteName returns the namespaced name for the route that exposes
+// the given ingresscontroller's router stats page.
+func RouterStatsRouteName(ic *operatorv1.IngressController, namespace string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: namespace,
+		Name:      "router-stats-" + ic.Name,
+	}
 }
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"golang.org/x/time/rate"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// DefaultOperandEventQPS is the default maximum sustained rate at which
+	// events for an ingresscontroller's operands trigger reconciliation of
+	// that ingresscontroller.
+	DefaultOperandEventQPS = 1.0
+
+	// DefaultOperandEventBurst is the default number of operand events
+	// for an ingresscontroller that trigger reconciliation immediately
+	// before rate limiting applies.
+	DefaultOperandEventBurst = 5
+)
+
+// operandEventsThrottled counts the operand events whose reconciliation was
+// delayed by rate limiting, by ingresscontroller.
+var operandEventsThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "ingress_controller_operand_events_throttled_total",
+	Help: "Number of operand events for which reconciliation of the owning ingresscontroller was delayed by rate limiting.",
+}, []string{"name"})
+
+func init() {
+	metrics.Registry.MustRegister(operandEventsThrottled)
+}
+
+// rateLimitedEnqueueRequests is an event handler that maps events to requests
+// using the given mapper and rate limits the requests separately for each
+// ingresscontroller.  A request that exceeds the limit is added to the queue
+// after a delay instead of immediately; because the queue coalesces identical
+// requests, a storm of events for one ingresscontroller results in at most
+// one reconciliation per interval for that ingresscontroller and does not
+// delay the reconciliation of other ingresscontrollers.
+type rateLimitedEnqueueRequests struct {
+	mapper handler.Mapper
+
+	*requestRateLimiters
+}
+
+// requestRateLimiters holds a rate limiter for each request.  Handlers that
+// share it share their rate limits.
+type requestRateLimiters struct {
+	limit rate.Limit
+	burst int
+
+	lock     sync.Mutex
+	limiters map[reconcile.Request]*rate.Limiter
+}
+
+var _ handler.EventHandler = &rateLimitedEnqueueRequests{}
+
+// newRateLimitedEnqueueRequests returns an event handler that rate limits the
+// requests that the given mapper returns to qps per second per request with
+// the given burst.  If qps is zero, requests are not rate limited.
+func newRateLimitedEnqueueRequests(mapper handler.Mapper, qps float64, burst int) *rateLimitedEnqueueRequests {
+	limit := rate.Limit(qps)
+	if qps == 0 {
+		limit = rate.Inf
+	}
+	return &rateLimitedEnqueueRequests{
+		mapper: mapper,
+		requestRateLimiters: &requestRateLimiters{
+			limit:    limit,
+			burst:    burst,
+			limiters: map[reconcile.Request]*rate.Limiter{},
+		},
+	}
+}
+
+// withMapper returns an event handler that maps events to requests using the
+// given mapper and shares the rate limits of e, so that events from either
+// handler count against the same limit for each request.
+func (e *rateLimitedEnqueueRequests) withMapper(mapper handler.Mapper) *rateLimitedEnqueueRequests {
+	return &rateLimitedEnqueueRequests{
+		mapper:              mapper,
+		requestRateLimiters: e.requestRateLimiters,
+	}
+}
+
+// Create implements handler.EventHandler.
+func (e *rateLimitedEnqueueRequests) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(q, handler.MapObject{Meta: evt.Meta, Object: evt.Object})
+}
+
+// Update implements handler.EventHandler.
+func (e *rateLimitedEnqueueRequests) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(q, handler.MapObject{Meta: evt.MetaOld, Object: evt.ObjectOld}, handler.MapObject{Meta: evt.MetaNew, Object: evt.ObjectNew})
+}
+
+// Delete implements handler.EventHandler.
+func (e *rateLimitedEnqueueRequests) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(q, handler.MapObject{Meta: evt.Meta, Object: evt.Object})
+}
+
+// Generic implements handler.EventHandler.
+func (e *rateLimitedEnqueueRequests) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(q, handler.MapObject{Meta: evt.Meta, Object: evt.Object})
+}
+
+// enqueue maps the given objects to requests and adds each distinct request to
+// the queue, immediately if the request is within its rate limit and after a
+// delay otherwise.
+func (e *rateLimitedEnqueueRequests) enqueue(q workqueue.RateLimitingInterface, objects ...handler.MapObject) {
+	requests := map[reconcile.Request]struct{}{}
+	for _, o := range objects {
+		for _, req := range e.mapper.Map(o) {
+			requests[req] = struct{}{}
+		}
+	}
+	for req := range requests {
+		if e.limiter(req).Allow() {
+			q.Add(req)
+			continue
+		}
+		operandEventsThrottled.WithLabelValues(req.Name).Inc()
+		q.AddAfter(req, time.Duration(float64(time.Second)/float64(e.limit)))
+	}
+}
+
+// limiter returns the rate limiter for the given request.
+func (e *requestRateLimiters) limiter(req reconcile.Request) *rate.Limiter {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	limiter, ok := e.limiters[req]
+	if !ok {
+		limiter = rate.NewLimiter(e.limit, e.burst)
+		e.limiters[req] = limiter
+	}
+	return limiter
+}
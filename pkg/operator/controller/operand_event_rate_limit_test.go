@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	dto "github.com/prometheus/client_model/go"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeQueue records the requests that are added to it.
+type fakeQueue struct {
+	workqueue.RateLimitingInterface
+	added   []reconcile.Request
+	delayed map[reconcile.Request][]time.Duration
+}
+
+func (q *fakeQueue) Add(item interface{}) {
+	q.added = append(q.added, item.(reconcile.Request))
+}
+
+func (q *fakeQueue) AddAfter(item interface{}, duration time.Duration) {
+	req := item.(reconcile.Request)
+	q.delayed[req] = append(q.delayed[req], duration)
+}
+
+func throttledOperandEvents(t *testing.T, name string) float64 {
+	m := &dto.Metric{}
+	if err := operandEventsThrottled.WithLabelValues(name).Write(m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestOperandEventRateLimit(t *testing.T) {
+	operand := func(ingressName string) event.CreateEvent {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "router-" + ingressName,
+				Labels: map[string]string{manifests.OwningIngressControllerLabel: ingressName},
+			},
+		}
+		return event.CreateEvent{Meta: service, Object: service}
+	}
+	request := func(ingressName string) reconcile.Request {
+		var req reconcile.Request
+		req.Namespace, req.Name = "openshift-ingress-operator", ingressName
+		return req
+	}
+
+	q := &fakeQueue{delayed: map[reconcile.Request][]time.Duration{}}
+	h := enqueueRequestForOwningIngressController("openshift-ingress-operator", 2, 3)
+	throttledBefore := throttledOperandEvents(t, "hot")
+
+	// A storm of events for one ingresscontroller is throttled after the
+	// burst.
+	for i := 0; i < 10; i++ {
+		h.Create(operand("hot"), q)
+	}
+	if len(q.added) != 3 {
+		t.Errorf("expected 3 immediate requests, got %d", len(q.added))
+	}
+	delays := q.delayed[request("hot")]
+	if len(delays) != 7 {
+		t.Errorf("expected 7 delayed requests, got %d", len(delays))
+	}
+	for _, d := range delays {
+		if d != 500*time.Millisecond {
+			t.Errorf("expected requests to be delayed by 500ms, got %v", d)
+		}
+	}
+	if throttled := throttledOperandEvents(t, "hot") - throttledBefore; throttled != 7 {
+		t.Errorf("expected 7 throttled events to be counted, got %v", throttled)
+	}
+
+	// Other ingresscontrollers are not affected.
+	h.Create(operand("quiet"), q)
+	if last := q.added[len(q.added)-1]; last != request("quiet") {
+		t.Errorf("expected an immediate request for the quiet ingresscontroller, got %v", last)
+	}
+	if len(q.delayed[request("quiet")]) != 0 {
+		t.Errorf("expected no delayed requests for the quiet ingresscontroller, got %v", q.delayed[request("quiet")])
+	}
+
+	// An update maps the old and new objects to the same request, which
+	// counts once against the limit.
+	update := operand("update")
+	h.Update(event.UpdateEvent{MetaOld: update.Meta, ObjectOld: update.Object, MetaNew: update.Meta, ObjectNew: update.Object}, q)
+	h.Update(event.UpdateEvent{MetaOld: update.Meta, ObjectOld: update.Object, MetaNew: update.Meta, ObjectNew: update.Object}, q)
+	h.Update(event.UpdateEvent{MetaOld: update.Meta, ObjectOld: update.Object, MetaNew: update.Meta, ObjectNew: update.Object}, q)
+	if len(q.delayed[request("update")]) != 0 {
+		t.Errorf("expected 3 updates to fit in the burst, got %d delayed requests", len(q.delayed[request("update")]))
+	}
+
+	// Objects without the owning ingresscontroller label are ignored.
+	added := len(q.added)
+	unowned := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "unowned"}}
+	h.Create(event.CreateEvent{Meta: unowned, Object: unowned}, q)
+	if len(q.added) != added {
+		t.Errorf("expected no request for an unowned object")
+	}
+
+	// A rate of zero disables rate limiting.
+	q = &fakeQueue{delayed: map[reconcile.Request][]time.Duration{}}
+	h = enqueueRequestForOwningIngressController("openshift-ingress-operator", 0, 1)
+	for i := 0; i < 10; i++ {
+		h.Create(operand("hot"), q)
+	}
+	if len(q.added) != 10 || len(q.delayed) != 0 {
+		t.Errorf("expected 10 immediate requests without rate limiting, got %d immediate and %v delayed", len(q.added), q.delayed)
+	}
+}
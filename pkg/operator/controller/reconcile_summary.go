@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// recordReconcileSummaryAnnotation, when set to "true" on an
+	// ingresscontroller, makes the operator record the outcome of each step
+	// of the last reconciliation in reconcileSummaryAnnotation, where
+	// "oc describe" shows it.  Allowed values are "true" and "false".  If
+	// unset, the summary is only logged.
+	recordReconcileSummaryAnnotation = "ingress.operator.openshift.io/record-reconcile-summary"
+
+	// reconcileSummaryAnnotation is set by the operator on an
+	// ingresscontroller that has recordReconcileSummaryAnnotation set to
+	// "true".  The value is a JSON list of the steps of the last
+	// reconciliation.
+	reconcileSummaryAnnotation = "ingress.operator.openshift.io/reconcile-summary"
+)
+
+// stepOutcome is the outcome of a reconcile step.
+type stepOutcome string
+
+const (
+	stepSucceeded stepOutcome = "Succeeded"
+	stepFailed    stepOutcome = "Failed"
+	stepSkipped   stepOutcome = "Skipped"
+)
+
+// stepResult describes the outcome of one step of reconciling an
+// ingresscontroller.
+type stepResult struct {
+	// Step is the name of the step, as used by stepLogger.
+	Step string `json:"step"`
+	// Resource identifies the resource that the step acts on, if any.
+	Resource string `json:"resource,omitempty"`
+	// Action is the action that the step attempted.
+	Action string `json:"action"`
+	// Outcome is the outcome of the step.
+	Outcome stepOutcome `json:"outcome"`
+	// Error is the error that the step failed with, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// reconcileSummary is the list of steps of one reconciliation of an
+// ingresscontroller, in the order in which they ran.
+type reconcileSummary []stepResult
+
+// record records the outcome of a step that ran and failed with err, if err
+// is not nil, and returns err.
+func (s *reconcileSummary) record(step, resource, action string, err error) error {
+	result := stepResult{Step: step, Resource: resource, Action: action, Outcome: stepSucceeded}
+	if err != nil {
+		result.Outcome = stepFailed
+		result.Error = err.Error()
+	}
+	*s = append(*s, result)
+	return err
+}
+
+// skip records a step that did not run.
+func (s *reconcileSummary) skip(step, resource, action string) {
+	*s = append(*s, stepResult{Step: step, Resource: resource, Action: action, Outcome: stepSkipped})
+}
+
+// String returns a compact summary of the form "step=outcome ..." for
+// logging.
+func (s reconcileSummary) String() string {
+	steps := make([]string, 0, len(s))
+	for _, result := range s {
+		steps = append(steps, result.Step+"="+string(result.Outcome))
+	}
+	return strings.Join(steps, " ")
+}
+
+// failedSteps returns the names of the steps that failed.
+func (s reconcileSummary) failedSteps() []string {
+	failed := []string{}
+	for _, result := range s {
+		if result.Outcome == stepFailed {
+			failed = append(failed, result.Step)
+		}
+	}
+	return failed
+}
+
+// recordReconcileSummary logs the given summary and, if the ingresscontroller
+// asks for it, records the summary in an annotation on the ingresscontroller.
+// If the ingresscontroller does not ask for the summary, any summary recorded
+// earlier is removed.
+func (r *reconciler) recordReconcileSummary(ic *operatorv1.IngressController, summary reconcileSummary) error {
+	if failed := summary.failedSteps(); len(failed) != 0 {
+		stepLogger(ic, "summary").Info("reconciled ingresscontroller with errors", "summary", summary.String(), "failed", failed)
+	} else {
+		stepLogger(ic, "summary").Info("reconciled ingresscontroller", "summary", summary.String())
+	}
+
+	_, recorded := ic.Annotations[reconcileSummaryAnnotation]
+	record := ic.Annotations[recordReconcileSummaryAnnotation] == "true"
+	if !record && !recorded {
+		return nil
+	}
+	// Earlier steps may have updated the ingresscontroller's status, so
+	// get the latest version before updating it.
+	name := types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}
+	if err := r.getIngressController(name, ic); err != nil {
+		return fmt.Errorf("failed to get ingresscontroller %s: %v", name, err)
+	}
+	if !record {
+		return r.removeIngressControllerAnnotation(ic, reconcileSummaryAnnotation)
+	}
+
+	// The summary has no timestamps so that reconciling an unchanged
+	// ingresscontroller does not update the annotation, which would
+	// trigger another reconciliation.
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reconcile summary: %v", err)
+	}
+	if ic.Annotations[reconcileSummaryAnnotation] == string(data) {
+		return nil
+	}
+	if err := r.updateIngressControllerMetadata(ic, func(meta *metav1.ObjectMeta) {
+		if meta.Annotations == nil {
+			meta.Annotations = map[string]string{}
+		}
+		meta.Annotations[reconcileSummaryAnnotation] = string(data)
+	}); err != nil {
+		return fmt.Errorf("failed to record reconcile summary on ingresscontroller %s/%s: %v", ic.Namespace, ic.Name, err)
+	}
+	if err := r.getIngressController(name, ic); err != nil {
+		return fmt.Errorf("failed to get ingresscontroller %s: %v", name, err)
+	}
+	return nil
+}
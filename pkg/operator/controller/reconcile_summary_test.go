@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReconcileSummary(t *testing.T) {
+	summary := reconcileSummary{}
+	if err := summary.record("deployment", "openshift-ingress/router-default", "ensure", nil); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	failure := fmt.Errorf("failed to get load balancer service")
+	if err := summary.record("load-balancer", "openshift-ingress/router-default", "ensure", failure); err != failure {
+		t.Errorf("expected the step's error to be returned, got %v", err)
+	}
+	summary.skip("dns", "", "ensure")
+
+	expect := reconcileSummary{
+		{Step: "deployment", Resource: "openshift-ingress/router-default", Action: "ensure", Outcome: stepSucceeded},
+		{Step: "load-balancer", Resource: "openshift-ingress/router-default", Action: "ensure", Outcome: stepFailed, Error: "failed to get load balancer service"},
+		{Step: "dns", Action: "ensure", Outcome: stepSkipped},
+	}
+	if !reflect.DeepEqual(summary, expect) {
+		t.Errorf("expected %#v, got %#v", expect, summary)
+	}
+	if s := summary.String(); s != "deployment=Succeeded load-balancer=Failed dns=Skipped" {
+		t.Errorf("unexpected summary string %q", s)
+	}
+	if failed := summary.failedSteps(); !reflect.DeepEqual(failed, []string{"load-balancer"}) {
+		t.Errorf("expected failed steps [load-balancer], got %v", failed)
+	}
+}
+
+// TestRecordReconcileSummary verifies that the summary is recorded in an
+// annotation only if the ingresscontroller asks for it, that recording an
+// unchanged summary does not update the ingresscontroller, and that the
+// annotation is removed once the ingresscontroller no longer asks for it.
+func TestRecordReconcileSummary(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+	}
+	client := newFakeClient(ic)
+	r := &reconciler{Config: Config{Namespace: "openshift-ingress-operator"}, client: client}
+	name := types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}
+	summary := reconcileSummary{}
+	summary.record("deployment", "openshift-ingress/router-default", "ensure", nil)
+	summary.record("status", "openshift-ingress-operator/default", "sync", fmt.Errorf("conflict"))
+
+	current := func() *operatorv1.IngressController {
+		stored := &operatorv1.IngressController{}
+		if err := client.Get(context.TODO(), name, stored); err != nil {
+			t.Fatal(err)
+		}
+		return stored
+	}
+
+	if err := r.recordReconcileSummary(ic, summary); err != nil {
+		t.Fatalf("failed to record reconcile summary: %v", err)
+	}
+	if v, ok := current().Annotations[reconcileSummaryAnnotation]; ok {
+		t.Errorf("expected no annotation %s, got %q", reconcileSummaryAnnotation, v)
+	}
+
+	ic.Annotations = map[string]string{recordReconcileSummaryAnnotation: "true"}
+	if err := client.Update(context.TODO(), ic); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.recordReconcileSummary(ic, summary); err != nil {
+		t.Fatalf("failed to record reconcile summary: %v", err)
+	}
+	recorded := reconcileSummary{}
+	if err := json.Unmarshal([]byte(current().Annotations[reconcileSummaryAnnotation]), &recorded); err != nil {
+		t.Fatalf("failed to unmarshal annotation %s: %v", reconcileSummaryAnnotation, err)
+	}
+	if !reflect.DeepEqual(recorded, summary) {
+		t.Errorf("expected recorded summary %#v, got %#v", summary, recorded)
+	}
+	if _, ok := ic.Annotations[reconcileSummaryAnnotation]; !ok {
+		t.Errorf("expected the ingresscontroller to be refreshed with annotation %s", reconcileSummaryAnnotation)
+	}
+
+	updates := client.calls["update"]
+	if err := r.recordReconcileSummary(ic, summary); err != nil {
+		t.Fatalf("failed to record reconcile summary: %v", err)
+	}
+	if client.calls["update"] != updates {
+		t.Errorf("expected no update for an unchanged summary, got %d", client.calls["update"]-updates)
+	}
+
+	ic.Annotations[recordReconcileSummaryAnnotation] = "false"
+	if err := client.Update(context.TODO(), ic); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.recordReconcileSummary(ic, summary); err != nil {
+		t.Fatalf("failed to record reconcile summary: %v", err)
+	}
+	if v, ok := current().Annotations[reconcileSummaryAnnotation]; ok {
+		t.Errorf("expected annotation %s to be removed, got %q", reconcileSummaryAnnotation, v)
+	}
+}
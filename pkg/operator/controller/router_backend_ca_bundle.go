@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// backendCABundleConfigMapAnnotation specifies the name of a configmap
+	// in the operand namespace with a bundle of PEM-encoded CA
+	// certificates under the key backendCABundleKey.  The router uses the
+	// bundle to verify the certificates of the backends of reencrypt routes
+	// that do not specify spec.tls.destinationCACertificate.  The bundle
+	// replaces the service CA, which the router uses for such routes by
+	// default, so it must also contain the service CA if any of those
+	// backends use service serving certificates.  Routes that specify a
+	// destination CA certificate are not affected, and neither are edge and
+	// passthrough routes.  Changes to the configmap roll out the router
+	// pods.  If unset, the service CA is used.
+	backendCABundleConfigMapAnnotation = "ingress.operator.openshift.io/backend-ca-bundle-configmap"
+
+	// backendCABundleKey is the key of the CA bundle in the backend CA
+	// bundle configmap.
+	backendCABundleKey = "ca-bundle.crt"
+
+	// backendCABundleHashAnnotation is set on the router pod template to a
+	// hash of the backend CA bundle so that changing the bundle rolls out
+	// the router pods.
+	backendCABundleHashAnnotation = "ingress.operator.openshift.io/backend-ca-bundle-hash"
+
+	// backendCABundleVolumeName is the name of the router volume with the
+	// backend CA bundle.
+	backendCABundleVolumeName = "backend-ca-bundle"
+
+	// backendCABundleMountPath is the directory in which the backend CA
+	// bundle is mounted in the router container.
+	backendCABundleMountPath = "/etc/pki/tls/backend-ca-bundle"
+
+	// backendCABundleInvalidReason is the reason of the Degraded condition
+	// when the backend CA bundle configmap is missing or has no usable CA
+	// certificates.
+	backendCABundleInvalidReason = "BackendCABundleInvalid"
+)
+
+// applyBackendCABundle mounts the backend CA bundle from the given configmap
+// in the given router deployment and makes the router verify backends with
+// it.  The volume is optional so that a missing configmap does not keep the
+// router pods from starting; the ingresscontroller is marked degraded instead.
+func applyBackendCABundle(deployment *appsv1.Deployment, configMapName string) {
+	podSpec := &deployment.Spec.Template.Spec
+	optional := true
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: backendCABundleVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+				Items:                []corev1.KeyToPath{{Key: backendCABundleKey, Path: backendCABundleKey}},
+				Optional:             &optional,
+			},
+		},
+	})
+	container := &podSpec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      backendCABundleVolumeName,
+		MountPath: backendCABundleMountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  "DEFAULT_DESTINATION_CA_PATH",
+		Value: backendCABundleMountPath + "/" + backendCABundleKey,
+	})
+}
+
+// backendCABundleConfigMap returns the given ingresscontroller's backend CA
+// bundle configmap, or nil if the ingresscontroller does not specify one or
+// the configmap does not exist.
+func (r *reconciler) backendCABundleConfigMap(ic *operatorv1.IngressController) (*corev1.ConfigMap, error) {
+	name, ok := ic.Annotations[backendCABundleConfigMapAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: r.OperandNamespace, Name: name}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get backend CA bundle configmap %s/%s: %v", r.OperandNamespace, name, err)
+	}
+	return cm, nil
+}
+
+// backendCABundleHash returns a hash of the given ingresscontroller's backend
+// CA bundle, or the empty string if the ingresscontroller does not specify a
+// bundle or the configmap does not exist, so that creating the configmap
+// changes it.
+func (r *reconciler) backendCABundleHash(ic *operatorv1.IngressController) (string, error) {
+	cm, err := r.backendCABundleConfigMap(ic)
+	if err != nil || cm == nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(cm.Data[backendCABundleKey]))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// backendCABundleProblem returns a description of what is wrong with the given
+// ingresscontroller's backend CA bundle, or the empty string if the
+// ingresscontroller does not specify a bundle or the bundle has at least one
+// CA certificate.
+func (r *reconciler) backendCABundleProblem(ic *operatorv1.IngressController) (string, error) {
+	name, ok := ic.Annotations[backendCABundleConfigMapAnnotation]
+	if !ok {
+		return "", nil
+	}
+	cm, err := r.backendCABundleConfigMap(ic)
+	if err != nil {
+		return "", err
+	}
+	if cm == nil {
+		return fmt.Sprintf("configmap %s/%s does not exist", r.OperandNamespace, name), nil
+	}
+	bundle, ok := cm.Data[backendCABundleKey]
+	if !ok {
+		return fmt.Sprintf("configmap %s/%s has no key %s", r.OperandNamespace, name, backendCABundleKey), nil
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM([]byte(bundle)) {
+		return fmt.Sprintf("key %s of configmap %s/%s has no PEM-encoded certificates", backendCABundleKey, r.OperandNamespace, name), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// newBackendCABundle returns a backend CA bundle configmap in the operand
+// namespace with a certificate for the given host.
+func newBackendCABundle(t *testing.T, name, host string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: name},
+		Data: map[string]string{
+			backendCABundleKey: string(newTLSSecret(t, name, host).Data[corev1.TLSCertKey]),
+		},
+	}
+}
+
+// TestDesiredRouterDeploymentBackendCABundle verifies that the backend CA
+// bundle is mounted from its configmap and passed to the router as the default
+// destination CA.
+func TestDesiredRouterDeploymentBackendCABundle(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{backendCABundleConfigMapAnnotation: "backend-ca"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	optional := true
+	expectVolume := corev1.Volume{
+		Name: "backend-ca-bundle",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "backend-ca"},
+				Items:                []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "ca-bundle.crt"}},
+				Optional:             &optional,
+			},
+		},
+	}
+	var volume corev1.Volume
+	for _, v := range deployment.Spec.Template.Spec.Volumes {
+		if v.Name == "backend-ca-bundle" {
+			volume = v
+		}
+	}
+	if !cmp.Equal(volume, expectVolume) {
+		t.Errorf("expected volume %#v, got %#v", expectVolume, volume)
+	}
+	mountPath := ""
+	for _, mount := range deployment.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if mount.Name == "backend-ca-bundle" {
+			mountPath = mount.MountPath
+		}
+	}
+	if mountPath != "/etc/pki/tls/backend-ca-bundle" {
+		t.Errorf("expected the backend CA bundle to be mounted at /etc/pki/tls/backend-ca-bundle, got %q", mountPath)
+	}
+	actualEnv := ""
+	for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+		if envVar.Name == "DEFAULT_DESTINATION_CA_PATH" {
+			actualEnv = envVar.Value
+		}
+	}
+	if expectEnv := "/etc/pki/tls/backend-ca-bundle/ca-bundle.crt"; actualEnv != expectEnv {
+		t.Errorf("expected DEFAULT_DESTINATION_CA_PATH to be %q, got %q", expectEnv, actualEnv)
+	}
+}
+
+// TestEnsureRouterDeploymentBackendCABundleRotation verifies that creating and
+// changing the backend CA bundle rolls out the router pods.
+func TestEnsureRouterDeploymentBackendCABundleRotation(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{backendCABundleConfigMapAnnotation: "backend-ca"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	client := newFakeClient()
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", IngressControllerImage: "quay.io/openshift/router:latest"},
+		client: client,
+	}
+	deployment, err := r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to create router deployment: %v", err)
+	}
+	if v, ok := deployment.Spec.Template.Annotations[backendCABundleHashAnnotation]; ok {
+		t.Errorf("expected no annotation %s while the configmap does not exist, got %q", backendCABundleHashAnnotation, v)
+	}
+
+	if err := client.Create(context.TODO(), newBackendCABundle(t, "backend-ca", "ca.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if deployment, err = r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to update router deployment: %v", err)
+	}
+	hash := deployment.Spec.Template.Annotations[backendCABundleHashAnnotation]
+	if len(hash) == 0 {
+		t.Fatalf("expected annotation %s once the configmap exists", backendCABundleHashAnnotation)
+	}
+
+	if err := client.replace(newBackendCABundle(t, "backend-ca", "ca.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if deployment, err = r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to update router deployment: %v", err)
+	}
+	if rotated := deployment.Spec.Template.Annotations[backendCABundleHashAnnotation]; rotated == hash {
+		t.Errorf("expected annotation %s to change after the bundle was rotated", backendCABundleHashAnnotation)
+	}
+}
+
+// TestBackendCABundleProblem verifies that a missing configmap, a missing key,
+// and a bundle without certificates are reported.
+func TestBackendCABundleProblem(t *testing.T) {
+	noKey := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "no-key"},
+		Data:       map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"},
+	}
+	noCerts := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "no-certs"},
+		Data:       map[string]string{backendCABundleKey: "not a certificate"},
+	}
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress"},
+		client: newFakeClient(newBackendCABundle(t, "backend-ca", "ca.example.com"), noKey, noCerts),
+	}
+	testCases := []struct {
+		configMap string
+		expect    string
+	}{
+		{"", ""},
+		{"backend-ca", ""},
+		{"missing", "configmap openshift-ingress/missing does not exist"},
+		{"no-key", "configmap openshift-ingress/no-key has no key ca-bundle.crt"},
+		{"no-certs", "key ca-bundle.crt of configmap openshift-ingress/no-certs has no PEM-encoded certificates"},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+		if len(tc.configMap) != 0 {
+			ic.Annotations = map[string]string{backendCABundleConfigMapAnnotation: tc.configMap}
+		}
+		problem, err := r.backendCABundleProblem(ic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if problem != tc.expect {
+			t.Errorf("%q: expected %q, got %q", tc.configMap, tc.expect, problem)
+		}
+	}
+}
+
+// TestConfigMapToIngressControllers verifies that changes to a configmap
+// trigger reconciliation of the ingresscontrollers that use it as their
+// maintenance page, backend CA bundle, or router template.
+func TestConfigMapToIngressControllers(t *testing.T) {
+	ingressController := func(name string, annotations map[string]string) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: name, Annotations: annotations},
+		}
+	}
+	client := newFakeClient(
+		ingressController("default", map[string]string{backendCABundleConfigMapAnnotation: "backend-ca"}),
+		ingressController("sharded", map[string]string{maintenancePageConfigMapAnnotation: "maintenance"}),
+		ingressController("internal", nil),
+		ingressController("custom", map[string]string{routerTemplateConfigMapAnnotation: "template"}),
+	)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	testCases := []struct {
+		configMap string
+		expect    []reconcile.Request
+	}{
+		{"backend-ca", []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "default"}}}},
+		{"maintenance", []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "sharded"}}}},
+		{"template", []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "custom"}}}},
+		{"other", []reconcile.Request{}},
+	}
+	for _, tc := range testCases {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: tc.configMap}}
+		if requests := r.configMapToIngressControllers(handler.MapObject{Meta: cm, Object: cm}); !cmp.Equal(requests, tc.expect) {
+			t.Errorf("configmap %s: expected requests %v, got %v", tc.configMap, tc.expect, requests)
+		}
+	}
+}
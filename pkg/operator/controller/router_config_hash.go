@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// referencedConfigHashAnnotation is set on the router pod template to a hash
+// of the data of every configmap and secret that the pod template references,
+// so that changing the content of any of them rolls out the router pods even
+// though the pod template itself does not change.  This covers, for example,
+// the maintenance page configmap and the stats secret, which the router only
+// reads when it starts.
+const referencedConfigHashAnnotation = "ingress.operator.openshift.io/referenced-config-hash"
+
+// podTemplateHashAnnotations are the annotations on the router pod template
+// with hashes of referenced content.  They are copied from the desired
+// deployment when it is updated, and removed if the desired deployment does
+// not have them.
+var podTemplateHashAnnotations = []string{
+	defaultCertificateHashAnnotation,
+	backendCABundleHashAnnotation,
+	referencedConfigHashAnnotation,
+}
+
+// podTemplateHashAnnotationsEqual returns a Boolean indicating whether the
+// given deployments have the same pod template hash annotations.
+func podTemplateHashAnnotationsEqual(a, b *appsv1.Deployment) bool {
+	for _, annotation := range podTemplateHashAnnotations {
+		if a.Spec.Template.Annotations[annotation] != b.Spec.Template.Annotations[annotation] {
+			return false
+		}
+	}
+	return true
+}
+
+// configReference identifies a configmap or secret that a pod template
+// references.
+type configReference struct {
+	// kind is "configmap" or "secret".
+	kind string
+	name string
+}
+
+// podTemplateConfigReferences returns the configmaps and secrets that the
+// given deployment's pod template references through volumes, environment
+// variables, or environment sources, sorted and without duplicates.
+func podTemplateConfigReferences(deployment *appsv1.Deployment) []configReference {
+	set := map[configReference]struct{}{}
+	podSpec := &deployment.Spec.Template.Spec
+	for _, volume := range podSpec.Volumes {
+		if volume.Secret != nil {
+			set[configReference{"secret", volume.Secret.SecretName}] = struct{}{}
+		}
+		if volume.ConfigMap != nil {
+			set[configReference{"configmap", volume.ConfigMap.Name}] = struct{}{}
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					set[configReference{"secret", source.Secret.Name}] = struct{}{}
+				}
+				if source.ConfigMap != nil {
+					set[configReference{"configmap", source.ConfigMap.Name}] = struct{}{}
+				}
+			}
+		}
+	}
+	for _, container := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				set[configReference{"secret", env.ValueFrom.SecretKeyRef.Name}] = struct{}{}
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				set[configReference{"configmap", env.ValueFrom.ConfigMapKeyRef.Name}] = struct{}{}
+			}
+		}
+		for _, source := range container.EnvFrom {
+			if source.SecretRef != nil {
+				set[configReference{"secret", source.SecretRef.Name}] = struct{}{}
+			}
+			if source.ConfigMapRef != nil {
+				set[configReference{"configmap", source.ConfigMapRef.Name}] = struct{}{}
+			}
+		}
+	}
+	refs := make([]configReference, 0, len(set))
+	for ref := range set {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].kind != refs[j].kind {
+			return refs[i].kind < refs[j].kind
+		}
+		return refs[i].name < refs[j].name
+	})
+	return refs
+}
+
+// podTemplateReferencesConfig returns a Boolean indicating whether the given
+// deployment's pod template references the configmap or secret with the given
+// kind and name.
+func podTemplateReferencesConfig(deployment *appsv1.Deployment, kind, name string) bool {
+	for _, ref := range podTemplateConfigReferences(deployment) {
+		if ref.kind == kind && ref.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedConfigHash returns a hash of the data of the configmaps and
+// secrets that the given deployment's pod template references, or the empty
+// string if it references none.  Missing configmaps and secrets contribute
+// only their names to the hash, so creating one changes it.
+func (r *reconciler) referencedConfigHash(deployment *appsv1.Deployment) (string, error) {
+	refs := podTemplateConfigReferences(deployment)
+	if len(refs) == 0 {
+		return "", nil
+	}
+	hash := sha256.New()
+	for _, ref := range refs {
+		name := types.NamespacedName{Namespace: deployment.Namespace, Name: ref.name}
+		var obj runtime.Object
+		switch ref.kind {
+		case "secret":
+			obj = &corev1.Secret{}
+		default:
+			obj = &corev1.ConfigMap{}
+		}
+		if err := r.client.Get(context.TODO(), name, obj); err != nil {
+			if errors.IsNotFound(err) {
+				fmt.Fprintf(hash, "%s\x00%s\x00\x00", ref.kind, ref.name)
+				continue
+			}
+			return "", fmt.Errorf("failed to get %s %s referenced by the router deployment: %v", ref.kind, name, err)
+		}
+		var dataHash string
+		switch o := obj.(type) {
+		case *corev1.Secret:
+			dataHash = secretDataHash(o)
+		case *corev1.ConfigMap:
+			dataHash = configMapDataHash(o)
+		}
+		fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", ref.kind, ref.name, dataHash)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// configMapDataHash returns a hex-encoded SHA-256 hash of the given
+// configmap's data and binary data.  The hash does not depend on the order of
+// the keys.
+func configMapDataHash(cm *corev1.ConfigMap) string {
+	data := make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+	for k, v := range cm.Data {
+		data[k] = []byte(v)
+	}
+	for k, v := range cm.BinaryData {
+		data[k] = v
+	}
+	return secretDataHash(&corev1.Secret{Data: data})
+}
+
+// routerDeploymentReferencesConfig returns a Boolean indicating whether the
+// given ingresscontroller's router deployment references the configmap or
+// secret with the given kind and name in the operand namespace.  It reads the
+// deployment from the cache and returns false if the deployment does not
+// exist.
+func (r *reconciler) routerDeploymentReferencesConfig(ic *operatorv1.IngressController, kind, name string) bool {
+	deployment := &appsv1.Deployment{}
+	if err := r.cache.Get(context.TODO(), RouterDeploymentName(ic, r.OperandNamespace), deployment); err != nil {
+		return false
+	}
+	return podTemplateReferencesConfig(deployment, kind, name)
+}
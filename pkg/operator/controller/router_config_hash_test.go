@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestEnsureRouterDeploymentReferencedConfigRollout verifies that changing the
+// content of a configmap that the router deployment references rolls out the
+// router pods, and that reconciling unchanged content does not update the
+// deployment.
+func TestEnsureRouterDeploymentReferencedConfigRollout(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+			Annotations: map[string]string{
+				maintenanceModeAnnotation:          "true",
+				maintenancePageConfigMapAnnotation: "maintenance",
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	page := func(body string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "maintenance"},
+			Data:       map[string]string{maintenancePageKey: "HTTP/1.0 503 Service Unavailable\r\n\r\n" + body},
+		}
+	}
+	client := newFakeClient(page("down for maintenance"))
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", IngressControllerImage: "quay.io/openshift/router:latest"},
+		client: client,
+	}
+	deployment, err := r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to create router deployment: %v", err)
+	}
+	hash := deployment.Spec.Template.Annotations[referencedConfigHashAnnotation]
+	if len(hash) == 0 {
+		t.Fatalf("expected annotation %s on the pod template", referencedConfigHashAnnotation)
+	}
+
+	if deployment, err = r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if client.calls["update"] != 0 {
+		t.Errorf("expected no update while the referenced content is unchanged, got %d", client.calls["update"])
+	}
+
+	if err := client.replace(page("back soon")); err != nil {
+		t.Fatal(err)
+	}
+	if deployment, err = r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to update router deployment: %v", err)
+	}
+	if client.calls["update"] != 1 {
+		t.Errorf("expected the deployment to be updated once after the page changed, got %d updates", client.calls["update"])
+	}
+	if rolled := deployment.Spec.Template.Annotations[referencedConfigHashAnnotation]; rolled == hash {
+		t.Errorf("expected annotation %s to change after the page changed", referencedConfigHashAnnotation)
+	}
+}
+
+// TestPodTemplateConfigReferences verifies that configmaps and secrets
+// referenced through volumes, environment variables, and environment sources
+// are found, sorted and without duplicates.
+func TestPodTemplateConfigReferences(t *testing.T) {
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec = corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "cert", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "router-certs-default"}}},
+			{Name: "page", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "maintenance"}}}},
+			{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		},
+		Containers: []corev1.Container{{
+			Name: "router",
+			Env: []corev1.EnvVar{
+				{Name: "STATS_USERNAME", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "router-stats-default"}, Key: "statsUsername"}}},
+				{Name: "STATS_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "router-stats-default"}, Key: "statsPassword"}}},
+				{Name: "ROUTER_THREADS", Value: "4"},
+			},
+			EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "extra-env"}}}},
+		}},
+	}
+	expect := []configReference{
+		{"configmap", "extra-env"},
+		{"configmap", "maintenance"},
+		{"secret", "router-certs-default"},
+		{"secret", "router-stats-default"},
+	}
+	if refs := podTemplateConfigReferences(deployment); !cmp.Equal(refs, expect, cmp.AllowUnexported(configReference{})) {
+		t.Errorf("expected references %v, got %v", expect, refs)
+	}
+}
+
+// TestSecretToIngressControllersReferencedByDeployment verifies that changes
+// to a secret that an ingresscontroller's router deployment references trigger
+// reconciliation of the ingresscontroller.
+func TestSecretToIngressControllersReferencedByDeployment(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"},
+		Spec: operatorv1.IngressControllerSpec{
+			DefaultCertificate: &corev1.LocalObjectReference{Name: "custom-cert"},
+		},
+	}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default"}}
+	deployment.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{Name: "metrics-certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "router-metrics-certs-default"}}},
+	}
+	client := newFakeClient(ic, deployment)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	testCases := []struct {
+		secret string
+		expect []reconcile.Request
+	}{
+		{"custom-cert", []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "default"}}}},
+		{"router-metrics-certs-default", []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "default"}}}},
+		{"other", []reconcile.Request{}},
+	}
+	for _, tc := range testCases {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: tc.secret}}
+		if requests := r.secretToIngressControllers(handler.MapObject{Meta: secret, Object: secret}); !cmp.Equal(requests, tc.expect) {
+			t.Errorf("secret %s: expected requests %v, got %v", tc.secret, tc.expect, requests)
+		}
+	}
+}
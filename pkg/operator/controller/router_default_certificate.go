@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// defaultCertificateInvalidReason is the reason of the Degraded
+	// condition when the default certificate secret that the
+	// ingresscontroller specifies is missing or does not have a valid
+	// certificate and key yet.
+	defaultCertificateInvalidReason = "DefaultCertificateInvalid"
+
+	// certManagerCertificateNameAnnotation is the annotation with which
+	// cert-manager marks the secrets that it issues certificates into with
+	// the name of the Certificate.
+	certManagerCertificateNameAnnotation = "cert-manager.io/certificate-name"
+)
+
+// defaultCertificateProblem returns a description of what is wrong with the
+// default certificate secret that the given ingresscontroller specifies, or
+// the empty string if the ingresscontroller uses the operator-generated
+// default certificate or specifies a secret with a valid certificate and key.
+// A secret that a certificate issuer such as cert-manager creates before it
+// issues the certificate is reported as not populated yet.
+func (r *reconciler) defaultCertificateProblem(ic *operatorv1.IngressController) (string, error) {
+	if ic.Spec.DefaultCertificate == nil {
+		return "", nil
+	}
+	name := RouterEffectiveDefaultCertificateSecretName(ic, r.OperandNamespace)
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), name, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Sprintf("secret %s does not exist", name), nil
+		}
+		return "", fmt.Errorf("failed to get default certificate secret %s: %v", name, err)
+	}
+	issuer := ""
+	if certificate, ok := secret.Annotations[certManagerCertificateNameAnnotation]; ok {
+		issuer = fmt.Sprintf(" by cert-manager certificate %s/%s", secret.Namespace, certificate)
+	}
+	if len(secret.Data[corev1.TLSCertKey]) == 0 || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return fmt.Sprintf("secret %s has not been populated%s yet: it has no %s or %s", name, issuer, corev1.TLSCertKey, corev1.TLSPrivateKeyKey), nil
+	}
+	if _, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		return fmt.Sprintf("secret %s does not contain a valid certificate and key: %v", name, err), nil
+	}
+	return "", nil
+}
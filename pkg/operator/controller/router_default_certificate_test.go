@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newTLSSecret returns a TLS secret in the operand namespace with a
+// self-signed certificate for the given host.
+func newTLSSecret(t *testing.T, name, host string) *corev1.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: name},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		},
+	}
+}
+
+// TestDefaultCertificateProblem verifies that a missing default certificate
+// secret, a secret that cert-manager has not populated yet, and a secret with
+// an invalid certificate are reported, and that the operator-generated default
+// certificate is not checked.
+func TestDefaultCertificateProblem(t *testing.T) {
+	pending := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "openshift-ingress",
+			Name:        "pending-cert",
+			Annotations: map[string]string{certManagerCertificateNameAnnotation: "apps"},
+		},
+		Data: map[string][]byte{"ca.crt": []byte("")},
+	}
+	empty := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "empty-cert"},
+	}
+	malformed := newTLSSecret(t, "malformed-cert", "*.apps.example.com")
+	malformed.Data[corev1.TLSPrivateKeyKey] = []byte("not a key")
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress"},
+		client: newFakeClient(newTLSSecret(t, "apps-cert", "*.apps.example.com"), pending, empty, malformed),
+	}
+	testCases := []struct {
+		description string
+		secret      string
+		expect      string
+	}{
+		{
+			description: "operator-generated default certificate",
+		},
+		{
+			description: "valid certificate",
+			secret:      "apps-cert",
+		},
+		{
+			description: "missing secret",
+			secret:      "missing-cert",
+			expect:      "secret openshift-ingress/missing-cert does not exist",
+		},
+		{
+			description: "secret not populated by cert-manager yet",
+			secret:      "pending-cert",
+			expect:      "secret openshift-ingress/pending-cert has not been populated by cert-manager certificate openshift-ingress/apps yet: it has no tls.crt or tls.key",
+		},
+		{
+			description: "empty secret",
+			secret:      "empty-cert",
+			expect:      "secret openshift-ingress/empty-cert has not been populated yet: it has no tls.crt or tls.key",
+		},
+		{
+			description: "malformed secret",
+			secret:      "malformed-cert",
+			expect:      "secret openshift-ingress/malformed-cert does not contain a valid certificate and key: tls: failed to find any PEM data in key input",
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+		if len(tc.secret) != 0 {
+			ic.Spec.DefaultCertificate = &corev1.LocalObjectReference{Name: tc.secret}
+		}
+		problem, err := r.defaultCertificateProblem(ic)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.description, err)
+		}
+		if problem != tc.expect {
+			t.Errorf("%s: expected %q, got %q", tc.description, tc.expect, problem)
+		}
+	}
+}
+
+// TestSyncIngressControllerStatusDefaultCertificateInvalid verifies that an
+// ingresscontroller whose default certificate secret has not been populated
+// yet is marked degraded.
+func TestSyncIngressControllerStatusDefaultCertificateInvalid(t *testing.T) {
+	deployment := manifests.RouterDeployment()
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "router"}}
+	ic := ingressController("default", operatorv1.PrivateStrategyType)
+	ic.Namespace = "openshift-ingress-operator"
+	ic.Spec.DefaultCertificate = &corev1.LocalObjectReference{Name: "apps-cert"}
+	pending := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "openshift-ingress",
+			Name:        "apps-cert",
+			Annotations: map[string]string{certManagerCertificateNameAnnotation: "apps"},
+		},
+	}
+	client := newFakeClient(ic, pending)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client: client,
+	}
+	if _, err := r.syncIngressControllerStatus(ic, deployment, nil, nil, nil, &configv1.DNS{}); err != nil {
+		t.Fatalf("failed to sync status: %v", err)
+	}
+	current := &operatorv1.IngressController{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+		t.Fatal(err)
+	}
+	expect := operatorv1.OperatorCondition{
+		Type:    operatorv1.OperatorStatusTypeDegraded,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "DefaultCertificateInvalid",
+		Message: "the default certificate from spec.defaultCertificate cannot be used: secret openshift-ingress/apps-cert has not been populated by cert-manager certificate openshift-ingress/apps yet: it has no tls.crt or tls.key; the router pods roll out when the secret changes",
+	}
+	condition := getIngressCondition(current.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+	if condition == nil {
+		t.Fatal("expected a Degraded condition")
+	}
+	if !cmp.Equal(*condition, expect, cmpopts.IgnoreFields(operatorv1.OperatorCondition{}, "LastTransitionTime")) {
+		t.Errorf("expected %#v, got %#v", expect, *condition)
+	}
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// defaultRouteSettingsAnnotation specifies settings that the router
+	// applies to every route that does not set them itself.  The value is
+	// a comma-separated list of entries of the form annotation=value, where
+	// annotation is one of the route annotations in defaultRouteSettings,
+	// for example
+	// "haproxy.router.openshift.io/balance=roundrobin,haproxy.router.openshift.io/timeout=30s".
+	// A route's own annotation takes precedence over the default.  If
+	// unset, the router's defaults are used.
+	defaultRouteSettingsAnnotation = "ingress.operator.openshift.io/default-route-settings"
+
+	// routeBalanceAnnotation is the route annotation that specifies the
+	// load-balancing algorithm for the route's endpoints.
+	routeBalanceAnnotation = "haproxy.router.openshift.io/balance"
+
+	// routeTimeoutAnnotation is the route annotation that specifies the
+	// server timeout of the route.
+	routeTimeoutAnnotation = "haproxy.router.openshift.io/timeout"
+)
+
+// defaultRouteSetting describes a route annotation that may be defaulted for
+// every route of an ingresscontroller.
+type defaultRouteSetting struct {
+	// validate returns an error if the given value is not allowed.
+	validate func(string) error
+	// env returns the router environment variables that make the given
+	// value the router's default.
+	env func(string) []corev1.EnvVar
+}
+
+// defaultRouteSettings maps the route annotations that may be defaulted to
+// how the router is configured to default them.
+var defaultRouteSettings = map[string]defaultRouteSetting{
+	routeBalanceAnnotation: {
+		validate: func(v string) error {
+			switch v {
+			case "roundrobin", "leastconn", "source":
+				return nil
+			}
+			return fmt.Errorf("allowed values are roundrobin, leastconn, and source")
+		},
+		// The router has separate defaults for HTTP and TLS passthrough
+		// routes, and the route annotation applies to both.
+		env: func(v string) []corev1.EnvVar {
+			return []corev1.EnvVar{
+				{Name: "ROUTER_LOAD_BALANCE_ALGORITHM", Value: v},
+				{Name: "ROUTER_TCP_BALANCE_SCHEME", Value: v},
+			}
+		},
+	},
+	routeTimeoutAnnotation: {
+		validate: func(v string) error {
+			if d, err := time.ParseDuration(v); err != nil || d < time.Millisecond || d > maxHAProxyTimeout {
+				return fmt.Errorf("must be a duration between 1ms and %s, such as 30s or 2m", maxHAProxyTimeout)
+			}
+			return nil
+		},
+		env: func(v string) []corev1.EnvVar {
+			d, _ := time.ParseDuration(v)
+			return []corev1.EnvVar{{Name: "ROUTER_DEFAULT_SERVER_TIMEOUT", Value: haproxyDuration(d)}}
+		},
+	},
+}
+
+// parseDefaultRouteSettings parses the value of the default route settings
+// annotation.
+func parseDefaultRouteSettings(v string) (map[string]string, error) {
+	settings := map[string]string{}
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not of the form annotation=value", entry)
+		}
+		name, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		setting, ok := defaultRouteSettings[name]
+		if !ok {
+			known := make([]string, 0, len(defaultRouteSettings))
+			for k := range defaultRouteSettings {
+				known = append(known, k)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("route annotation %s cannot be defaulted; allowed annotations are %s", name, strings.Join(known, ", "))
+		}
+		if _, ok := settings[name]; ok {
+			return nil, fmt.Errorf("route annotation %s is specified more than once", name)
+		}
+		if err := setting.validate(value); err != nil {
+			return nil, fmt.Errorf("invalid value %q for route annotation %s: %v", value, name, err)
+		}
+		settings[name] = value
+	}
+	return settings, nil
+}
+
+// defaultRouteSettingsEnv returns the router environment variables for the
+// given ingresscontroller's default route settings.  An annotation that cannot
+// be parsed yields no variables; validation rejects such values.
+func defaultRouteSettingsEnv(ic *operatorv1.IngressController) []corev1.EnvVar {
+	v, ok := ic.Annotations[defaultRouteSettingsAnnotation]
+	if !ok {
+		return nil
+	}
+	settings, err := parseDefaultRouteSettings(v)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	env := []corev1.EnvVar{}
+	for _, name := range names {
+		env = append(env, defaultRouteSettings[name].env(settings[name])...)
+	}
+	return env
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredRouterDeploymentDefaultRouteSettings verifies that the default
+// route settings annotation sets the corresponding router environment
+// variables, and that changing it updates the deployment.
+func TestDesiredRouterDeploymentDefaultRouteSettings(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	routeSettingsEnv := func(deployment *appsv1.Deployment) []corev1.EnvVar {
+		env := []corev1.EnvVar{}
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			switch envVar.Name {
+			case "ROUTER_LOAD_BALANCE_ALGORITHM", "ROUTER_TCP_BALANCE_SCHEME", "ROUTER_DEFAULT_SERVER_TIMEOUT":
+				env = append(env, envVar)
+			}
+		}
+		return env
+	}
+
+	withoutSettings, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if env := routeSettingsEnv(withoutSettings); len(env) != 0 {
+		t.Errorf("expected no default route settings without annotation %s, got %v", defaultRouteSettingsAnnotation, env)
+	}
+
+	ci.Annotations = map[string]string{defaultRouteSettingsAnnotation: "haproxy.router.openshift.io/timeout=2m, haproxy.router.openshift.io/balance=roundrobin"}
+	withSettings, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	expect := []corev1.EnvVar{
+		{Name: "ROUTER_LOAD_BALANCE_ALGORITHM", Value: "roundrobin"},
+		{Name: "ROUTER_TCP_BALANCE_SCHEME", Value: "roundrobin"},
+		{Name: "ROUTER_DEFAULT_SERVER_TIMEOUT", Value: "120000ms"},
+	}
+	if env := routeSettingsEnv(withSettings); !cmp.Equal(env, expect) {
+		t.Errorf("expected env %v, got %v", expect, env)
+	}
+	if changed, _ := deploymentConfigChanged(withoutSettings, withSettings); !changed {
+		t.Error("expected adding default route settings to change the deployment")
+	}
+}
+
+// TestParseDefaultRouteSettings verifies that unknown route annotations,
+// invalid values, and duplicates are rejected with a message that names the
+// problem.
+func TestParseDefaultRouteSettings(t *testing.T) {
+	testCases := []struct {
+		value  string
+		expect string
+	}{
+		{
+			value:  "haproxy.router.openshift.io/balance=random",
+			expect: `invalid value "random" for route annotation haproxy.router.openshift.io/balance: allowed values are roundrobin, leastconn, and source`,
+		},
+		{
+			value:  "haproxy.router.openshift.io/timeout=30",
+			expect: `invalid value "30" for route annotation haproxy.router.openshift.io/timeout: must be a duration between 1ms and 596h31m23.647s, such as 30s or 2m`,
+		},
+		{
+			value:  "haproxy.router.openshift.io/rate-limit-connections=true",
+			expect: "route annotation haproxy.router.openshift.io/rate-limit-connections cannot be defaulted; allowed annotations are haproxy.router.openshift.io/balance, haproxy.router.openshift.io/timeout",
+		},
+		{
+			value:  "haproxy.router.openshift.io/balance=source,haproxy.router.openshift.io/balance=leastconn",
+			expect: "route annotation haproxy.router.openshift.io/balance is specified more than once",
+		},
+		{
+			value:  "haproxy.router.openshift.io/balance",
+			expect: `"haproxy.router.openshift.io/balance" is not of the form annotation=value`,
+		},
+	}
+	for _, tc := range testCases {
+		if _, err := parseDefaultRouteSettings(tc.value); err == nil || err.Error() != tc.expect {
+			t.Errorf("%q: expected error %q, got %v", tc.value, tc.expect, err)
+		}
+	}
+}
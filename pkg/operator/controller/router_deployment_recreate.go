@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// recreateRouterDeploymentAnnotation, when present on an ingresscontroller,
+// allows the operator to delete and recreate the router deployment if the
+// deployment's pod selector, which is immutable, does not match the desired
+// selector.  Recreating the deployment causes a full rollout of the router
+// pods.  The old pods keep serving until the new deployment is available.  The
+// operator removes the annotation once the deployment has the desired selector
+// and is available and the old pods have been deleted.
+const recreateRouterDeploymentAnnotation = "ingress.operator.openshift.io/recreate-router-deployment"
+
+// routerDeploymentSelectorChanged returns a Boolean indicating whether the
+// current router deployment's pod selector differs from the desired one.
+func routerDeploymentSelectorChanged(current, desired *appsv1.Deployment) bool {
+	return !cmp.Equal(current.Spec.Selector, desired.Spec.Selector, cmpopts.EquateEmpty())
+}
+
+// deleteRouterDeploymentForRecreate deletes the given router deployment so
+// that it can be recreated with the desired pod selector.  The deployment's
+// dependents, including the load balancer service and the replicasets, are
+// orphaned rather than deleted so that the load balancer is kept and the old
+// router pods keep serving until the new deployment is available.
+func (r *reconciler) deleteRouterDeploymentForRecreate(ci *operatorv1.IngressController, current, desired *appsv1.Deployment) error {
+	stepLogger(ci, "deployment").Info("recreating router deployment to change its immutable pod selector; this rolls out all router pods", "namespace", current.Namespace, "name", current.Name, "currentSelector", current.Spec.Selector, "desiredSelector", desired.Spec.Selector)
+	if r.recorder != nil {
+		r.recorder.Eventf(ci, "Normal", "RecreatingRouterDeployment", "Recreating router deployment %s/%s to change its pod selector; all router pods will be replaced", current.Namespace, current.Name)
+	}
+	if current.DeletionTimestamp != nil {
+		return nil
+	}
+	if err := r.client.Delete(context.TODO(), current, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete router deployment %s/%s for recreation: %v", current.Namespace, current.Name, err)
+	}
+	return nil
+}
+
+// adoptRouterDeploymentDependents sets the owner reference of the objects that
+// the given router deployment owns but that were orphaned when a previous
+// deployment was deleted for recreation.  Objects that do not exist yet are
+// created with the owner reference later.
+func (r *reconciler) adoptRouterDeploymentDependents(ci *operatorv1.IngressController, deployment *appsv1.Deployment) error {
+	trueVar := true
+	deploymentRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       deployment.Name,
+		UID:        deployment.UID,
+		Controller: &trueVar,
+	}
+	statsSecret := manifests.RouterStatsSecret(ci, r.OperandNamespace)
+	serviceMonitor := &unstructured.Unstructured{}
+	serviceMonitor.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Kind:    "ServiceMonitor",
+		Version: "v1",
+	})
+	prometheusRule := &unstructured.Unstructured{}
+	prometheusRule.SetGroupVersionKind(prometheusRuleGVK)
+	dependents := []struct {
+		name types.NamespacedName
+		obj  interface {
+			metav1.Object
+			runtime.Object
+		}
+	}{
+		{LoadBalancerServiceName(ci, r.OperandNamespace), &corev1.Service{}},
+		{InternalIngressControllerServiceName(ci, r.OperandNamespace), &corev1.Service{}},
+		{types.NamespacedName{Namespace: statsSecret.Namespace, Name: statsSecret.Name}, &corev1.Secret{}},
+		{MetricsCABundleConfigMapName(ci, r.OperandNamespace), &corev1.ConfigMap{}},
+		{IngressControllerServiceMonitorName(ci, r.OperandNamespace), serviceMonitor},
+		{IngressControllerPrometheusRuleName(ci, r.OperandNamespace), prometheusRule},
+	}
+	errs := []error{}
+	for _, dependent := range dependents {
+		obj := dependent.obj
+		if err := r.client.Get(context.TODO(), dependent.name, obj); err != nil {
+			if !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+				errs = append(errs, fmt.Errorf("failed to get %s: %v", dependent.name, err))
+			}
+			continue
+		}
+		if metav1.GetControllerOf(obj) != nil {
+			continue
+		}
+		obj.SetOwnerReferences(append(obj.GetOwnerReferences(), deploymentRef))
+		if err := r.client.Update(context.TODO(), obj); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set owner reference on %s: %v", dependent.name, err))
+			continue
+		}
+		stepLogger(ci, "deployment").Info("adopted router deployment dependent", "namespace", dependent.name.Namespace, "name", dependent.name.Name)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// finishRouterDeploymentRecreate completes a requested recreation of the
+// router deployment.  Once the given deployment has the desired pod selector
+// and is available, the replicasets that were orphaned by the old deployment
+// are deleted and the recreation request annotation is removed.
+func (r *reconciler) finishRouterDeploymentRecreate(ci *operatorv1.IngressController, deployment, desired *appsv1.Deployment) error {
+	if routerDeploymentSelectorChanged(deployment, desired) || deployment.DeletionTimestamp != nil {
+		return nil
+	}
+	if deployment.Spec.Replicas == nil || deployment.Status.ObservedGeneration < deployment.Generation || deployment.Status.AvailableReplicas < *deployment.Spec.Replicas {
+		return nil
+	}
+	replicaSets := &appsv1.ReplicaSetList{}
+	if err := r.client.List(context.TODO(), replicaSets, client.InNamespace(deployment.Namespace), client.MatchingLabels(map[string]string{controllerDeploymentLabel: IngressControllerDeploymentLabel(ci)})); err != nil {
+		return fmt.Errorf("failed to list replicasets in namespace %q: %v", deployment.Namespace, err)
+	}
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if metav1.GetControllerOf(rs) != nil {
+			continue
+		}
+		if err := r.client.Delete(context.TODO(), rs, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete orphaned replicaset %s/%s: %v", rs.Namespace, rs.Name, err)
+		}
+		stepLogger(ci, "deployment").Info("deleted replicaset orphaned by the recreated router deployment", "namespace", rs.Namespace, "name", rs.Name)
+	}
+	return r.removeIngressControllerAnnotation(ci, recreateRouterDeploymentAnnotation)
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestEnsureRouterDeploymentRecreate verifies that a router deployment with a
+// stale pod selector is only updated in place unless recreation is requested,
+// that a requested recreation orphans the deployment's dependents, that the
+// recreated deployment adopts them, and that the orphaned replicasets and the
+// request annotation are removed once the new deployment is available.
+func TestEnsureRouterDeploymentRecreate(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	lbService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      LoadBalancerServiceName(ci, "openshift-ingress").Name,
+		},
+	}
+	orphanedReplicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      "router-default-old",
+			Labels:    map[string]string{controllerDeploymentLabel: IngressControllerDeploymentLabel(ci)},
+		},
+	}
+	serviceMonitor := desiredServiceMonitor(ci, "openshift-ingress", lbService, nil, metav1.OwnerReference{})
+	serviceMonitor.SetOwnerReferences(nil)
+	client := newFakeClient(ci, lbService, serviceMonitor)
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", IngressControllerImage: "quay.io/openshift/router:latest"},
+		client: client,
+	}
+
+	// Create the deployment and give it a legacy selector.
+	deployment, err := r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to create router deployment: %v", err)
+	}
+	desiredSelector := deployment.Spec.Selector.DeepCopy()
+	legacy := deployment.DeepCopy()
+	legacy.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"router": "legacy"}}
+	if err := client.replace(legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the annotation, the deployment is not deleted.
+	deletes := client.calls["delete"]
+	deployment, err = r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if client.calls["delete"] != deletes {
+		t.Errorf("expected no delete without annotation %s, got %d", recreateRouterDeploymentAnnotation, client.calls["delete"]-deletes)
+	}
+	if deployment == nil || deployment.Spec.Selector.MatchLabels["router"] != "legacy" {
+		t.Fatalf("expected deployment with the legacy selector, got %v", deployment)
+	}
+
+	// With the annotation, the deployment is deleted and its dependents
+	// are kept.
+	ci.Annotations = map[string]string{recreateRouterDeploymentAnnotation: ""}
+	if err := client.replace(ci); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if current, err := r.currentRouterDeployment(ci); err != nil || current != nil {
+		t.Fatalf("expected router deployment to be deleted, got %v, %v", current, err)
+	}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: lbService.Namespace, Name: lbService.Name}, &corev1.Service{}); err != nil {
+		t.Fatalf("expected load balancer service to be kept: %v", err)
+	}
+
+	// The recreated deployment has the desired selector and adopts the
+	// orphaned load balancer service and servicemonitor.  The old
+	// replicaset is kept until the new deployment is available.
+	if err := client.Create(context.TODO(), orphanedReplicaSet); err != nil {
+		t.Fatal(err)
+	}
+	deployment, err = r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to recreate router deployment: %v", err)
+	}
+	if deployment == nil || deployment.Spec.Selector.MatchLabels["router"] == "legacy" || deployment.Spec.Selector.MatchLabels[controllerDeploymentLabel] != desiredSelector.MatchLabels[controllerDeploymentLabel] {
+		t.Fatalf("expected recreated deployment with selector %v, got %v", desiredSelector, deployment)
+	}
+	svc := &corev1.Service{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: lbService.Namespace, Name: lbService.Name}, svc); err != nil {
+		t.Fatal(err)
+	}
+	if owner := metav1.GetControllerOf(svc); owner == nil || owner.Kind != "Deployment" || owner.Name != deployment.Name {
+		t.Errorf("expected load balancer service to be owned by deployment %s, got %v", deployment.Name, owner)
+	}
+	sm, err := r.currentServiceMonitor(ci)
+	if err != nil || sm == nil {
+		t.Fatalf("failed to get servicemonitor: %v", err)
+	}
+	if owner := metav1.GetControllerOf(sm); owner == nil || owner.Kind != "Deployment" || owner.Name != deployment.Name {
+		t.Errorf("expected servicemonitor to be owned by deployment %s, got %v", deployment.Name, owner)
+	}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: orphanedReplicaSet.Namespace, Name: orphanedReplicaSet.Name}, &appsv1.ReplicaSet{}); err != nil {
+		t.Errorf("expected orphaned replicaset to be kept until the deployment is available: %v", err)
+	}
+	if _, ok := ci.Annotations[recreateRouterDeploymentAnnotation]; !ok {
+		t.Errorf("expected annotation %s to be kept until the deployment is available", recreateRouterDeploymentAnnotation)
+	}
+
+	// Once the deployment is available, the orphaned replicaset and the
+	// annotation are removed.
+	available := deployment.DeepCopy()
+	available.Status.AvailableReplicas = *available.Spec.Replicas
+	if err := client.replace(available); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: orphanedReplicaSet.Namespace, Name: orphanedReplicaSet.Name}, &appsv1.ReplicaSet{}); !errors.IsNotFound(err) {
+		t.Errorf("expected orphaned replicaset to be deleted, got %v", err)
+	}
+	if _, ok := ci.Annotations[recreateRouterDeploymentAnnotation]; ok {
+		t.Errorf("expected annotation %s to be removed", recreateRouterDeploymentAnnotation)
+	}
+}
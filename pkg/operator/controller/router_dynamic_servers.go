@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// maxDynamicServersAnnotation specifies how many spare server slots
+	// the router adds to each backend so that it can add endpoints to a
+	// route without reloading HAProxy.  When a route gains more endpoints
+	// than it has slots, the router reloads.  Raising the number reduces
+	// reloads in clusters with rapid endpoint churn, but every slot is a
+	// server in every backend, so HAProxy's memory use and the size of
+	// its configuration grow with the number of routes times the number
+	// of slots.  Setting this annotation or
+	// blueprintRoutePoolSizeAnnotation enables the router's dynamic
+	// configuration manager.  The value must be a positive whole number.
+	// If unset, the router's default of 5 is used.
+	maxDynamicServersAnnotation = "ingress.operator.openshift.io/max-dynamic-servers"
+
+	// blueprintRoutePoolSizeAnnotation specifies how many spare backends
+	// the router's dynamic configuration manager keeps so that it can add
+	// routes without reloading HAProxy.  Each spare backend has the
+	// dynamic server slots of maxDynamicServersAnnotation, so memory use
+	// grows with both numbers.  The value must be a whole number; 0 keeps
+	// no spare backends, so every new route causes a reload.  If unset,
+	// the router's default of 10 is used.
+	blueprintRoutePoolSizeAnnotation = "ingress.operator.openshift.io/blueprint-route-pool-size"
+)
+
+// parseDynamicServersSetting parses the given value of a dynamic servers
+// annotation as a whole number that is at least min and fits in an int32.
+func parseDynamicServersSetting(v string, min int64) (int64, bool) {
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || n < min || strconv.FormatInt(n, 10) != v {
+		return 0, false
+	}
+	return n, true
+}
+
+// validateDynamicServers verifies that the dynamic servers annotations, if
+// set, are a positive number of slots and a non-negative pool size.
+func validateDynamicServers(ic *operatorv1.IngressController) error {
+	if v, ok := ic.Annotations[maxDynamicServersAnnotation]; ok {
+		if _, ok := parseDynamicServersSetting(v, 1); !ok {
+			return fmt.Errorf("invalid value for annotation %s: %q; must be a positive whole number no greater than %d", maxDynamicServersAnnotation, v, math.MaxInt32)
+		}
+	}
+	if v, ok := ic.Annotations[blueprintRoutePoolSizeAnnotation]; ok {
+		if _, ok := parseDynamicServersSetting(v, 0); !ok {
+			return fmt.Errorf("invalid value for annotation %s: %q; must be a whole number no greater than %d", blueprintRoutePoolSizeAnnotation, v, math.MaxInt32)
+		}
+	}
+	return nil
+}
+
+// dynamicServersEnv returns the router environment variables for the given
+// ingresscontroller's dynamic servers annotations.  If either is set, the
+// dynamic configuration manager is enabled, and the router's default is kept
+// for the other.
+func dynamicServersEnv(ic *operatorv1.IngressController) []corev1.EnvVar {
+	servers, hasServers := parseDynamicServersSetting(ic.Annotations[maxDynamicServersAnnotation], 1)
+	poolSize, hasPoolSize := parseDynamicServersSetting(ic.Annotations[blueprintRoutePoolSizeAnnotation], 0)
+	if !hasServers && !hasPoolSize {
+		return nil
+	}
+	env := []corev1.EnvVar{{Name: "ROUTER_HAPROXY_CONFIG_MANAGER", Value: "true"}}
+	if hasServers {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_MAX_DYNAMIC_SERVERS", Value: strconv.FormatInt(servers, 10)})
+	}
+	if hasPoolSize {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_BLUEPRINT_ROUTE_POOL_SIZE", Value: strconv.FormatInt(poolSize, 10)})
+	}
+	return env
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredRouterDeploymentDynamicServers verifies that the dynamic servers
+// annotations enable the router's dynamic configuration manager with the given
+// settings and that the router's defaults are kept for unset annotations.
+func TestDesiredRouterDeploymentDynamicServers(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	dynamicServersEnv := func(deployment *appsv1.Deployment) []corev1.EnvVar {
+		env := []corev1.EnvVar{}
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			switch envVar.Name {
+			case "ROUTER_HAPROXY_CONFIG_MANAGER", "ROUTER_MAX_DYNAMIC_SERVERS", "ROUTER_BLUEPRINT_ROUTE_POOL_SIZE":
+				env = append(env, envVar)
+			}
+		}
+		return env
+	}
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expectEnv   []corev1.EnvVar
+	}{
+		{
+			description: "unset",
+			expectEnv:   []corev1.EnvVar{},
+		},
+		{
+			description: "max dynamic servers only",
+			annotations: map[string]string{maxDynamicServersAnnotation: "20"},
+			expectEnv: []corev1.EnvVar{
+				{Name: "ROUTER_HAPROXY_CONFIG_MANAGER", Value: "true"},
+				{Name: "ROUTER_MAX_DYNAMIC_SERVERS", Value: "20"},
+			},
+		},
+		{
+			description: "both",
+			annotations: map[string]string{maxDynamicServersAnnotation: "20", blueprintRoutePoolSizeAnnotation: "0"},
+			expectEnv: []corev1.EnvVar{
+				{Name: "ROUTER_HAPROXY_CONFIG_MANAGER", Value: "true"},
+				{Name: "ROUTER_MAX_DYNAMIC_SERVERS", Value: "20"},
+				{Name: "ROUTER_BLUEPRINT_ROUTE_POOL_SIZE", Value: "0"},
+			},
+		},
+	}
+	var previous *appsv1.Deployment
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: tc.annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("%s: invalid router Deployment: %v", tc.description, err)
+		}
+		if env := dynamicServersEnv(deployment); !cmp.Equal(env, tc.expectEnv) {
+			t.Errorf("%s: expected env %v, got %v", tc.description, tc.expectEnv, env)
+		}
+		if previous != nil {
+			if changed, _ := deploymentConfigChanged(previous, deployment); !changed {
+				t.Errorf("%s: expected the deployment to change", tc.description)
+			}
+		}
+		previous = deployment
+	}
+}
+
+// TestValidateDynamicServers verifies that the number of dynamic servers must
+// be positive and the blueprint route pool size non-negative, with a message
+// that names the annotation.
+func TestValidateDynamicServers(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		expect      string
+	}{
+		{
+			annotations: map[string]string{maxDynamicServersAnnotation: "1", blueprintRoutePoolSizeAnnotation: "0"},
+		},
+		{
+			annotations: map[string]string{maxDynamicServersAnnotation: "0"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/max-dynamic-servers: "0"; must be a positive whole number no greater than 2147483647`,
+		},
+		{
+			annotations: map[string]string{maxDynamicServersAnnotation: "+5"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/max-dynamic-servers: "+5"; must be a positive whole number no greater than 2147483647`,
+		},
+		{
+			annotations: map[string]string{blueprintRoutePoolSizeAnnotation: "-1"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/blueprint-route-pool-size: "-1"; must be a whole number no greater than 2147483647`,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		err := validateDynamicServers(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%v: expected no error, got %v", tc.annotations, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%v: expected error %q, got %v", tc.annotations, tc.expect, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// headerBufferSizeAnnotation specifies the size of the buffers in
+	// which the router holds a request or response, as a quantity of bytes
+	// such as "65536" or "64Ki".  A request whose line and headers do not
+	// fit in the buffer less the space reserved by
+	// headerBufferMaxRewriteSizeAnnotation is rejected with status 400, so
+	// clients that send large cookies or headers need a larger buffer.
+	// Each connection may hold two buffers, one for each direction, so the
+	// router pod may use up to twice the buffer size times the maximum
+	// number of connections (see maxConnectionsAnnotation) in memory; for
+	// example, 64Ki buffers with 20000 connections may take 2.5Gi.  If
+	// unset, the router's default of defaultHeaderBufferSize is used.
+	headerBufferSizeAnnotation = "ingress.operator.openshift.io/header-buffer-size"
+
+	// headerBufferMaxRewriteSizeAnnotation specifies how much of each
+	// buffer the router reserves for adding and rewriting headers, as a
+	// quantity of bytes.  The largest request line and headers that the
+	// router accepts are the buffer size less this size.  The value must
+	// be less than the buffer size.  If unset, the router's default of
+	// defaultHeaderBufferMaxRewriteSize is used.
+	headerBufferMaxRewriteSizeAnnotation = "ingress.operator.openshift.io/header-buffer-max-rewrite-size"
+
+	// defaultHeaderBufferSize is the router's default buffer size in bytes.
+	defaultHeaderBufferSize = 32768
+
+	// defaultHeaderBufferMaxRewriteSize is the router's default size in
+	// bytes of the space reserved for rewriting headers.
+	defaultHeaderBufferMaxRewriteSize = 8192
+)
+
+// parseByteSize parses the given quantity as a positive whole number of bytes
+// that fits in an int32, which is the range of HAProxy's buffer sizes.
+func parseByteSize(v string) (int64, bool) {
+	q, err := resource.ParseQuantity(v)
+	if err != nil {
+		return 0, false
+	}
+	n, ok := q.AsInt64()
+	if !ok || n < 1 || n > math.MaxInt32 {
+		return 0, false
+	}
+	return n, true
+}
+
+// headerBufferSizes returns the router's buffer size and the space reserved
+// for rewriting headers for the given ingresscontroller, using the router's
+// defaults for unset or invalid annotations.
+func headerBufferSizes(ic *operatorv1.IngressController) (int64, int64) {
+	size, maxRewrite := int64(defaultHeaderBufferSize), int64(defaultHeaderBufferMaxRewriteSize)
+	if n, ok := parseByteSize(ic.Annotations[headerBufferSizeAnnotation]); ok {
+		size = n
+	}
+	if n, ok := parseByteSize(ic.Annotations[headerBufferMaxRewriteSizeAnnotation]); ok {
+		maxRewrite = n
+	}
+	return size, maxRewrite
+}
+
+// validateHeaderBufferSizes verifies that the header buffer size annotations,
+// if set, are positive sizes and that the space reserved for rewriting headers
+// leaves room for the request in the buffer.
+func validateHeaderBufferSizes(ic *operatorv1.IngressController) error {
+	for _, annotation := range []string{headerBufferSizeAnnotation, headerBufferMaxRewriteSizeAnnotation} {
+		if v, ok := ic.Annotations[annotation]; ok {
+			if _, ok := parseByteSize(v); !ok {
+				return fmt.Errorf("invalid value for annotation %s: %q; must be a positive whole number of bytes no greater than %d, such as 65536 or 64Ki", annotation, v, math.MaxInt32)
+			}
+		}
+	}
+	if size, maxRewrite := headerBufferSizes(ic); maxRewrite >= size {
+		return fmt.Errorf("invalid header buffer sizes: the max rewrite size of %d bytes (annotation %s) must be less than the buffer size of %d bytes (annotation %s)", maxRewrite, headerBufferMaxRewriteSizeAnnotation, size, headerBufferSizeAnnotation)
+	}
+	return nil
+}
+
+// headerBufferEnv returns the router environment variables for the given
+// ingresscontroller's header buffer size annotations.  Sizes are passed in
+// bytes, and unset annotations leave the router's defaults in place.
+func headerBufferEnv(ic *operatorv1.IngressController) []corev1.EnvVar {
+	env := []corev1.EnvVar{}
+	if n, ok := parseByteSize(ic.Annotations[headerBufferSizeAnnotation]); ok {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_BUF_SIZE", Value: strconv.FormatInt(n, 10)})
+	}
+	if n, ok := parseByteSize(ic.Annotations[headerBufferMaxRewriteSizeAnnotation]); ok {
+		env = append(env, corev1.EnvVar{Name: "ROUTER_MAX_REWRITE_SIZE", Value: strconv.FormatInt(n, 10)})
+	}
+	return env
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredRouterDeploymentHeaderBuffer verifies that the header buffer size
+// annotations are passed to the router in bytes, that the router's defaults are
+// kept if they are unset, and that changing them updates the deployment.
+func TestDesiredRouterDeploymentHeaderBuffer(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	bufferEnv := func(deployment *appsv1.Deployment) []corev1.EnvVar {
+		env := []corev1.EnvVar{}
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			switch envVar.Name {
+			case "ROUTER_BUF_SIZE", "ROUTER_MAX_REWRITE_SIZE":
+				env = append(env, envVar)
+			}
+		}
+		return env
+	}
+
+	withoutSizes, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if env := bufferEnv(withoutSizes); len(env) != 0 {
+		t.Errorf("expected no buffer sizes without annotations, got %v", env)
+	}
+
+	ci.Annotations = map[string]string{
+		headerBufferSizeAnnotation:           "64Ki",
+		headerBufferMaxRewriteSizeAnnotation: "16384",
+	}
+	withSizes, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	expect := []corev1.EnvVar{
+		{Name: "ROUTER_BUF_SIZE", Value: "65536"},
+		{Name: "ROUTER_MAX_REWRITE_SIZE", Value: "16384"},
+	}
+	if env := bufferEnv(withSizes); !cmp.Equal(env, expect) {
+		t.Errorf("expected env %v, got %v", expect, env)
+	}
+	if changed, _ := deploymentConfigChanged(withoutSizes, withSizes); !changed {
+		t.Error("expected setting the buffer sizes to change the deployment")
+	}
+}
+
+// TestValidateHeaderBufferSizes verifies that invalid sizes, and a max rewrite
+// size that leaves no room for the request, are rejected with a message that
+// names the problem.
+func TestValidateHeaderBufferSizes(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		expect      string
+	}{
+		{
+			annotations: map[string]string{headerBufferSizeAnnotation: "64Ki", headerBufferMaxRewriteSizeAnnotation: "16Ki"},
+		},
+		{
+			annotations: map[string]string{headerBufferSizeAnnotation: "0"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/header-buffer-size: "0"; must be a positive whole number of bytes no greater than 2147483647, such as 65536 or 64Ki`,
+		},
+		{
+			annotations: map[string]string{headerBufferMaxRewriteSizeAnnotation: "large"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/header-buffer-max-rewrite-size: "large"; must be a positive whole number of bytes no greater than 2147483647, such as 65536 or 64Ki`,
+		},
+		{
+			annotations: map[string]string{headerBufferSizeAnnotation: "8Ki"},
+			expect:      "invalid header buffer sizes: the max rewrite size of 8192 bytes (annotation ingress.operator.openshift.io/header-buffer-max-rewrite-size) must be less than the buffer size of 8192 bytes (annotation ingress.operator.openshift.io/header-buffer-size)",
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		err := validateHeaderBufferSizes(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%v: expected no error, got %v", tc.annotations, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%v: expected error %q, got %v", tc.annotations, tc.expect, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// idleConnectionTimeoutAnnotation specifies how long the router keeps a
+	// client's keep-alive connection open while it waits for the next
+	// request, as a duration such as "5s" or "2m".  Lowering it frees the
+	// connections of idle clients sooner, at the cost of clients having to
+	// reconnect more often.  It is not a request timeout: it applies only
+	// between requests, and does not limit how long the router waits for a
+	// client to send a request or for a server to respond, which the
+	// router's client and server timeouts govern.  The value must be
+	// between 1ms and maxHAProxyTimeout.  If unset, the router's default of
+	// 300s is used.
+	idleConnectionTimeoutAnnotation = "ingress.operator.openshift.io/idle-connection-timeout"
+)
+
+// parseIdleConnectionTimeout parses the given idle connection timeout as a
+// duration between 1ms and the maximum timeout that HAProxy supports.
+func parseIdleConnectionTimeout(v string) (time.Duration, bool) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < time.Millisecond || d > maxHAProxyTimeout {
+		return 0, false
+	}
+	return d, true
+}
+
+// validateIdleConnectionTimeout verifies that the idle connection timeout
+// annotation, if set, is a valid duration.
+func validateIdleConnectionTimeout(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[idleConnectionTimeoutAnnotation]
+	if !ok {
+		return nil
+	}
+	if _, ok := parseIdleConnectionTimeout(v); !ok {
+		return fmt.Errorf("invalid value for annotation %s: %q; must be a duration between 1ms and %s, such as 5s or 2m; this limits how long an idle keep-alive connection stays open between requests, not how long a request may take", idleConnectionTimeoutAnnotation, v, maxHAProxyTimeout)
+	}
+	return nil
+}
+
+// idleConnectionTimeoutEnv returns the router environment variable for the
+// given ingresscontroller's idle connection timeout, which the router
+// applies as HAProxy's http-keep-alive timeout.
+func idleConnectionTimeoutEnv(ic *operatorv1.IngressController) []corev1.EnvVar {
+	d, ok := parseIdleConnectionTimeout(ic.Annotations[idleConnectionTimeoutAnnotation])
+	if !ok {
+		return nil
+	}
+	return []corev1.EnvVar{{Name: "ROUTER_SLOWLORIS_HTTP_KEEPALIVE", Value: haproxyDuration(d)}}
+}
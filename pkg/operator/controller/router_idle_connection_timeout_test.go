@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredRouterDeploymentIdleConnectionTimeout verifies that the idle
+// connection timeout annotation sets the router's keep-alive timeout without
+// touching its request timeouts and that changing it updates the deployment.
+func TestDesiredRouterDeploymentIdleConnectionTimeout(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	timeoutEnv := func(deployment *appsv1.Deployment) []corev1.EnvVar {
+		env := []corev1.EnvVar{}
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			switch envVar.Name {
+			case "ROUTER_SLOWLORIS_HTTP_KEEPALIVE", "ROUTER_DEFAULT_CLIENT_TIMEOUT", "ROUTER_DEFAULT_SERVER_TIMEOUT":
+				env = append(env, envVar)
+			}
+		}
+		return env
+	}
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expectEnv   []corev1.EnvVar
+	}{
+		{
+			description: "unset",
+			expectEnv:   []corev1.EnvVar{},
+		},
+		{
+			description: "idle timeout",
+			annotations: map[string]string{idleConnectionTimeoutAnnotation: "5s"},
+			expectEnv: []corev1.EnvVar{
+				{Name: "ROUTER_SLOWLORIS_HTTP_KEEPALIVE", Value: "5000ms"},
+			},
+		},
+		{
+			description: "idle timeout with a default route timeout",
+			annotations: map[string]string{idleConnectionTimeoutAnnotation: "1m30s", defaultRouteSettingsAnnotation: routeTimeoutAnnotation + "=2m"},
+			expectEnv: []corev1.EnvVar{
+				{Name: "ROUTER_SLOWLORIS_HTTP_KEEPALIVE", Value: "90000ms"},
+				{Name: "ROUTER_DEFAULT_SERVER_TIMEOUT", Value: "120000ms"},
+			},
+		},
+	}
+	var previous *appsv1.Deployment
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: tc.annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("%s: invalid router Deployment: %v", tc.description, err)
+		}
+		if env := timeoutEnv(deployment); !cmp.Equal(env, tc.expectEnv) {
+			t.Errorf("%s: expected env %v, got %v", tc.description, tc.expectEnv, env)
+		}
+		if previous != nil {
+			if changed, _ := deploymentConfigChanged(previous, deployment); !changed {
+				t.Errorf("%s: expected the deployment to change", tc.description)
+			}
+		}
+		previous = deployment
+	}
+}
+
+// TestValidateIdleConnectionTimeout verifies that the idle connection timeout
+// must be a duration that HAProxy accepts, with a message that names the
+// annotation and says that it is not a request timeout.
+func TestValidateIdleConnectionTimeout(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		expect      string
+	}{
+		{
+			annotations: map[string]string{idleConnectionTimeoutAnnotation: "5s"},
+		},
+		{
+			annotations: map[string]string{idleConnectionTimeoutAnnotation: "5"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/idle-connection-timeout: "5"; must be a duration between 1ms and 596h31m23.647s, such as 5s or 2m; this limits how long an idle keep-alive connection stays open between requests, not how long a request may take`,
+		},
+		{
+			annotations: map[string]string{idleConnectionTimeoutAnnotation: "0s"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/idle-connection-timeout: "0s"; must be a duration between 1ms and 596h31m23.647s, such as 5s or 2m; this limits how long an idle keep-alive connection stays open between requests, not how long a request may take`,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		err := validateIdleConnectionTimeout(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%v: expected no error, got %v", tc.annotations, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%v: expected error %q, got %v", tc.annotations, tc.expect, err)
+		}
+	}
+}
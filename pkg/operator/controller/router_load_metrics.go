@@ -0,0 +1,272 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// routerLoadInterval is how often the router load collector scrapes
+	// the stats endpoint of each router pod.
+	routerLoadInterval = 30 * time.Second
+
+	// routerCurrentSessionsMetric is the router metric with the number of
+	// connections that a frontend currently has open.
+	routerCurrentSessionsMetric = "haproxy_frontend_current_sessions"
+
+	// routerHTTPRequestsMetric is the router metric with the number of
+	// HTTP requests that a frontend has received since haproxy started.
+	routerHTTPRequestsMetric = "haproxy_frontend_http_requests_total"
+)
+
+// routerClientFrontends are the router frontends to which clients connect.
+// The router passes TLS connections from the public_ssl frontend on to
+// internal frontends, which are not counted so that each client connection is
+// only counted once.
+var routerClientFrontends = map[string]bool{
+	"public":     true,
+	"public_ssl": true,
+}
+
+var (
+	// routerCurrentConnections is the number of client connections that
+	// the router pods of each ingresscontroller have open.
+	routerCurrentConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_controller_router_current_connections",
+		Help: "Number of client connections that the router pods of the ingresscontroller have open, as of the last scrape of their stats endpoints.",
+	}, []string{"name"})
+
+	// routerHTTPRequestsRate is the rate at which the router pods of each
+	// ingresscontroller receive HTTP requests.
+	routerHTTPRequestsRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_controller_router_http_requests_per_second",
+		Help: "Rate at which the router pods of the ingresscontroller received HTTP requests between the last two scrapes of their stats endpoints.",
+	}, []string{"name"})
+
+	// routerStatsScrapeFailures counts the failed scrapes of the stats
+	// endpoints of the router pods of each ingresscontroller.
+	routerStatsScrapeFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingress_controller_router_stats_scrape_failures_total",
+		Help: "Number of failed scrapes of the stats endpoints of the router pods of the ingresscontroller.",
+	}, []string{"name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(routerCurrentConnections, routerHTTPRequestsRate, routerStatsScrapeFailures)
+}
+
+// routerLoad is the load that a router pod reports on its stats endpoint.
+type routerLoad struct {
+	// currentConnections is the number of client connections that the
+	// router has open.
+	currentConnections float64
+	// httpRequests is the number of HTTP requests that the router has
+	// received since haproxy started.
+	httpRequests float64
+}
+
+// routerLoadSample is the number of HTTP requests that a router pod reported
+// at a given time, from which the next scrape computes the request rate.
+type routerLoadSample struct {
+	httpRequests float64
+	time         time.Time
+}
+
+// routerLoadCollector periodically scrapes the stats endpoint of each router
+// pod and exposes the load of each ingresscontroller's router pods as operator
+// metrics, so that the load of all ingresscontrollers can be watched in one
+// place.  Scrape failures are logged and counted and never stop the
+// collector; an ingresscontroller none of whose router pods can be scraped
+// has no load metrics until one can.
+type routerLoadCollector struct {
+	reconciler *reconciler
+
+	lock sync.Mutex
+	// samples has the last sample of each router pod, by pod UID.
+	samples map[types.UID]routerLoadSample
+	// names has the names of the ingresscontrollers that have load
+	// metrics.
+	names map[string]bool
+}
+
+var _ manager.Runnable = &routerLoadCollector{}
+
+// newRouterLoadCollector returns a router load collector that uses the given
+// reconciler's client, cache, and configuration.
+func newRouterLoadCollector(r *reconciler) *routerLoadCollector {
+	return &routerLoadCollector{
+		reconciler: r,
+		samples:    map[types.UID]routerLoadSample{},
+		names:      map[string]bool{},
+	}
+}
+
+// Start collects the router load every routerLoadInterval until the stop
+// channel is closed.
+func (c *routerLoadCollector) Start(stop <-chan struct{}) error {
+	if !c.reconciler.cache.WaitForCacheSync(stop) {
+		return fmt.Errorf("failed to sync cache before collecting router load")
+	}
+	wait.Until(c.collect, routerLoadInterval, stop)
+	return nil
+}
+
+// collect scrapes the router pods of every ingresscontroller with metrics
+// integration and records their load.
+func (c *routerLoadCollector) collect() {
+	r := c.reconciler
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for router load")
+		return
+	}
+	loads := map[string]map[types.UID]routerLoad{}
+	for i := range ingresses.Items {
+		ic := &ingresses.Items[i]
+		if !metricsIntegrationEnabled(ic) || ic.DeletionTimestamp != nil {
+			continue
+		}
+		loads[ic.Name] = c.scrape(ic)
+	}
+	c.record(loads, time.Now())
+}
+
+// scrape returns the load of each router pod of the given ingresscontroller
+// whose stats endpoint can be scraped, by pod UID.
+func (c *routerLoadCollector) scrape(ic *operatorv1.IngressController) map[types.UID]routerLoad {
+	r := c.reconciler
+	loads := map[types.UID]routerLoad{}
+	httpClient, username, password, err := r.routerStatsClient(ic)
+	if err != nil {
+		log.V(1).Info("cannot scrape the router stats endpoint", "ingresscontroller", ic.Name, "error", err)
+		return loads
+	}
+	pods := &corev1.PodList{}
+	if err := r.cache.List(context.TODO(), pods, client.InNamespace(r.OperandNamespace), client.MatchingLabels(IngressControllerDeploymentPodSelector(ic).MatchLabels)); err != nil {
+		log.Error(err, "failed to list router pods for router load", "ingresscontroller", ic.Name)
+		return loads
+	}
+	for _, pod := range runningRouterPods(pods.Items) {
+		metrics, err := getRouterMetrics(httpClient, routerStatsURL(pod), username, password)
+		if err == nil {
+			loads[pod.UID], err = parseRouterLoad(bytes.NewReader(metrics))
+		}
+		if err != nil {
+			log.V(1).Info("failed to scrape router load", "namespace", pod.Namespace, "name", pod.Name, "error", err)
+			routerStatsScrapeFailures.WithLabelValues(ic.Name).Inc()
+			delete(loads, pod.UID)
+		}
+	}
+	return loads
+}
+
+// record sets the load metrics of each of the given ingresscontrollers from
+// the given loads of its router pods, scraped at the given time, and removes
+// the load metrics of any other ingresscontroller.  The request rate of a pod
+// is computed from its previous sample; a pod without one, or whose haproxy
+// restarted since, contributes to the rate from its next scrape on.
+func (c *routerLoadCollector) record(loads map[string]map[types.UID]routerLoad, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	samples := map[types.UID]routerLoadSample{}
+	for name, pods := range loads {
+		if len(pods) == 0 {
+			c.deleteMetrics(name)
+			continue
+		}
+		connections, rate, rated := 0.0, 0.0, false
+		for uid, load := range pods {
+			connections += load.currentConnections
+			samples[uid] = routerLoadSample{httpRequests: load.httpRequests, time: now}
+			previous, ok := c.samples[uid]
+			if !ok || load.httpRequests < previous.httpRequests || !now.After(previous.time) {
+				continue
+			}
+			rate += (load.httpRequests - previous.httpRequests) / now.Sub(previous.time).Seconds()
+			rated = true
+		}
+		routerCurrentConnections.WithLabelValues(name).Set(connections)
+		if rated {
+			routerHTTPRequestsRate.WithLabelValues(name).Set(rate)
+		} else {
+			routerHTTPRequestsRate.DeleteLabelValues(name)
+		}
+		c.names[name] = true
+	}
+	for name := range c.names {
+		if _, ok := loads[name]; !ok {
+			c.deleteMetrics(name)
+		}
+	}
+	c.samples = samples
+}
+
+// deleteMetrics removes the load metrics of the ingresscontroller with the
+// given name.  The lock must be held.
+func (c *routerLoadCollector) deleteMetrics(name string) {
+	routerCurrentConnections.DeleteLabelValues(name)
+	routerHTTPRequestsRate.DeleteLabelValues(name)
+	delete(c.names, name)
+}
+
+// parseRouterLoad parses the load of a router from the given metrics in the
+// prometheus text format.
+func parseRouterLoad(metrics io.Reader) (routerLoad, error) {
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(metrics)
+	if err != nil {
+		return routerLoad{}, fmt.Errorf("failed to parse metrics: %v", err)
+	}
+	sum := func(name string, frontends map[string]bool) float64 {
+		family, ok := families[name]
+		if !ok {
+			return 0
+		}
+		total := 0.0
+		for _, metric := range family.Metric {
+			if frontends != nil {
+				frontend := ""
+				for _, label := range metric.Label {
+					if label.GetName() == "frontend" {
+						frontend = label.GetValue()
+					}
+				}
+				if !frontends[frontend] {
+					continue
+				}
+			}
+			switch {
+			case metric.Gauge != nil:
+				total += metric.Gauge.GetValue()
+			case metric.Counter != nil:
+				total += metric.Counter.GetValue()
+			case metric.Untyped != nil:
+				total += metric.Untyped.GetValue()
+			}
+		}
+		return total
+	}
+	return routerLoad{
+		currentConnections: sum(routerCurrentSessionsMetric, routerClientFrontends),
+		httpRequests:       sum(routerHTTPRequestsMetric, nil),
+	}, nil
+}
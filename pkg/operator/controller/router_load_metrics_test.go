@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestParseRouterLoad verifies that the current connections are summed over
+// the frontends to which clients connect and the HTTP requests over all
+// frontends.
+func TestParseRouterLoad(t *testing.T) {
+	metrics := `# TYPE haproxy_frontend_current_sessions gauge
+haproxy_frontend_current_sessions{frontend="public"} 3
+haproxy_frontend_current_sessions{frontend="public_ssl"} 5
+haproxy_frontend_current_sessions{frontend="fe_sni"} 4
+haproxy_frontend_current_sessions{frontend="fe_no_sni"} 1
+# TYPE haproxy_frontend_http_requests_total counter
+haproxy_frontend_http_requests_total{frontend="public"} 100
+haproxy_frontend_http_requests_total{frontend="public_ssl"} 0
+haproxy_frontend_http_requests_total{frontend="fe_sni"} 40
+haproxy_frontend_http_requests_total{frontend="fe_no_sni"} 2
+`
+	load, err := parseRouterLoad(strings.NewReader(metrics))
+	if err != nil {
+		t.Fatalf("failed to parse router load: %v", err)
+	}
+	if expect := (routerLoad{currentConnections: 8, httpRequests: 142}); load != expect {
+		t.Errorf("expected %+v, got %+v", expect, load)
+	}
+
+	if load, err := parseRouterLoad(strings.NewReader("haproxy_up 1\n")); err != nil || load != (routerLoad{}) {
+		t.Errorf("expected a zero load for metrics without load, got %+v, %v", load, err)
+	}
+	if _, err := parseRouterLoad(strings.NewReader("not metrics\n")); err == nil {
+		t.Error("expected an error for invalid metrics")
+	}
+}
+
+// routerLoadMetric returns the value of the given router load gauge for the
+// ingresscontroller with the given name and a Boolean indicating whether it
+// has a value.
+func routerLoadMetric(t *testing.T, gauge *prometheus.GaugeVec, name string) (float64, bool) {
+	ch := make(chan prometheus.Metric, 10)
+	gauge.Collect(ch)
+	close(ch)
+	for metric := range ch {
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil {
+			t.Fatal(err)
+		}
+		for _, label := range m.Label {
+			if label.GetName() == "name" && label.GetValue() == name {
+				return m.GetGauge().GetValue(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// TestRouterLoadCollectorRecord verifies that the load metrics sum the
+// connections of the scraped router pods, compute the request rate from the
+// previous scrape, skip pods whose haproxy restarted, and are removed when an
+// ingresscontroller has no scraped pods or is gone.
+func TestRouterLoadCollectorRecord(t *testing.T) {
+	c := newRouterLoadCollector(&reconciler{})
+	start := time.Unix(1559390400, 0)
+	expect := func(step, name string, connections, rate float64, hasConnections, hasRate bool) {
+		t.Helper()
+		if v, ok := routerLoadMetric(t, routerCurrentConnections, name); ok != hasConnections || v != connections {
+			t.Errorf("%s: expected connections %v (present %t) for %s, got %v (present %t)", step, connections, hasConnections, name, v, ok)
+		}
+		if v, ok := routerLoadMetric(t, routerHTTPRequestsRate, name); ok != hasRate || v != rate {
+			t.Errorf("%s: expected request rate %v (present %t) for %s, got %v (present %t)", step, rate, hasRate, name, v, ok)
+		}
+	}
+
+	c.record(map[string]map[types.UID]routerLoad{
+		"load-a": {"pod-1": {currentConnections: 3, httpRequests: 100}, "pod-2": {currentConnections: 4, httpRequests: 200}},
+		"load-b": {"pod-3": {currentConnections: 1, httpRequests: 10}},
+	}, start)
+	expect("first scrape", "load-a", 7, 0, true, false)
+	expect("first scrape", "load-b", 1, 0, true, false)
+
+	// pod-2's haproxy restarted, and pod-4 is new, so only pod-1
+	// contributes to the rate.
+	c.record(map[string]map[types.UID]routerLoad{
+		"load-a": {"pod-1": {currentConnections: 5, httpRequests: 400}, "pod-2": {currentConnections: 1, httpRequests: 20}, "pod-4": {currentConnections: 2, httpRequests: 50}},
+		"load-b": {},
+	}, start.Add(30*time.Second))
+	expect("second scrape", "load-a", 8, 10, true, true)
+	expect("second scrape", "load-b", 0, 0, false, false)
+
+	c.record(map[string]map[types.UID]routerLoad{
+		"load-a": {"pod-1": {currentConnections: 0, httpRequests: 460}, "pod-2": {currentConnections: 0, httpRequests: 50}, "pod-4": {currentConnections: 0, httpRequests: 50}},
+	}, start.Add(60*time.Second))
+	expect("third scrape", "load-a", 0, 3, true, true)
+
+	c.record(map[string]map[types.UID]routerLoad{}, start.Add(90*time.Second))
+	expect("ingresscontroller deleted", "load-a", 0, 0, false, false)
+	if len(c.samples) != 0 || len(c.names) != 0 {
+		t.Errorf("expected no samples or names to be kept, got %v and %v", c.samples, c.names)
+	}
+}
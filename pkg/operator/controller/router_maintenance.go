@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// maintenanceModeAnnotation, when set to "true" on an
+	// ingresscontroller, puts the ingresscontroller in maintenance mode:
+	// the router stops serving routes and responds to every request with
+	// 503 Service Unavailable.  Routes are not modified.  Allowed values
+	// are "true" and "false".  If unset, the router serves routes
+	// normally.
+	maintenanceModeAnnotation = "ingress.operator.openshift.io/maintenance-mode"
+
+	// maintenancePageConfigMapAnnotation specifies the name of a configmap
+	// in the operand namespace with the response that the router serves
+	// in maintenance mode.  The configmap must have the key
+	// maintenancePageKey with a complete HTTP response, including the
+	// status line and headers.  If unset, or if the configmap or key does
+	// not exist, the router's default 503 response is served.
+	maintenancePageConfigMapAnnotation = "ingress.operator.openshift.io/maintenance-page-configmap"
+
+	// maintenancePageKey is the key of the maintenance page in the
+	// maintenance page configmap.
+	maintenancePageKey = "error-page-503.http"
+
+	// maintenancePageVolumeName is the name of the router volume with the
+	// maintenance page.
+	maintenancePageVolumeName = "maintenance-page"
+
+	// maintenancePageMountPath is the directory in which the maintenance
+	// page is mounted in the router container.
+	maintenancePageMountPath = "/var/lib/haproxy/conf/error_code_pages"
+)
+
+// maintenanceRouteSelector is a route label selector that matches no route.
+// The router serves 503 for requests that match no route.
+var maintenanceRouteSelector = fmt.Sprintf("%s,!%s", maintenanceModeAnnotation, maintenanceModeAnnotation)
+
+// maintenanceModeEnabled returns a Boolean indicating whether the given
+// ingresscontroller is in maintenance mode.
+func maintenanceModeEnabled(ic *operatorv1.IngressController) bool {
+	return ic.Annotations[maintenanceModeAnnotation] == "true"
+}
+
+// maintenancePageConfigMap returns the configmap with the maintenance page
+// for the given ingresscontroller, or nil if the ingresscontroller is not in
+// maintenance mode, does not specify a page, or the configmap or its page does
+// not exist.
+func (r *reconciler) maintenancePageConfigMap(ic *operatorv1.IngressController) (*corev1.ConfigMap, error) {
+	name, ok := ic.Annotations[maintenancePageConfigMapAnnotation]
+	if !ok || !maintenanceModeEnabled(ic) {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: r.OperandNamespace, Name: name}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get maintenance page configmap %s/%s: %v", r.OperandNamespace, name, err)
+	}
+	if _, ok := cm.Data[maintenancePageKey]; !ok {
+		return nil, nil
+	}
+	return cm, nil
+}
+
+// applyMaintenancePage configures the given router deployment to serve the
+// maintenance page from the given configmap for 503 responses.
+func applyMaintenancePage(deployment *appsv1.Deployment, cm *corev1.ConfigMap) {
+	podSpec := &deployment.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: maintenancePageVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+				Items:                []corev1.KeyToPath{{Key: maintenancePageKey, Path: maintenancePageKey}},
+			},
+		},
+	})
+	container := &podSpec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      maintenancePageVolumeName,
+		MountPath: maintenancePageMountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  "ROUTER_ERRORFILE_503",
+		Value: maintenancePageMountPath + "/" + maintenancePageKey,
+	})
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// TestMaintenanceRouteSelector verifies that the route selector for
+// maintenance mode matches no route.
+func TestMaintenanceRouteSelector(t *testing.T) {
+	selector, err := labels.Parse(maintenanceRouteSelector)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", maintenanceRouteSelector, err)
+	}
+	for _, set := range []labels.Set{
+		{},
+		{"type": "sharded"},
+		{maintenanceModeAnnotation: "true"},
+	} {
+		if selector.Matches(set) {
+			t.Errorf("expected selector %q not to match labels %v", maintenanceRouteSelector, set)
+		}
+	}
+}
+
+// TestEnsureRouterDeploymentMaintenanceMode verifies that maintenance mode
+// makes the router select no routes and serve the maintenance page, that
+// reconciling an unchanged ingresscontroller makes no updates, and that
+// leaving maintenance mode restores the route selector.
+func TestEnsureRouterDeploymentMaintenanceMode(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+			Annotations: map[string]string{
+				maintenanceModeAnnotation:          "true",
+				maintenancePageConfigMapAnnotation: "maintenance",
+			},
+		},
+		Spec: operatorv1.IngressControllerSpec{
+			RouteSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"type": "sharded"}},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	page := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "maintenance"},
+		Data:       map[string]string{maintenancePageKey: "HTTP/1.0 503 Service Unavailable\r\n\r\nDown for maintenance\n"},
+	}
+	client := newFakeClient(page)
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", IngressControllerImage: "quay.io/openshift/router:latest"},
+		client: client,
+	}
+	env := func(deployment *appsv1.Deployment, name string) (string, bool) {
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == name {
+				return envVar.Value, true
+			}
+		}
+		return "", false
+	}
+	hasMount := func(deployment *appsv1.Deployment) bool {
+		for _, mount := range deployment.Spec.Template.Spec.Containers[0].VolumeMounts {
+			if mount.Name == maintenancePageVolumeName {
+				return true
+			}
+		}
+		return false
+	}
+
+	deployment, err := r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to create router deployment: %v", err)
+	}
+	if v, _ := env(deployment, "ROUTE_LABELS"); v != maintenanceRouteSelector {
+		t.Errorf("expected ROUTE_LABELS to be %q, got %q", maintenanceRouteSelector, v)
+	}
+	if v, _ := env(deployment, "ROUTER_ERRORFILE_503"); v != maintenancePageMountPath+"/"+maintenancePageKey {
+		t.Errorf("expected ROUTER_ERRORFILE_503 to be the mounted maintenance page, got %q", v)
+	}
+	if !hasMount(deployment) {
+		t.Errorf("expected the router container to mount volume %s", maintenancePageVolumeName)
+	}
+
+	// Simulate the API server's defaulting of the configmap volume.
+	for i := range deployment.Spec.Template.Spec.Volumes {
+		if v := deployment.Spec.Template.Spec.Volumes[i].ConfigMap; v != nil {
+			mode := int32(420)
+			v.DefaultMode = &mode
+		}
+	}
+	if err := client.replace(deployment); err != nil {
+		t.Fatalf("failed to store defaulted router deployment: %v", err)
+	}
+	if _, err := r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if client.calls["update"] != 0 {
+		t.Errorf("expected no deployment updates for an unchanged ingresscontroller, got %d", client.calls["update"])
+	}
+
+	// Without the configmap, the default page is served.
+	if err := client.Delete(context.TODO(), page); err != nil {
+		t.Fatal(err)
+	}
+	deployment, err = r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if v, ok := env(deployment, "ROUTER_ERRORFILE_503"); ok {
+		t.Errorf("expected ROUTER_ERRORFILE_503 to be unset, got %q", v)
+	}
+
+	// Leaving maintenance mode restores normal serving.
+	delete(ci.Annotations, maintenanceModeAnnotation)
+	deployment, err = r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to reconcile router deployment: %v", err)
+	}
+	if v, _ := env(deployment, "ROUTE_LABELS"); v != "type=sharded" {
+		t.Errorf("expected ROUTE_LABELS to be restored to %q, got %q", "type=sharded", v)
+	}
+	if hasMount(deployment) {
+		t.Errorf("expected volume %s not to be mounted", maintenancePageVolumeName)
+	}
+}
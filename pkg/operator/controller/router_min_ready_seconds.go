@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+const (
+	// minReadySecondsAnnotation specifies for how many seconds a new router
+	// pod must be ready before the deployment counts it as available, as a
+	// whole number such as "30".  Because the deployment does not tear
+	// down old router pods until new ones are available, this gives new
+	// pods time to start accepting traffic, for example from a load
+	// balancer whose health checks have yet to pass, before old ones go
+	// away.  It also slows down rollouts by as much per pod.  The value
+	// must be between 0 and maxMinReadySeconds.  If unset, new pods are
+	// available as soon as they are ready.
+	minReadySecondsAnnotation = "ingress.operator.openshift.io/min-ready-seconds"
+
+	// maxMinReadySeconds is the largest allowed min ready seconds.  The
+	// API server requires it to be less than the deployment's progress
+	// deadline, which defaults to 600 seconds.
+	maxMinReadySeconds = 599
+)
+
+// parseMinReadySeconds parses the given min ready seconds as an integer
+// between 0 and maxMinReadySeconds.
+func parseMinReadySeconds(v string) (int32, bool) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 || n > maxMinReadySeconds {
+		return 0, false
+	}
+	return int32(n), true
+}
+
+// validateMinReadySeconds verifies that the min ready seconds annotation, if
+// set, is a valid number of seconds.
+func validateMinReadySeconds(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[minReadySecondsAnnotation]
+	if !ok {
+		return nil
+	}
+	if _, ok := parseMinReadySeconds(v); !ok {
+		return fmt.Errorf("invalid value for annotation %s: %q; must be a whole number of seconds between 0 and %d", minReadySecondsAnnotation, v, maxMinReadySeconds)
+	}
+	return nil
+}
+
+// routerMinReadySeconds returns the min ready seconds of the router
+// deployment for the given ingresscontroller.
+func routerMinReadySeconds(ic *operatorv1.IngressController) int32 {
+	n, _ := parseMinReadySeconds(ic.Annotations[minReadySecondsAnnotation])
+	return n
+}
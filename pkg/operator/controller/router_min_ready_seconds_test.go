@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredRouterDeploymentMinReadySeconds verifies that the min ready
+// seconds annotation sets the router deployment's minReadySeconds and that
+// changing it updates the deployment.
+func TestDesiredRouterDeploymentMinReadySeconds(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	current, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if current.Spec.MinReadySeconds != 0 {
+		t.Errorf("expected minReadySeconds 0 by default, got %d", current.Spec.MinReadySeconds)
+	}
+
+	ci.Annotations = map[string]string{minReadySecondsAnnotation: "30"}
+	desired, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if desired.Spec.MinReadySeconds != 30 {
+		t.Errorf("expected minReadySeconds 30, got %d", desired.Spec.MinReadySeconds)
+	}
+	changed, updated := deploymentConfigChanged(current, desired)
+	if !changed {
+		t.Fatal("expected the deployment to change")
+	}
+	if updated.Spec.MinReadySeconds != 30 {
+		t.Errorf("expected the updated deployment to have minReadySeconds 30, got %d", updated.Spec.MinReadySeconds)
+	}
+	if changed, _ := deploymentConfigChanged(updated, desired); changed {
+		t.Error("expected no further change")
+	}
+}
+
+// TestValidateMinReadySeconds verifies that the min ready seconds annotation
+// must be a whole number of seconds that is less than the deployment's
+// progress deadline.
+func TestValidateMinReadySeconds(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		expect      string
+	}{
+		{},
+		{
+			annotations: map[string]string{minReadySecondsAnnotation: "0"},
+		},
+		{
+			annotations: map[string]string{minReadySecondsAnnotation: "599"},
+		},
+		{
+			annotations: map[string]string{minReadySecondsAnnotation: "-1"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/min-ready-seconds: "-1"; must be a whole number of seconds between 0 and 599`,
+		},
+		{
+			annotations: map[string]string{minReadySecondsAnnotation: "600"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/min-ready-seconds: "600"; must be a whole number of seconds between 0 and 599`,
+		},
+		{
+			annotations: map[string]string{minReadySecondsAnnotation: "30s"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/min-ready-seconds: "30s"; must be a whole number of seconds between 0 and 599`,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		err := validateMinReadySeconds(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%v: expected no error, got %v", tc.annotations, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%v: expected error %q, got %v", tc.annotations, tc.expect, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// noSchedulableNodesReason is the reason of the Degraded condition when the
+// router deployment's node selector matches no schedulable node.
+const noSchedulableNodesReason = "NoSchedulableNodes"
+
+// noSchedulableNodesProblem returns a description of why the given router
+// deployment's pods cannot be scheduled on any node, or the empty string if
+// its node selector matches at least one node that is not cordoned.  Taints
+// are not taken into account.
+func (r *reconciler) noSchedulableNodesProblem(deployment *appsv1.Deployment) (string, error) {
+	nodeSelector := deployment.Spec.Template.Spec.NodeSelector
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodes, client.MatchingLabels(nodeSelector)); err != nil {
+		return "", fmt.Errorf("failed to list nodes: %v", err)
+	}
+	for _, node := range nodes.Items {
+		if !node.Spec.Unschedulable {
+			return "", nil
+		}
+	}
+	selector := labels.SelectorFromSet(nodeSelector).String()
+	if len(nodes.Items) == 0 {
+		return fmt.Sprintf("no nodes match the router's node selector %q", selector), nil
+	}
+	return fmt.Sprintf("all %d nodes that match the router's node selector %q are unschedulable", len(nodes.Items), selector), nil
+}
+
+// nodePredicate returns a predicate that accepts node events that can change
+// whether a node selector matches a schedulable node: creation, deletion, and
+// updates to a node's labels or to whether it is cordoned.  Status updates,
+// such as heartbeats, are ignored.
+func nodePredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return true },
+		DeleteFunc: func(e event.DeleteEvent) bool { return true },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return true
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return true
+			}
+			return !reflect.DeepEqual(oldNode.Labels, newNode.Labels) || oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}
+}
+
+// nodeToIngressControllers maps a node to requests for the ingresscontrollers
+// whose router node selector matches the node.
+func (r *reconciler) nodeToIngressControllers(a handler.MapObject) []reconcile.Request {
+	ingresses := &operatorv1.IngressControllerList{}
+	if err := r.cache.List(context.TODO(), ingresses, client.InNamespace(r.Namespace)); err != nil {
+		log.Error(err, "failed to list ingresscontrollers for node", "related", a.Meta.GetSelfLink())
+		return []reconcile.Request{}
+	}
+	requests := []reconcile.Request{}
+	for i := range ingresses.Items {
+		ic := &ingresses.Items[i]
+		nodeSelector, err := routerNodeSelector(ic)
+		if err != nil || !labels.SelectorFromSet(nodeSelector).Matches(labels.Set(a.Meta.GetLabels())) {
+			continue
+		}
+		log.V(1).Info("queueing ingress", "name", ic.Name, "related", a.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+		})
+	}
+	return requests
+}
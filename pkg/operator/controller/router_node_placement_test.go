@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestSyncIngressControllerStatusNoSchedulableNodes verifies that an
+// ingresscontroller whose node selector matches no schedulable node is marked
+// degraded with the selector in the message, and recovers once a schedulable
+// node matches.
+func TestSyncIngressControllerStatusNoSchedulableNodes(t *testing.T) {
+	deployment := manifests.RouterDeployment()
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "router"}}
+	deployment.Spec.Template.Spec.NodeSelector = map[string]string{"node-role.kubernetes.io/infra": ""}
+	ic := ingressController("default", operatorv1.PrivateStrategyType)
+	ic.Namespace = "openshift-ingress-operator"
+	worker := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Labels: map[string]string{"node-role.kubernetes.io/worker": ""}}}
+	client := newFakeClient(ic, worker)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client: client,
+	}
+	degraded := func() operatorv1.OperatorCondition {
+		t.Helper()
+		current := &operatorv1.IngressController{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.syncIngressControllerStatus(current, deployment, nil, nil, nil, &configv1.DNS{}); err != nil {
+			t.Fatalf("failed to sync status: %v", err)
+		}
+		if err := client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+			t.Fatal(err)
+		}
+		condition := getIngressCondition(current.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+		if condition == nil {
+			t.Fatal("expected a Degraded condition")
+		}
+		return *condition
+	}
+	ignoreTime := cmpopts.IgnoreFields(operatorv1.OperatorCondition{}, "LastTransitionTime")
+
+	expect := operatorv1.OperatorCondition{
+		Type:    operatorv1.OperatorStatusTypeDegraded,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  noSchedulableNodesReason,
+		Message: `no nodes match the router's node selector "node-role.kubernetes.io/infra="; the router pods cannot be scheduled until spec.nodePlacement.nodeSelector matches a schedulable node`,
+	}
+	if condition := degraded(); !cmp.Equal(condition, expect, ignoreTime) {
+		t.Errorf("expected %#v, got %#v", expect, condition)
+	}
+
+	infra := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "infra-0", Labels: map[string]string{"node-role.kubernetes.io/infra": ""}}}
+	infra.Spec.Unschedulable = true
+	if err := client.Create(context.TODO(), infra); err != nil {
+		t.Fatal(err)
+	}
+	expect.Message = `all 1 nodes that match the router's node selector "node-role.kubernetes.io/infra=" are unschedulable; the router pods cannot be scheduled until spec.nodePlacement.nodeSelector matches a schedulable node`
+	if condition := degraded(); !cmp.Equal(condition, expect, ignoreTime) {
+		t.Errorf("expected %#v, got %#v", expect, condition)
+	}
+
+	infra.Spec.Unschedulable = false
+	if err := client.replace(infra); err != nil {
+		t.Fatal(err)
+	}
+	expect = operatorv1.OperatorCondition{
+		Type:   operatorv1.OperatorStatusTypeDegraded,
+		Status: operatorv1.ConditionFalse,
+	}
+	if condition := degraded(); !cmp.Equal(condition, expect, ignoreTime) {
+		t.Errorf("expected %#v once a schedulable node matches, got %#v", expect, condition)
+	}
+}
+
+// TestNodePredicate verifies that node status updates are ignored and that
+// changes to a node's labels or schedulability are not.
+func TestNodePredicate(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Labels: map[string]string{"node-role.kubernetes.io/worker": ""}}}
+	heartbeat := node.DeepCopy()
+	heartbeat.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+	relabeled := node.DeepCopy()
+	relabeled.Labels["node-role.kubernetes.io/infra"] = ""
+	cordoned := node.DeepCopy()
+	cordoned.Spec.Unschedulable = true
+
+	p := nodePredicate()
+	testCases := []struct {
+		description string
+		updated     *corev1.Node
+		expect      bool
+	}{
+		{"status update", heartbeat, false},
+		{"label change", relabeled, true},
+		{"cordon", cordoned, true},
+	}
+	for _, tc := range testCases {
+		e := event.UpdateEvent{MetaOld: node, ObjectOld: node, MetaNew: tc.updated, ObjectNew: tc.updated}
+		if actual := p.Update(e); actual != tc.expect {
+			t.Errorf("%s: expected %t, got %t", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+// TestNodeToIngressControllers verifies that a node is mapped to the
+// ingresscontrollers whose node selector matches it.
+func TestNodeToIngressControllers(t *testing.T) {
+	defaultIC := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"}}
+	infraIC := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "infra"},
+		Spec: operatorv1.IngressControllerSpec{
+			NodePlacement: &operatorv1.NodePlacement{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"node-role.kubernetes.io/infra": ""}},
+			},
+		},
+	}
+	client := newFakeClient(defaultIC, infraIC)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator"},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "infra-0", Labels: map[string]string{"node-role.kubernetes.io/infra": ""}}}
+	expect := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "infra"}}}
+	if requests := r.nodeToIngressControllers(handler.MapObject{Meta: node, Object: node}); !cmp.Equal(requests, expect) {
+		t.Errorf("expected requests %v, got %v", expect, requests)
+	}
+}
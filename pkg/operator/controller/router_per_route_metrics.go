@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// perRouteMetricsAnnotation, when set to "true" on an
+	// ingresscontroller, makes the router report per-route metrics and
+	// prometheus scrape them.  These are the haproxy_server_* series, which
+	// break down the requests, responses, and health of each route by
+	// endpoint and are labeled with the route, namespace, service, and pod.
+	// The number of series grows with the number of routes and of their
+	// endpoints, so enabling this on a cluster with many routes can put
+	// considerable load on prometheus.  Allowed values are "true" and
+	// "false".  If unset, per-route metrics are dropped when prometheus
+	// scrapes the router; the per-backend haproxy_backend_* series are
+	// scraped either way.  The annotation has no effect if metrics
+	// integration is disabled.
+	perRouteMetricsAnnotation = "ingress.operator.openshift.io/per-route-metrics"
+
+	// perRouteMetricsServerThreshold is the number of servers above which
+	// the router stops reporting per-server metrics when per-route metrics
+	// are enabled.  It is high enough that the router reports them for
+	// every route in practice.
+	perRouteMetricsServerThreshold = 100000
+
+	// perRouteMetricsPattern matches the names of the per-route metrics
+	// that the servicemonitor drops unless per-route metrics are enabled.
+	perRouteMetricsPattern = "haproxy_server_.*"
+)
+
+// perRouteMetricsEnabled returns a Boolean indicating whether the given
+// ingresscontroller reports per-route metrics.
+func perRouteMetricsEnabled(ic *operatorv1.IngressController) bool {
+	return metricsIntegrationEnabled(ic) && ic.Annotations[perRouteMetricsAnnotation] == "true"
+}
+
+// validatePerRouteMetrics verifies that the per-route metrics annotation, if
+// set, is "true" or "false".
+func validatePerRouteMetrics(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[perRouteMetricsAnnotation]
+	if !ok {
+		return nil
+	}
+	if v != "true" && v != "false" {
+		return fmt.Errorf("invalid value for annotation %s: %q; allowed values are true and false", perRouteMetricsAnnotation, v)
+	}
+	return nil
+}
+
+// perRouteMetricsEnv returns the router environment variables that make the
+// router report per-route metrics for the given ingresscontroller.
+func perRouteMetricsEnv(ic *operatorv1.IngressController) []corev1.EnvVar {
+	if !perRouteMetricsEnabled(ic) {
+		return nil
+	}
+	return []corev1.EnvVar{{Name: "ROUTER_METRICS_HAPROXY_SERVER_THRESHOLD", Value: strconv.Itoa(perRouteMetricsServerThreshold)}}
+}
+
+// perRouteMetricsRelabelings returns the metric relabelings for the given
+// ingresscontroller's servicemonitor endpoint, which drop per-route metrics
+// unless they are enabled, or nil if there are none.
+func perRouteMetricsRelabelings(ic *operatorv1.IngressController) []interface{} {
+	if perRouteMetricsEnabled(ic) {
+		return nil
+	}
+	return []interface{}{
+		map[string]interface{}{
+			"action":       "drop",
+			"sourceLabels": []interface{}{"__name__"},
+			"regex":        perRouteMetricsPattern,
+		},
+	}
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestPerRouteMetrics verifies that the per-route metrics annotation sets the
+// router's server threshold, that the servicemonitor drops per-route metrics
+// unless they are enabled, and that changing it updates the deployment and the
+// servicemonitor.
+func TestPerRouteMetrics(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-internal-default"}}
+	thresholdEnv := func(deployment *appsv1.Deployment) []corev1.EnvVar {
+		env := []corev1.EnvVar{}
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == "ROUTER_METRICS_HAPROXY_SERVER_THRESHOLD" {
+				env = append(env, envVar)
+			}
+		}
+		return env
+	}
+	relabelings := func(sm *unstructured.Unstructured) interface{} {
+		endpoints, _, err := unstructured.NestedSlice(sm.Object, "spec", "endpoints")
+		if err != nil || len(endpoints) != 1 {
+			t.Fatalf("expected 1 endpoint, got %v (error: %v)", endpoints, err)
+		}
+		return endpoints[0].(map[string]interface{})["metricRelabelings"]
+	}
+	drop := []interface{}{
+		map[string]interface{}{
+			"action":       "drop",
+			"sourceLabels": []interface{}{"__name__"},
+			"regex":        "haproxy_server_.*",
+		},
+	}
+	testCases := []struct {
+		description       string
+		annotations       map[string]string
+		expectEnv         []corev1.EnvVar
+		expectRelabelings interface{}
+	}{
+		{
+			description:       "unset",
+			expectEnv:         []corev1.EnvVar{},
+			expectRelabelings: drop,
+		},
+		{
+			description: "enabled",
+			annotations: map[string]string{perRouteMetricsAnnotation: "true"},
+			expectEnv: []corev1.EnvVar{
+				{Name: "ROUTER_METRICS_HAPROXY_SERVER_THRESHOLD", Value: "100000"},
+			},
+		},
+		{
+			description:       "disabled",
+			annotations:       map[string]string{perRouteMetricsAnnotation: "false"},
+			expectEnv:         []corev1.EnvVar{},
+			expectRelabelings: drop,
+		},
+		{
+			description:       "enabled without metrics integration",
+			annotations:       map[string]string{perRouteMetricsAnnotation: "true", disableMetricsIntegrationAnnotation: "true"},
+			expectEnv:         []corev1.EnvVar{},
+			expectRelabelings: drop,
+		},
+	}
+	var previous *appsv1.Deployment
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: tc.annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("%s: invalid router Deployment: %v", tc.description, err)
+		}
+		if env := thresholdEnv(deployment); !cmp.Equal(env, tc.expectEnv) {
+			t.Errorf("%s: expected env %v, got %v", tc.description, tc.expectEnv, env)
+		}
+		sm := desiredServiceMonitor(ci, "openshift-ingress", svc, nil, metav1.OwnerReference{})
+		if actual := relabelings(sm); !cmp.Equal(actual, tc.expectRelabelings) {
+			t.Errorf("%s: expected metric relabelings %v, got %v", tc.description, tc.expectRelabelings, actual)
+		}
+		if previous != nil && !cmp.Equal(thresholdEnv(previous), tc.expectEnv) {
+			if changed, _ := deploymentConfigChanged(previous, deployment); !changed {
+				t.Errorf("%s: expected the deployment to change", tc.description)
+			}
+		}
+		previous = deployment
+	}
+}
+
+// TestEnsureServiceMonitorPerRouteMetrics verifies that enabling per-route
+// metrics updates an existing servicemonitor so that it no longer drops them.
+func TestEnsureServiceMonitorPerRouteMetrics(t *testing.T) {
+	ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-internal-default"}}
+	client := newFakeClient()
+	r := &reconciler{Config: Config{OperandNamespace: "openshift-ingress"}, client: client}
+
+	if _, err := r.ensureServiceMonitor(ic, svc, nil, metav1.OwnerReference{}); err != nil {
+		t.Fatalf("failed to ensure servicemonitor: %v", err)
+	}
+	ic.Annotations = map[string]string{perRouteMetricsAnnotation: "true"}
+	sm, err := r.ensureServiceMonitor(ic, svc, nil, metav1.OwnerReference{})
+	if err != nil {
+		t.Fatalf("failed to ensure servicemonitor: %v", err)
+	}
+	if client.calls["update"] != 1 {
+		t.Errorf("expected 1 update to the servicemonitor, got %d", client.calls["update"])
+	}
+	endpoints, _, _ := unstructured.NestedSlice(sm.Object, "spec", "endpoints")
+	if len(endpoints) != 1 {
+		t.Fatalf("expected 1 endpoint, got %v", endpoints)
+	}
+	if v, ok := endpoints[0].(map[string]interface{})["metricRelabelings"]; ok {
+		t.Errorf("expected no metric relabelings with per-route metrics enabled, got %v", v)
+	}
+}
+
+// TestValidatePerRouteMetrics verifies that the per-route metrics annotation
+// must be "true" or "false".
+func TestValidatePerRouteMetrics(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		expect      string
+	}{
+		{},
+		{
+			annotations: map[string]string{perRouteMetricsAnnotation: "true"},
+		},
+		{
+			annotations: map[string]string{perRouteMetricsAnnotation: "yes"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/per-route-metrics: "yes"; allowed values are true and false`,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		err := validatePerRouteMetrics(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%v: expected no error, got %v", tc.annotations, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%v: expected error %q, got %v", tc.annotations, tc.expect, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// routerDNSPolicyAnnotation specifies the DNS policy of the router
+	// pods, which determines the nameservers with which the router
+	// resolves the names of route backends.  Allowed values are
+	// "ClusterFirst", which uses the cluster DNS service,
+	// "ClusterFirstWithHostNet", which does the same for pods that use the
+	// host network, "Default", which uses the node's resolver
+	// configuration, and "None", which uses only the nameservers and
+	// search domains that routerDNSNameserversAnnotation and
+	// routerDNSSearchesAnnotation specify.  If unset, the router uses the
+	// cluster DNS service: the policy is ClusterFirstWithHostNet if the
+	// ingresscontroller uses the HostNetwork endpoint publishing strategy,
+	// because a host network pod with the ClusterFirst policy would fall
+	// back to the node's resolver configuration, and ClusterFirst
+	// otherwise.
+	routerDNSPolicyAnnotation = "ingress.operator.openshift.io/router-dns-policy"
+
+	// routerDNSNameserversAnnotation specifies a comma-separated list of
+	// at most maxRouterDNSNameservers IP addresses of nameservers that are
+	// added to the router pods' resolver configuration.  The annotation is
+	// required if the DNS policy is None.
+	routerDNSNameserversAnnotation = "ingress.operator.openshift.io/router-dns-nameservers"
+
+	// routerDNSSearchesAnnotation specifies a comma-separated list of at
+	// most maxRouterDNSSearches search domains that are added to the
+	// router pods' resolver configuration.
+	routerDNSSearchesAnnotation = "ingress.operator.openshift.io/router-dns-searches"
+
+	// maxRouterDNSNameservers and maxRouterDNSSearches are the limits that
+	// the API imposes on the nameservers and search domains of a pod.
+	maxRouterDNSNameservers = 3
+	maxRouterDNSSearches    = 6
+)
+
+// routerDNSPolicy returns the DNS policy of the given ingresscontroller's
+// router pods.
+func routerDNSPolicy(ic *operatorv1.IngressController) corev1.DNSPolicy {
+	if v, ok := ic.Annotations[routerDNSPolicyAnnotation]; ok {
+		return corev1.DNSPolicy(v)
+	}
+	if ic.Status.EndpointPublishingStrategy != nil && ic.Status.EndpointPublishingStrategy.Type == operatorv1.HostNetworkStrategyType {
+		return corev1.DNSClusterFirstWithHostNet
+	}
+	return corev1.DNSClusterFirst
+}
+
+// routerDNSConfig returns the resolver configuration that the given
+// ingresscontroller adds to its router pods, or nil if it adds none.
+func routerDNSConfig(ic *operatorv1.IngressController) *corev1.PodDNSConfig {
+	nameservers := splitRouterDNSAnnotation(ic, routerDNSNameserversAnnotation)
+	searches := splitRouterDNSAnnotation(ic, routerDNSSearchesAnnotation)
+	if len(nameservers) == 0 && len(searches) == 0 {
+		return nil
+	}
+	return &corev1.PodDNSConfig{Nameservers: nameservers, Searches: searches}
+}
+
+// splitRouterDNSAnnotation returns the non-empty values of the given
+// comma-separated annotation of the given ingresscontroller.
+func splitRouterDNSAnnotation(ic *operatorv1.IngressController, annotation string) []string {
+	var values []string
+	for _, v := range strings.Split(ic.Annotations[annotation], ",") {
+		if v = strings.TrimSpace(v); len(v) != 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// validateRouterPodDNS verifies that the router DNS annotations, if set,
+// specify a DNS policy and resolver configuration that the API accepts.
+func validateRouterPodDNS(ic *operatorv1.IngressController) error {
+	if v, ok := ic.Annotations[routerDNSPolicyAnnotation]; ok {
+		switch corev1.DNSPolicy(v) {
+		case corev1.DNSClusterFirst, corev1.DNSClusterFirstWithHostNet, corev1.DNSDefault, corev1.DNSNone:
+		default:
+			return fmt.Errorf("invalid value for annotation %s: %q; allowed values are ClusterFirst, ClusterFirstWithHostNet, Default, and None", routerDNSPolicyAnnotation, v)
+		}
+	}
+	nameservers := splitRouterDNSAnnotation(ic, routerDNSNameserversAnnotation)
+	if len(nameservers) > maxRouterDNSNameservers {
+		return fmt.Errorf("invalid value for annotation %s: at most %d nameservers may be specified, got %d", routerDNSNameserversAnnotation, maxRouterDNSNameservers, len(nameservers))
+	}
+	for _, nameserver := range nameservers {
+		if net.ParseIP(nameserver) == nil {
+			return fmt.Errorf("invalid value for annotation %s: %q is not an IP address", routerDNSNameserversAnnotation, nameserver)
+		}
+	}
+	searches := splitRouterDNSAnnotation(ic, routerDNSSearchesAnnotation)
+	if len(searches) > maxRouterDNSSearches {
+		return fmt.Errorf("invalid value for annotation %s: at most %d search domains may be specified, got %d", routerDNSSearchesAnnotation, maxRouterDNSSearches, len(searches))
+	}
+	for _, search := range searches {
+		if msgs := validation.IsDNS1123Subdomain(search); len(msgs) != 0 {
+			return fmt.Errorf("invalid value for annotation %s: %q is not a valid search domain: %s", routerDNSSearchesAnnotation, search, strings.Join(msgs, ", "))
+		}
+	}
+	if routerDNSPolicy(ic) == corev1.DNSNone && len(nameservers) == 0 {
+		return fmt.Errorf("annotation %s is set to None, which requires annotation %s to specify at least one nameserver", routerDNSPolicyAnnotation, routerDNSNameserversAnnotation)
+	}
+	return nil
+}
+
+// applyRouterPodDNS sets the DNS policy and resolver configuration of the
+// given ingresscontroller on the given router deployment's pod template.
+func applyRouterPodDNS(ic *operatorv1.IngressController, deployment *appsv1.Deployment) {
+	deployment.Spec.Template.Spec.DNSPolicy = routerDNSPolicy(ic)
+	deployment.Spec.Template.Spec.DNSConfig = routerDNSConfig(ic)
+}
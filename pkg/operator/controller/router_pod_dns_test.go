@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredRouterDeploymentPodDNS verifies that the router pods use the
+// cluster DNS service by default, including with the HostNetwork strategy,
+// that the router DNS annotations set the pods' DNS policy and resolver
+// configuration, and that changing them updates the deployment.
+func TestDesiredRouterDeploymentPodDNS(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	ingressController := func(strategy operatorv1.EndpointPublishingStrategyType, annotations map[string]string) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: strategy,
+				},
+			},
+		}
+	}
+	testCases := []struct {
+		description  string
+		strategy     operatorv1.EndpointPublishingStrategyType
+		annotations  map[string]string
+		expectPolicy corev1.DNSPolicy
+		expectConfig *corev1.PodDNSConfig
+	}{
+		{
+			description:  "container network default",
+			strategy:     operatorv1.PrivateStrategyType,
+			expectPolicy: corev1.DNSClusterFirst,
+		},
+		{
+			description:  "host network default",
+			strategy:     operatorv1.HostNetworkStrategyType,
+			expectPolicy: corev1.DNSClusterFirstWithHostNet,
+		},
+		{
+			description:  "host network with the node's resolver",
+			strategy:     operatorv1.HostNetworkStrategyType,
+			annotations:  map[string]string{routerDNSPolicyAnnotation: "Default"},
+			expectPolicy: corev1.DNSDefault,
+		},
+		{
+			description: "explicit nameservers and search domains",
+			strategy:    operatorv1.HostNetworkStrategyType,
+			annotations: map[string]string{
+				routerDNSPolicyAnnotation:      "None",
+				routerDNSNameserversAnnotation: "192.0.2.53, 2001:db8::53",
+				routerDNSSearchesAnnotation:    "corp.example.com,",
+			},
+			expectPolicy: corev1.DNSNone,
+			expectConfig: &corev1.PodDNSConfig{
+				Nameservers: []string{"192.0.2.53", "2001:db8::53"},
+				Searches:    []string{"corp.example.com"},
+			},
+		},
+		{
+			description:  "search domains added to cluster DNS",
+			strategy:     operatorv1.PrivateStrategyType,
+			annotations:  map[string]string{routerDNSSearchesAnnotation: "corp.example.com"},
+			expectPolicy: corev1.DNSClusterFirst,
+			expectConfig: &corev1.PodDNSConfig{Searches: []string{"corp.example.com"}},
+		},
+	}
+	for _, tc := range testCases {
+		deployment, err := desiredRouterDeployment(ingressController(tc.strategy, tc.annotations), "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("%s: invalid router Deployment: %v", tc.description, err)
+		}
+		if policy := deployment.Spec.Template.Spec.DNSPolicy; policy != tc.expectPolicy {
+			t.Errorf("%s: expected DNS policy %q, got %q", tc.description, tc.expectPolicy, policy)
+		}
+		if config := deployment.Spec.Template.Spec.DNSConfig; !cmp.Equal(config, tc.expectConfig) {
+			t.Errorf("%s: expected DNS config %#v, got %#v", tc.description, tc.expectConfig, config)
+		}
+	}
+
+	current, err := desiredRouterDeployment(ingressController(operatorv1.HostNetworkStrategyType, nil), "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	desired, err := desiredRouterDeployment(ingressController(operatorv1.HostNetworkStrategyType, map[string]string{routerDNSNameserversAnnotation: "192.0.2.53"}), "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	changed, updated := deploymentConfigChanged(current, desired)
+	if !changed {
+		t.Fatal("expected the deployment to change")
+	}
+	if !cmp.Equal(updated.Spec.Template.Spec.DNSConfig, desired.Spec.Template.Spec.DNSConfig) {
+		t.Errorf("expected the updated deployment to have DNS config %#v, got %#v", desired.Spec.Template.Spec.DNSConfig, updated.Spec.Template.Spec.DNSConfig)
+	}
+	if changed, _ := deploymentConfigChanged(updated, desired); changed {
+		t.Error("expected no further change")
+	}
+}
+
+// TestValidateRouterPodDNS verifies that the router DNS annotations must
+// specify a known DNS policy and a resolver configuration within the API's
+// limits, and that the None policy requires nameservers.
+func TestValidateRouterPodDNS(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		expect      string
+	}{
+		{},
+		{
+			annotations: map[string]string{routerDNSPolicyAnnotation: "ClusterFirstWithHostNet"},
+		},
+		{
+			annotations: map[string]string{
+				routerDNSPolicyAnnotation:      "None",
+				routerDNSNameserversAnnotation: "192.0.2.53",
+				routerDNSSearchesAnnotation:    "corp.example.com",
+			},
+		},
+		{
+			annotations: map[string]string{routerDNSPolicyAnnotation: "clusterfirst"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-dns-policy: "clusterfirst"; allowed values are ClusterFirst, ClusterFirstWithHostNet, Default, and None`,
+		},
+		{
+			annotations: map[string]string{routerDNSPolicyAnnotation: "None"},
+			expect:      "annotation ingress.operator.openshift.io/router-dns-policy is set to None, which requires annotation ingress.operator.openshift.io/router-dns-nameservers to specify at least one nameserver",
+		},
+		{
+			annotations: map[string]string{routerDNSNameserversAnnotation: "dns.example.com"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-dns-nameservers: "dns.example.com" is not an IP address`,
+		},
+		{
+			annotations: map[string]string{routerDNSNameserversAnnotation: "192.0.2.1,192.0.2.2,192.0.2.3,192.0.2.4"},
+			expect:      "invalid value for annotation ingress.operator.openshift.io/router-dns-nameservers: at most 3 nameservers may be specified, got 4",
+		},
+		{
+			annotations: map[string]string{routerDNSSearchesAnnotation: "a.example.com,b.example.com,c.example.com,d.example.com,e.example.com,f.example.com,g.example.com"},
+			expect:      "invalid value for annotation ingress.operator.openshift.io/router-dns-searches: at most 6 search domains may be specified, got 7",
+		},
+		{
+			annotations: map[string]string{routerDNSSearchesAnnotation: "Corp_Example"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-dns-searches: "Corp_Example" is not a valid search domain: a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		err := validateRouterPodDNS(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%v: expected no error, got %v", tc.annotations, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%v: expected error %q, got %v", tc.annotations, tc.expect, err)
+		}
+	}
+}
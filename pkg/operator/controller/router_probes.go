@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// routerProbePathAnnotation specifies the path that the router
+	// container's liveness and readiness probes request on the router's
+	// stats port, for example to go through a proxy that only allows
+	// certain paths.  The path must be absolute and may not have a query
+	// or fragment.  Whatever serves the path must reflect the router's own
+	// health check, /healthz, so that router pods are not marked ready
+	// before the router is serving or kept running when it has failed.
+	// The probes' timing is unchanged.  If unset, the probes request
+	// /healthz.
+	routerProbePathAnnotation = "ingress.operator.openshift.io/router-probe-path"
+
+	// routerProbeSchemeAnnotation specifies the scheme with which the
+	// router container's liveness and readiness probes connect to the
+	// router's stats port.  Allowed values are HTTP and HTTPS.  The
+	// kubelet does not verify the certificate of an HTTPS probe.  If
+	// unset, the probes use HTTP.
+	routerProbeSchemeAnnotation = "ingress.operator.openshift.io/router-probe-scheme"
+)
+
+// validateRouterProbes verifies that the router probe annotations, if set,
+// specify an absolute path and a supported scheme.
+func validateRouterProbes(ic *operatorv1.IngressController) error {
+	if v, ok := ic.Annotations[routerProbePathAnnotation]; ok {
+		u, err := url.Parse(v)
+		if err != nil || !strings.HasPrefix(v, "/") || strings.HasPrefix(v, "//") || len(u.RawQuery) != 0 || len(u.Fragment) != 0 || strings.ContainsAny(v, " \t?#") {
+			return fmt.Errorf("invalid value for annotation %s: %q; must be an absolute path without a query or fragment, such as /healthz", routerProbePathAnnotation, v)
+		}
+	}
+	if v, ok := ic.Annotations[routerProbeSchemeAnnotation]; ok {
+		switch corev1.URIScheme(v) {
+		case corev1.URISchemeHTTP, corev1.URISchemeHTTPS:
+		default:
+			return fmt.Errorf("invalid value for annotation %s: %q; allowed values are %s and %s", routerProbeSchemeAnnotation, v, corev1.URISchemeHTTP, corev1.URISchemeHTTPS)
+		}
+	}
+	return nil
+}
+
+// applyRouterProbes sets the path and scheme that the given ingresscontroller
+// specifies on the given router deployment's liveness and readiness probes.
+func applyRouterProbes(ic *operatorv1.IngressController, deployment *appsv1.Deployment) {
+	path, hasPath := ic.Annotations[routerProbePathAnnotation]
+	scheme, hasScheme := ic.Annotations[routerProbeSchemeAnnotation]
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe} {
+		if probe == nil || probe.Handler.HTTPGet == nil {
+			continue
+		}
+		if hasPath {
+			probe.Handler.HTTPGet.Path = path
+		}
+		if hasScheme {
+			probe.Handler.HTTPGet.Scheme = corev1.URIScheme(scheme)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredRouterDeploymentProbes verifies that the probe path and scheme
+// annotations are applied to both router probes, that the probes keep
+// /healthz over HTTP if they are unset, and that changing them updates the
+// deployment.
+func TestDesiredRouterDeploymentProbes(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.HostNetworkStrategyType,
+			},
+		},
+	}
+	checkProbes := func(deployment *appsv1.Deployment, path string, scheme corev1.URIScheme) {
+		t.Helper()
+		container := deployment.Spec.Template.Spec.Containers[0]
+		for name, probe := range map[string]*corev1.Probe{"liveness": container.LivenessProbe, "readiness": container.ReadinessProbe} {
+			get := probe.Handler.HTTPGet
+			if get.Path != path || get.Scheme != scheme || get.Port.IntValue() != 1936 || get.Host != "localhost" {
+				t.Errorf("expected the %s probe to request %s %s on localhost:1936, got %s %s on %s:%s", name, scheme, path, get.Scheme, get.Path, get.Host, get.Port.String())
+			}
+		}
+	}
+
+	defaults, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	checkProbes(defaults, "/healthz", "")
+
+	ci.Annotations = map[string]string{
+		routerProbePathAnnotation:   "/proxy/healthz",
+		routerProbeSchemeAnnotation: "HTTPS",
+	}
+	custom, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	checkProbes(custom, "/proxy/healthz", corev1.URISchemeHTTPS)
+	changed, updated := deploymentConfigChanged(defaults, custom)
+	if !changed {
+		t.Fatal("expected setting the probe path and scheme to change the deployment")
+	}
+	checkProbes(updated, "/proxy/healthz", corev1.URISchemeHTTPS)
+
+	// The API server defaults the scheme to HTTP, which is not a change.
+	ci.Annotations = map[string]string{routerProbeSchemeAnnotation: "HTTP"}
+	explicitHTTP, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if changed, _ := deploymentConfigChanged(defaults, explicitHTTP); changed {
+		t.Error("expected an explicit HTTP scheme not to change the deployment")
+	}
+}
+
+// TestValidateRouterProbes verifies that the probe path must be an absolute
+// path and the scheme HTTP or HTTPS, with a message that names the annotation.
+func TestValidateRouterProbes(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		expect      string
+	}{
+		{
+			annotations: map[string]string{routerProbePathAnnotation: "/healthz/ready", routerProbeSchemeAnnotation: "HTTPS"},
+		},
+		{
+			annotations: map[string]string{routerProbePathAnnotation: "healthz"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-probe-path: "healthz"; must be an absolute path without a query or fragment, such as /healthz`,
+		},
+		{
+			annotations: map[string]string{routerProbePathAnnotation: "/healthz?verbose=1"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-probe-path: "/healthz?verbose=1"; must be an absolute path without a query or fragment, such as /healthz`,
+		},
+		{
+			annotations: map[string]string{routerProbePathAnnotation: "//proxy.example.com/healthz"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-probe-path: "//proxy.example.com/healthz"; must be an absolute path without a query or fragment, such as /healthz`,
+		},
+		{
+			annotations: map[string]string{routerProbeSchemeAnnotation: "https"},
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-probe-scheme: "https"; allowed values are HTTP and HTTPS`,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		err := validateRouterProbes(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%v: expected no error, got %v", tc.annotations, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%v: expected error %q, got %v", tc.annotations, tc.expect, err)
+		}
+	}
+}
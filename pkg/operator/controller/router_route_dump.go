@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// dumpRouteConfigAnnotation, when set on an ingresscontroller to the
+	// namespace and name of a route in the form "namespace/name", makes the
+	// operator scrape the route's backend and server metrics from the stats
+	// endpoint of each running router pod and write them to the route dump
+	// configmap in the operand namespace, where only users who may read
+	// configmaps in that namespace can see them.  The metrics show the
+	// backend that the router renders for the route, which reflects the
+	// route's termination type, and the servers, or endpoints, behind it.
+	// The router configuration itself, which includes certificates and keys,
+	// is not dumped.  The operator removes the annotation once the dump has
+	// been written.  Metrics integration must be enabled.
+	dumpRouteConfigAnnotation = "ingress.operator.openshift.io/dump-route-config"
+
+	// routeDumpRouteKey is the key of the route dump configmap with the
+	// namespace and name of the dumped route.
+	routeDumpRouteKey = "route"
+
+	// routeDumpKey is the key of the route dump configmap with the dump.
+	routeDumpKey = "dump"
+)
+
+// parseRouteName parses a route name of the form "namespace/name".
+func parseRouteName(v string) (string, string, error) {
+	parts := strings.Split(v, "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("%q is not of the form namespace/name", v)
+	}
+	if errs := validation.IsDNS1123Label(parts[0]); len(errs) != 0 {
+		return "", "", fmt.Errorf("%q is not a valid namespace: %s", parts[0], strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Subdomain(parts[1]); len(errs) != 0 {
+		return "", "", fmt.Errorf("%q is not a valid route name: %s", parts[1], strings.Join(errs, ", "))
+	}
+	return parts[0], parts[1], nil
+}
+
+// filterRouteMetrics returns the router metrics in the prometheus text format
+// from the given metrics that have the given route's namespace and name as
+// their namespace and route labels.
+func filterRouteMetrics(metrics io.Reader, namespace, route string) (string, error) {
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(metrics)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse metrics: %v", err)
+	}
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := &bytes.Buffer{}
+	for _, name := range names {
+		family := families[name]
+		matched := []*dto.Metric{}
+		for _, metric := range family.Metric {
+			labels := map[string]string{}
+			for _, label := range metric.Label {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == namespace && labels["route"] == route {
+				matched = append(matched, metric)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		filtered := &dto.MetricFamily{Name: family.Name, Help: family.Help, Type: family.Type, Metric: matched}
+		if _, err := expfmt.MetricFamilyToText(out, filtered); err != nil {
+			return "", fmt.Errorf("failed to format metrics: %v", err)
+		}
+	}
+	return out.String(), nil
+}
+
+// ensureRouteConfigDump writes the dump of the route that the given
+// ingresscontroller's dump route config annotation names, if any, to the route
+// dump configmap and removes the annotation.
+func (r *reconciler) ensureRouteConfigDump(ic *operatorv1.IngressController, pods []corev1.Pod, deploymentRef metav1.OwnerReference) error {
+	v, ok := ic.Annotations[dumpRouteConfigAnnotation]
+	if !ok {
+		return nil
+	}
+	namespace, route, err := parseRouteName(v)
+	if err != nil {
+		return fmt.Errorf("invalid value for annotation %s: %v", dumpRouteConfigAnnotation, err)
+	}
+	client, username, password, err := r.routerStatsClient(ic)
+	if err != nil {
+		return fmt.Errorf("cannot scrape the router stats endpoint: %v", err)
+	}
+	running := runningRouterPods(pods)
+	if len(running) == 0 {
+		return fmt.Errorf("no router pod is running")
+	}
+	dump := &bytes.Buffer{}
+	scraped := false
+	for _, pod := range running {
+		metrics, err := getRouterMetrics(client, routerStatsURL(pod), username, password)
+		if err != nil {
+			log.V(1).Info("failed to scrape router stats", "namespace", pod.Namespace, "name", pod.Name, "error", err)
+			fmt.Fprintf(dump, "# router pod %s: stats unavailable\n", pod.Name)
+			continue
+		}
+		routeMetrics, err := filterRouteMetrics(bytes.NewReader(metrics), namespace, route)
+		if err != nil {
+			fmt.Fprintf(dump, "# router pod %s: %v\n", pod.Name, err)
+			continue
+		}
+		scraped = true
+		if len(routeMetrics) == 0 {
+			fmt.Fprintf(dump, "# router pod %s: no backend for route %s/%s\n", pod.Name, namespace, route)
+			continue
+		}
+		fmt.Fprintf(dump, "# router pod %s\n%s", pod.Name, routeMetrics)
+	}
+	if !scraped {
+		return fmt.Errorf("cannot scrape the stats endpoint of any router pod")
+	}
+	if err := r.writeRouteConfigDump(ic, namespace+"/"+route, dump.String(), deploymentRef); err != nil {
+		return err
+	}
+	return r.removeIngressControllerAnnotation(ic, dumpRouteConfigAnnotation)
+}
+
+// writeRouteConfigDump creates or updates the route dump configmap of the given
+// ingresscontroller with the given dump of the given route.
+func (r *reconciler) writeRouteConfigDump(ic *operatorv1.IngressController, route, dump string, deploymentRef metav1.OwnerReference) error {
+	name := RouteConfigDumpConfigMapName(ic, r.OperandNamespace)
+	desired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: ic.Name,
+			},
+		},
+		Data: map[string]string{
+			routeDumpRouteKey: route,
+			routeDumpKey:      dump,
+		},
+	}
+	desired.SetOwnerReferences([]metav1.OwnerReference{deploymentRef})
+	applyOperandMetadata(desired, r.OperandLabels, r.OperandAnnotations)
+
+	current := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get route dump configmap %s: %v", name, err)
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create route dump configmap %s: %v", name, err)
+		}
+		stepLogger(ic, "route-dump").Info("created route dump configmap", "namespace", name.Namespace, "name", name.Name, "route", route)
+		return nil
+	}
+	updated := current.DeepCopy()
+	updateOperandMetadata(updated, desired, r.OperandLabels, r.OperandAnnotations)
+	updated.Data = desired.Data
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update route dump configmap %s: %v", name, err)
+	}
+	stepLogger(ic, "route-dump").Info("updated route dump configmap", "namespace", name.Namespace, "name", name.Name, "route", route)
+	return nil
+}
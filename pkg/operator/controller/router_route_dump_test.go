@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestFilterRouteMetrics verifies that only the metrics of the given route are
+// dumped.
+func TestFilterRouteMetrics(t *testing.T) {
+	metrics := `# HELP haproxy_backend_up Current health status of the backend (1 = UP, 0 = DOWN).
+# TYPE haproxy_backend_up gauge
+haproxy_backend_up{backend="https",namespace="shop",route="frontend"} 1
+haproxy_backend_up{backend="http",namespace="shop",route="api"} 1
+# HELP haproxy_server_up Current health status of the server (1 = UP, 0 = DOWN).
+# TYPE haproxy_server_up gauge
+haproxy_server_up{namespace="shop",pod="frontend-1",route="frontend",server="10.128.0.10:8443",service="frontend"} 1
+haproxy_server_up{namespace="other",pod="frontend-1",route="frontend",server="10.128.0.11:8443",service="frontend"} 0
+# HELP process_start_time_seconds Start time of the process since unix epoch in seconds.
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 1.5593904e+09
+`
+	expect := `# HELP haproxy_backend_up Current health status of the backend (1 = UP, 0 = DOWN).
+# TYPE haproxy_backend_up gauge
+haproxy_backend_up{backend="https",namespace="shop",route="frontend"} 1
+# HELP haproxy_server_up Current health status of the server (1 = UP, 0 = DOWN).
+# TYPE haproxy_server_up gauge
+haproxy_server_up{namespace="shop",pod="frontend-1",route="frontend",server="10.128.0.10:8443",service="frontend"} 1
+`
+	actual, err := filterRouteMetrics(strings.NewReader(metrics), "shop", "frontend")
+	if err != nil {
+		t.Fatalf("failed to filter route metrics: %v", err)
+	}
+	if actual != expect {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, actual)
+	}
+	if actual, err := filterRouteMetrics(strings.NewReader(metrics), "shop", "missing"); err != nil || len(actual) != 0 {
+		t.Errorf("expected no metrics for a route without a backend, got %q, %v", actual, err)
+	}
+}
+
+// TestEnsureRouteConfigDump verifies that a dump that cannot be scraped fails
+// without removing the annotation, and that the dump configmap is created and
+// updated.
+func TestEnsureRouteConfigDump(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "openshift-ingress-operator",
+			Name:        "default",
+			Annotations: map[string]string{dumpRouteConfigAnnotation: "shop/frontend"},
+		},
+	}
+	deploymentRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "router-default", UID: "1"}
+	client := newFakeClient(ic)
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress"},
+		client: client,
+	}
+	pods := []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "127.0.0.1"},
+	}}
+
+	expectErr := "cannot scrape the router stats endpoint: router stats secret openshift-ingress/router-stats-default does not exist"
+	if err := r.ensureRouteConfigDump(ic, pods, deploymentRef); err == nil || err.Error() != expectErr {
+		t.Errorf("expected error %q, got %v", expectErr, err)
+	}
+	if _, ok := ic.Annotations[dumpRouteConfigAnnotation]; !ok {
+		t.Error("expected the annotation to be kept after a failed dump")
+	}
+	name := RouteConfigDumpConfigMapName(ic, "openshift-ingress")
+	if err := client.Get(context.TODO(), name, &corev1.ConfigMap{}); err == nil {
+		t.Error("expected no route dump configmap after a failed dump")
+	}
+
+	for _, dump := range []string{"# router pod router-default-1\nfirst\n", "# router pod router-default-1\nsecond\n"} {
+		if err := r.writeRouteConfigDump(ic, "shop/frontend", dump, deploymentRef); err != nil {
+			t.Fatalf("failed to write route dump: %v", err)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Namespace: name.Namespace, Name: name.Name}, cm); err != nil {
+			t.Fatalf("failed to get route dump configmap: %v", err)
+		}
+		if cm.Data[routeDumpRouteKey] != "shop/frontend" || cm.Data[routeDumpKey] != dump {
+			t.Errorf("expected route dump configmap with route shop/frontend and dump %q, got %v", dump, cm.Data)
+		}
+		if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0] != deploymentRef {
+			t.Errorf("expected route dump configmap to be owned by the router deployment, got %v", cm.OwnerReferences)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// routerSecurityContextAnnotation specifies a comma-separated list of
+	// settings that harden the security context of the router container,
+	// such as "drop-capabilities,read-only-root-filesystem".  The allowed
+	// settings are:
+	//
+	// drop-capabilities drops all capabilities except NET_BIND_SERVICE,
+	// which the router needs to bind ports 80 and 443.
+	//
+	// no-privilege-escalation disallows privilege escalation.  The router
+	// then cannot gain NET_BIND_SERVICE from its binary's file
+	// capabilities, so the setting requires the router sysctls annotation
+	// to set net.ipv4.ip_unprivileged_port_start to 80 or lower, and it
+	// cannot be used with the HostNetwork strategy, whose pods cannot set
+	// that sysctl.
+	//
+	// read-only-root-filesystem mounts the router's root filesystem
+	// read-only.  The directories to which the router writes its
+	// configuration and state are mounted from empty directories; an init
+	// container copies the configuration templates from the router image
+	// into the configuration directory.
+	//
+	// If unset, the router container has no security context of its own.
+	routerSecurityContextAnnotation = "ingress.operator.openshift.io/router-security-context"
+
+	// routerSecurityContextFailedReason is the reason of the Degraded
+	// condition when router containers fail because of the hardened
+	// security context.
+	routerSecurityContextFailedReason = "RouterSecurityContextFailed"
+
+	// routerConfigDir is the directory in the router image with the
+	// configuration templates, to which the router also writes its
+	// generated configuration.
+	routerConfigDir = "/var/lib/haproxy/conf"
+
+	// routerConfigTemplatesMountPath is where the init container mounts the
+	// router's writable configuration directory while it copies the
+	// configuration templates into it.
+	routerConfigTemplatesMountPath = "/var/lib/haproxy/conf-writable"
+
+	// unprivilegedPortStartSysctl is the sysctl with the lowest port that
+	// processes without NET_BIND_SERVICE may bind.
+	unprivilegedPortStartSysctl = "net.ipv4.ip_unprivileged_port_start"
+)
+
+// Router security context settings.
+const (
+	dropCapabilitiesSetting       = "drop-capabilities"
+	noPrivilegeEscalationSetting  = "no-privilege-escalation"
+	readOnlyRootFilesystemSetting = "read-only-root-filesystem"
+)
+
+// routerSecurityContextSettings are the allowed settings of
+// routerSecurityContextAnnotation.
+var routerSecurityContextSettings = []string{dropCapabilitiesSetting, noPrivilegeEscalationSetting, readOnlyRootFilesystemSetting}
+
+// routerWritableDirs maps the names of the empty directory volumes that are
+// mounted when the router's root filesystem is read-only to the directories to
+// which the router writes.
+var routerWritableDirs = map[string]string{
+	"haproxy-config": routerConfigDir,
+	"haproxy-router": "/var/lib/haproxy/router",
+	"haproxy-run":    "/var/lib/haproxy/run",
+	"tmp":            "/tmp",
+}
+
+// parseRouterSecurityContext parses the value of the router security context
+// annotation into a set of settings.
+func parseRouterSecurityContext(v string) (map[string]bool, error) {
+	settings := map[string]bool{}
+	for _, setting := range strings.Split(v, ",") {
+		setting = strings.TrimSpace(setting)
+		if len(setting) == 0 {
+			continue
+		}
+		allowed := false
+		for _, s := range routerSecurityContextSettings {
+			if setting == s {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("unknown setting %q; allowed settings are %s", setting, strings.Join(routerSecurityContextSettings, ", "))
+		}
+		if settings[setting] {
+			return nil, fmt.Errorf("setting %s is specified more than once", setting)
+		}
+		settings[setting] = true
+	}
+	return settings, nil
+}
+
+// routerSecurityContext returns the given ingresscontroller's router security
+// context settings.  An annotation that cannot be parsed yields no settings;
+// validation rejects such values.
+func routerSecurityContext(ic *operatorv1.IngressController) map[string]bool {
+	v, ok := ic.Annotations[routerSecurityContextAnnotation]
+	if !ok {
+		return nil
+	}
+	settings, err := parseRouterSecurityContext(v)
+	if err != nil {
+		return nil
+	}
+	return settings
+}
+
+// validateRouterSecurityContext verifies that the router security context
+// annotation, if set, only has known settings and that the router can still
+// bind its ports with them.
+func validateRouterSecurityContext(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[routerSecurityContextAnnotation]
+	if !ok {
+		return nil
+	}
+	settings, err := parseRouterSecurityContext(v)
+	if err != nil {
+		return fmt.Errorf("invalid value for annotation %s: %v", routerSecurityContextAnnotation, err)
+	}
+	if !settings[noPrivilegeEscalationSetting] {
+		return nil
+	}
+	if usesHostNetwork(ic) {
+		return fmt.Errorf("annotation %s: setting %s cannot be used with the %s endpoint publishing strategy; the router could not bind ports 80 and 443", routerSecurityContextAnnotation, noPrivilegeEscalationSetting, operatorv1.HostNetworkStrategyType)
+	}
+	for _, sysctl := range routerSysctls(ic) {
+		if sysctl.Name != unprivilegedPortStartSysctl {
+			continue
+		}
+		if port, err := strconv.Atoi(sysctl.Value); err == nil && port <= 80 {
+			return nil
+		}
+	}
+	return fmt.Errorf("annotation %s: setting %s requires annotation %s to set %s to 80 or lower; otherwise the router could not bind ports 80 and 443", routerSecurityContextAnnotation, noPrivilegeEscalationSetting, routerSysctlsAnnotation, unprivilegedPortStartSysctl)
+}
+
+// applyRouterSecurityContext applies the given security context settings to
+// the given router deployment.  The router container's image must already be
+// set.
+func applyRouterSecurityContext(deployment *appsv1.Deployment, settings map[string]bool) {
+	if len(settings) == 0 {
+		return
+	}
+	podSpec := &deployment.Spec.Template.Spec
+	container := &podSpec.Containers[0]
+	securityContext := &corev1.SecurityContext{}
+	if settings[dropCapabilitiesSetting] {
+		securityContext.Capabilities = &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+			Add:  []corev1.Capability{"NET_BIND_SERVICE"},
+		}
+	}
+	if settings[noPrivilegeEscalationSetting] {
+		allowPrivilegeEscalation := false
+		securityContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	}
+	if settings[readOnlyRootFilesystemSetting] {
+		readOnlyRootFilesystem := true
+		securityContext.ReadOnlyRootFilesystem = &readOnlyRootFilesystem
+		names := make([]string, 0, len(routerWritableDirs))
+		for name := range routerWritableDirs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+				Name:         name,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			})
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      name,
+				MountPath: routerWritableDirs[name],
+			})
+		}
+		podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+			Name:    "copy-router-config",
+			Image:   container.Image,
+			Command: []string{"/bin/sh", "-c", fmt.Sprintf("cp -a %s/. %s/", routerConfigDir, routerConfigTemplatesMountPath)},
+			VolumeMounts: []corev1.VolumeMount{{
+				Name:      "haproxy-config",
+				MountPath: routerConfigTemplatesMountPath,
+			}},
+			SecurityContext:          securityContext.DeepCopy(),
+			ImagePullPolicy:          container.ImagePullPolicy,
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		})
+	}
+	container.SecurityContext = securityContext
+}
+
+// routerSecurityContextEqual returns a Boolean indicating whether the given
+// router deployments have the same router security context, including the
+// init containers that the read-only root filesystem setting adds.  Only the
+// init container fields that the operator sets are compared, as the API
+// defaults the others.
+func routerSecurityContextEqual(a, b *appsv1.Deployment) bool {
+	if !cmp.Equal(a.Spec.Template.Spec.Containers[0].SecurityContext, b.Spec.Template.Spec.Containers[0].SecurityContext, cmpopts.EquateEmpty()) {
+		return false
+	}
+	if len(a.Spec.Template.Spec.InitContainers) != len(b.Spec.Template.Spec.InitContainers) {
+		return false
+	}
+	for i := range a.Spec.Template.Spec.InitContainers {
+		ac, bc := a.Spec.Template.Spec.InitContainers[i], b.Spec.Template.Spec.InitContainers[i]
+		if ac.Name != bc.Name || ac.Image != bc.Image ||
+			!cmp.Equal(ac.Command, bc.Command, cmpopts.EquateEmpty()) ||
+			!cmp.Equal(ac.VolumeMounts, bc.VolumeMounts, cmpopts.EquateEmpty()) ||
+			!cmp.Equal(ac.SecurityContext, bc.SecurityContext, cmpopts.EquateEmpty()) {
+			return false
+		}
+	}
+	return true
+}
+
+// routerSecurityContextFailedPods returns the names of the given router pods
+// whose router container, or init container, last exited because it could not
+// write to the read-only root filesystem or bind its ports, along with the
+// exit message of the first such pod, if the ingresscontroller hardens the
+// router's security context.
+func routerSecurityContextFailedPods(ic *operatorv1.IngressController, pods []corev1.Pod) ([]string, string) {
+	if len(routerSecurityContext(ic)) == 0 {
+		return nil, ""
+	}
+	failed := []string{}
+	message := ""
+	for _, pod := range pods {
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			terminated := status.LastTerminationState.Terminated
+			if terminated == nil && status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 {
+				terminated = status.State.Terminated
+			}
+			if status.Ready || terminated == nil {
+				continue
+			}
+			if !strings.Contains(terminated.Message, "Read-only file system") && !strings.Contains(terminated.Message, "cannot bind socket") {
+				continue
+			}
+			failed = append(failed, pod.Name)
+			if len(message) == 0 {
+				message = strings.TrimSpace(terminated.Message)
+			}
+			break
+		}
+	}
+	return failed, message
+}
@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredRouterDeploymentSecurityContext verifies that the router security
+// context settings are applied to the router container, that the read-only
+// root filesystem setting adds writable directories and the init container
+// that populates the configuration directory, and that changing the settings
+// updates the deployment.
+func TestDesiredRouterDeploymentSecurityContext(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	withoutSettings, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if sc := withoutSettings.Spec.Template.Spec.Containers[0].SecurityContext; sc != nil {
+		t.Errorf("expected no router security context without annotation %s, got %#v", routerSecurityContextAnnotation, sc)
+	}
+
+	ci.Annotations = map[string]string{routerSecurityContextAnnotation: "drop-capabilities, read-only-root-filesystem"}
+	withSettings, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	readOnly := true
+	expect := &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+			Add:  []corev1.Capability{"NET_BIND_SERVICE"},
+		},
+		ReadOnlyRootFilesystem: &readOnly,
+	}
+	if sc := withSettings.Spec.Template.Spec.Containers[0].SecurityContext; !cmp.Equal(sc, expect) {
+		t.Errorf("expected router security context %#v, got %#v", expect, sc)
+	}
+	mounts := map[string]string{}
+	for _, mount := range withSettings.Spec.Template.Spec.Containers[0].VolumeMounts {
+		mounts[mount.Name] = mount.MountPath
+	}
+	for name, path := range map[string]string{"haproxy-config": "/var/lib/haproxy/conf", "haproxy-router": "/var/lib/haproxy/router", "haproxy-run": "/var/lib/haproxy/run", "tmp": "/tmp"} {
+		if mounts[name] != path {
+			t.Errorf("expected volume %s to be mounted at %s, got %q", name, path, mounts[name])
+		}
+	}
+	initContainers := withSettings.Spec.Template.Spec.InitContainers
+	if len(initContainers) != 1 {
+		t.Fatalf("expected 1 init container, got %d", len(initContainers))
+	}
+	if initContainers[0].Image != "quay.io/openshift/router:latest" {
+		t.Errorf("expected the init container to use the router image, got %q", initContainers[0].Image)
+	}
+	expectCommand := []string{"/bin/sh", "-c", "cp -a /var/lib/haproxy/conf/. /var/lib/haproxy/conf-writable/"}
+	if !cmp.Equal(initContainers[0].Command, expectCommand) {
+		t.Errorf("expected init container command %q, got %q", expectCommand, initContainers[0].Command)
+	}
+
+	changed, updated := deploymentConfigChanged(withoutSettings, withSettings)
+	if !changed {
+		t.Fatal("expected setting the router security context to change the deployment")
+	}
+	if changedAgain, _ := deploymentConfigChanged(updated, withSettings); changedAgain {
+		t.Error("deploymentConfigChanged does not behave as a fixed point function")
+	}
+	// The API defaults init container fields that the operator does not
+	// set; this must not be considered a change.
+	updated.Spec.Template.Spec.InitContainers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+	if changedAgain, _ := deploymentConfigChanged(updated, withSettings); changedAgain {
+		t.Error("expected defaulted init container fields not to change the deployment")
+	}
+
+	if changed, reverted := deploymentConfigChanged(updated, withoutSettings); !changed {
+		t.Error("expected removing the router security context to change the deployment")
+	} else if sc := reverted.Spec.Template.Spec.Containers[0].SecurityContext; sc != nil || len(reverted.Spec.Template.Spec.InitContainers) != 0 {
+		t.Errorf("expected no router security context or init containers, got %#v and %d init containers", sc, len(reverted.Spec.Template.Spec.InitContainers))
+	}
+}
+
+// TestValidateRouterSecurityContext verifies that unknown settings, and
+// settings with which the router could not bind its ports, are rejected with a
+// message that names the problem.
+func TestValidateRouterSecurityContext(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		strategy    operatorv1.EndpointPublishingStrategyType
+		expect      string
+	}{
+		{
+			annotations: map[string]string{routerSecurityContextAnnotation: "drop-capabilities,read-only-root-filesystem"},
+			strategy:    operatorv1.HostNetworkStrategyType,
+		},
+		{
+			annotations: map[string]string{
+				routerSecurityContextAnnotation: "no-privilege-escalation",
+				routerSysctlsAnnotation:         "net.ipv4.ip_unprivileged_port_start=80",
+			},
+			strategy: operatorv1.LoadBalancerServiceStrategyType,
+		},
+		{
+			annotations: map[string]string{routerSecurityContextAnnotation: "run-as-root"},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expect:      `invalid value for annotation ingress.operator.openshift.io/router-security-context: unknown setting "run-as-root"; allowed settings are drop-capabilities, no-privilege-escalation, read-only-root-filesystem`,
+		},
+		{
+			annotations: map[string]string{routerSecurityContextAnnotation: "drop-capabilities,drop-capabilities"},
+			strategy:    operatorv1.LoadBalancerServiceStrategyType,
+			expect:      "invalid value for annotation ingress.operator.openshift.io/router-security-context: setting drop-capabilities is specified more than once",
+		},
+		{
+			annotations: map[string]string{routerSecurityContextAnnotation: "no-privilege-escalation"},
+			strategy:    operatorv1.HostNetworkStrategyType,
+			expect:      "annotation ingress.operator.openshift.io/router-security-context: setting no-privilege-escalation cannot be used with the HostNetwork endpoint publishing strategy; the router could not bind ports 80 and 443",
+		},
+		{
+			annotations: map[string]string{
+				routerSecurityContextAnnotation: "no-privilege-escalation",
+				routerSysctlsAnnotation:         "net.ipv4.ip_unprivileged_port_start=1024",
+			},
+			strategy: operatorv1.LoadBalancerServiceStrategyType,
+			expect:   "annotation ingress.operator.openshift.io/router-security-context: setting no-privilege-escalation requires annotation ingress.operator.openshift.io/router-sysctls to set net.ipv4.ip_unprivileged_port_start to 80 or lower; otherwise the router could not bind ports 80 and 443",
+		},
+	}
+	for _, tc := range testCases {
+		ic := ingressController("default", tc.strategy)
+		ic.Annotations = tc.annotations
+		err := validateRouterSecurityContext(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%v with %s: expected no error, got %v", tc.annotations, tc.strategy, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%v with %s: expected error %q, got %v", tc.annotations, tc.strategy, tc.expect, err)
+		}
+	}
+}
+
+// TestComputeIngressDegradedConditionRouterSecurityContextFailed verifies that
+// router pods that fail because of the hardened security context mark the
+// ingresscontroller degraded.
+func TestComputeIngressDegradedConditionRouterSecurityContextFailed(t *testing.T) {
+	ic := ingressController("default", operatorv1.PrivateStrategyType)
+	ic.Annotations = map[string]string{routerSecurityContextAnnotation: "read-only-root-filesystem"}
+	pods := []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "router-default-1"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name: "copy-router-config",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 0},
+				},
+			}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "router",
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Message:  "error: open /var/lib/haproxy/log/haproxy.log: read-only file system: Read-only file system\n",
+					},
+				},
+			}},
+		},
+	}}
+	actual := computeIngressDegradedCondition(ic, pods)
+	expectMessage := "router pod router-default-1 fails with the security context from annotation ingress.operator.openshift.io/router-security-context: error: open /var/lib/haproxy/log/haproxy.log: read-only file system: Read-only file system; relax the settings in the annotation"
+	if actual.Status != operatorv1.ConditionTrue || actual.Reason != routerSecurityContextFailedReason || actual.Message != expectMessage {
+		t.Errorf("expected Degraded=True with reason %s and message %q, got %s, %s, %q", routerSecurityContextFailedReason, expectMessage, actual.Status, actual.Reason, actual.Message)
+	}
+
+	ic.Annotations = nil
+	if actual := computeIngressDegradedCondition(ic, pods); actual.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected Degraded=False without a hardened security context, got %s, %s, %q", actual.Status, actual.Reason, actual.Message)
+	}
+}
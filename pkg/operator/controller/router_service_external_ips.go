@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// serviceExternalIPsAnnotation specifies a comma-separated list of IP
+// addresses, such as virtual IPs that are routed to the cluster's nodes, on
+// which the router is reachable.  They are set as spec.externalIPs of the
+// ingresscontroller's load balancer service if the ingresscontroller is
+// published by a load balancer, and of its internal service otherwise.  If the
+// ingresscontroller is published by a load balancer, its DNS records point at
+// these addresses instead of at the load balancer, which is useful on bare
+// metal, where no load balancer may ever be provisioned.  The cluster's
+// external IP policy must allow the addresses; otherwise the API rejects the
+// service.  If unset, the services have no external IPs.
+const serviceExternalIPsAnnotation = "ingress.operator.openshift.io/service-external-ips"
+
+// serviceExternalIPs returns the external IPs that the given
+// ingresscontroller specifies for its router service, in order and without
+// duplicates, or nil if it specifies none.
+func serviceExternalIPs(ic *operatorv1.IngressController) []string {
+	var ips []string
+	for _, v := range strings.Split(ic.Annotations[serviceExternalIPsAnnotation], ",") {
+		ip := net.ParseIP(strings.TrimSpace(v))
+		if ip == nil {
+			continue
+		}
+		duplicate := false
+		for _, other := range ips {
+			if other == ip.String() {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			ips = append(ips, ip.String())
+		}
+	}
+	return ips
+}
+
+// validateServiceExternalIPs verifies that the service external IPs
+// annotation, if set, lists only IP addresses that can be external IPs of a
+// service.
+func validateServiceExternalIPs(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[serviceExternalIPsAnnotation]
+	if !ok {
+		return nil
+	}
+	if len(strings.TrimSpace(v)) == 0 {
+		return fmt.Errorf("invalid value for annotation %s: %q; must list at least one IP address", serviceExternalIPsAnnotation, v)
+	}
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		ip := net.ParseIP(s)
+		switch {
+		case ip == nil:
+			return fmt.Errorf("invalid value for annotation %s: %q is not an IP address", serviceExternalIPsAnnotation, s)
+		case ip.IsUnspecified(), ip.IsLoopback(), ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast(), ip.IsMulticast():
+			return fmt.Errorf("invalid value for annotation %s: %q may not be an unspecified, loopback, link-local, or multicast address", serviceExternalIPsAnnotation, s)
+		}
+	}
+	return nil
+}
+
+// applyServiceExternalIPs sets the external IPs that the given
+// ingresscontroller specifies on the given router service.
+func applyServiceExternalIPs(ic *operatorv1.IngressController, service *corev1.Service) {
+	service.Spec.ExternalIPs = serviceExternalIPs(ic)
+}
+
+// serviceExternalIPsEqual returns a Boolean indicating whether the given
+// services have the same external IPs.
+func serviceExternalIPsEqual(a, b *corev1.Service) bool {
+	return strings.Join(a.Spec.ExternalIPs, ",") == strings.Join(b.Spec.ExternalIPs, ",")
+}
+
+// serviceAddresses returns the addresses at which the given router service is
+// reachable from outside the cluster: its external IPs if it has any, and the
+// addresses of its load balancer otherwise.
+func serviceAddresses(service *corev1.Service) []corev1.LoadBalancerIngress {
+	if len(service.Spec.ExternalIPs) == 0 {
+		return service.Status.LoadBalancer.Ingress
+	}
+	addresses := make([]corev1.LoadBalancerIngress, 0, len(service.Spec.ExternalIPs))
+	for _, ip := range service.Spec.ExternalIPs {
+		addresses = append(addresses, corev1.LoadBalancerIngress{IP: ip})
+	}
+	return addresses
+}
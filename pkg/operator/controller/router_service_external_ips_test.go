@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestEnsureLoadBalancerServiceExternalIPs verifies that the external IPs are
+// set on the load balancer service, that changing them updates the service,
+// and that the internal service does not get them while there is a load
+// balancer service.
+func TestEnsureLoadBalancerServiceExternalIPs(t *testing.T) {
+	ic := ingressController("default", operatorv1.LoadBalancerServiceStrategyType)
+	ic.Annotations = map[string]string{serviceExternalIPsAnnotation: "192.0.2.10, 2001:db8::10,192.0.2.10"}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.BareMetalPlatformType,
+		},
+	}
+	client := newFakeClient()
+	r := &reconciler{Config: Config{OperandNamespace: "openshift-ingress"}, client: client}
+
+	service, err := r.ensureLoadBalancerService(ic, metav1.OwnerReference{}, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to ensure load balancer service: %v", err)
+	}
+	if expect := []string{"192.0.2.10", "2001:db8::10"}; !cmp.Equal(service.Spec.ExternalIPs, expect) {
+		t.Errorf("expected external IPs %v, got %v", expect, service.Spec.ExternalIPs)
+	}
+
+	ic.Annotations[serviceExternalIPsAnnotation] = "192.0.2.11"
+	if service, err = r.ensureLoadBalancerService(ic, metav1.OwnerReference{}, infraConfig); err != nil {
+		t.Fatalf("failed to ensure load balancer service: %v", err)
+	}
+	if expect := []string{"192.0.2.11"}; !cmp.Equal(service.Spec.ExternalIPs, expect) {
+		t.Errorf("expected external IPs %v after the annotation changed, got %v", expect, service.Spec.ExternalIPs)
+	}
+	if client.calls["update"] != 1 {
+		t.Errorf("expected the service to be updated once, got %d updates", client.calls["update"])
+	}
+
+	delete(ic.Annotations, serviceExternalIPsAnnotation)
+	if service, err = r.ensureLoadBalancerService(ic, metav1.OwnerReference{}, infraConfig); err != nil {
+		t.Fatalf("failed to ensure load balancer service: %v", err)
+	}
+	if len(service.Spec.ExternalIPs) != 0 {
+		t.Errorf("expected no external IPs after the annotation was removed, got %v", service.Spec.ExternalIPs)
+	}
+
+	ic.Annotations[serviceExternalIPsAnnotation] = "192.0.2.10"
+	if internal := desiredInternalIngressControllerService(ic, "openshift-ingress", metav1.OwnerReference{}); len(internal.Spec.ExternalIPs) != 0 {
+		t.Errorf("expected the internal service to have no external IPs when there is a load balancer service, got %v", internal.Spec.ExternalIPs)
+	}
+}
+
+// TestInternalServiceExternalIPs verifies that the internal service gets the
+// external IPs of an ingresscontroller that is not published by a load
+// balancer, and that changing them updates the service.
+func TestInternalServiceExternalIPs(t *testing.T) {
+	ic := ingressController("default", operatorv1.HostNetworkStrategyType)
+	current := desiredInternalIngressControllerService(ic, "openshift-ingress", metav1.OwnerReference{})
+	if len(current.Spec.ExternalIPs) != 0 {
+		t.Errorf("expected no external IPs without the annotation, got %v", current.Spec.ExternalIPs)
+	}
+
+	ic.Annotations = map[string]string{serviceExternalIPsAnnotation: "192.0.2.10"}
+	desired := desiredInternalIngressControllerService(ic, "openshift-ingress", metav1.OwnerReference{})
+	changed, updated := internalServiceChanged(current, desired)
+	if !changed {
+		t.Fatal("expected setting the external IPs to change the internal service")
+	}
+	if expect := []string{"192.0.2.10"}; !cmp.Equal(updated.Spec.ExternalIPs, expect) {
+		t.Errorf("expected external IPs %v, got %v", expect, updated.Spec.ExternalIPs)
+	}
+	if changed, _ := internalServiceChanged(updated, desired); changed {
+		t.Error("expected no change once the external IPs are set")
+	}
+}
+
+// TestDesiredDNSRecordsExternalIPs verifies that the DNS records point at the
+// load balancer service's external IPs rather than at its load balancer.
+func TestDesiredDNSRecordsExternalIPs(t *testing.T) {
+	ic := ingressController("default", operatorv1.LoadBalancerServiceStrategyType)
+	ic.Status.Domain = "apps.example.com"
+	service := &corev1.Service{}
+	service.Spec.ExternalIPs = []string{"192.0.2.10", "2001:db8::10"}
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.cloudprovider.example.com"}}
+
+	expect := []*dns.Record{
+		newARecord("*.apps.example.com", "192.0.2.10", privateZone),
+		newAAAARecord("*.apps.example.com", "2001:db8::10", privateZone),
+	}
+	if records := desiredDNSRecords(ic, privateConfig, service); !cmp.Equal(records, expect, cmpopts.EquateEmpty()) {
+		t.Errorf("expected records %v, got %v", expect, records)
+	}
+	if !hasIPv6Address(service) {
+		t.Error("expected an IPv6 external IP to count as an IPv6 address of the service")
+	}
+}
+
+// TestValidateServiceExternalIPs verifies that the external IPs must be IP
+// addresses that a service can have, with a message that names the problem.
+func TestValidateServiceExternalIPs(t *testing.T) {
+	testCases := []struct {
+		value  string
+		expect string
+	}{
+		{value: "192.0.2.10, 2001:db8::10"},
+		{
+			value:  "",
+			expect: `invalid value for annotation ingress.operator.openshift.io/service-external-ips: ""; must list at least one IP address`,
+		},
+		{
+			value:  "192.0.2.10,router.example.com",
+			expect: `invalid value for annotation ingress.operator.openshift.io/service-external-ips: "router.example.com" is not an IP address`,
+		},
+		{
+			value:  "127.0.0.1",
+			expect: `invalid value for annotation ingress.operator.openshift.io/service-external-ips: "127.0.0.1" may not be an unspecified, loopback, link-local, or multicast address`,
+		},
+		{
+			value:  "fe80::1",
+			expect: `invalid value for annotation ingress.operator.openshift.io/service-external-ips: "fe80::1" may not be an unspecified, loopback, link-local, or multicast address`,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{serviceExternalIPsAnnotation: tc.value}}}
+		err := validateServiceExternalIPs(ic)
+		switch {
+		case len(tc.expect) == 0 && err != nil:
+			t.Errorf("%q: expected no error, got %v", tc.value, err)
+		case len(tc.expect) != 0 && (err == nil || err.Error() != tc.expect):
+			t.Errorf("%q: expected error %q, got %v", tc.value, tc.expect, err)
+		}
+	}
+}
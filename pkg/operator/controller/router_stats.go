@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// routerStatsPort is the port of the router's stats endpoint.
+	routerStatsPort = 1936
+
+	// routerStatsTimeout bounds scraping the stats endpoint of one router
+	// pod so that an unresponsive router does not block reconciliation.
+	routerStatsTimeout = 2 * time.Second
+)
+
+// routerStatsClient returns an HTTP client that verifies the router's metrics
+// certificate and the credentials for the router stats endpoint of the given
+// ingresscontroller.
+func (r *reconciler) routerStatsClient(ic *operatorv1.IngressController) (*http.Client, string, string, error) {
+	if !metricsIntegrationEnabled(ic) {
+		return nil, "", "", fmt.Errorf("metrics integration is disabled")
+	}
+	statsSecret := manifests.RouterStatsSecret(ic, r.OperandNamespace)
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: statsSecret.Namespace, Name: statsSecret.Name}, statsSecret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, "", "", fmt.Errorf("router stats secret %s/%s does not exist", statsSecret.Namespace, statsSecret.Name)
+		}
+		return nil, "", "", fmt.Errorf("failed to get router stats secret %s/%s: %v", statsSecret.Namespace, statsSecret.Name, err)
+	}
+	caBundle, err := r.currentMetricsCABundleConfigMap(ic)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to get metrics CA bundle configmap: %v", err)
+	}
+	if !hasInjectedCABundle(caBundle) {
+		name := MetricsCABundleConfigMapName(ic, r.OperandNamespace)
+		return nil, "", "", fmt.Errorf("metrics CA bundle configmap %s/%s has no CA bundle yet", name.Namespace, name.Name)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(caBundle.Data[serviceCABundleKey])) {
+		return nil, "", "", fmt.Errorf("metrics CA bundle configmap %s/%s has no valid certificates", caBundle.Namespace, caBundle.Name)
+	}
+	// The router's metrics certificate is issued for the internal
+	// service, so verify it against the service name rather than the pod
+	// IP.
+	svc := InternalIngressControllerServiceName(ic, r.OperandNamespace)
+	client := &http.Client{
+		Timeout: routerStatsTimeout,
+		Transport: &http.Transport{
+			// A new client is created for every sync, so do not keep
+			// idle connections around.
+			DisableKeepAlives: true,
+			TLSClientConfig: &tls.Config{
+				RootCAs:    roots,
+				ServerName: fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace),
+			},
+		},
+	}
+	return client, string(statsSecret.Data["statsUsername"]), string(statsSecret.Data["statsPassword"]), nil
+}
+
+// getRouterMetrics gets the metrics from the router stats endpoint at the
+// given URL with the given client and credentials.
+func getRouterMetrics(client *http.Client, url, username, password string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(username, password)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// routerStatsURL returns the URL of the stats endpoint of the given router pod.
+func routerStatsURL(pod corev1.Pod) string {
+	return fmt.Sprintf("https://%s/metrics", net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(routerStatsPort)))
+}
+
+// runningRouterPods returns the given router pods that are running and can be
+// scraped, sorted by name.
+func runningRouterPods(pods []corev1.Pod) []corev1.Pod {
+	running := []corev1.Pod{}
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && len(pod.Status.PodIP) != 0 && pod.DeletionTimestamp == nil {
+			running = append(running, pod)
+		}
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].Name < running[j].Name })
+	return running
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TestGetRouterMetrics verifies that the router stats endpoint is scraped
+// with the stats credentials.
+func TestGetRouterMetrics(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if username, password, ok := req.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintln(w, "process_start_time_seconds 1559390400")
+	}))
+	defer server.Close()
+
+	metrics, err := getRouterMetrics(server.Client(), server.URL+"/metrics", "user", "pass")
+	if err != nil {
+		t.Fatalf("failed to get router metrics: %v", err)
+	}
+	if expect := "process_start_time_seconds 1559390400\n"; string(metrics) != expect {
+		t.Errorf("expected metrics %q, got %q", expect, string(metrics))
+	}
+	if _, err := getRouterMetrics(server.Client(), server.URL+"/metrics", "user", "wrong"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected an unauthorized error for wrong credentials, got %v", err)
+	}
+}
+
+// TestRouterStatsClient verifies that the router stats client cannot be
+// created without metrics integration, the stats secret, or the metrics CA
+// bundle.
+func TestRouterStatsClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"},
+	}
+	statsSecret := manifests.RouterStatsSecret(ic, "openshift-ingress")
+	caName := MetricsCABundleConfigMapName(ic, "openshift-ingress")
+	emptyCABundle := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: caName.Namespace, Name: caName.Name},
+	}
+	caBundle := emptyCABundle.DeepCopy()
+	caBundle.Data = map[string]string{serviceCABundleKey: caPEM}
+
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		objects     []runtime.Object
+		expectError string
+	}{
+		{
+			description: "metrics integration disabled",
+			annotations: map[string]string{disableMetricsIntegrationAnnotation: "true"},
+			objects:     []runtime.Object{statsSecret, caBundle},
+			expectError: "metrics integration is disabled",
+		},
+		{
+			description: "no stats secret",
+			objects:     []runtime.Object{caBundle},
+			expectError: "router stats secret openshift-ingress/router-stats-default does not exist",
+		},
+		{
+			description: "no CA bundle",
+			objects:     []runtime.Object{statsSecret, emptyCABundle},
+			expectError: "metrics CA bundle configmap openshift-ingress/router-metrics-ca-default has no CA bundle yet",
+		},
+		{
+			description: "stats secret and CA bundle",
+			objects:     []runtime.Object{statsSecret, caBundle},
+		},
+	}
+	for _, tc := range testCases {
+		ic := ic.DeepCopy()
+		ic.Annotations = tc.annotations
+		objects := []runtime.Object{}
+		for _, obj := range tc.objects {
+			objects = append(objects, obj.DeepCopyObject())
+		}
+		r := &reconciler{
+			Config: Config{OperandNamespace: "openshift-ingress"},
+			client: newFakeClient(objects...),
+		}
+		client, _, _, err := r.routerStatsClient(ic)
+		switch {
+		case len(tc.expectError) != 0 && (err == nil || err.Error() != tc.expectError):
+			t.Errorf("%s: expected error %q, got %v", tc.description, tc.expectError, err)
+		case len(tc.expectError) == 0 && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.description, err)
+		case len(tc.expectError) == 0 && client == nil:
+			t.Errorf("%s: expected a client", tc.description)
+		}
+	}
+}
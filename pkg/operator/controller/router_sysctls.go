@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// routerSysctlsAnnotation specifies a comma-separated list of sysctls
+	// of the form name=value, such as
+	// "net.core.somaxconn=4096,net.ipv4.ip_local_port_range=1024 65000",
+	// that are set in the router pods.  Only namespaced sysctls may be set.
+	// Sysctls other than safeSysctls must be allowed on the nodes with the
+	// kubelet's --allowed-unsafe-sysctls flag; otherwise the kubelet
+	// rejects the router pods.  If unset, no sysctls are set.
+	routerSysctlsAnnotation = "ingress.operator.openshift.io/router-sysctls"
+
+	// sysctlForbiddenReason is the reason with which the kubelet rejects a
+	// pod that sets a sysctl that the node does not allow.
+	sysctlForbiddenReason = "SysctlForbidden"
+)
+
+// safeSysctls are the sysctls that the kubelet allows in every pod.
+var safeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced":       true,
+	"net.ipv4.ip_local_port_range": true,
+	"net.ipv4.tcp_syncookies":      true,
+}
+
+// namespacedSysctlPrefixes are the prefixes of the sysctls that are
+// namespaced, and may therefore be set per pod if the kubelet allows them.
+var namespacedSysctlPrefixes = []string{
+	"kernel.shm",
+	"kernel.msg",
+	"kernel.sem",
+	"fs.mqueue.",
+	"net.",
+}
+
+// sysctlNameRegexp matches a valid sysctl name.
+var sysctlNameRegexp = regexp.MustCompile(`^([a-z0-9]([-_a-z0-9]*[a-z0-9])?[\./])*[a-z0-9]([-_a-z0-9]*[a-z0-9])?$`)
+
+// parseRouterSysctls parses the value of the router sysctls annotation.
+func parseRouterSysctls(v string) ([]corev1.Sysctl, error) {
+	sysctls := []corev1.Sysctl{}
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not of the form name=value", entry)
+		}
+		name, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if len(name) > 253 || !sysctlNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("%q is not a valid sysctl name", name)
+		}
+		if len(value) == 0 {
+			return nil, fmt.Errorf("sysctl %s has an empty value", name)
+		}
+		if !sysctlNamespaced(name) {
+			return nil, fmt.Errorf("sysctl %s is not namespaced and cannot be set in a pod", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("sysctl %s is specified more than once", name)
+		}
+		seen[name] = true
+		sysctls = append(sysctls, corev1.Sysctl{Name: name, Value: value})
+	}
+	return sysctls, nil
+}
+
+// sysctlNamespaced returns a Boolean indicating whether the sysctl with the
+// given name is namespaced.
+func sysctlNamespaced(name string) bool {
+	if safeSysctls[name] {
+		return true
+	}
+	for _, prefix := range namespacedSysctlPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// routerSysctls returns the sysctls that the ingresscontroller sets in its
+// router pods.  An annotation that cannot be parsed yields no sysctls;
+// validation rejects such values.
+func routerSysctls(ic *operatorv1.IngressController) []corev1.Sysctl {
+	v, ok := ic.Annotations[routerSysctlsAnnotation]
+	if !ok {
+		return nil
+	}
+	sysctls, err := parseRouterSysctls(v)
+	if err != nil {
+		return nil
+	}
+	return sysctls
+}
+
+// podSysctls returns the sysctls in the given deployment's pod template.
+func podSysctls(deployment *appsv1.Deployment) []corev1.Sysctl {
+	if deployment.Spec.Template.Spec.SecurityContext == nil {
+		return nil
+	}
+	return deployment.Spec.Template.Spec.SecurityContext.Sysctls
+}
+
+// setPodSysctls sets the sysctls in the given deployment's pod template and
+// leaves the rest of the pod security context unchanged.  The pod security
+// context is only allocated if there are sysctls to set.
+func setPodSysctls(deployment *appsv1.Deployment, sysctls []corev1.Sysctl) {
+	podSpec := &deployment.Spec.Template.Spec
+	if len(sysctls) == 0 {
+		if podSpec.SecurityContext != nil {
+			podSpec.SecurityContext.Sysctls = nil
+		}
+		return
+	}
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	podSpec.SecurityContext.Sysctls = sysctls
+}
+
+// sysctlForbiddenPods returns the names of the given router pods that the
+// kubelet rejected because their node does not allow the sysctls that the
+// ingresscontroller sets, along with the kubelet's message for the first such
+// pod.  Rejected pods are not deleted, so a rejected pod is ignored if it has
+// sysctls other than the ones that the ingresscontroller now sets, or if a
+// later pod with the same sysctls is running.
+func sysctlForbiddenPods(ic *operatorv1.IngressController, pods []corev1.Pod) ([]string, string) {
+	desired := routerSysctls(ic)
+	if len(desired) == 0 {
+		return nil, ""
+	}
+	sysctlsOf := func(pod corev1.Pod) []corev1.Sysctl {
+		if pod.Spec.SecurityContext == nil {
+			return nil
+		}
+		return pod.Spec.SecurityContext.Sysctls
+	}
+	forbidden := []string{}
+	message := ""
+	for _, pod := range pods {
+		if pod.Status.Reason != sysctlForbiddenReason || pod.DeletionTimestamp != nil || !cmp.Equal(sysctlsOf(pod), desired, cmpopts.EquateEmpty()) {
+			continue
+		}
+		superseded := false
+		for _, other := range pods {
+			if other.Status.Phase == corev1.PodRunning && other.CreationTimestamp.After(pod.CreationTimestamp.Time) && cmp.Equal(sysctlsOf(other), desired, cmpopts.EquateEmpty()) {
+				superseded = true
+				break
+			}
+		}
+		if superseded {
+			continue
+		}
+		forbidden = append(forbidden, pod.Name)
+		if len(message) == 0 {
+			message = pod.Status.Message
+		}
+	}
+	return forbidden, message
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredRouterDeploymentSysctls verifies that the router pod template
+// sets the sysctls from the ingresscontroller's annotation, and that adding,
+// changing, and removing the annotation updates the deployment.
+func TestDesiredRouterDeploymentSysctls(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	withoutSysctls, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	if sysctls := podSysctls(withoutSysctls); len(sysctls) != 0 {
+		t.Errorf("expected no sysctls without annotation %s, got %v", routerSysctlsAnnotation, sysctls)
+	}
+
+	ci.Annotations = map[string]string{routerSysctlsAnnotation: "net.core.somaxconn=4096, net.ipv4.ip_local_port_range=1024 65000"}
+	withSysctls, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	expect := []corev1.Sysctl{
+		{Name: "net.core.somaxconn", Value: "4096"},
+		{Name: "net.ipv4.ip_local_port_range", Value: "1024 65000"},
+	}
+	if sysctls := podSysctls(withSysctls); !cmp.Equal(sysctls, expect) {
+		t.Errorf("expected sysctls %v, got %v", expect, sysctls)
+	}
+
+	if changed, updated := deploymentConfigChanged(withoutSysctls, withSysctls); !changed {
+		t.Error("expected adding sysctls to change the deployment")
+	} else if sysctls := podSysctls(updated); !cmp.Equal(sysctls, expect) {
+		t.Errorf("expected updated deployment to have sysctls %v, got %v", expect, sysctls)
+	}
+	if changed, updated := deploymentConfigChanged(withSysctls, withoutSysctls); !changed {
+		t.Error("expected removing sysctls to change the deployment")
+	} else if sysctls := podSysctls(updated); len(sysctls) != 0 {
+		t.Errorf("expected updated deployment to have no sysctls, got %v", sysctls)
+	}
+
+	// Setting or clearing the sysctls must leave the rest of the current
+	// pod security context alone.
+	fsGroup := int64(1000)
+	current := withoutSysctls.DeepCopy()
+	current.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{FSGroup: &fsGroup}
+	changed, updated := deploymentConfigChanged(current, withSysctls)
+	if !changed {
+		t.Fatal("expected adding sysctls to change the deployment")
+	}
+	expectSecurityContext := &corev1.PodSecurityContext{FSGroup: &fsGroup, Sysctls: expect}
+	if securityContext := updated.Spec.Template.Spec.SecurityContext; !cmp.Equal(securityContext, expectSecurityContext) {
+		t.Errorf("expected updated pod security context %v, got %v", expectSecurityContext, securityContext)
+	}
+	changed, updated = deploymentConfigChanged(updated, withoutSysctls)
+	if !changed {
+		t.Fatal("expected removing sysctls to change the deployment")
+	}
+	expectSecurityContext = &corev1.PodSecurityContext{FSGroup: &fsGroup}
+	if securityContext := updated.Spec.Template.Spec.SecurityContext; !cmp.Equal(securityContext, expectSecurityContext) {
+		t.Errorf("expected updated pod security context %v, got %v", expectSecurityContext, securityContext)
+	}
+}
+
+// TestSysctlForbiddenDegradedCondition verifies that the ingresscontroller is
+// degraded when the kubelet rejects its router pods because of their sysctls,
+// but not because of rejected pods that have been superseded.
+func TestSysctlForbiddenDegradedCondition(t *testing.T) {
+	sysctls := []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "4096"}}
+	created := time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)
+	pod := func(name string, minutes int, sysctls []corev1.Sysctl, forbidden bool) corev1.Pod {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created.Add(time.Duration(minutes) * time.Minute)),
+			},
+			Spec: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{Sysctls: sysctls},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		if forbidden {
+			pod.Status = corev1.PodStatus{
+				Phase:   corev1.PodFailed,
+				Reason:  sysctlForbiddenReason,
+				Message: `Pod forbidden sysctl: "net.core.somaxconn" not whitelisted`,
+			}
+		}
+		return pod
+	}
+	notDegraded := operatorv1.OperatorCondition{
+		Type:   operatorv1.OperatorStatusTypeDegraded,
+		Status: operatorv1.ConditionFalse,
+	}
+	testCases := []struct {
+		description string
+		annotation  string
+		pods        []corev1.Pod
+		expect      operatorv1.OperatorCondition
+	}{
+		{
+			description: "pods running with sysctls",
+			annotation:  "net.core.somaxconn=4096",
+			pods:        []corev1.Pod{pod("router-default-1", 0, sysctls, false)},
+			expect:      notDegraded,
+		},
+		{
+			description: "one pod rejected",
+			annotation:  "net.core.somaxconn=4096",
+			pods:        []corev1.Pod{pod("router-default-1", 0, sysctls, true)},
+			expect: operatorv1.OperatorCondition{
+				Type:    operatorv1.OperatorStatusTypeDegraded,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "SysctlForbidden",
+				Message: `router pod router-default-1 was rejected by its node: Pod forbidden sysctl: "net.core.somaxconn" not whitelisted; allow the sysctls on the nodes with the kubelet's --allowed-unsafe-sysctls flag or remove them from annotation ingress.operator.openshift.io/router-sysctls`,
+			},
+		},
+		{
+			description: "several pods rejected",
+			annotation:  "net.core.somaxconn=4096",
+			pods:        []corev1.Pod{pod("router-default-2", 1, sysctls, true), pod("router-default-1", 0, sysctls, true)},
+			expect: operatorv1.OperatorCondition{
+				Type:    operatorv1.OperatorStatusTypeDegraded,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "SysctlForbidden",
+				Message: `2 router pods, including router-default-1, were rejected by their nodes: Pod forbidden sysctl: "net.core.somaxconn" not whitelisted; allow the sysctls on the nodes with the kubelet's --allowed-unsafe-sysctls flag or remove them from annotation ingress.operator.openshift.io/router-sysctls`,
+			},
+		},
+		{
+			description: "rejected pod superseded by a running pod",
+			annotation:  "net.core.somaxconn=4096",
+			pods:        []corev1.Pod{pod("router-default-1", 0, sysctls, true), pod("router-default-2", 1, sysctls, false)},
+			expect:      notDegraded,
+		},
+		{
+			description: "rejected pod with sysctls that are no longer set",
+			annotation:  "net.ipv4.tcp_syncookies=1",
+			pods:        []corev1.Pod{pod("router-default-1", 0, sysctls, true)},
+			expect:      notDegraded,
+		},
+		{
+			description: "rejected pod after the annotation is removed",
+			pods:        []corev1.Pod{pod("router-default-1", 0, sysctls, true)},
+			expect:      notDegraded,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			Status: operatorv1.IngressControllerStatus{
+				Domain: "apps.example.com",
+			},
+		}
+		if len(tc.annotation) != 0 {
+			ic.Annotations = map[string]string{routerSysctlsAnnotation: tc.annotation}
+		}
+		actual := computeIngressDegradedCondition(ic, tc.pods)
+		if !cmp.Equal(actual, tc.expect) {
+			t.Errorf("%q: expected %#v, got %#v", tc.description, tc.expect, actual)
+		}
+	}
+}
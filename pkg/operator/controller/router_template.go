@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// routerTemplateConfigMapAnnotation specifies the name of a configmap
+	// in the operand namespace with a custom HAProxy configuration
+	// template under the key routerTemplateKey, which the router uses
+	// instead of the template in the router image.  Custom templates are
+	// an unsupported customization: the template must match the version
+	// of the router that renders it, so it may break on any upgrade.
+	// Changes to the configmap roll out the router pods.  If the configmap
+	// or key does not exist or the template is empty, the router uses the
+	// template in the image and the ingresscontroller is marked degraded.
+	// If unset, the router uses the template in the image.
+	routerTemplateConfigMapAnnotation = "ingress.operator.openshift.io/router-template-configmap"
+
+	// routerTemplateKey is the key of the template in the router template
+	// configmap.
+	routerTemplateKey = "haproxy-config.template"
+
+	// routerTemplateVolumeName is the name of the router volume with the
+	// custom template.
+	routerTemplateVolumeName = "router-template"
+
+	// routerTemplateMountPath is the directory in which the custom
+	// template is mounted in the router container.  It is a subdirectory
+	// of the configuration directory so that the template can include the
+	// other files in that directory by the same relative paths as the
+	// template in the image.
+	routerTemplateMountPath = routerConfigDir + "/custom"
+
+	// routerTemplateInvalidReason is the reason of the Degraded condition
+	// when the router template configmap is missing or has no template.
+	routerTemplateInvalidReason = "RouterTemplateInvalid"
+)
+
+// routerTemplateConfigMap returns the given ingresscontroller's router
+// template configmap, or nil if the ingresscontroller does not specify one or
+// the configmap does not exist.
+func (r *reconciler) routerTemplateConfigMap(ic *operatorv1.IngressController) (*corev1.ConfigMap, error) {
+	name, ok := ic.Annotations[routerTemplateConfigMapAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: r.OperandNamespace, Name: name}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get router template configmap %s/%s: %v", r.OperandNamespace, name, err)
+	}
+	return cm, nil
+}
+
+// routerTemplateProblem returns a description of what is wrong with the given
+// router template configmap of the given ingresscontroller, or the empty
+// string if the ingresscontroller does not specify a configmap or the
+// configmap has a template.
+func routerTemplateProblem(ic *operatorv1.IngressController, namespace string, cm *corev1.ConfigMap) string {
+	name, ok := ic.Annotations[routerTemplateConfigMapAnnotation]
+	if !ok {
+		return ""
+	}
+	if cm == nil {
+		return fmt.Sprintf("configmap %s/%s does not exist", namespace, name)
+	}
+	template, ok := cm.Data[routerTemplateKey]
+	if !ok {
+		return fmt.Sprintf("configmap %s/%s has no key %s", namespace, name, routerTemplateKey)
+	}
+	if len(strings.TrimSpace(template)) == 0 {
+		return fmt.Sprintf("key %s of configmap %s/%s is empty", routerTemplateKey, namespace, name)
+	}
+	return ""
+}
+
+// applyRouterTemplate mounts the template from the given configmap in the
+// given router deployment and makes the router use it.
+func applyRouterTemplate(deployment *appsv1.Deployment, cm *corev1.ConfigMap) {
+	podSpec := &deployment.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: routerTemplateVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+				Items:                []corev1.KeyToPath{{Key: routerTemplateKey, Path: routerTemplateKey}},
+			},
+		},
+	})
+	container := &podSpec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      routerTemplateVolumeName,
+		MountPath: routerTemplateMountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  "TEMPLATE_FILE",
+		Value: routerTemplateMountPath + "/" + routerTemplateKey,
+	})
+}
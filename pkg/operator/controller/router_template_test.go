@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newRouterTemplate returns a router template configmap in the operand
+// namespace with the given template.
+func newRouterTemplate(name, template string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: name},
+		Data:       map[string]string{routerTemplateKey: template},
+	}
+}
+
+// TestEnsureRouterDeploymentRouterTemplate verifies that the router uses the
+// custom template only once its configmap has a template, that the template
+// is mounted and passed to the router, and that changing the template rolls
+// out the router pods.
+func TestEnsureRouterDeploymentRouterTemplate(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{routerTemplateConfigMapAnnotation: "router-template"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	client := newFakeClient()
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress", IngressControllerImage: "quay.io/openshift/router:latest"},
+		client: client,
+	}
+	templateFile := func(deployment *appsv1.Deployment) string {
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == "TEMPLATE_FILE" {
+				return envVar.Value
+			}
+		}
+		return ""
+	}
+
+	deployment, err := r.ensureRouterDeployment(ci, infraConfig)
+	if err != nil {
+		t.Fatalf("failed to create router deployment: %v", err)
+	}
+	if v := templateFile(deployment); len(v) != 0 {
+		t.Errorf("expected no TEMPLATE_FILE while the configmap does not exist, got %q", v)
+	}
+
+	// An empty template is not used either.
+	if err := client.Create(context.TODO(), newRouterTemplate("router-template", "\n")); err != nil {
+		t.Fatal(err)
+	}
+	if deployment, err = r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to update router deployment: %v", err)
+	}
+	if v := templateFile(deployment); len(v) != 0 {
+		t.Errorf("expected no TEMPLATE_FILE while the template is empty, got %q", v)
+	}
+
+	if err := client.replace(newRouterTemplate("router-template", "global\n")); err != nil {
+		t.Fatal(err)
+	}
+	if deployment, err = r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to update router deployment: %v", err)
+	}
+	if expect, v := "/var/lib/haproxy/conf/custom/haproxy-config.template", templateFile(deployment); v != expect {
+		t.Errorf("expected TEMPLATE_FILE %q, got %q", expect, v)
+	}
+	expectVolume := corev1.Volume{
+		Name: "router-template",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "router-template"},
+				Items:                []corev1.KeyToPath{{Key: "haproxy-config.template", Path: "haproxy-config.template"}},
+			},
+		},
+	}
+	var volume corev1.Volume
+	for _, v := range deployment.Spec.Template.Spec.Volumes {
+		if v.Name == "router-template" {
+			volume = v
+		}
+	}
+	if !cmp.Equal(volume, expectVolume) {
+		t.Errorf("expected volume %#v, got %#v", expectVolume, volume)
+	}
+	expectMount := corev1.VolumeMount{Name: "router-template", MountPath: "/var/lib/haproxy/conf/custom", ReadOnly: true}
+	var mount corev1.VolumeMount
+	for _, m := range deployment.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if m.Name == "router-template" {
+			mount = m
+		}
+	}
+	if !cmp.Equal(mount, expectMount) {
+		t.Errorf("expected volume mount %#v, got %#v", expectMount, mount)
+	}
+	hash := deployment.Spec.Template.Annotations[referencedConfigHashAnnotation]
+
+	if err := client.replace(newRouterTemplate("router-template", "global\n  maxconn 1000\n")); err != nil {
+		t.Fatal(err)
+	}
+	if deployment, err = r.ensureRouterDeployment(ci, infraConfig); err != nil {
+		t.Fatalf("failed to update router deployment: %v", err)
+	}
+	if changed := deployment.Spec.Template.Annotations[referencedConfigHashAnnotation]; changed == hash {
+		t.Errorf("expected annotation %s to change after the template changed", referencedConfigHashAnnotation)
+	}
+}
+
+// TestRouterTemplateProblem verifies that a missing configmap, a missing key,
+// and an empty template are reported.
+func TestRouterTemplateProblem(t *testing.T) {
+	noKey := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "no-key"},
+		Data:       map[string]string{"template": "global\n"},
+	}
+	r := &reconciler{
+		Config: Config{OperandNamespace: "openshift-ingress"},
+		client: newFakeClient(newRouterTemplate("router-template", "global\n"), newRouterTemplate("empty", " "), noKey),
+	}
+	testCases := []struct {
+		configMap string
+		expect    string
+	}{
+		{"", ""},
+		{"router-template", ""},
+		{"missing", "configmap openshift-ingress/missing does not exist"},
+		{"no-key", "configmap openshift-ingress/no-key has no key haproxy-config.template"},
+		{"empty", "key haproxy-config.template of configmap openshift-ingress/empty is empty"},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+		if len(tc.configMap) != 0 {
+			ic.Annotations = map[string]string{routerTemplateConfigMapAnnotation: tc.configMap}
+		}
+		cm, err := r.routerTemplateConfigMap(ic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if problem := routerTemplateProblem(ic, r.OperandNamespace, cm); problem != tc.expect {
+			t.Errorf("%q: expected %q, got %q", tc.configMap, tc.expect, problem)
+		}
+	}
+}
+
+// TestSyncIngressControllerStatusRouterTemplateInvalid verifies that an
+// ingresscontroller whose router template configmap is missing is marked
+// degraded.
+func TestSyncIngressControllerStatusRouterTemplateInvalid(t *testing.T) {
+	deployment := manifests.RouterDeployment()
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "router"}}
+	ic := ingressController("default", operatorv1.PrivateStrategyType)
+	ic.Namespace = "openshift-ingress-operator"
+	ic.Annotations = map[string]string{routerTemplateConfigMapAnnotation: "router-template"}
+	client := newFakeClient(ic)
+	r := &reconciler{
+		Config: Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress"},
+		client: client,
+	}
+	if _, err := r.syncIngressControllerStatus(ic, deployment, nil, nil, nil, &configv1.DNS{}); err != nil {
+		t.Fatalf("failed to sync status: %v", err)
+	}
+	current := &operatorv1.IngressController{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+		t.Fatal(err)
+	}
+	expect := operatorv1.OperatorCondition{
+		Type:    operatorv1.OperatorStatusTypeDegraded,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  routerTemplateInvalidReason,
+		Message: "the router template from annotation ingress.operator.openshift.io/router-template-configmap cannot be used: configmap openshift-ingress/router-template does not exist; the router uses the template in the router image",
+	}
+	condition := getIngressCondition(current.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+	if condition == nil {
+		t.Fatal("expected a Degraded condition")
+	}
+	if !cmp.Equal(*condition, expect, cmpopts.IgnoreFields(operatorv1.OperatorCondition{}, "LastTransitionTime")) {
+		t.Errorf("expected %#v, got %#v", expect, *condition)
+	}
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// weightedBalancingAnnotation, when set to "true" on an
+	// ingresscontroller, makes roundrobin the router's default
+	// load-balancing algorithm for HTTP and TLS passthrough routes, so
+	// that requests are distributed among a route's services, and their
+	// endpoints, strictly in proportion to the weights in the route's
+	// spec.to and spec.alternateBackends, as weighted canaries require.
+	// The router always honors the weights, and it already uses
+	// roundrobin for routes with alternate backends, but under the other
+	// algorithms the share of requests also depends on how many
+	// connections each endpoint has open or on the clients' addresses.
+	// A route's own haproxy.router.openshift.io/balance annotation still
+	// takes precedence.  Allowed values are "true" and "false".  The
+	// annotation cannot be set to "true" when defaultRouteSettingsAnnotation
+	// specifies a different default algorithm.  If unset, the router's
+	// default algorithm is used.
+	weightedBalancingAnnotation = "ingress.operator.openshift.io/weighted-balancing"
+
+	// weightedBalancingAlgorithm is the load-balancing algorithm that
+	// distributes requests strictly in proportion to weights.
+	weightedBalancingAlgorithm = "roundrobin"
+)
+
+// validateWeightedBalancing verifies that the weighted balancing annotation,
+// if set, is "true" or "false" and that it does not conflict with the default
+// route settings.
+func validateWeightedBalancing(ic *operatorv1.IngressController) error {
+	v, ok := ic.Annotations[weightedBalancingAnnotation]
+	if !ok {
+		return nil
+	}
+	if v != "true" && v != "false" {
+		return fmt.Errorf("invalid value for annotation %s: %q; allowed values are true and false", weightedBalancingAnnotation, v)
+	}
+	if v != "true" {
+		return nil
+	}
+	if settings, err := parseDefaultRouteSettings(ic.Annotations[defaultRouteSettingsAnnotation]); err == nil {
+		if balance, ok := settings[routeBalanceAnnotation]; ok && balance != weightedBalancingAlgorithm {
+			return fmt.Errorf("annotation %s is set to true, which requires the default %s of %s, but annotation %s specifies %s", weightedBalancingAnnotation, routeBalanceAnnotation, weightedBalancingAlgorithm, defaultRouteSettingsAnnotation, balance)
+		}
+	}
+	return nil
+}
+
+// weightedBalancingEnv returns the router environment variables for the given
+// ingresscontroller's weighted balancing annotation.  If the default route
+// settings also specify the algorithm, which validation only allows to be the
+// same one, they set the variables instead.
+func weightedBalancingEnv(ic *operatorv1.IngressController) []corev1.EnvVar {
+	if ic.Annotations[weightedBalancingAnnotation] != "true" {
+		return nil
+	}
+	if settings, err := parseDefaultRouteSettings(ic.Annotations[defaultRouteSettingsAnnotation]); err == nil {
+		if _, ok := settings[routeBalanceAnnotation]; ok {
+			return nil
+		}
+	}
+	return defaultRouteSettings[routeBalanceAnnotation].env(weightedBalancingAlgorithm)
+}
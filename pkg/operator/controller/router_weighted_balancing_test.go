@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDesiredRouterDeploymentWeightedBalancing verifies that weighted
+// balancing makes roundrobin the router's default algorithm and that the
+// default route settings are not duplicated.
+func TestDesiredRouterDeploymentWeightedBalancing(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			Platform: configv1.AWSPlatformType,
+		},
+	}
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		expectEnv   []string
+	}{
+		{
+			description: "unset",
+		},
+		{
+			description: "false",
+			annotations: map[string]string{weightedBalancingAnnotation: "false"},
+		},
+		{
+			description: "true",
+			annotations: map[string]string{weightedBalancingAnnotation: "true"},
+			expectEnv:   []string{"ROUTER_LOAD_BALANCE_ALGORITHM=roundrobin", "ROUTER_TCP_BALANCE_SCHEME=roundrobin"},
+		},
+		{
+			description: "true with a roundrobin default route setting",
+			annotations: map[string]string{
+				weightedBalancingAnnotation:    "true",
+				defaultRouteSettingsAnnotation: "haproxy.router.openshift.io/balance=roundrobin",
+			},
+			expectEnv: []string{"ROUTER_LOAD_BALANCE_ALGORITHM=roundrobin", "ROUTER_TCP_BALANCE_SCHEME=roundrobin"},
+		},
+		{
+			description: "leastconn default route setting",
+			annotations: map[string]string{defaultRouteSettingsAnnotation: "haproxy.router.openshift.io/balance=leastconn"},
+			expectEnv:   []string{"ROUTER_LOAD_BALANCE_ALGORITHM=leastconn", "ROUTER_TCP_BALANCE_SCHEME=leastconn"},
+		},
+	}
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: tc.annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		deployment, err := desiredRouterDeployment(ci, "openshift-ingress", "quay.io/openshift/router:latest", infraConfig)
+		if err != nil {
+			t.Fatalf("%s: invalid router Deployment: %v", tc.description, err)
+		}
+		var actual []string
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == "ROUTER_LOAD_BALANCE_ALGORITHM" || envVar.Name == "ROUTER_TCP_BALANCE_SCHEME" {
+				actual = append(actual, envVar.Name+"="+envVar.Value)
+			}
+		}
+		if !cmp.Equal(actual, tc.expectEnv) {
+			t.Errorf("%s: expected %v, got %v", tc.description, tc.expectEnv, actual)
+		}
+	}
+}
+
+// TestValidateWeightedBalancing verifies the message with which a default
+// balance algorithm that conflicts with weighted balancing is rejected.
+func TestValidateWeightedBalancing(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				weightedBalancingAnnotation:    "true",
+				defaultRouteSettingsAnnotation: "haproxy.router.openshift.io/balance=source",
+			},
+		},
+	}
+	expect := "annotation ingress.operator.openshift.io/weighted-balancing is set to true, which requires the default haproxy.router.openshift.io/balance of roundrobin, but annotation ingress.operator.openshift.io/default-route-settings specifies source"
+	if err := validateWeightedBalancing(ic); err == nil || err.Error() != expect {
+		t.Errorf("expected error %q, got %v", expect, err)
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// Shutdown tracks the reconciliations that are in progress so that the
+// operator can let them finish when it stops, and keeps new reconciliations
+// from starting once the operator is stopping.  A reconciliation that is
+// refused does nothing, so it cannot leave an ingresscontroller or its status
+// half-updated; the new operator process reconciles it again.  A nil Shutdown
+// tracks nothing.
+type Shutdown struct {
+	lock     sync.Mutex
+	stopping bool
+	inFlight sync.WaitGroup
+}
+
+// NewShutdown returns a new Shutdown.
+func NewShutdown() *Shutdown {
+	return &Shutdown{}
+}
+
+// startReconcile records that a reconciliation is starting and returns a
+// Boolean indicating whether it may proceed.  If it returns true, the caller
+// must call finishReconcile when the reconciliation is done.
+func (s *Shutdown) startReconcile() bool {
+	if s == nil {
+		return true
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stopping {
+		return false
+	}
+	s.inFlight.Add(1)
+	return true
+}
+
+// finishReconcile records that a reconciliation is done.
+func (s *Shutdown) finishReconcile() {
+	if s == nil {
+		return
+	}
+	s.inFlight.Done()
+}
+
+// Stop refuses any further reconciliations and waits up to the given timeout
+// for those that are in progress to finish.  It returns a Boolean indicating
+// whether they finished in time.
+func (s *Shutdown) Stop(timeout time.Duration) bool {
+	if s == nil {
+		return true
+	}
+	s.lock.Lock()
+	s.stopping = true
+	s.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestShutdown verifies that Stop waits for the reconciliations in progress to
+// finish, gives up after the timeout, and refuses new reconciliations.
+func TestShutdown(t *testing.T) {
+	s := NewShutdown()
+	if !s.startReconcile() {
+		t.Fatal("expected a reconciliation to start before Stop")
+	}
+	if s.Stop(10 * time.Millisecond) {
+		t.Error("expected Stop to time out while a reconciliation is in progress")
+	}
+	if s.startReconcile() {
+		t.Error("expected no reconciliation to start after Stop")
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.finishReconcile()
+		close(finished)
+	}()
+	if !s.Stop(time.Minute) {
+		t.Error("expected Stop to return once the reconciliation in progress finished")
+	}
+	select {
+	case <-finished:
+	default:
+		t.Error("expected Stop to wait for the reconciliation in progress to finish")
+	}
+
+	var nilShutdown *Shutdown
+	if !nilShutdown.startReconcile() || !nilShutdown.Stop(0) {
+		t.Error("expected a nil Shutdown to allow reconciliations and stop immediately")
+	}
+	nilShutdown.finishReconcile()
+}
+
+// TestReconcileAfterShutdown verifies that reconciliations requested after the
+// operator started to stop do not touch any resources.
+func TestReconcileAfterShutdown(t *testing.T) {
+	client := newFakeClient(ingressController("default", operatorv1.PrivateStrategyType))
+	shutdown := NewShutdown()
+	shutdown.Stop(0)
+	config := Config{Namespace: "openshift-ingress-operator", OperandNamespace: "openshift-ingress", Shutdown: shutdown}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "openshift-ingress-operator", Name: "default"}}
+
+	r := &reconciler{Config: config, client: client, cache: &fakeCache{client: client}}
+	if result, err := r.Reconcile(request); err != nil || result != (reconcile.Result{}) {
+		t.Errorf("expected an empty result and no error, got %v, %v", result, err)
+	}
+	sr := &statusReconciler{Config: config, client: client, cache: &fakeCache{client: client}}
+	if result, err := sr.Reconcile(request); err != nil || result != (reconcile.Result{}) {
+		t.Errorf("expected an empty result and no error, got %v, %v", result, err)
+	}
+	if len(client.calls) != 0 {
+		t.Errorf("expected no API calls, got %v", client.calls)
+	}
+}
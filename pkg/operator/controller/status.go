@@ -17,7 +17,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
@@ -28,13 +36,73 @@ const (
 	UnknownVersionValue          = "unknown"
 
 	ingressesEqualConditionMessage = "desired and current number of IngressControllers are equal"
+
+	statusControllerName = "status_controller"
 )
 
+// statusReconciler syncs the operator's ClusterOperator status.  It runs in
+// its own controller so that the status does not depend on when the ingress
+// controller happens to reconcile an ingresscontroller.
+type statusReconciler struct {
+	Config
+
+	client client.Client
+	cache  cache.Cache
+}
+
+// NewStatusController creates the status controller from configuration.  The
+// controller syncs the ClusterOperator status whenever an ingresscontroller
+// or the operand namespace changes.
+func NewStatusController(mgr manager.Manager, config Config) (controller.Controller, error) {
+	reconciler := &statusReconciler{
+		Config: config,
+		client: mgr.GetClient(),
+		cache:  mgr.GetCache(),
+	}
+	c, err := controller.New(statusControllerName, mgr, controller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+	// The status is computed from the state of every ingresscontroller,
+	// so map every event to the same request.
+	toClusterOperator := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(handler.MapObject) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: IngressClusterOperatorName}}}
+		}),
+	}
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, toClusterOperator); err != nil {
+		return nil, err
+	}
+	isOperandNamespace := func(meta metav1.Object) bool { return meta.GetName() == config.OperandNamespace }
+	if err := c.Watch(&source.Kind{Type: &corev1.Namespace{}}, toClusterOperator, predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isOperandNamespace(e.Meta) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isOperandNamespace(e.Meta) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isOperandNamespace(e.MetaNew) },
+		GenericFunc: func(e event.GenericEvent) bool { return isOperandNamespace(e.Meta) },
+	}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reconcile syncs the ClusterOperator status.  The request is ignored because
+// there is only one ClusterOperator.
+func (r *statusReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	if !r.Shutdown.startReconcile() {
+		log.Info("operator is stopping; status sync will be skipped")
+		return reconcile.Result{}, nil
+	}
+	defer r.Shutdown.finishReconcile()
+
+	if err := r.syncOperatorStatus(); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to sync operator status: %v", err)
+	}
+	return reconcile.Result{}, nil
+}
+
 // syncOperatorStatus computes the operator's current status and therefrom
 // creates or updates the ClusterOperator resource for the operator.
-func (r *reconciler) syncOperatorStatus() error {
-	ns := manifests.RouterNamespace()
-
+func (r *statusReconciler) syncOperatorStatus() error {
 	co := &configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: IngressClusterOperatorName}}
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: co.Name}, co); err != nil {
 		if errors.IsNotFound(err) {
@@ -49,7 +117,7 @@ func (r *reconciler) syncOperatorStatus() error {
 	}
 	oldStatus := co.Status.DeepCopy()
 
-	ingresses, ns, err := r.getOperatorState(ns.Name)
+	ingresses, ns, err := r.getOperatorState(manifests.RouterNamespace(r.OperandNamespace).Name)
 	if err != nil {
 		return fmt.Errorf("failed to get operator state: %v", err)
 	}
@@ -57,11 +125,13 @@ func (r *reconciler) syncOperatorStatus() error {
 	related := []configv1.ObjectReference{
 		{
 			Resource: "namespaces",
-			Name:     "openshift-ingress-operator",
+			Name:     r.Namespace,
 		},
 		{
+			// Use the configured name because ns is nil if the
+			// namespace does not exist.
 			Resource: "namespaces",
-			Name:     ns.Name,
+			Name:     r.OperandNamespace,
 		},
 	}
 	for _, ingress := range ingresses {
@@ -74,11 +144,23 @@ func (r *reconciler) syncOperatorStatus() error {
 	}
 	co.Status.RelatedObjects = related
 
-	allIngressesAvailable := checkAllIngressesAvailable(ingresses)
+	// Unmanaged ingresscontrollers are reported in the related objects
+	// and in the Available condition's message, but their availability
+	// is not the operator's concern.
+	managed := []operatorv1.IngressController{}
+	unmanaged := []string{}
+	for _, ingress := range ingresses {
+		if r.isIngressControllerManaged(ingress.Name) {
+			managed = append(managed, ingress)
+		} else {
+			unmanaged = append(unmanaged, ingress.Name)
+		}
+	}
+	allIngressesAvailable := checkAllIngressesAvailable(managed) || len(managed) == 0 && len(unmanaged) != 0
 
 	co.Status.Versions = r.computeOperatorStatusVersions(oldStatus.Versions, allIngressesAvailable)
 	co.Status.Conditions = r.computeOperatorStatusConditions(oldStatus.Conditions,
-		ns, allIngressesAvailable, oldStatus.Versions, co.Status.Versions)
+		ns, allIngressesAvailable, unmanaged, oldStatus.Versions, co.Status.Versions)
 
 	if !operatorStatusesEqual(*oldStatus, co.Status) {
 		if err := r.client.Status().Update(context.TODO(), co); err != nil {
@@ -119,7 +201,7 @@ func initializeClusterOperator(co *configv1.ClusterOperator) {
 
 // getOperatorState gets and returns the resources necessary to compute the
 // operator's current state.
-func (r *reconciler) getOperatorState(nsName string) ([]operatorv1.IngressController, *corev1.Namespace, error) {
+func (r *statusReconciler) getOperatorState(nsName string) ([]operatorv1.IngressController, *corev1.Namespace, error) {
 	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: nsName}}
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: nsName}, ns); err != nil {
 		if errors.IsNotFound(err) {
@@ -137,7 +219,7 @@ func (r *reconciler) getOperatorState(nsName string) ([]operatorv1.IngressContro
 }
 
 // computeOperatorStatusVersions computes the operator's current versions.
-func (r *reconciler) computeOperatorStatusVersions(oldVersions []configv1.OperandVersion, allIngressesAvailable bool) []configv1.OperandVersion {
+func (r *statusReconciler) computeOperatorStatusVersions(oldVersions []configv1.OperandVersion, allIngressesAvailable bool) []configv1.OperandVersion {
 	// We need to report old version until the operator fully transitions to the new version.
 	// https://github.com/openshift/cluster-version-operator/blob/master/docs/dev/clusteroperator.md#version-reporting-during-an-upgrade
 	if !allIngressesAvailable {
@@ -157,8 +239,8 @@ func (r *reconciler) computeOperatorStatusVersions(oldVersions []configv1.Operan
 }
 
 // computeOperatorStatusConditions computes the operator's current state.
-func (r *reconciler) computeOperatorStatusConditions(oldConditions []configv1.ClusterOperatorStatusCondition,
-	ns *corev1.Namespace, allIngressesAvailable bool,
+func (r *statusReconciler) computeOperatorStatusConditions(oldConditions []configv1.ClusterOperatorStatusCondition,
+	ns *corev1.Namespace, allIngressesAvailable bool, unmanaged []string,
 	oldVersions, curVersions []configv1.OperandVersion) []configv1.ClusterOperatorStatusCondition {
 	var oldDegradedCondition, oldProgressingCondition, oldAvailableCondition *configv1.ClusterOperatorStatusCondition
 	for i := range oldConditions {
@@ -175,7 +257,7 @@ func (r *reconciler) computeOperatorStatusConditions(oldConditions []configv1.Cl
 	conditions := []configv1.ClusterOperatorStatusCondition{
 		computeOperatorDegradedCondition(oldDegradedCondition, ns),
 		r.computeOperatorProgressingCondition(oldProgressingCondition, allIngressesAvailable, oldVersions, curVersions),
-		computeOperatorAvailableCondition(oldAvailableCondition, allIngressesAvailable),
+		computeOperatorAvailableCondition(oldAvailableCondition, allIngressesAvailable, unmanaged),
 	}
 
 	return conditions
@@ -219,7 +301,7 @@ func computeOperatorDegradedCondition(oldCondition *configv1.ClusterOperatorStat
 }
 
 // computeOperatorProgressingCondition computes the operator's current Progressing status state.
-func (r *reconciler) computeOperatorProgressingCondition(oldCondition *configv1.ClusterOperatorStatusCondition,
+func (r *statusReconciler) computeOperatorProgressingCondition(oldCondition *configv1.ClusterOperatorStatusCondition,
 	allIngressesAvailable bool, oldVersions, curVersions []configv1.OperandVersion) configv1.ClusterOperatorStatusCondition {
 	// TODO: Update progressingCondition when an ingresscontroller
 	//       progressing condition is created. The Operator's condition
@@ -274,9 +356,11 @@ func (r *reconciler) computeOperatorProgressingCondition(oldCondition *configv1.
 	return progressingCondition
 }
 
-// computeOperatorAvailableCondition computes the operator's current Available status state.
+// computeOperatorAvailableCondition computes the operator's current Available
+// status state.  The names of unmanaged ingresscontrollers, if any, are listed
+// in the message.
 func computeOperatorAvailableCondition(oldCondition *configv1.ClusterOperatorStatusCondition,
-	allIngressesAvailable bool) configv1.ClusterOperatorStatusCondition {
+	allIngressesAvailable bool, unmanaged []string) configv1.ClusterOperatorStatusCondition {
 	availableCondition := configv1.ClusterOperatorStatusCondition{
 		Type: configv1.OperatorAvailable,
 	}
@@ -289,6 +373,9 @@ func computeOperatorAvailableCondition(oldCondition *configv1.ClusterOperatorSta
 		availableCondition.Reason = "IngressUnavailable"
 		availableCondition.Message = "Not all ingress controllers are available."
 	}
+	if len(unmanaged) != 0 {
+		availableCondition.Message += fmt.Sprintf("\nIngress controllers not managed by the operator: %s.", strings.Join(unmanaged, ", "))
+	}
 
 	setLastTransitionTime(&availableCondition, oldCondition)
 	return availableCondition
@@ -1,15 +1,21 @@
 package controller
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func TestComputeOperatorStatusConditions(t *testing.T) {
@@ -136,7 +142,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 				Version: tc.reportedVersions.operand,
 			},
 		}
-		r := &reconciler{
+		r := &statusReconciler{
 			Config: Config{
 				OperatorReleaseVersion: tc.curVersions.operator,
 				IngressControllerImage: tc.curVersions.operand,
@@ -168,7 +174,7 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 		}
 
 		conditions := r.computeOperatorStatusConditions([]configv1.ClusterOperatorStatusCondition{},
-			namespace, tc.allIngressesAvailable, oldVersions, reportedVersions)
+			namespace, tc.allIngressesAvailable, nil, oldVersions, reportedVersions)
 		conditionsCmpOpts := []cmp.Option{
 			cmpopts.IgnoreFields(configv1.ClusterOperatorStatusCondition{}, "LastTransitionTime", "Reason", "Message"),
 			cmpopts.EquateEmpty(),
@@ -180,6 +186,48 @@ func TestComputeOperatorStatusConditions(t *testing.T) {
 	}
 }
 
+// TestCheckAllIngressesAvailableWithPendingLoadBalancer verifies that the
+// clusteroperator follows an ingresscontroller's Available condition when
+// that condition is false because the ingresscontroller's load balancer has
+// not been provisioned yet.
+func TestCheckAllIngressesAvailableWithPendingLoadBalancer(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{
+			Replicas:          2,
+			AvailableReplicas: 2,
+		},
+	}
+	testCases := []struct {
+		description string
+		service     *corev1.Service
+		expected    bool
+	}{
+		{"lb pending", pendingLBService("default"), false},
+		{"lb service missing", nil, false},
+		{"lb provisioned", provisionedLBservice("default"), true},
+	}
+
+	for _, tc := range testCases {
+		ic := ingressController("default", operatorv1.LoadBalancerServiceStrategyType)
+		lbConditions := computeLoadBalancerStatus(ic, tc.service, nil)
+		ic.Status.Conditions = append(computeIngressStatusConditions(nil, deploy, lbConditions), lbConditions...)
+
+		allIngressesAvailable := checkAllIngressesAvailable([]operatorv1.IngressController{*ic})
+		if allIngressesAvailable != tc.expected {
+			t.Errorf("%q: expected checkAllIngressesAvailable to return %t, got %t", tc.description, tc.expected, allIngressesAvailable)
+		}
+
+		expectedStatus := configv1.ConditionFalse
+		if tc.expected {
+			expectedStatus = configv1.ConditionTrue
+		}
+		availableCondition := computeOperatorAvailableCondition(nil, allIngressesAvailable, nil)
+		if availableCondition.Status != expectedStatus {
+			t.Errorf("%q: expected clusteroperator Available=%s, got %s", tc.description, expectedStatus, availableCondition.Status)
+		}
+	}
+}
+
 func TestOperatorStatusesEqual(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -513,7 +561,7 @@ func TestComputeOperatorStatusVersions(t *testing.T) {
 			},
 		}
 
-		r := &reconciler{
+		r := &statusReconciler{
 			Config: Config{
 				OperatorReleaseVersion: tc.curVersions.operator,
 				IngressControllerImage: tc.curVersions.operand,
@@ -529,3 +577,139 @@ func TestComputeOperatorStatusVersions(t *testing.T) {
 		}
 	}
 }
+
+// TestStatusReconcile verifies that the status controller creates the
+// clusteroperator, reports the ingresscontrollers and the operand namespace
+// in its status, and does not update the status when nothing has changed.
+func TestStatusReconcile(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Conditions: []operatorv1.OperatorCondition{{
+				Type:   operatorv1.IngressControllerAvailableConditionType,
+				Status: operatorv1.ConditionTrue,
+			}},
+		},
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-ingress"}}
+	client := newFakeClient(ic, ns)
+	r := &statusReconciler{
+		Config: Config{
+			Namespace:              "openshift-ingress-operator",
+			OperandNamespace:       "openshift-ingress",
+			OperatorReleaseVersion: "4.2.0",
+			IngressControllerImage: "quay.io/openshift/router:latest",
+		},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: IngressClusterOperatorName}}
+	getConditions := func() map[configv1.ClusterStatusConditionType]configv1.ClusterOperatorStatusCondition {
+		co := &configv1.ClusterOperator{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: IngressClusterOperatorName}, co); err != nil {
+			t.Fatalf("failed to get clusteroperator: %v", err)
+		}
+		if co.Status.RelatedObjects[1].Name != "openshift-ingress" {
+			t.Errorf("expected the operand namespace in the related objects, got %v", co.Status.RelatedObjects)
+		}
+		conditions := map[configv1.ClusterStatusConditionType]configv1.ClusterOperatorStatusCondition{}
+		for _, c := range co.Status.Conditions {
+			conditions[c.Type] = c
+		}
+		return conditions
+	}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if client.calls["create"] != 1 || client.calls["status-update"] != 1 {
+		t.Errorf("expected the clusteroperator to be created and its status updated, got %v", client.calls)
+	}
+	conditions := getConditions()
+	co := &configv1.ClusterOperator{}
+	if err := client.Get(context.TODO(), request.NamespacedName, co); err != nil {
+		t.Fatal(err)
+	}
+	if len(co.Status.RelatedObjects) != 3 || co.Status.RelatedObjects[2].Name != "default" {
+		t.Errorf("expected the ingresscontroller in the related objects, got %v", co.Status.RelatedObjects)
+	}
+	if c := conditions[configv1.OperatorAvailable]; c.Status != configv1.ConditionTrue || c.Message != ingressesEqualConditionMessage {
+		t.Errorf("expected Available=True with message %q, got %s with message %q", ingressesEqualConditionMessage, c.Status, c.Message)
+	}
+	if c := conditions[configv1.OperatorDegraded]; c.Status != configv1.ConditionFalse || c.Message != "operand namespace exists" {
+		t.Errorf("expected Degraded=False with message %q, got %s with message %q", "operand namespace exists", c.Status, c.Message)
+	}
+
+	// The next sync drops the "Upgraded" messages from the Progressing
+	// condition because the reported versions are now current.  After that,
+	// the status is stable and is not updated again.
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	updates := client.calls["status-update"]
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if client.calls["status-update"] != updates {
+		t.Errorf("expected no further status updates, got %d", client.calls["status-update"]-updates)
+	}
+
+	// Deleting the operand namespace degrades the operator.  This used to
+	// panic because the namespace was dereferenced for the related objects.
+	if err := client.Delete(context.TODO(), ns); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	conditions = getConditions()
+	if c := conditions[configv1.OperatorDegraded]; c.Status != configv1.ConditionTrue || c.Reason != "NoNamespace" || c.Message != "operand namespace does not exist" {
+		t.Errorf("expected Degraded=True with reason NoNamespace and message %q, got %s with reason %q and message %q", "operand namespace does not exist", c.Status, c.Reason, c.Message)
+	}
+}
+
+// TestStatusReconcileUnmanagedIngressController verifies that an unmanaged
+// ingresscontroller is reported in the clusteroperator status but does not
+// affect the operator's availability.
+func TestStatusReconcileUnmanagedIngressController(t *testing.T) {
+	unmanaged := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-ingress"}}
+	client := newFakeClient(unmanaged, ns)
+	r := &statusReconciler{
+		Config: Config{
+			Namespace:                   "openshift-ingress-operator",
+			OperandNamespace:            "openshift-ingress",
+			UnmanagedIngressControllers: []string{"default"},
+		},
+		client: client,
+		cache:  &fakeCache{client: client},
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: IngressClusterOperatorName}}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	co := &configv1.ClusterOperator{}
+	if err := client.Get(context.TODO(), request.NamespacedName, co); err != nil {
+		t.Fatal(err)
+	}
+	if len(co.Status.RelatedObjects) != 3 || co.Status.RelatedObjects[2].Name != "default" {
+		t.Errorf("expected the unmanaged ingresscontroller in the related objects, got %v", co.Status.RelatedObjects)
+	}
+	expectMessage := ingressesEqualConditionMessage + "\nIngress controllers not managed by the operator: default."
+	for _, c := range co.Status.Conditions {
+		if c.Type != configv1.OperatorAvailable {
+			continue
+		}
+		if c.Status != configv1.ConditionTrue || c.Message != expectMessage {
+			t.Errorf("expected Available=True with message %q, got %s with message %q", expectMessage, c.Status, c.Message)
+		}
+	}
+}
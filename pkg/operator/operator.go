@@ -3,6 +3,8 @@ package operator
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
@@ -14,7 +16,9 @@ import (
 	certcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/certificate"
 	certpublishercontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/certificate-publisher"
 	operatorutil "github.com/openshift/cluster-ingress-operator/pkg/util"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
 
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -37,6 +41,23 @@ const (
 	// DefaultIngressController is the name of the default IngressController
 	// instance.
 	DefaultIngressController = "default"
+
+	// DefaultClientQPS is the default maximum sustained rate per second of
+	// the operator's requests to the API server.  It is higher than the
+	// client-go default of 5 so that the operator can keep up when many
+	// ingresscontrollers change at once.
+	DefaultClientQPS = 20
+
+	// DefaultClientBurst is the default number of requests that the
+	// operator may make to the API server at once before DefaultClientQPS
+	// applies.
+	DefaultClientBurst = 40
+
+	// shutdownTimeout is how long the operator waits, once it is told to
+	// stop, for the reconciliations in progress to finish.  It is shorter
+	// than the default termination grace period of 30 seconds so that the
+	// operator exits before it is killed.
+	shutdownTimeout = 20 * time.Second
 )
 
 func init() {
@@ -54,11 +75,27 @@ type Operator struct {
 	manager manager.Manager
 
 	namespace string
+
+	// manageDefaultIngressController indicates whether the operator
+	// creates the default ingresscontroller.
+	manageDefaultIngressController bool
+
+	// shutdown tracks the reconciliations in progress so that Start can
+	// let them finish when the operator stops.
+	shutdown *operatorcontroller.Shutdown
 }
 
-// New creates (but does not start) a new operator from configuration.
-func New(config operatorconfig.Config, dnsManager dns.Manager, kubeConfig *rest.Config) (*Operator, error) {
+// New creates (but does not start) a new operator from configuration.  The
+// operator keeps the given DNS proxy, through which the DNS managers call their
+// provider's API, up to date with the cluster proxy config.
+func New(config operatorconfig.Config, dnsManager dns.Manager, dnsManagerFactory dns.ManagerFactory, dnsProxy *dns.Proxy, kubeConfig *rest.Config) (*Operator, error) {
 	scheme := operatorclient.GetScheme()
+	// Apply the client rate limits to every client that the manager
+	// creates.  Higher limits speed up reconciliation at the cost of more
+	// load on the API server.
+	kubeConfig = rest.CopyConfig(kubeConfig)
+	kubeConfig.QPS = config.ClientQPS
+	kubeConfig.Burst = config.ClientBurst
 	// Set up an operator manager for the operator namespace.
 	mgr, err := manager.New(kubeConfig, manager.Options{
 		Namespace:      config.Namespace,
@@ -66,7 +103,7 @@ func New(config operatorconfig.Config, dnsManager dns.Manager, kubeConfig *rest.
 		MapperProvider: operatorutil.NewDynamicRESTMapper,
 		NewCache: cache.MultiNamespacedCacheBuilder([]string{
 			config.Namespace,
-			"openshift-ingress",
+			config.OperandNamespace,
 			operatorcontroller.GlobalMachineSpecifiedConfigNamespace,
 		}),
 		// Use a non-caching client everywhere. The default split client does not
@@ -84,23 +121,99 @@ func New(config operatorconfig.Config, dnsManager dns.Manager, kubeConfig *rest.
 		return nil, fmt.Errorf("failed to create operator manager: %v", err)
 	}
 
+	// Track the operator's health if the health endpoints are enabled.
+	var health *operatorcontroller.Health
+	if len(config.HealthBindAddress) != 0 {
+		health = operatorcontroller.NewHealth()
+		if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+			if mgr.GetCache().WaitForCacheSync(stop) {
+				health.SetCacheSynced()
+			}
+			return nil
+		})); err != nil {
+			return nil, fmt.Errorf("failed to add cache sync tracker: %v", err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/healthz", operatorcontroller.NewHealthHandler(health, false))
+		mux.Handle("/readyz", operatorcontroller.NewHealthHandler(health, true))
+		if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+			return serve("health", config.HealthBindAddress, mux, stop)
+		})); err != nil {
+			return nil, fmt.Errorf("failed to add health endpoint: %v", err)
+		}
+	}
+
+	topologyAwareHintsSupported, err := topologyAwareHintsSupported(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create and register the operator controller with the operator manager.
-	if _, err := operatorcontroller.New(mgr, operatorcontroller.Config{
+	shutdown := operatorcontroller.NewShutdown()
+	controllerConfig := operatorcontroller.Config{
 		Namespace:              config.Namespace,
+		OperandNamespace:       config.OperandNamespace,
 		DNSManager:             dnsManager,
 		IngressControllerImage: config.IngressControllerImage,
+		MonitoringNamespace:    config.MonitoringNamespace,
 		OperatorReleaseVersion: config.OperatorReleaseVersion,
-	}); err != nil {
+		OperandLabels:          config.OperandLabels,
+		OperandAnnotations:     config.OperandAnnotations,
+
+		MaxLoadBalancerIngressControllers: config.MaxLoadBalancerIngressControllers,
+		OperandEventQPS:                   config.OperandEventQPS,
+		OperandEventBurst:                 config.OperandEventBurst,
+		UnmanagedIngressControllers:       config.UnmanagedIngressControllers,
+		DegradedGracePeriod:               config.DegradedGracePeriod,
+		FinalizerTimeout:                  config.FinalizerTimeout,
+		ResyncPeriod:                      config.ResyncPeriod,
+		DegradeOnOperandVersionSkew:       config.DegradeOnOperandVersionSkew,
+		PreferClusterIngressDomain:        config.PreferClusterIngressDomain,
+		FailOnEmptyIngressDomain:          config.FailOnEmptyIngressDomain,
+		TopologyAwareHintsSupported:       topologyAwareHintsSupported,
+		DNSManagerFactory:                 dnsManagerFactory,
+		DNSProxy:                          dnsProxy,
+		Health:                            health,
+		Shutdown:                          shutdown,
+	}
+	if _, err := operatorcontroller.New(mgr, controllerConfig); err != nil {
 		return nil, fmt.Errorf("failed to create operator controller: %v", err)
 	}
 
+	// Set up the status controller
+	if _, err := operatorcontroller.NewStatusController(mgr, controllerConfig); err != nil {
+		return nil, fmt.Errorf("failed to create status controller: %v", err)
+	}
+
+	// Set up the ingress addresses and ingresscontroller resources endpoints
+	if len(config.IngressAddressesBindAddress) != 0 {
+		host, port, err := net.SplitHostPort(config.IngressAddressesBindAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ingress addresses bind address %q: %v", config.IngressAddressesBindAddress, err)
+		}
+		// The endpoints are not authenticated, so they listen only on
+		// the loopback interface unless the bind address names a host.
+		if len(host) == 0 {
+			host = "127.0.0.1"
+		}
+		addr := net.JoinHostPort(host, port)
+		mux := http.NewServeMux()
+		mux.Handle("/ingresscontrollers", operatorcontroller.NewIngressAddressesHandler(mgr.GetCache(), config.Namespace, config.OperandNamespace))
+		mux.Handle("/ingresscontrollers/", operatorcontroller.NewIngressControllerResourcesHandler(mgr.GetClient(), config.Namespace, config.OperandNamespace))
+		if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+			return serve("ingress addresses", addr, mux, stop)
+		})); err != nil {
+			return nil, fmt.Errorf("failed to add ingress addresses endpoint: %v", err)
+		}
+	}
+
 	// Set up the certificate controller
-	if _, err := certcontroller.New(mgr, config.Namespace); err != nil {
+	if _, err := certcontroller.New(mgr, config.Namespace, config.OperandNamespace); err != nil {
 		return nil, fmt.Errorf("failed to create cacert controller: %v", err)
 	}
 
 	// Set up the certificate-publisher controller
-	if _, err := certpublishercontroller.New(mgr, config.Namespace, "openshift-ingress"); err != nil {
+	if _, err := certpublishercontroller.New(mgr, config.Namespace, config.OperandNamespace); err != nil {
 		return nil, fmt.Errorf("failed to create certificate-publisher controller: %v", err)
 	}
 
@@ -110,11 +223,37 @@ func New(config operatorconfig.Config, dnsManager dns.Manager, kubeConfig *rest.
 		// should be refactored away.
 		client:    mgr.GetClient(),
 		namespace: config.Namespace,
+
+		manageDefaultIngressController: !slice.ContainsString(config.UnmanagedIngressControllers, DefaultIngressController),
+		shutdown:                       shutdown,
 	}, nil
 }
 
+// serve serves the given handler, which is described by name in log entries,
+// on the given address until the stop channel is closed.
+func serve(name, addr string, handler http.Handler, stop <-chan struct{}) error {
+	server := &http.Server{Addr: addr, Handler: handler}
+	errChan := make(chan error, 1)
+	go func() {
+		log.Info("serving "+name, "address", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+		close(errChan)
+	}()
+	select {
+	case <-stop:
+		return server.Shutdown(context.Background())
+	case err := <-errChan:
+		return err
+	}
+}
+
 // Start creates the default IngressController and then starts the operator
-// synchronously until a message is received on the stop channel.
+// synchronously until a message is received on the stop channel.  On stop, it
+// refuses new reconciliations and waits for those in progress to finish
+// before it returns, so that the operator does not exit in the middle of
+// updating an ingresscontroller or its status.
 // TODO: Move the default IngressController logic elsewhere.
 func (o *Operator) Start(stop <-chan struct{}) error {
 	// Periodicaly ensure the default controller exists.
@@ -137,6 +276,12 @@ func (o *Operator) Start(stop <-chan struct{}) error {
 	// Wait for the manager to exit or an explicit stop.
 	select {
 	case <-stop:
+		log.Info("stopping; waiting for reconciliations in progress to finish", "timeout", shutdownTimeout)
+		if !o.shutdown.Stop(shutdownTimeout) {
+			log.Info("timed out waiting for reconciliations in progress to finish")
+			return nil
+		}
+		log.Info("reconciliations in progress finished")
 		return nil
 	case err := <-errChan:
 		return err
@@ -144,8 +289,11 @@ func (o *Operator) Start(stop <-chan struct{}) error {
 }
 
 // ensureDefaultIngressController creates the default ingresscontroller if it
-// doesn't already exist.
+// doesn't already exist and the operator manages it.
 func (o *Operator) ensureDefaultIngressController() error {
+	if !o.manageDefaultIngressController {
+		return nil
+	}
 	ic := &operatorv1.IngressController{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      DefaultIngressController,
@@ -166,3 +314,22 @@ func (o *Operator) ensureDefaultIngressController() error {
 	log.Info("created default ingresscontroller", "namespace", ic.Namespace, "name", ic.Name)
 	return nil
 }
+
+// topologyAwareHintsSupported returns a Boolean indicating whether the cluster
+// supports topology-aware hints on services, which is the case if the API
+// server serves the endpointslice API version that carries the hints.  The
+// result is determined once at startup; the operator is restarted when the
+// cluster is upgraded.
+func topologyAwareHintsSupported(kubeConfig *rest.Config) (bool, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(kubeConfig)
+	if err != nil {
+		return false, fmt.Errorf("failed to create discovery client: %v", err)
+	}
+	if _, err := client.ServerResourcesForGroupVersion(operatorcontroller.TopologyAwareHintsGroupVersion); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to discover %s: %v", operatorcontroller.TopologyAwareHintsGroupVersion, err)
+	}
+	return true, nil
+}